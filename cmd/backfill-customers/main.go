@@ -0,0 +1,60 @@
+// backfill-customers creates a customer for every customer_ref that only exists on orders,
+// then validates the orders -> customers foreign key that was added as NOT VALID.
+//
+//	go run ./cmd/backfill-customers
+package main
+
+import (
+	"context"
+	"ecomApis/internals/customers"
+	"ecomApis/internals/env"
+	"ecomApis/internals/repo"
+	"log/slog"
+	"os"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func main() {
+	ctx := context.Background()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	slog.SetDefault(logger)
+
+	dsn := env.GetString("GOOSE_DBSTRING", "host=localhost port=5433 user=postgres password=postgres dbname=ecommerce_db sslmode=disable")
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		logger.Error("failed to connect to the database", "error", err)
+		os.Exit(1)
+	}
+	defer conn.Close(ctx)
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		os.Exit(1)
+	}
+	defer tx.Rollback(ctx)
+
+	service := customers.NewCustomerService(repo.New(tx))
+
+	created, err := service.BackfillFromOrders(ctx)
+	if err != nil {
+		logger.Error("backfill failed", "error", err)
+		os.Exit(1)
+	}
+
+	// every order has a customer now, so the foreign key can be enforced on old rows too
+	_, err = tx.Exec(ctx, "ALTER TABLE orders VALIDATE CONSTRAINT fk_orders_customer_ref")
+	if err != nil {
+		logger.Error("failed to validate fk_orders_customer_ref", "error", err)
+		os.Exit(1)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error("failed to commit backfill", "error", err)
+		os.Exit(1)
+	}
+
+	logger.Info("customer backfill complete", "customers_created", created)
+}
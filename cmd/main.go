@@ -2,11 +2,23 @@ package main
 
 import (
 	"context"
+	"ecomApis/internals/auth"
+	"ecomApis/internals/carts"
 	"ecomApis/internals/env"
+	"ecomApis/internals/idempotency"
+	"ecomApis/internals/media"
+	"ecomApis/internals/money"
+	"ecomApis/internals/orders"
+	"ecomApis/internals/outbox"
+	"ecomApis/internals/payments"
+	"ecomApis/internals/pricing"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/tax"
+	"ecomApis/internals/webhooks"
 	"log/slog"
 	"os"
-
-	"github.com/jackc/pgx/v5"
+	"strings"
+	"time"
 )
 
 func main() {
@@ -15,26 +27,147 @@ func main() {
 	appconfig := appconfig{
 		Address: env.GetString("APP_ADDRESS", ":8080"),
 		DB: dbConfig{
-			DatabaseURL: env.GetString("GOOSE_DBSTRING", "host=localhost port=5433 user=postgres password=postgres dbname=ecommerce_db sslmode=disable"),
+			DatabaseURL:       env.GetString("GOOSE_DBSTRING", "host=localhost port=5433 user=postgres password=postgres dbname=ecommerce_db sslmode=disable"),
+			MaxConns:          int32(env.GetInt("DB_MAX_CONNS", 10)),
+			MinConns:          int32(env.GetInt("DB_MIN_CONNS", 2)),
+			MaxConnLifetime:   env.GetDuration("DB_MAX_CONN_LIFETIME", time.Hour),
+			MaxConnIdleTime:   env.GetDuration("DB_MAX_CONN_IDLE_TIME", 30*time.Minute),
+			HealthCheckPeriod: env.GetDuration("DB_HEALTH_CHECK_PERIOD", time.Minute),
+		},
+		Orders: orders.Config{
+			CancelCutoffStatus: env.GetString("ORDER_CANCEL_CUTOFF_STATUS", orders.StatusPaid),
+			ReservationTTL:     env.GetDuration("ORDER_RESERVATION_TTL", 30*time.Minute),
+		},
+		Pricing: pricing.Config{
+			BaseCurrency: env.GetString("BASE_CURRENCY", "USD"),
+			Rounding: money.Rounding{
+				Mode:      money.RoundingMode(env.GetString("PRICE_ROUNDING_MODE", string(money.RoundHalfUp))),
+				Increment: int64(env.GetInt("PRICE_ROUNDING_INCREMENT", 1)),
+			},
+		},
+		Tax: tax.Config{
+			PricesIncludeTax: env.GetBool("TAX_PRICES_INCLUDE_TAX", false),
+			Rounding: money.Rounding{
+				Mode: money.RoundingMode(env.GetString("TAX_ROUNDING_MODE", string(money.RoundHalfUp))),
+			},
+			DefaultJurisdiction: env.GetString("TAX_DEFAULT_JURISDICTION", ""),
+		},
+		Payments: payments.Config{
+			Gateway:       env.GetString("PAYMENT_GATEWAY", payments.FakeGatewayName),
+			WebhookSecret: env.GetString("PAYMENT_WEBHOOK_SECRET", ""),
+		},
+		Media: media.Config{
+			Store:    env.GetString("MEDIA_STORE", media.LocalStoreName),
+			LocalDir: env.GetString("MEDIA_LOCAL_DIR", "media"),
+			BaseURL:  env.GetString("MEDIA_BASE_URL", "/media"),
+			S3: media.S3Config{
+				Endpoint:  env.GetString("MEDIA_S3_ENDPOINT", ""),
+				Region:    env.GetString("MEDIA_S3_REGION", "us-east-1"),
+				Bucket:    env.GetString("MEDIA_S3_BUCKET", ""),
+				AccessKey: env.GetString("MEDIA_S3_ACCESS_KEY", ""),
+				SecretKey: env.GetString("MEDIA_S3_SECRET_KEY", ""),
+				PublicURL: env.GetString("MEDIA_S3_PUBLIC_URL", ""),
+			},
+			MaxUploadBytes: int64(env.GetInt("MEDIA_MAX_UPLOAD_BYTES", 10<<20)),
+			ThumbnailSize:  env.GetInt("MEDIA_THUMBNAIL_SIZE", 320),
+		},
+		Outbox: outbox.Config{
+			Publisher:   env.GetString("OUTBOX_PUBLISHER", outbox.MemoryPublisherName),
+			TopicPrefix: env.GetString("OUTBOX_TOPIC_PREFIX", "ecom"),
+			NATS: outbox.NATSConfig{
+				URL:    env.GetString("OUTBOX_NATS_URL", "nats://localhost:4222"),
+				Stream: env.GetString("OUTBOX_NATS_STREAM", "ECOM_EVENTS"),
+			},
+			Kafka: outbox.KafkaConfig{
+				// a comma separated list of host:port
+				Brokers: strings.Split(env.GetString("OUTBOX_KAFKA_BROKERS", "localhost:9092"), ","),
+			},
+			BatchSize:      env.GetInt("OUTBOX_BATCH_SIZE", 100),
+			PollInterval:   env.GetDuration("OUTBOX_POLL_INTERVAL", time.Second),
+			PublishTimeout: env.GetDuration("OUTBOX_PUBLISH_TIMEOUT", 10*time.Second),
+			Retention:      env.GetDuration("OUTBOX_RETENTION", 7*24*time.Hour),
 		},
+		Webhooks: webhooks.Config{
+			Timeout:              env.GetDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+			MaxAttempts:          env.GetInt("WEBHOOK_MAX_ATTEMPTS", 8),
+			RetryBase:            env.GetDuration("WEBHOOK_RETRY_BASE", 30*time.Second),
+			Concurrency:          env.GetInt("WEBHOOK_CONCURRENCY", 8),
+			PollInterval:         env.GetDuration("WEBHOOK_POLL_INTERVAL", time.Second),
+			AllowPrivateNetworks: env.GetBool("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false),
+		},
+		IdempotencyKeyTTL:  env.GetDuration("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
+		CartTTL:            env.GetDuration("CART_TTL", 7*24*time.Hour),
+		BulkRequestTimeout: env.GetDuration("BULK_REQUEST_TIMEOUT", 10*time.Minute),
+		Auth: auth.Config{
+			HS256Secret: env.GetString("AUTH_JWT_HS256_SECRET", ""),
+			Issuer:      env.GetString("AUTH_JWT_ISSUER", ""),
+			Audience:    env.GetString("AUTH_JWT_AUDIENCE", ""),
+		},
+	}
+
+	// the RS256 public key is read from a PEM file
+	if path := env.GetString("AUTH_JWT_RS256_PUBLIC_KEY_FILE", ""); path != "" {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			panic(err)
+		}
+		appconfig.Auth.RS256PublicKeyPEM = string(pem)
 	}
 
 	// use slog for structured logging
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	slog.SetDefault(logger)
 
-	// setup the db
-	conn, err := pgx.Connect(ctx, appconfig.DB.DatabaseURL)
+	// setup the db pool
+	pool, err := newDBPool(ctx, appconfig.DB)
+	if err != nil {
+		panic(err)
+	}
+	defer pool.Close()
+
+	logger.Info("Connected to the database successfully",
+		"max_conns", appconfig.DB.MaxConns,
+		"min_conns", appconfig.DB.MinConns,
+	)
+
+	authService, err := auth.NewService(repo.New(pool), appconfig.Auth)
+	if err != nil {
+		panic(err)
+	}
+
+	gateway, err := payments.NewGateway(appconfig.Payments)
+	if err != nil {
+		panic(err)
+	}
+
+	blobStore, err := media.NewBlobStore(appconfig.Media)
+	if err != nil {
+		panic(err)
+	}
+
+	publisher, err := outbox.NewPublisher(ctx, appconfig.Outbox)
 	if err != nil {
 		panic(err)
 	}
-	defer conn.Close(ctx)
+	defer publisher.Close()
 
-	logger.Info("Connected to the database successfully")
+	// background jobs
+	go outbox.NewRelay(repo.New(pool), pool, publisher, appconfig.Outbox).Run(ctx)
+	// the dispatcher queues the webhook deliveries of each event on its own
+	go webhooks.NewService(repo.New(pool), pool, appconfig.Webhooks).RunDispatcher(ctx)
+	go idempotency.NewService(repo.New(pool), pool, appconfig.IdempotencyKeyTTL).RunCleanup(ctx, time.Hour)
+	// the expiry sweeper never checks out, so it needs no order service
+	go carts.NewCartService(repo.New(pool), nil, appconfig.CartTTL).RunExpiry(ctx, env.GetDuration("CART_EXPIRY_INTERVAL", 15*time.Minute))
+	// the reservation sweeper never prices orders, so it needs no pricing service or tax calculator
+	go orders.NewOrderService(repo.New(pool), pool, appconfig.Orders, nil, nil).RunReservationSweeper(ctx, env.GetDuration("ORDER_RESERVATION_SWEEP_INTERVAL", time.Minute))
+	go payments.NewPaymentService(repo.New(pool), pool, gateway).RunReconciler(ctx, env.GetDuration("PAYMENT_RECONCILE_INTERVAL", time.Minute))
 
 	app := &application{
-		config: appconfig,
-		db:     conn,
+		config:   appconfig,
+		db:       pool,
+		auth:     authService,
+		payments: gateway,
+		media:    blobStore,
 	}
 
 	// start the server
@@ -0,0 +1,76 @@
+// reconcile-inventory compares products.stock and product_variants.stock with the sum of the
+// inventory ledger and reports every product or variant that drifted. With -apply the stock
+// columns are rebuilt from the ledger.
+//
+//	go run ./cmd/reconcile-inventory [-apply]
+package main
+
+import (
+	"context"
+	"ecomApis/internals/env"
+	"ecomApis/internals/inventory"
+	"ecomApis/internals/repo"
+	"flag"
+	"log/slog"
+	"os"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func main() {
+	apply := flag.Bool("apply", false, "rewrite the stock columns from the ledger instead of only reporting drift")
+	flag.Parse()
+
+	ctx := context.Background()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	slog.SetDefault(logger)
+
+	dsn := env.GetString("GOOSE_DBSTRING", "host=localhost port=5433 user=postgres password=postgres dbname=ecommerce_db sslmode=disable")
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		logger.Error("failed to connect to the database", "error", err)
+		os.Exit(1)
+	}
+	defer conn.Close(ctx)
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		logger.Error("failed to begin transaction", "error", err)
+		os.Exit(1)
+	}
+	defer tx.Rollback(ctx)
+
+	// wait for in-flight stock changes and hold off new ones so stock and ledger are read at the same point
+	_, err = tx.Exec(ctx, "LOCK TABLE products, product_variants, inventory_movements IN SHARE ROW EXCLUSIVE MODE")
+	if err != nil {
+		logger.Error("failed to lock inventory tables", "error", err)
+		os.Exit(1)
+	}
+
+	drift, err := inventory.NewService(repo.New(tx)).Reconcile(ctx, *apply)
+	if err != nil {
+		logger.Error("reconcile failed", "error", err)
+		os.Exit(1)
+	}
+
+	for _, d := range drift {
+		attrs := []any{"product_id", d.ProductID}
+		if d.VariantID != nil {
+			attrs = append(attrs, "variant_id", *d.VariantID)
+		}
+		attrs = append(attrs,
+			"stock", d.Stock,
+			"ledger_stock", d.LedgerStock,
+			"difference", d.Difference,
+		)
+		logger.Warn("stock drift", attrs...)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error("failed to commit reconcile", "error", err)
+		os.Exit(1)
+	}
+
+	logger.Info("inventory reconcile complete", "drifted_products", len(drift), "applied", *apply)
+}
@@ -8,12 +8,28 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/cors"
 
+	"ecomApis/internals/auth"
+	"ecomApis/internals/carts"
+	"ecomApis/internals/categories"
+	"ecomApis/internals/customers"
+	"ecomApis/internals/idempotency"
+	"ecomApis/internals/inventory"
+	"ecomApis/internals/media"
 	"ecomApis/internals/orders"
+	"ecomApis/internals/outbox"
+	"ecomApis/internals/payments"
+	"ecomApis/internals/pricing"
 	"ecomApis/internals/products"
+	"ecomApis/internals/promotions"
 	"ecomApis/internals/repo"
+	"ecomApis/internals/returns"
+	"ecomApis/internals/shipping"
+	"ecomApis/internals/tax"
+	"ecomApis/internals/utils"
+	"ecomApis/internals/webhooks"
 )
 
 func healthCheck(w http.ResponseWriter, r *http.Request) {
@@ -22,6 +38,26 @@ func healthCheck(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// dbStats reports the connection pool usage so we can see if the pool is saturated
+func (app *application) dbStats(w http.ResponseWriter, r *http.Request) {
+	stats := app.db.Stat()
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"total_conns":                stats.TotalConns(),
+		"acquired_conns":             stats.AcquiredConns(),
+		"idle_conns":                 stats.IdleConns(),
+		"constructing_conns":         stats.ConstructingConns(),
+		"max_conns":                  stats.MaxConns(),
+		"acquire_count":              stats.AcquireCount(),
+		"acquire_duration_ms":        stats.AcquireDuration().Milliseconds(),
+		"empty_acquire_count":        stats.EmptyAcquireCount(),
+		"canceled_acquire_count":     stats.CanceledAcquireCount(),
+		"new_conns_count":            stats.NewConnsCount(),
+		"max_lifetime_destroy_count": stats.MaxLifetimeDestroyCount(),
+		"max_idle_destroy_count":     stats.MaxIdleDestroyCount(),
+	})
+}
+
 func (app *application) mount() http.Handler {
 	r := chi.NewRouter()
 
@@ -34,43 +70,294 @@ func (app *application) mount() http.Handler {
 	// cors
 	r.Use(cors.New(cors.Options{
 		AllowedOrigins: []string{"*"},
-		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE"},
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		AllowedHeaders: []string{"Accept", "Content-Type", "Authorization", auth.HeaderAPIKey, idempotency.HeaderKey},
+		ExposedHeaders: []string{idempotency.HeaderReplayed},
 	}).Handler)
 
-	// timeout context
-	r.Use(middleware.Timeout(60 * time.Second))
+	// who is calling, routes below decide what they may do
+	r.Use(app.auth.Authenticate)
+	adminOnly := auth.RequireRole(auth.RoleAdmin)
 
-	// create a healthcheck endpoint
-	r.Get("/health", healthCheck)
+	// imports and exports move whole catalogs, the routes mounted on bulk get
+	// BULK_REQUEST_TIMEOUT instead of the minute every other request gets
+	bulk := r.With(adminOnly, longRequest(app.config.BulkRequestTimeout))
 
-	// product routes
-	productService := products.NewProductService(repo.New(app.db))
-	productHandler := products.NewProductHandler(productService)
+	r.Group(func(r chi.Router) {
+		// timeout context
+		r.Use(middleware.Timeout(60 * time.Second))
 
-	r.Route("/products", func(r chi.Router) {
-		r.Post("/", productHandler.CreateProduct)
-		r.Get("/", productHandler.ListAllProducts)
-		r.Get("/{id}", productHandler.GetProductById)
-		r.Delete("/{id}", productHandler.DeleteProduct)
+		// create a healthcheck endpoint
+		r.Get("/health", healthCheck)
+		r.With(adminOnly).Get("/health/db", app.dbStats)
 
-	})
+		// auth routes
+		authHandler := auth.NewHandler(app.auth)
+
+		r.Route("/auth", func(r chi.Router) {
+			r.Get("/me", authHandler.Me)
+
+			r.Group(func(r chi.Router) {
+				r.Use(adminOnly)
+				r.Post("/api-keys", authHandler.CreateAPIKey)
+				r.Get("/api-keys", authHandler.ListAPIKeys)
+				r.Delete("/api-keys/{id}", authHandler.RevokeAPIKey)
+			})
+		})
+
+		// idempotent POSTs
+		idempotencyService := idempotency.NewService(repo.New(app.db), app.db, app.config.IdempotencyKeyTTL)
+
+		// prices in other currencies and exchange rates
+		pricingService := pricing.NewService(repo.New(app.db), app.config.Pricing)
+		pricingHandler := pricing.NewHandler(pricingService)
+
+		r.Route("/exchange-rates", func(r chi.Router) {
+			r.Get("/", pricingHandler.ListExchangeRates)
+
+			r.Group(func(r chi.Router) {
+				r.Use(adminOnly)
+				r.Put("/{base}/{quote}", pricingHandler.SetExchangeRate)
+				r.Delete("/{base}/{quote}", pricingHandler.DeleteExchangeRate)
+			})
+		})
+
+		// tax rates per jurisdiction and tax class
+		taxHandler := tax.NewHandler(tax.NewService(repo.New(app.db)))
+
+		r.Route("/tax-rates", func(r chi.Router) {
+			r.Get("/", taxHandler.ListTaxRates)
+
+			r.Group(func(r chi.Router) {
+				r.Use(adminOnly)
+				r.Put("/{jurisdiction}/{class}", taxHandler.SetTaxRate)
+				r.Delete("/{jurisdiction}/{class}", taxHandler.DeleteTaxRate)
+			})
+		})
+
+		// product routes
+		mediaService := media.NewService(repo.New(app.db), app.db, app.media, app.config.Media)
+		mediaHandler := media.NewHandler(mediaService)
+		productService := products.NewProductService(repo.New(app.db), app.db, pricingService, mediaService)
+		productHandler := products.NewProductHandler(productService)
+		inventoryHandler := inventory.NewHandler(inventory.NewService(repo.New(app.db)))
+
+		categoryHandler := categories.NewHandler(categories.NewService(repo.New(app.db), app.db))
+
+		bulk.Post("/products/import", productHandler.ImportProducts)
+		bulk.Get("/products/export", productHandler.ExportProducts)
+
+		r.Route("/products", func(r chi.Router) {
+			// the catalog is public to browse
+			r.Get("/", productHandler.ListAllProducts)
+			r.Get("/search", productHandler.SearchProducts)
+			r.Get("/{id}", productHandler.GetProductById)
+			r.Get("/{id}/prices", pricingHandler.ListProductPrices)
+			r.Get("/{id}/categories", categoryHandler.ListProductCategories)
+			r.Get("/{id}/variants", productHandler.ListVariants)
+			r.Get("/{id}/images", mediaHandler.ListImages)
+
+			// only admins manage it
+			r.Group(func(r chi.Router) {
+				r.Use(adminOnly)
+				r.With(idempotencyService.Middleware("products.create")).Post("/", productHandler.CreateProduct)
+				r.Put("/{id}", productHandler.UpdateProduct)
+				r.Patch("/{id}", productHandler.PatchProduct)
+				r.Post("/{id}/stock", productHandler.AdjustStock)
+				r.Get("/{id}/inventory/movements", inventoryHandler.ListMovements)
+				r.Put("/{id}/prices/{currency}", pricingHandler.SetProductPrice)
+				r.Delete("/{id}/prices/{currency}", pricingHandler.DeleteProductPrice)
+				r.Put("/{id}/categories", categoryHandler.SetProductCategories)
+				r.Post("/{id}/variants", productHandler.CreateVariant)
+				r.Put("/{id}/variants/{variantId}", productHandler.UpdateVariant)
+				r.Delete("/{id}/variants/{variantId}", productHandler.DeleteVariant)
+				r.Post("/{id}/variants/{variantId}/stock", productHandler.AdjustVariantStock)
+				r.Post("/{id}/images", mediaHandler.UploadImage)
+				r.Put("/{id}/images/order", mediaHandler.ReorderImages)
+				r.Patch("/{id}/images/{imageId}", mediaHandler.UpdateImage)
+				r.Delete("/{id}/images/{imageId}", mediaHandler.DeleteImage)
+				r.Delete("/{id}", productHandler.DeleteProduct)
+			})
+		})
+
+		// uploaded files, for stores that are not served from their own URLs
+		r.Get("/media/*", mediaHandler.ServeFile)
+
+		// option types such as size or colour that variants are described by
+		r.Route("/option-types", func(r chi.Router) {
+			r.Get("/", productHandler.ListOptionTypes)
+
+			r.Group(func(r chi.Router) {
+				r.Use(adminOnly)
+				r.Post("/", productHandler.CreateOptionType)
+				r.Delete("/{id}", productHandler.DeleteOptionType)
+			})
+		})
+
+		// the category tree
+		r.Route("/categories", func(r chi.Router) {
+			r.Get("/", categoryHandler.Tree)
+			r.Get("/{id}", categoryHandler.GetCategory)
+			r.Get("/{id}/products", productHandler.ListCategoryProducts)
 
-	// order routes
-	orderService := orders.NewOrderService(repo.New(app.db), app.db)
-	orderHandler := orders.NewOrderHandler(orderService)
+			r.Group(func(r chi.Router) {
+				r.Use(adminOnly)
+				r.Post("/", categoryHandler.CreateCategory)
+				r.Put("/{id}", categoryHandler.UpdateCategory)
+				r.Post("/{id}/move", categoryHandler.MoveCategory)
+				r.Delete("/{id}", categoryHandler.DeleteCategory)
+			})
+		})
 
-	r.Route("/orders", func(r chi.Router) {
-		r.Post("/", orderHandler.CreateOrder)
-		r.Get("/customer/{customerRef}", orderHandler.GetOrdersByCustomerRef)
-		r.Get("/", orderHandler.GetAllOrders)
-		r.Get("/{id}", orderHandler.GetOrderByID)
-		r.Delete("/{id}", orderHandler.DeleteOrder)
+		// customer routes
+		customerService := customers.NewCustomerService(repo.New(app.db))
+		customerHandler := customers.NewCustomerHandler(customerService)
+
+		r.Route("/customers", func(r chi.Router) {
+			// customers can read and update their own record
+			r.Use(auth.RequireRole(auth.RoleAdmin, auth.RoleCustomer))
+			r.Get("/{id}", customerHandler.GetCustomer)
+			r.Put("/{id}", customerHandler.UpdateCustomer)
+
+			r.Group(func(r chi.Router) {
+				r.Use(adminOnly)
+				r.Post("/", customerHandler.CreateCustomer)
+				r.Get("/", customerHandler.ListCustomers)
+				r.Delete("/{id}", customerHandler.DeleteCustomer)
+			})
+		})
+
+		// shipping methods and quotes
+		shippingHandler := shipping.NewHandler(shipping.NewService(repo.New(app.db), app.db, pricingService))
+
+		r.Route("/shipping", func(r chi.Router) {
+			r.Get("/quote", shippingHandler.Quote)
+			r.Get("/methods", shippingHandler.ListMethods)
+			r.Get("/methods/{id}", shippingHandler.GetMethod)
+
+			r.Group(func(r chi.Router) {
+				r.Use(adminOnly)
+				r.Post("/methods", shippingHandler.CreateMethod)
+				r.Post("/methods/{id}/deactivate", shippingHandler.DeactivateMethod)
+			})
+		})
+
+		// promotions and coupon codes
+		promotionHandler := promotions.NewPromotionHandler(promotions.NewPromotionService(repo.New(app.db), app.db))
+
+		r.Route("/promotions", func(r chi.Router) {
+			r.Use(adminOnly)
+			r.Post("/", promotionHandler.CreatePromotion)
+			r.Get("/", promotionHandler.ListPromotions)
+			r.Get("/{id}", promotionHandler.GetPromotion)
+			r.Post("/{id}/deactivate", promotionHandler.DeactivatePromotion)
+		})
+
+		// order routes
+		orderService := orders.NewOrderService(repo.New(app.db), app.db, app.config.Orders, pricingService, tax.NewTableCalculator(repo.New(app.db), app.config.Tax))
+		orderHandler := orders.NewOrderHandler(orderService)
+		paymentService := payments.NewPaymentService(repo.New(app.db), app.db, app.payments)
+		paymentHandler := payments.NewPaymentHandler(paymentService)
+		returnHandler := returns.NewHandler(returns.NewService(repo.New(app.db), app.db, paymentService))
+
+		r.Route("/orders", func(r chi.Router) {
+			// customers are limited to their own orders inside the handlers
+			r.Use(auth.RequireRole(auth.RoleAdmin, auth.RoleCustomer))
+			r.With(idempotencyService.Middleware("orders.create")).Post("/", orderHandler.CreateOrder)
+			r.Get("/customer/{customerRef}", orderHandler.GetOrdersByCustomerRef)
+			r.Get("/{id}", orderHandler.GetOrderByID)
+			r.Post("/{id}/cancel", orderHandler.CancelOrder)
+			r.With(idempotencyService.Middleware("payments.create")).Post("/{id}/payments", paymentHandler.CreatePayment)
+			r.Get("/{id}/payments", paymentHandler.ListOrderPayments)
+			r.Post("/{id}/returns", returnHandler.CreateReturn)
+			r.Get("/{id}/returns", returnHandler.ListOrderReturns)
+
+			r.Group(func(r chi.Router) {
+				r.Use(adminOnly)
+				r.Get("/", orderHandler.GetAllOrders)
+				r.Post("/{id}/transitions", orderHandler.TransitionOrder)
+				r.Delete("/{id}", orderHandler.DeleteOrder)
+			})
+		})
+
+		r.Route("/payments", func(r chi.Router) {
+			r.Use(adminOnly)
+			r.Get("/{id}", paymentHandler.GetPayment)
+			r.Post("/{id}/capture", paymentHandler.CapturePayment)
+			r.Post("/{id}/void", paymentHandler.VoidPayment)
+			r.Post("/{id}/refund", paymentHandler.RefundPayment)
+		})
+
+		r.Route("/returns", func(r chi.Router) {
+			// customers can follow their own returns
+			r.Use(auth.RequireRole(auth.RoleAdmin, auth.RoleCustomer))
+			r.Get("/{id}", returnHandler.GetReturn)
+
+			r.Group(func(r chi.Router) {
+				r.Use(adminOnly)
+				r.Get("/", returnHandler.ListReturns)
+				r.Post("/{id}/approve", returnHandler.ApproveReturn)
+				r.Post("/{id}/reject", returnHandler.RejectReturn)
+				r.Post("/{id}/receive", returnHandler.ReceiveReturn)
+				r.Post("/{id}/refund", returnHandler.RetryRefund)
+			})
+		})
+
+		// webhook routes
+		webhookHandler := webhooks.NewHandler(webhooks.NewService(repo.New(app.db), app.db, app.config.Webhooks))
+
+		r.Route("/webhooks", func(r chi.Router) {
+			// gateway callbacks are authenticated by their signature, not by a caller
+			r.Post("/payments", paymentHandler.HandleWebhook)
+
+			// customers are limited to their own subscriptions inside the handlers
+			r.Group(func(r chi.Router) {
+				r.Use(auth.RequireRole(auth.RoleAdmin, auth.RoleCustomer))
+				r.Post("/", webhookHandler.CreateWebhook)
+				r.Get("/", webhookHandler.ListWebhooks)
+				r.Get("/{id}", webhookHandler.GetWebhook)
+				r.Patch("/{id}", webhookHandler.UpdateWebhook)
+				r.Delete("/{id}", webhookHandler.DeleteWebhook)
+				r.Get("/{id}/deliveries", webhookHandler.ListDeliveries)
+				r.Get("/{id}/deliveries/{deliveryId}", webhookHandler.GetDelivery)
+				r.Post("/{id}/deliveries/{deliveryId}/redeliver", webhookHandler.Redeliver)
+			})
+		})
+
+		// cart routes
+		cartService := carts.NewCartService(repo.New(app.db), orderService, app.config.CartTTL)
+		cartHandler := carts.NewCartHandler(cartService)
+
+		r.Route("/carts", func(r chi.Router) {
+			// customers are limited to their own carts inside the handlers
+			r.Use(auth.RequireRole(auth.RoleAdmin, auth.RoleCustomer))
+			r.Post("/", cartHandler.CreateCart)
+			r.Get("/{id}", cartHandler.GetCart)
+			r.Post("/{id}/items", cartHandler.AddItem)
+			r.Put("/{id}/items/{productId}", cartHandler.UpdateItem)
+			r.Delete("/{id}/items/{productId}", cartHandler.RemoveItem)
+			r.With(idempotencyService.Middleware("carts.checkout")).Post("/{id}/checkout", cartHandler.Checkout)
+		})
 	})
 
 	// other routes...
 	return r
 }
 
+// longRequest lets a request run for d, also past the server's read and write timeouts
+func longRequest(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		next = middleware.Timeout(d)(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			deadline := time.Now().Add(d)
+			rc := http.NewResponseController(w)
+			rc.SetReadDeadline(deadline)
+			rc.SetWriteDeadline(deadline)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func (app *application) run(h http.Handler) error {
 	srv := &http.Server{
 		Addr:         app.config.Address,
@@ -87,14 +374,34 @@ func (app *application) run(h http.Handler) error {
 }
 
 type application struct {
-	config appconfig
-	db     *pgx.Conn
+	config   appconfig
+	db       *pgxpool.Pool
+	auth     *auth.Service
+	payments payments.Gateway
+	media    media.BlobStore
 }
 
 type appconfig struct {
-	Address string
-	DB      dbConfig
+	Address  string
+	DB       dbConfig
+	Orders   orders.Config
+	Auth     auth.Config
+	Pricing  pricing.Config
+	Tax      tax.Config
+	Payments payments.Config
+	Media    media.Config
+	Outbox   outbox.Config
+	Webhooks webhooks.Config
+
+	IdempotencyKeyTTL  time.Duration
+	CartTTL            time.Duration
+	BulkRequestTimeout time.Duration
 }
 type dbConfig struct {
-	DatabaseURL string
+	DatabaseURL       string
+	MaxConns          int32
+	MinConns          int32
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
 }
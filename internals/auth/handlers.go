@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"ecomApis/internals/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(s *Service) *Handler {
+	return &Handler{
+		service: s,
+	}
+}
+
+// Me returns the principal the request was authenticated as
+func (h *Handler) Me(w http.ResponseWriter, r *http.Request) {
+	p, ok := FromContext(r.Context())
+	if !ok {
+		WriteError(w, &utils.AuthenticationError{Message: "authentication required"})
+		return
+	}
+	utils.WriteJSON(w, http.StatusOK, p)
+}
+
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req CreateAPIKeyRequest
+	err := utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	record, key, err := h.service.CreateAPIKey(ctx, req)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"api_key": record,
+		// only returned once, it cannot be recovered later
+		"key": key,
+	}
+	utils.WriteJSON(w, http.StatusCreated, response)
+}
+
+func (h *Handler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	keys, err := h.service.ListAPIKeys(ctx)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, keys)
+}
+
+func (h *Handler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idParam := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid api key id"})
+		return
+	}
+
+	key, err := h.service.RevokeAPIKey(ctx, id)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, key)
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"ecomApis/internals/utils"
+	"net/http"
+	"strings"
+)
+
+const HeaderAPIKey = "X-API-Key"
+
+type contextKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the authenticated caller, ok is false for anonymous requests
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(Principal)
+	return p, ok
+}
+
+// Authenticate reads an "Authorization: Bearer <jwt>" or "X-API-Key" header and stores the principal in the context.
+// Requests without credentials continue anonymously, RequireRole decides if that is enough.
+// Credentials that are present but wrong are always rejected
+func (s *Service) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			p   Principal
+			err error
+		)
+
+		if key := r.Header.Get(HeaderAPIKey); key != "" {
+			p, err = s.AuthenticateAPIKey(r.Context(), key)
+		} else if header := r.Header.Get("Authorization"); header != "" {
+			token, found := strings.CutPrefix(header, "Bearer ")
+			if !found || token == "" {
+				err = &utils.AuthenticationError{Message: "authorization header must be 'Bearer <token>'"}
+			} else {
+				p, err = s.AuthenticateJWT(token)
+			}
+		} else {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), p)))
+	})
+}
+
+// RequireRole only lets authenticated callers with one of the given roles through
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := FromContext(r.Context())
+			if !ok {
+				WriteError(w, &utils.AuthenticationError{Message: "authentication required"})
+				return
+			}
+
+			for _, role := range roles {
+				if p.Role == role {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			WriteError(w, &utils.AuthorizationError{Action: r.Method + " " + r.URL.Path})
+		})
+	}
+}
+
+// WriteError maps authentication and authorization failures to 401 and 403
+func WriteError(w http.ResponseWriter, err error) {
+	switch e := err.(type) {
+	case *utils.AuthenticationError:
+		w.Header().Set("WWW-Authenticate", `Bearer realm="ecommerce-api"`)
+		utils.WriteJSON(w, http.StatusUnauthorized, map[string]string{"error": e.Error()})
+	case *utils.AuthorizationError:
+		utils.WriteJSON(w, http.StatusForbidden, map[string]string{"error": e.Error()})
+	case *utils.ValidationError:
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": e.Error()})
+	case *utils.NotFoundError:
+		utils.WriteJSON(w, http.StatusNotFound, map[string]string{"error": e.Error()})
+	case *utils.DatabaseError:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": e.Error()})
+	default:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+}
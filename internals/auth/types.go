@@ -0,0 +1,47 @@
+package auth
+
+// roles
+const (
+	RoleAdmin    = "admin"
+	RoleCustomer = "customer"
+)
+
+// how the caller proved who they are
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	Subject     string `json:"subject"`
+	Role        string `json:"role"`
+	CustomerRef string `json:"customer_ref,omitempty"`
+	Method      string `json:"method"`
+}
+
+func (p Principal) IsAdmin() bool {
+	return p.Role == RoleAdmin
+}
+
+// CanAccessCustomer reports whether the principal may see data that belongs to customerRef
+func (p Principal) CanAccessCustomer(customerRef string) bool {
+	return p.IsAdmin() || (p.Role == RoleCustomer && p.CustomerRef != "" && p.CustomerRef == customerRef)
+}
+
+// Config holds the JWT verification settings. A signing method is only accepted when its key is set
+type Config struct {
+	// HS256Secret is the shared secret for HS256 tokens
+	HS256Secret string
+	// RS256PublicKeyPEM is the PEM encoded public key for RS256 tokens
+	RS256PublicKeyPEM string
+	// Issuer and Audience are checked when set
+	Issuer   string
+	Audience string
+}
+
+type CreateAPIKeyRequest struct {
+	Name        string `json:"name"`
+	Role        string `json:"role"`
+	CustomerRef string `json:"customer_ref"`
+}
@@ -0,0 +1,244 @@
+package carts
+
+import (
+	"ecomApis/internals/auth"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type CartHandler struct {
+	service *CartService
+}
+
+func NewCartHandler(s *CartService) *CartHandler {
+	return &CartHandler{
+		service: s,
+	}
+}
+
+func (h *CartHandler) CreateCart(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req CreateCartRequest
+	err := utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	// customers always get a cart of their own
+	p, _ := auth.FromContext(ctx)
+	if req.CustomerRef == "" && p.Role == auth.RoleCustomer {
+		req.CustomerRef = p.CustomerRef
+	}
+	if !p.CanAccessCustomer(req.CustomerRef) {
+		writeCartError(w, &utils.AuthorizationError{Action: "create cart for customer " + req.CustomerRef})
+		return
+	}
+
+	cart, err := h.service.CreateCart(ctx, req.CustomerRef)
+	if err != nil {
+		writeCartError(w, err)
+		return
+	}
+
+	h.writeView(w, r, http.StatusCreated, cart)
+}
+
+func (h *CartHandler) GetCart(w http.ResponseWriter, r *http.Request) {
+	cart, ok := h.loadCart(w, r)
+	if !ok {
+		return
+	}
+
+	h.writeView(w, r, http.StatusOK, cart)
+}
+
+func (h *CartHandler) AddItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	cart, ok := h.loadCart(w, r)
+	if !ok {
+		return
+	}
+
+	var req AddItemRequest
+	err := utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	err = h.service.AddItem(ctx, cart, req)
+	if err != nil {
+		writeCartError(w, err)
+		return
+	}
+
+	h.reloadAndWriteView(w, r, cart.ID)
+}
+
+func (h *CartHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	cart, ok := h.loadCart(w, r)
+	if !ok {
+		return
+	}
+
+	productID, variantID, ok := parseItemPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateItemRequest
+	err := utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	err = h.service.UpdateItem(ctx, cart, productID, variantID, req.Quantity)
+	if err != nil {
+		writeCartError(w, err)
+		return
+	}
+
+	h.reloadAndWriteView(w, r, cart.ID)
+}
+
+func (h *CartHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	cart, ok := h.loadCart(w, r)
+	if !ok {
+		return
+	}
+
+	productID, variantID, ok := parseItemPath(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.service.RemoveItem(ctx, cart, productID, variantID)
+	if err != nil {
+		writeCartError(w, err)
+		return
+	}
+
+	h.reloadAndWriteView(w, r, cart.ID)
+}
+
+// parseItemPath reads the product of /carts/{id}/items/{productId} and the optional ?variant_id=,
+// writing the error response when one is invalid
+func parseItemPath(w http.ResponseWriter, r *http.Request) (int64, *int64, bool) {
+	productID, err := strconv.ParseInt(chi.URLParam(r, "productId"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid product id"})
+		return 0, nil, false
+	}
+
+	raw := r.URL.Query().Get("variant_id")
+	if raw == "" {
+		return productID, nil, true
+	}
+	variantID, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid variant id"})
+		return 0, nil, false
+	}
+	return productID, &variantID, true
+}
+
+func (h *CartHandler) Checkout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	cart, ok := h.loadCart(w, r)
+	if !ok {
+		return
+	}
+
+	// the body is optional, an empty one checks out with the defaults
+	var req CheckoutRequest
+	err := utils.ParseJSON(r.Body, &req)
+	if err != nil && err != io.EOF {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	order, items, err := h.service.Checkout(ctx, cart, req)
+	if err != nil {
+		writeCartError(w, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"order":       order,
+		"order_items": items,
+	}
+	utils.WriteJSON(w, http.StatusCreated, response)
+}
+
+// loadCart fetches the cart in the url, customers may only load their own carts
+func (h *CartHandler) loadCart(w http.ResponseWriter, r *http.Request) (repo.Cart, bool) {
+	ctx := r.Context()
+
+	idParam := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid cart id"})
+		return repo.Cart{}, false
+	}
+
+	cart, err := h.service.GetCart(ctx, id)
+	if err != nil {
+		writeCartError(w, err)
+		return repo.Cart{}, false
+	}
+
+	if p, _ := auth.FromContext(ctx); !p.CanAccessCustomer(cart.CustomerRef) {
+		writeCartError(w, &utils.AuthorizationError{Action: "access cart " + idParam})
+		return repo.Cart{}, false
+	}
+
+	return cart, true
+}
+
+func (h *CartHandler) reloadAndWriteView(w http.ResponseWriter, r *http.Request, id int64) {
+	cart, err := h.service.GetCart(r.Context(), id)
+	if err != nil {
+		writeCartError(w, err)
+		return
+	}
+	h.writeView(w, r, http.StatusOK, cart)
+}
+
+func (h *CartHandler) writeView(w http.ResponseWriter, r *http.Request, status int, cart repo.Cart) {
+	view, err := h.service.ViewCart(r.Context(), cart)
+	if err != nil {
+		writeCartError(w, err)
+		return
+	}
+	utils.WriteJSON(w, status, view)
+}
+
+// writeCartError maps service errors to their http status codes
+func writeCartError(w http.ResponseWriter, err error) {
+	switch e := err.(type) {
+	case *utils.ValidationError:
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": e.Error()})
+	case *utils.NotFoundError:
+		utils.WriteJSON(w, http.StatusNotFound, map[string]string{"error": e.Error()})
+	case *utils.AuthorizationError:
+		auth.WriteError(w, e)
+	case *utils.DatabaseError:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": e.Error()})
+	default:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,407 @@
+package carts
+
+import (
+	"context"
+	"database/sql"
+	"ecomApis/internals/money"
+	"ecomApis/internals/orders"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type CartService struct {
+	repo   *repo.Queries
+	orders *orders.OrderService
+	ttl    time.Duration
+}
+
+// NewCartService builds the cart service, ttl is how long an untouched cart stays open
+func NewCartService(r *repo.Queries, o *orders.OrderService, ttl time.Duration) *CartService {
+	return &CartService{
+		repo:   r,
+		orders: o,
+		ttl:    ttl,
+	}
+}
+
+func (s *CartService) CreateCart(ctx context.Context, customerRef string) (repo.Cart, error) {
+	if customerRef == "" {
+		return repo.Cart{}, &utils.ValidationError{
+			Field:   "customer_ref",
+			Message: "cannot be empty",
+		}
+	}
+
+	_, err := s.repo.GetCustomerByRef(ctx, customerRef)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return repo.Cart{}, &utils.NotFoundError{
+				Resource: "Customer",
+				ID:       customerRef,
+			}
+		}
+		return repo.Cart{}, &utils.DatabaseError{Query: "GetCustomerByRef", Err: err}
+	}
+
+	cart, err := s.repo.CreateCart(ctx, repo.CreateCartParams{
+		CustomerRef: customerRef,
+		TtlSeconds:  int32(s.ttl.Seconds()),
+	})
+	if err != nil {
+		return repo.Cart{}, &utils.DatabaseError{Query: "CreateCart", Err: err}
+	}
+	return cart, nil
+}
+
+func (s *CartService) GetCart(ctx context.Context, id int64) (repo.Cart, error) {
+	cart, err := s.repo.GetCart(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return repo.Cart{}, &utils.NotFoundError{
+				Resource: "Cart",
+				ID:       strconv.FormatInt(id, 10),
+			}
+		}
+		return repo.Cart{}, &utils.DatabaseError{Query: "GetCart", Err: err}
+	}
+	return cart, nil
+}
+
+// ViewCart prices every line with the live product or variant price and flags stock problems
+func (s *CartService) ViewCart(ctx context.Context, cart repo.Cart) (CartView, error) {
+	items, err := s.repo.ListCartItems(ctx, cart.ID)
+	if err != nil {
+		return CartView{}, &utils.DatabaseError{Query: "ListCartItems", Err: err}
+	}
+
+	view := CartView{
+		Cart:        cart,
+		Items:       []CartLine{},
+		CanCheckout: requireOpen(cart) == nil && len(items) > 0,
+	}
+
+	for _, item := range items {
+		line := CartLine{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		}
+		if item.VariantID.Valid {
+			variantID := item.VariantID.Int64
+			line.VariantID = &variantID
+		}
+
+		product, err := s.repo.FindProductByID(ctx, item.ProductID)
+		if err != nil {
+			if err != sql.ErrNoRows && err != pgx.ErrNoRows {
+				return CartView{}, &utils.DatabaseError{Query: "FindProductByID", Err: err}
+			}
+			// deleted between reading the items and the product
+			line.Warning = WarningProductRemoved
+		} else {
+			line.Name = product.Name
+			line.UnitPrice = product.PriceMoney()
+
+			// stock held by unpaid orders is not available to the cart
+			var available int32
+			if item.VariantID.Valid {
+				variant, err := s.repo.GetVariant(ctx, item.VariantID.Int64)
+				if err != nil {
+					return CartView{}, &utils.DatabaseError{Query: "GetVariant", Err: err}
+				}
+				reserved, err := s.repo.GetVariantReservedQuantity(ctx, item.VariantID)
+				if err != nil {
+					return CartView{}, &utils.DatabaseError{Query: "GetVariantReservedQuantity", Err: err}
+				}
+				available = variant.Stock - reserved
+				line.SKU = variant.Sku
+				if variant.Price.Valid {
+					line.UnitPrice.Amount = variant.Price.Int64
+				}
+			} else {
+				reserved, err := s.repo.GetReservedQuantity(ctx, product.ID)
+				if err != nil {
+					return CartView{}, &utils.DatabaseError{Query: "GetReservedQuantity", Err: err}
+				}
+				available = product.Stock - reserved
+			}
+			line.AvailableStock = available
+			line.LineTotal, err = line.UnitPrice.Mul(int64(item.Quantity))
+			if err != nil {
+				return CartView{}, &utils.ValidationError{Field: "quantity", Message: "line total is too large"}
+			}
+
+			switch {
+			case available <= 0:
+				line.Warning = WarningOutOfStock
+			case available < item.Quantity:
+				line.Warning = WarningInsufficientStock
+			}
+
+			// the first priced line decides the currency of the cart
+			if view.Subtotal.Currency == "" {
+				view.Subtotal = money.Zero(product.Currency)
+			}
+			subtotal, err := view.Subtotal.Add(line.LineTotal)
+			switch {
+			case errors.Is(err, money.ErrCurrencyMismatch):
+				line.Warning = WarningCurrencyMismatch
+			case err != nil:
+				return CartView{}, &utils.ValidationError{Field: "subtotal", Message: "cart subtotal is too large"}
+			default:
+				view.Subtotal = subtotal
+			}
+		}
+
+		if line.Warning != "" {
+			view.CanCheckout = false
+		}
+		view.Items = append(view.Items, line)
+	}
+
+	return view, nil
+}
+
+func (s *CartService) AddItem(ctx context.Context, cart repo.Cart, req AddItemRequest) error {
+	if err := requireOpen(cart); err != nil {
+		return err
+	}
+	if req.Quantity <= 0 {
+		return &utils.ValidationError{
+			Field:   "quantity",
+			Message: "must be greater than zero",
+		}
+	}
+
+	_, err := s.repo.FindProductByID(ctx, req.ProductID)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return &utils.NotFoundError{
+				Resource: "Product",
+				ID:       strconv.FormatInt(req.ProductID, 10),
+			}
+		}
+		return &utils.DatabaseError{Query: "FindProductByID", Err: err}
+	}
+
+	// products with variants are bought per variant
+	if req.VariantID == nil {
+		hasVariants, err := s.repo.ProductHasVariants(ctx, req.ProductID)
+		if err != nil {
+			return &utils.DatabaseError{Query: "ProductHasVariants", Err: err}
+		}
+		if hasVariants {
+			return &utils.ValidationError{
+				Field:   "variant_id",
+				Message: fmt.Sprintf("product %d has variants, choose one", req.ProductID),
+			}
+		}
+	} else {
+		variant, err := s.repo.GetVariant(ctx, *req.VariantID)
+		if err != nil && err != sql.ErrNoRows && err != pgx.ErrNoRows {
+			return &utils.DatabaseError{Query: "GetVariant", Err: err}
+		}
+		if err != nil || variant.ProductID != req.ProductID {
+			return &utils.NotFoundError{
+				Resource: "Variant",
+				ID:       strconv.FormatInt(*req.VariantID, 10),
+			}
+		}
+	}
+
+	_, err = s.repo.AddCartItem(ctx, repo.AddCartItemParams{
+		CartID:    cart.ID,
+		ProductID: req.ProductID,
+		VariantID: variantParam(req.VariantID),
+		Quantity:  req.Quantity,
+	})
+	if err != nil {
+		return &utils.DatabaseError{Query: "AddCartItem", Err: err}
+	}
+
+	return s.touch(ctx, cart.ID)
+}
+
+// UpdateItem sets the quantity of a product, or of one of its variants when variantID is set
+func (s *CartService) UpdateItem(ctx context.Context, cart repo.Cart, productID int64, variantID *int64, quantity int32) error {
+	if err := requireOpen(cart); err != nil {
+		return err
+	}
+	if quantity <= 0 {
+		return &utils.ValidationError{
+			Field:   "quantity",
+			Message: "must be greater than zero, remove the item instead",
+		}
+	}
+
+	_, err := s.repo.SetCartItemQuantity(ctx, repo.SetCartItemQuantityParams{
+		Quantity:  quantity,
+		CartID:    cart.ID,
+		ProductID: productID,
+		VariantID: variantParam(variantID),
+	})
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return &utils.NotFoundError{
+				Resource: "Cart item",
+				ID:       strconv.FormatInt(productID, 10),
+			}
+		}
+		return &utils.DatabaseError{Query: "SetCartItemQuantity", Err: err}
+	}
+
+	return s.touch(ctx, cart.ID)
+}
+
+func (s *CartService) RemoveItem(ctx context.Context, cart repo.Cart, productID int64, variantID *int64) error {
+	if err := requireOpen(cart); err != nil {
+		return err
+	}
+
+	removed, err := s.repo.RemoveCartItem(ctx, repo.RemoveCartItemParams{
+		CartID:    cart.ID,
+		ProductID: productID,
+		VariantID: variantParam(variantID),
+	})
+	if err != nil {
+		return &utils.DatabaseError{Query: "RemoveCartItem", Err: err}
+	}
+	if removed == 0 {
+		return &utils.NotFoundError{
+			Resource: "Cart item",
+			ID:       strconv.FormatInt(productID, 10),
+		}
+	}
+
+	return s.touch(ctx, cart.ID)
+}
+
+// Checkout places an order for the cart contents through OrderService.CreateOrder, with the
+// coupon, shipping and currency of the request. The cart is marked checked out in the same
+// transaction, and only if nobody changed it since we read it
+func (s *CartService) Checkout(ctx context.Context, cart repo.Cart, req CheckoutRequest) (repo.Order, []repo.OrderItem, error) {
+	if err := requireOpen(cart); err != nil {
+		return repo.Order{}, nil, err
+	}
+
+	items, err := s.repo.ListCartItems(ctx, cart.ID)
+	if err != nil {
+		return repo.Order{}, nil, &utils.DatabaseError{Query: "ListCartItems", Err: err}
+	}
+	if len(items) == 0 {
+		return repo.Order{}, nil, &utils.ValidationError{
+			Field:   "items",
+			Message: "cart is empty",
+		}
+	}
+
+	orderItems := make([]orders.OrderItemRequest, 0, len(items))
+	for _, item := range items {
+		orderItem := orders.OrderItemRequest{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		}
+		if item.VariantID.Valid {
+			variantID := item.VariantID.Int64
+			orderItem.VariantID = &variantID
+		}
+		orderItems = append(orderItems, orderItem)
+	}
+
+	orderReq := orders.CreateOrderRequest{
+		CustomerRef:     cart.CustomerRef,
+		Currency:        req.Currency,
+		Items:           orderItems,
+		CouponCode:      req.CouponCode,
+		ShippingMethod:  req.ShippingMethod,
+		ShippingAddress: req.ShippingAddress,
+		BillingAddress:  req.BillingAddress,
+	}
+	return s.orders.CreateOrderWith(ctx, orderReq, func(ctx context.Context, qtx *repo.Queries, order repo.Order) error {
+		_, err := qtx.MarkCartCheckedOut(ctx, repo.MarkCartCheckedOutParams{
+			OrderID:   pgtype.Int8{Int64: order.ID, Valid: true},
+			ID:        cart.ID,
+			UpdatedAt: cart.UpdatedAt,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+				return &utils.ValidationError{
+					Field:   "cart",
+					Message: "cart changed or was checked out during checkout, review it and try again",
+				}
+			}
+			return &utils.DatabaseError{Query: "MarkCartCheckedOut", Err: err}
+		}
+		return nil
+	})
+}
+
+// RunExpiry marks carts that were not touched within the ttl as expired, every interval until ctx is cancelled
+func (s *CartService) RunExpiry(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := s.repo.ExpireCarts(ctx)
+			if err != nil {
+				slog.Error("failed to expire carts", "error", err)
+				continue
+			}
+			if expired > 0 {
+				slog.Info("expired abandoned carts", "count", expired)
+			}
+		}
+	}
+}
+
+// touch pushes the expiry forward and bumps updated_at so a checkout in progress notices the change
+func (s *CartService) touch(ctx context.Context, id int64) error {
+	_, err := s.repo.TouchCart(ctx, repo.TouchCartParams{
+		TtlSeconds: int32(s.ttl.Seconds()),
+		ID:         id,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return &utils.ValidationError{Field: "cart", Message: "cart is no longer open"}
+		}
+		return &utils.DatabaseError{Query: "TouchCart", Err: err}
+	}
+	return nil
+}
+
+// variantParam turns an optional variant id into the nullable column value
+func variantParam(id *int64) pgtype.Int8 {
+	if id == nil {
+		return pgtype.Int8{}
+	}
+	return pgtype.Int8{Int64: *id, Valid: true}
+}
+
+// requireOpen rejects carts that were checked out or expired, and open carts past their
+// expiry that the sweeper has not reached yet
+func requireOpen(cart repo.Cart) error {
+	if cart.Status != StatusOpen {
+		return &utils.ValidationError{
+			Field:   "cart",
+			Message: fmt.Sprintf("cart is %s", cart.Status),
+		}
+	}
+	if cart.ExpiresAt.Valid && cart.ExpiresAt.Time.Before(time.Now()) {
+		return &utils.ValidationError{
+			Field:   "cart",
+			Message: "cart has expired",
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package carts
+
+import (
+	"context"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestCheckoutRefusesClosedCarts(t *testing.T) {
+	tests := []struct {
+		name    string
+		cart    repo.Cart
+		wantMsg string
+	}{
+		{
+			name:    "checked out",
+			cart:    repo.Cart{ID: 1, Status: StatusCheckedOut},
+			wantMsg: "cart is checked_out",
+		},
+		{
+			name:    "expired",
+			cart:    repo.Cart{ID: 1, Status: StatusExpired},
+			wantMsg: "cart is expired",
+		},
+		{
+			// the sweeper has not marked it expired yet
+			name: "open past its expiry",
+			cart: repo.Cart{
+				ID:        1,
+				Status:    StatusOpen,
+				ExpiresAt: pgtype.Timestamp{Time: time.Now().Add(-time.Minute), Valid: true},
+			},
+			wantMsg: "cart has expired",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the cart is checked before the database is reached
+			_, _, err := NewCartService(nil, nil, time.Hour).Checkout(context.Background(), tt.cart, CheckoutRequest{})
+			var verr *utils.ValidationError
+			if !errors.As(err, &verr) || verr.Message != tt.wantMsg {
+				t.Errorf("Checkout: err = %v, want %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestRequireOpen(t *testing.T) {
+	cart := repo.Cart{
+		Status:    StatusOpen,
+		ExpiresAt: pgtype.Timestamp{Time: time.Now().Add(time.Minute), Valid: true},
+	}
+	if err := requireOpen(cart); err != nil {
+		t.Errorf("requireOpen(open cart) = %v, want nil", err)
+	}
+}
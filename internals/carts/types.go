@@ -0,0 +1,70 @@
+package carts
+
+import (
+	"ecomApis/internals/money"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/shipping"
+)
+
+// cart statuses
+const (
+	StatusOpen       = "open"
+	StatusCheckedOut = "checked_out"
+	StatusExpired    = "expired"
+)
+
+// line warnings
+const (
+	WarningOutOfStock        = "out_of_stock"
+	WarningInsufficientStock = "insufficient_stock"
+	WarningProductRemoved    = "product_removed"
+	// the product is priced in another currency than the rest of the cart
+	WarningCurrencyMismatch = "currency_mismatch"
+)
+
+type CreateCartRequest struct {
+	CustomerRef string `json:"customer_ref"`
+}
+
+// AddItemRequest adds a product to the cart, products with variants also need the variant
+type AddItemRequest struct {
+	ProductID int64  `json:"product_id"`
+	VariantID *int64 `json:"variant_id"`
+	Quantity  int32  `json:"quantity"`
+}
+
+type UpdateItemRequest struct {
+	Quantity int32 `json:"quantity"`
+}
+
+// CheckoutRequest carries the order options a cart does not hold. Every field is optional and
+// means the same as on POST /orders
+type CheckoutRequest struct {
+	Currency        string            `json:"currency"`
+	CouponCode      string            `json:"coupon_code"`
+	ShippingMethod  string            `json:"shipping_method"`
+	ShippingAddress *shipping.Address `json:"shipping_address"`
+	BillingAddress  *shipping.Address `json:"billing_address"`
+}
+
+// CartLine is a cart item priced with the current price and stock of its product or variant
+type CartLine struct {
+	ProductID      int64       `json:"product_id"`
+	VariantID      *int64      `json:"variant_id,omitempty"`
+	SKU            string      `json:"sku,omitempty"`
+	Name           string      `json:"name"`
+	Quantity       int32       `json:"quantity"`
+	UnitPrice      money.Money `json:"unit_price"`
+	LineTotal      money.Money `json:"line_total"`
+	AvailableStock int32       `json:"available_stock"`
+	Warning        string      `json:"warning,omitempty"`
+}
+
+// CartView is what GET /carts/{id} returns
+type CartView struct {
+	Cart     repo.Cart   `json:"cart"`
+	Items    []CartLine  `json:"items"`
+	Subtotal money.Money `json:"subtotal"`
+	// CanCheckout is false while any line has a warning
+	CanCheckout bool `json:"can_checkout"`
+}
@@ -0,0 +1,182 @@
+package categories
+
+import (
+	"ecomApis/internals/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(s *Service) *Handler {
+	return &Handler{
+		service: s,
+	}
+}
+
+// Tree handles GET /categories
+func (h *Handler) Tree(w http.ResponseWriter, r *http.Request) {
+	tree, err := h.service.Tree(r.Context())
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, tree)
+}
+
+func (h *Handler) GetCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid category ID"})
+		return
+	}
+
+	details, err := h.service.GetCategory(r.Context(), id)
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, details)
+}
+
+func (h *Handler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var req CreateCategoryRequest
+	err := utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	category, err := h.service.CreateCategory(r.Context(), req)
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, category)
+}
+
+func (h *Handler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid category ID"})
+		return
+	}
+
+	var req UpdateCategoryRequest
+	err = utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	category, err := h.service.UpdateCategory(r.Context(), id, req)
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, category)
+}
+
+// MoveCategory handles POST /categories/{id}/move
+func (h *Handler) MoveCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid category ID"})
+		return
+	}
+
+	var req MoveCategoryRequest
+	err = utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	category, err := h.service.MoveCategory(r.Context(), id, req)
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, category)
+}
+
+func (h *Handler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid category ID"})
+		return
+	}
+
+	err = h.service.DeleteCategory(r.Context(), id)
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+// ListProductCategories handles GET /products/{id}/categories
+func (h *Handler) ListProductCategories(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid product ID"})
+		return
+	}
+
+	categories, err := h.service.ListProductCategories(r.Context(), id)
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, categories)
+}
+
+// SetProductCategories handles PUT /products/{id}/categories
+func (h *Handler) SetProductCategories(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid product ID"})
+		return
+	}
+
+	var req SetProductCategoriesRequest
+	err = utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	categories, err := h.service.SetProductCategories(r.Context(), id, req)
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, categories)
+}
+
+func writeCategoryError(w http.ResponseWriter, err error) {
+	switch e := err.(type) {
+	case *utils.ValidationError:
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": e.Error()})
+	case *utils.NotFoundError:
+		utils.WriteJSON(w, http.StatusNotFound, map[string]string{"error": e.Error()})
+	case *utils.AlreadyExistsError:
+		utils.WriteJSON(w, http.StatusConflict, map[string]string{"error": e.Error()})
+	case *utils.DatabaseError:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": e.Error()})
+	default:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,316 @@
+package categories
+
+import (
+	"context"
+	"database/sql"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// postgres error codes
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+// slugs are lowercase words joined by single dashes, the same rule as the column check
+var (
+	slugPattern   = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	slugSeparator = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+type Service struct {
+	repo *repo.Queries
+	db   *pgxpool.Pool
+}
+
+func NewService(r *repo.Queries, db *pgxpool.Pool) *Service {
+	return &Service{
+		repo: r,
+		db:   db,
+	}
+}
+
+// Slugify turns a name into a slug, "Men's Shoes" becomes "men-s-shoes"
+func Slugify(name string) string {
+	return strings.Trim(slugSeparator.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// validateCategory checks the name and returns the slug to store
+func validateCategory(name, slug string) (string, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", &utils.ValidationError{Field: "name", Message: "cannot be empty"}
+	}
+	if slug == "" {
+		slug = Slugify(name)
+	}
+	if !slugPattern.MatchString(slug) {
+		return "", &utils.ValidationError{Field: "slug", Message: "must be lowercase letters and digits separated by single dashes"}
+	}
+	return slug, nil
+}
+
+// Tree returns the whole catalog tree, roots first
+func (s *Service) Tree(ctx context.Context) ([]*Node, error) {
+	categories, err := s.repo.ListCategories(ctx)
+	if err != nil {
+		return nil, &utils.DatabaseError{Query: "ListCategories", Err: err}
+	}
+
+	nodes := make(map[int64]*Node, len(categories))
+	for _, c := range categories {
+		nodes[c.ID] = &Node{Category: c, Children: []*Node{}}
+	}
+
+	// categories come sorted, so appending keeps every level in order
+	roots := []*Node{}
+	for _, c := range categories {
+		node := nodes[c.ID]
+		if parent, ok := nodes[c.ParentID.Int64]; c.ParentID.Valid && ok {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	return roots, nil
+}
+
+func (s *Service) GetCategory(ctx context.Context, id int64) (CategoryDetails, error) {
+	category, err := s.repo.GetCategory(ctx, id)
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return CategoryDetails{}, &utils.NotFoundError{Resource: "Category", ID: strconv.FormatInt(id, 10)}
+		}
+		return CategoryDetails{}, &utils.DatabaseError{Query: "GetCategory", Err: err}
+	}
+
+	path, err := s.repo.ListCategoryAncestors(ctx, id)
+	if err != nil {
+		return CategoryDetails{}, &utils.DatabaseError{Query: "ListCategoryAncestors", Err: err}
+	}
+	if path == nil {
+		path = []repo.Category{}
+	}
+
+	children, err := s.repo.ListChildCategories(ctx, pgtype.Int8{Int64: id, Valid: true})
+	if err != nil {
+		return CategoryDetails{}, &utils.DatabaseError{Query: "ListChildCategories", Err: err}
+	}
+	if children == nil {
+		children = []repo.Category{}
+	}
+
+	return CategoryDetails{Category: category, Path: path, Children: children}, nil
+}
+
+func (s *Service) CreateCategory(ctx context.Context, req CreateCategoryRequest) (repo.Category, error) {
+	slug, err := validateCategory(req.Name, req.Slug)
+	if err != nil {
+		return repo.Category{}, err
+	}
+
+	params := repo.CreateCategoryParams{
+		Name:        strings.TrimSpace(req.Name),
+		Slug:        slug,
+		Description: req.Description,
+		Position:    req.Position,
+	}
+	if req.ParentID != nil {
+		params.ParentID = pgtype.Int8{Int64: *req.ParentID, Valid: true}
+	}
+
+	category, err := s.repo.CreateCategory(ctx, params)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case uniqueViolation:
+				return repo.Category{}, &utils.AlreadyExistsError{Resource: "Category", ID: slug}
+			case foreignKeyViolation:
+				return repo.Category{}, &utils.NotFoundError{Resource: "Parent category", ID: strconv.FormatInt(*req.ParentID, 10)}
+			}
+		}
+		return repo.Category{}, &utils.DatabaseError{Query: "CreateCategory", Err: err}
+	}
+	return category, nil
+}
+
+func (s *Service) UpdateCategory(ctx context.Context, id int64, req UpdateCategoryRequest) (repo.Category, error) {
+	slug, err := validateCategory(req.Name, req.Slug)
+	if err != nil {
+		return repo.Category{}, err
+	}
+
+	category, err := s.repo.UpdateCategory(ctx, repo.UpdateCategoryParams{
+		Name:        strings.TrimSpace(req.Name),
+		Slug:        slug,
+		Description: req.Description,
+		Position:    req.Position,
+		ID:          id,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return repo.Category{}, &utils.NotFoundError{Resource: "Category", ID: strconv.FormatInt(id, 10)}
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return repo.Category{}, &utils.AlreadyExistsError{Resource: "Category", ID: slug}
+		}
+		return repo.Category{}, &utils.DatabaseError{Query: "UpdateCategory", Err: err}
+	}
+	return category, nil
+}
+
+// MoveCategory moves a category with its whole subtree under a new parent. Moves take a lock
+// on the tree, otherwise two moves that are fine on their own could together form a cycle
+func (s *Service) MoveCategory(ctx context.Context, id int64, req MoveCategoryRequest) (repo.Category, error) {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return repo.Category{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	err = qtx.LockCategoryTree(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Category{}, &utils.DatabaseError{Query: "LockCategoryTree", Err: err}
+	}
+
+	subtree, err := qtx.ListCategorySubtreeIDs(ctx, id)
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Category{}, &utils.DatabaseError{Query: "ListCategorySubtreeIDs", Err: err}
+	}
+	if len(subtree) == 0 {
+		tx.Rollback(ctx)
+		return repo.Category{}, &utils.NotFoundError{Resource: "Category", ID: strconv.FormatInt(id, 10)}
+	}
+
+	params := repo.MoveCategoryParams{Position: req.Position, ID: id}
+	if req.ParentID != nil {
+		for _, descendant := range subtree {
+			if descendant == *req.ParentID {
+				tx.Rollback(ctx)
+				return repo.Category{}, &utils.ValidationError{
+					Field:   "parent_id",
+					Message: "cannot move a category under itself or one of its subcategories",
+				}
+			}
+		}
+		params.ParentID = pgtype.Int8{Int64: *req.ParentID, Valid: true}
+	}
+
+	category, err := qtx.MoveCategory(ctx, params)
+	if err != nil {
+		tx.Rollback(ctx)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			return repo.Category{}, &utils.NotFoundError{Resource: "Parent category", ID: strconv.FormatInt(*req.ParentID, 10)}
+		}
+		return repo.Category{}, &utils.DatabaseError{Query: "MoveCategory", Err: err}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Category{}, fmt.Errorf("commit tx: %w", err)
+	}
+	return category, nil
+}
+
+// DeleteCategory removes an empty branch of the tree, categories with subcategories are refused.
+// Products only lose the category, they are not deleted
+func (s *Service) DeleteCategory(ctx context.Context, id int64) error {
+	deleted, err := s.repo.DeleteCategory(ctx, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			return &utils.ValidationError{Field: "id", Message: "category has subcategories, move or delete them first"}
+		}
+		return &utils.DatabaseError{Query: "DeleteCategory", Err: err}
+	}
+	if deleted == 0 {
+		return &utils.NotFoundError{Resource: "Category", ID: strconv.FormatInt(id, 10)}
+	}
+	return nil
+}
+
+func (s *Service) ListProductCategories(ctx context.Context, productID int64) ([]repo.Category, error) {
+	_, err := s.repo.FindProductByID(ctx, productID)
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return nil, &utils.NotFoundError{Resource: "Product", ID: strconv.FormatInt(productID, 10)}
+		}
+		return nil, &utils.DatabaseError{Query: "FindProductByID", Err: err}
+	}
+
+	categories, err := s.repo.ListProductCategories(ctx, productID)
+	if err != nil {
+		return nil, &utils.DatabaseError{Query: "ListProductCategories", Err: err}
+	}
+	if categories == nil {
+		categories = []repo.Category{}
+	}
+	return categories, nil
+}
+
+// SetProductCategories replaces the categories a product belongs to
+func (s *Service) SetProductCategories(ctx context.Context, productID int64, req SetProductCategoriesRequest) ([]repo.Category, error) {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	_, err = qtx.GetProductForUpdate(ctx, productID)
+	if err != nil {
+		tx.Rollback(ctx)
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return nil, &utils.NotFoundError{Resource: "Product", ID: strconv.FormatInt(productID, 10)}
+		}
+		return nil, &utils.DatabaseError{Query: "GetProductForUpdate", Err: err}
+	}
+
+	err = qtx.DeleteProductCategories(ctx, productID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return nil, &utils.DatabaseError{Query: "DeleteProductCategories", Err: err}
+	}
+
+	for _, categoryID := range req.CategoryIDs {
+		err = qtx.AddProductCategory(ctx, repo.AddProductCategoryParams{
+			ProductID:  productID,
+			CategoryID: categoryID,
+		})
+		if err != nil {
+			tx.Rollback(ctx)
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+				return nil, &utils.NotFoundError{Resource: "Category", ID: strconv.FormatInt(categoryID, 10)}
+			}
+			return nil, &utils.DatabaseError{Query: "AddProductCategory", Err: err}
+		}
+	}
+
+	categories, err := qtx.ListProductCategories(ctx, productID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return nil, &utils.DatabaseError{Query: "ListProductCategories", Err: err}
+	}
+	if categories == nil {
+		categories = []repo.Category{}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return categories, nil
+}
@@ -0,0 +1,45 @@
+package categories
+
+import "ecomApis/internals/repo"
+
+// CreateCategoryRequest adds a category under ParentID, or a root category without one.
+// Slug is derived from Name when empty
+type CreateCategoryRequest struct {
+	ParentID    *int64 `json:"parent_id"`
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Description string `json:"description"`
+	Position    int32  `json:"position"`
+}
+
+type UpdateCategoryRequest struct {
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Description string `json:"description"`
+	Position    int32  `json:"position"`
+}
+
+// MoveCategoryRequest moves a category and everything below it under ParentID,
+// a null parent makes it a root
+type MoveCategoryRequest struct {
+	ParentID *int64 `json:"parent_id"`
+	Position int32  `json:"position"`
+}
+
+// SetProductCategoriesRequest replaces the categories of a product
+type SetProductCategoriesRequest struct {
+	CategoryIDs []int64 `json:"category_ids"`
+}
+
+// Node is a category with its subcategories, siblings are sorted by position and name
+type Node struct {
+	repo.Category
+	Children []*Node `json:"children"`
+}
+
+// CategoryDetails is a category with the path from the root down to its parent and its direct children
+type CategoryDetails struct {
+	Category repo.Category   `json:"category"`
+	Path     []repo.Category `json:"path"`
+	Children []repo.Category `json:"children"`
+}
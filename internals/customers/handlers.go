@@ -0,0 +1,153 @@
+package customers
+
+import (
+	"ecomApis/internals/auth"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type CustomerHandler struct {
+	service *CustomerService
+}
+
+func NewCustomerHandler(s *CustomerService) *CustomerHandler {
+	return &CustomerHandler{
+		service: s,
+	}
+}
+
+func (h *CustomerHandler) CreateCustomer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req CreateCustomerRequest
+	err := utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	customer, err := h.service.CreateCustomer(ctx, req)
+	if err != nil {
+		writeCustomerError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, customer)
+}
+
+func (h *CustomerHandler) ListCustomers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	limit, err := utils.ParseLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		writeCustomerError(w, err)
+		return
+	}
+
+	page, err := h.service.ListCustomers(ctx, limit, r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeCustomerError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, page)
+}
+
+func (h *CustomerHandler) GetCustomer(w http.ResponseWriter, r *http.Request) {
+	customer, ok := h.loadOwnCustomer(w, r)
+	if !ok {
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, customer)
+}
+
+func (h *CustomerHandler) UpdateCustomer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	customer, ok := h.loadOwnCustomer(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateCustomerRequest
+	err := utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	customer, err = h.service.UpdateCustomer(ctx, customer.ID, req)
+	if err != nil {
+		writeCustomerError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, customer)
+}
+
+func (h *CustomerHandler) DeleteCustomer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idParam := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid customer id"})
+		return
+	}
+
+	err = h.service.DeleteCustomer(ctx, id)
+	if err != nil {
+		writeCustomerError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+// loadOwnCustomer fetches the customer in the url, customers may only load themselves
+func (h *CustomerHandler) loadOwnCustomer(w http.ResponseWriter, r *http.Request) (repo.Customer, bool) {
+	ctx := r.Context()
+
+	idParam := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid customer id"})
+		return repo.Customer{}, false
+	}
+
+	customer, err := h.service.GetCustomer(ctx, id)
+	if err != nil {
+		writeCustomerError(w, err)
+		return repo.Customer{}, false
+	}
+
+	if p, _ := auth.FromContext(ctx); !p.CanAccessCustomer(customer.CustomerRef) {
+		writeCustomerError(w, &utils.AuthorizationError{Action: "access customer " + idParam})
+		return repo.Customer{}, false
+	}
+
+	return customer, true
+}
+
+// writeCustomerError maps service errors to their http status codes
+func writeCustomerError(w http.ResponseWriter, err error) {
+	switch e := err.(type) {
+	case *utils.ValidationError:
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": e.Error()})
+	case *utils.NotFoundError:
+		utils.WriteJSON(w, http.StatusNotFound, map[string]string{"error": e.Error()})
+	case *utils.AlreadyExistsError:
+		utils.WriteJSON(w, http.StatusConflict, map[string]string{"error": e.Error()})
+	case *utils.AuthorizationError:
+		auth.WriteError(w, e)
+	case *utils.DatabaseError:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": e.Error()})
+	default:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,201 @@
+package customers
+
+import (
+	"context"
+	"database/sql"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"errors"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// postgres error code for unique constraint violations
+const uniqueViolation = "23505"
+
+type CustomerService struct {
+	repo *repo.Queries
+}
+
+func NewCustomerService(r *repo.Queries) *CustomerService {
+	return &CustomerService{
+		repo: r,
+	}
+}
+
+func (s *CustomerService) CreateCustomer(ctx context.Context, req CreateCustomerRequest) (repo.Customer, error) {
+	// --- Validation ---
+	req.CustomerRef = strings.TrimSpace(req.CustomerRef)
+	if req.CustomerRef == "" {
+		return repo.Customer{}, &utils.ValidationError{
+			Field:   "customer_ref",
+			Message: "cannot be empty",
+		}
+	}
+	email, err := validateDetails(req.CustomerDetails)
+	if err != nil {
+		return repo.Customer{}, err
+	}
+
+	customer, err := s.repo.CreateCustomer(ctx, repo.CreateCustomerParams{
+		CustomerRef:  req.CustomerRef,
+		Email:        email,
+		Name:         req.Name,
+		Phone:        req.Phone,
+		AddressLine1: req.AddressLine1,
+		AddressLine2: req.AddressLine2,
+		City:         req.City,
+		Region:       req.Region,
+		PostalCode:   req.PostalCode,
+		Country:      req.Country,
+	})
+	if err != nil {
+		if conflict := uniqueConflict(err, req.CustomerRef, req.Email); conflict != nil {
+			return repo.Customer{}, conflict
+		}
+		return repo.Customer{}, &utils.DatabaseError{
+			Query: "CreateCustomer",
+			Err:   err,
+		}
+	}
+
+	return customer, nil
+}
+
+func (s *CustomerService) GetCustomer(ctx context.Context, id int64) (repo.Customer, error) {
+	customer, err := s.repo.GetCustomerByID(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return repo.Customer{}, &utils.NotFoundError{
+				Resource: "Customer",
+				ID:       strconv.FormatInt(id, 10),
+			}
+		}
+		return repo.Customer{}, &utils.DatabaseError{
+			Query: "GetCustomerByID",
+			Err:   err,
+		}
+	}
+	return customer, nil
+}
+
+func (s *CustomerService) ListCustomers(ctx context.Context, limit int32, cursor string) (utils.Page[repo.Customer], error) {
+	params := repo.ListCustomersPageParams{PageLimit: limit + 1}
+
+	if cursor != "" {
+		c, err := utils.DecodeCursor(cursor)
+		if err != nil {
+			return utils.Page[repo.Customer]{}, err
+		}
+		params.CursorID = pgtype.Int8{Int64: c.ID, Valid: true}
+	}
+
+	customers, err := s.repo.ListCustomersPage(ctx, params)
+	if err != nil {
+		return utils.Page[repo.Customer]{}, &utils.DatabaseError{
+			Query: "ListCustomersPage",
+			Err:   err,
+		}
+	}
+
+	return utils.NewPage(customers, limit, func(c repo.Customer) utils.Cursor {
+		return utils.Cursor{ID: c.ID}
+	}), nil
+}
+
+func (s *CustomerService) UpdateCustomer(ctx context.Context, id int64, req UpdateCustomerRequest) (repo.Customer, error) {
+	email, err := validateDetails(req.CustomerDetails)
+	if err != nil {
+		return repo.Customer{}, err
+	}
+
+	customer, err := s.repo.UpdateCustomer(ctx, repo.UpdateCustomerParams{
+		Email:        email,
+		Name:         req.Name,
+		Phone:        req.Phone,
+		AddressLine1: req.AddressLine1,
+		AddressLine2: req.AddressLine2,
+		City:         req.City,
+		Region:       req.Region,
+		PostalCode:   req.PostalCode,
+		Country:      req.Country,
+		ID:           id,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return repo.Customer{}, &utils.NotFoundError{
+				Resource: "Customer",
+				ID:       strconv.FormatInt(id, 10),
+			}
+		}
+		if conflict := uniqueConflict(err, "", req.Email); conflict != nil {
+			return repo.Customer{}, conflict
+		}
+		return repo.Customer{}, &utils.DatabaseError{
+			Query: "UpdateCustomer",
+			Err:   err,
+		}
+	}
+	return customer, nil
+}
+
+// DeleteCustomer soft deletes the customer, their orders keep pointing at the row
+func (s *CustomerService) DeleteCustomer(ctx context.Context, id int64) error {
+	// check if the customer exists
+	_, err := s.GetCustomer(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.DeleteCustomer(ctx, id)
+	if err != nil {
+		return &utils.DatabaseError{
+			Query: "DeleteCustomer",
+			Err:   err,
+		}
+	}
+	return nil
+}
+
+// BackfillFromOrders creates a bare customer for every customer_ref that only exists on orders
+func (s *CustomerService) BackfillFromOrders(ctx context.Context) (int64, error) {
+	created, err := s.repo.BackfillCustomersFromOrders(ctx)
+	if err != nil {
+		return 0, &utils.DatabaseError{
+			Query: "BackfillCustomersFromOrders",
+			Err:   err,
+		}
+	}
+	return created, nil
+}
+
+func validateDetails(d CustomerDetails) (pgtype.Text, error) {
+	if d.Email == "" {
+		return pgtype.Text{}, nil
+	}
+	addr, err := mail.ParseAddress(d.Email)
+	if err != nil || addr.Address != d.Email {
+		return pgtype.Text{}, &utils.ValidationError{
+			Field:   "email",
+			Message: "must be a valid email address",
+		}
+	}
+	return pgtype.Text{String: d.Email, Valid: true}, nil
+}
+
+// uniqueConflict turns a unique violation into an AlreadyExistsError for the column that clashed
+func uniqueConflict(err error, customerRef, email string) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != uniqueViolation {
+		return nil
+	}
+	if pgErr.ConstraintName == "uq_customers_email" {
+		return &utils.AlreadyExistsError{Resource: "Customer with email", ID: email}
+	}
+	return &utils.AlreadyExistsError{Resource: "Customer", ID: customerRef}
+}
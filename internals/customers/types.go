@@ -0,0 +1,23 @@
+package customers
+
+type CustomerDetails struct {
+	Email        string `json:"email"`
+	Name         string `json:"name"`
+	Phone        string `json:"phone"`
+	AddressLine1 string `json:"address_line1"`
+	AddressLine2 string `json:"address_line2"`
+	City         string `json:"city"`
+	Region       string `json:"region"`
+	PostalCode   string `json:"postal_code"`
+	Country      string `json:"country"`
+}
+
+type CreateCustomerRequest struct {
+	CustomerRef string `json:"customer_ref"`
+	CustomerDetails
+}
+
+// UpdateCustomerRequest replaces the customer details, the customer_ref never changes
+type UpdateCustomerRequest struct {
+	CustomerDetails
+}
@@ -1,6 +1,10 @@
 package env
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"time"
+)
 
 func GetString(key, defaultValue string) string {
 	value, exists := os.LookupEnv(key)
@@ -9,3 +13,41 @@ func GetString(key, defaultValue string) string {
 	}
 	return value
 }
+
+func GetInt(key string, defaultValue int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return defaultValue
+	}
+	return i
+}
+
+// GetDuration parses values such as "30s" or "1h"
+func GetDuration(key string, defaultValue time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultValue
+	}
+	return d
+}
+
+// GetBool parses values such as "true", "1" or "false"
+func GetBool(key string, defaultValue bool) bool {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return defaultValue
+	}
+	return b
+}
@@ -0,0 +1,151 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"ecomApis/internals/auth"
+	"ecomApis/internals/utils"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+const (
+	HeaderKey      = "Idempotency-Key"
+	HeaderReplayed = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+
+	// key statuses
+	statusInProgress = "in_progress"
+)
+
+// responseRecorder passes the response through while keeping a copy of it
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+// Middleware makes the wrapped handler idempotent for requests that carry an Idempotency-Key.
+// scope separates keys used on different endpoints.
+//
+//   - a replay with the same key and body gets the stored status and body back
+//   - the same key with a different body is rejected with 422
+//   - a request sent while another with the same key is running gets 409
+//
+// Server errors are not stored so the client can retry them.
+func (s *Service) Middleware(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(HeaderKey)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxKeyLength {
+				utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "Idempotency-Key is too long"})
+				return
+			}
+
+			ctx := r.Context()
+
+			// keys are private to the caller that sent them
+			scope := scope
+			if p, ok := auth.FromContext(ctx); ok {
+				scope += ":" + p.Subject
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			sum := sha256.Sum256(body)
+			requestHash := hex.EncodeToString(sum[:])
+
+			record, claimed, err := s.claim(ctx, scope, key, requestHash)
+			if err != nil {
+				utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+				return
+			}
+
+			if !claimed {
+				s.replay(w, r, scope, key, requestHash)
+				return
+			}
+
+			// the key is freed unless the response is stored, also when the handler panics.
+			// the request context may already be cancelled by then
+			stored := false
+			defer func() {
+				if stored {
+					return
+				}
+				if err := s.release(context.WithoutCancel(ctx), record.ID); err != nil {
+					// the claim lapses once its lease runs out
+					slog.Error("failed to release idempotency key", "scope", scope, "error", err)
+				}
+			}()
+
+			rec := &responseRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+
+			if rec.status == 0 || rec.status >= http.StatusInternalServerError {
+				return
+			}
+			if err := s.complete(context.WithoutCancel(ctx), record.ID, rec.status, rec.body.Bytes()); err != nil {
+				// the response already went out, a retry will just run again
+				slog.Error("failed to store idempotent response", "scope", scope, "error", err)
+				return
+			}
+			stored = true
+		})
+	}
+}
+
+// replay answers a request whose key is already taken, with the stored response or 409 while
+// the first request is still running
+func (s *Service) replay(w http.ResponseWriter, r *http.Request, scope, key, requestHash string) {
+	record, found, err := s.find(r.Context(), scope, key)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	if found && record.RequestHash != requestHash {
+		utils.WriteJSON(w, http.StatusUnprocessableEntity, map[string]string{
+			"error": "Idempotency-Key was already used with a different request body",
+		})
+		return
+	}
+	// not found means the first request just failed and freed the key
+	if !found || record.Status == statusInProgress {
+		utils.WriteJSON(w, http.StatusConflict, map[string]string{
+			"error": "a request with this Idempotency-Key is still in progress, retry later",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(HeaderReplayed, "true")
+	w.WriteHeader(int(record.StatusCode))
+	w.Write(record.ResponseBody)
+}
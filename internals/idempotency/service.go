@@ -0,0 +1,111 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Service stores the responses of requests sent with an Idempotency-Key header
+// so a retried request gets the original response instead of running twice
+type Service struct {
+	repo *repo.Queries
+	db   *pgxpool.Pool
+	ttl  time.Duration
+}
+
+// claimLease is how long a running request holds its key. It outlasts the request timeout, so
+// only a key whose request died with its instance is taken over
+const claimLease = 2 * time.Minute
+
+func NewService(r *repo.Queries, db *pgxpool.Pool, ttl time.Duration) *Service {
+	return &Service{
+		repo: r,
+		db:   db,
+		ttl:  ttl,
+	}
+}
+
+// claim records that a request with this key is running. ok is false when the key is already
+// taken by a stored response or a request still in flight, which find tells apart
+func (s *Service) claim(ctx context.Context, scope, key, requestHash string) (repo.IdempotencyKey, bool, error) {
+	record, err := s.repo.ClaimIdempotencyKey(ctx, repo.ClaimIdempotencyKeyParams{
+		Scope:          scope,
+		IdempotencyKey: key,
+		RequestHash:    requestHash,
+		LeaseSeconds:   int32(claimLease.Seconds()),
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return repo.IdempotencyKey{}, false, nil
+		}
+		return repo.IdempotencyKey{}, false, &utils.DatabaseError{Query: "ClaimIdempotencyKey", Err: err}
+	}
+	return record, true, nil
+}
+
+// find returns the stored response for a key, ok is false when there is none or it expired
+func (s *Service) find(ctx context.Context, scope, key string) (repo.IdempotencyKey, bool, error) {
+	record, err := s.repo.GetIdempotencyKey(ctx, repo.GetIdempotencyKeyParams{
+		Scope:          scope,
+		IdempotencyKey: key,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return repo.IdempotencyKey{}, false, nil
+		}
+		return repo.IdempotencyKey{}, false, &utils.DatabaseError{Query: "GetIdempotencyKey", Err: err}
+	}
+	return record, true, nil
+}
+
+// complete stores the response of a claimed key for the TTL
+func (s *Service) complete(ctx context.Context, id int64, status int, body []byte) error {
+	_, err := s.repo.CompleteIdempotencyKey(ctx, repo.CompleteIdempotencyKeyParams{
+		StatusCode:   int32(status),
+		ResponseBody: body,
+		TtlSeconds:   int32(s.ttl.Seconds()),
+		ID:           id,
+	})
+	if err != nil {
+		return &utils.DatabaseError{Query: "CompleteIdempotencyKey", Err: err}
+	}
+	return nil
+}
+
+// release frees a claimed key without a response so the request can be retried
+func (s *Service) release(ctx context.Context, id int64) error {
+	err := s.repo.ReleaseIdempotencyKey(ctx, id)
+	if err != nil {
+		return &utils.DatabaseError{Query: "ReleaseIdempotencyKey", Err: err}
+	}
+	return nil
+}
+
+// RunCleanup deletes expired keys every interval until ctx is cancelled
+func (s *Service) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.repo.DeleteExpiredIdempotencyKeys(ctx)
+			if err != nil {
+				slog.Error("failed to delete expired idempotency keys", "error", err)
+				continue
+			}
+			if deleted > 0 {
+				slog.Info("deleted expired idempotency keys", "count", deleted)
+			}
+		}
+	}
+}
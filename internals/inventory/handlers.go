@@ -0,0 +1,55 @@
+package inventory
+
+import (
+	"ecomApis/internals/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(s *Service) *Handler {
+	return &Handler{
+		service: s,
+	}
+}
+
+// ListMovements handles GET /products/{id}/inventory/movements
+func (h *Handler) ListMovements(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid product id"})
+		return
+	}
+
+	limit, err := utils.ParseLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		writeInventoryError(w, err)
+		return
+	}
+
+	page, err := h.service.ListMovements(ctx, id, limit, r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeInventoryError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, page)
+}
+
+func writeInventoryError(w http.ResponseWriter, err error) {
+	switch e := err.(type) {
+	case *utils.ValidationError:
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": e.Error()})
+	case *utils.DatabaseError:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": e.Error()})
+	default:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,163 @@
+package inventory
+
+import (
+	"context"
+	"ecomApis/internals/outbox"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type Service struct {
+	repo *repo.Queries
+}
+
+func NewService(r *repo.Queries) *Service {
+	return &Service{
+		repo: r,
+	}
+}
+
+// Record appends a movement to the ledger and queues its product.stock_changed event. Pass the
+// queries of the transaction that changed the stock so the movement and the change commit or
+// roll back together
+func Record(ctx context.Context, q *repo.Queries, arg repo.AddInventoryMovementParams) error {
+	if arg.Quantity == 0 {
+		return nil
+	}
+	if arg.Actor == "" {
+		arg.Actor = ActorSystem
+	}
+
+	_, err := q.AddInventoryMovement(ctx, arg)
+	if err != nil {
+		return &utils.DatabaseError{Query: "AddInventoryMovement", Err: err}
+	}
+	return outbox.Write(ctx, q, stockChangedEvent(arg.ProductID, arg.VariantID, arg.Quantity, arg.StockAfter, arg.Reason, arg.Actor, arg.ReferenceType, arg.ReferenceID))
+}
+
+// CopyMovements appends many movements with a single COPY, along with their events
+func CopyMovements(ctx context.Context, q *repo.Queries, movements []repo.CopyInventoryMovementsParams) error {
+	if len(movements) == 0 {
+		return nil
+	}
+
+	events := make([]outbox.Event, 0, len(movements))
+	for i, m := range movements {
+		if m.Actor == "" {
+			movements[i].Actor = ActorSystem
+		}
+		events = append(events, stockChangedEvent(m.ProductID, m.VariantID, m.Quantity, m.StockAfter, m.Reason, movements[i].Actor, m.ReferenceType, m.ReferenceID))
+	}
+
+	_, err := q.CopyInventoryMovements(ctx, movements)
+	if err != nil {
+		return &utils.DatabaseError{Query: "CopyInventoryMovements", Err: err}
+	}
+	return outbox.Copy(ctx, q, events)
+}
+
+func stockChangedEvent(productID int64, variantID pgtype.Int8, quantity, stockAfter int32, reason, actor, referenceType, referenceID string) outbox.Event {
+	data := outbox.StockChanged{
+		ProductID:     productID,
+		Quantity:      quantity,
+		Stock:         stockAfter,
+		Reason:        reason,
+		Actor:         actor,
+		ReferenceType: referenceType,
+		ReferenceID:   referenceID,
+	}
+	if variantID.Valid {
+		id := variantID.Int64
+		data.VariantID = &id
+	}
+	return outbox.StockChangedEvent(data)
+}
+
+// ListMovements returns the ledger of a product, newest first
+func (s *Service) ListMovements(ctx context.Context, productID int64, limit int32, cursor string) (utils.Page[repo.InventoryMovement], error) {
+	params := repo.ListInventoryMovementsPageParams{
+		ProductID: productID,
+		PageLimit: limit + 1,
+	}
+
+	if cursor != "" {
+		c, err := utils.DecodeCursor(cursor)
+		if err != nil {
+			return utils.Page[repo.InventoryMovement]{}, err
+		}
+		params.CursorID = pgtype.Int8{Int64: c.ID, Valid: true}
+	}
+
+	movements, err := s.repo.ListInventoryMovementsPage(ctx, params)
+	if err != nil {
+		return utils.Page[repo.InventoryMovement]{}, &utils.DatabaseError{
+			Query: "ListInventoryMovementsPage",
+			Err:   err,
+		}
+	}
+
+	return utils.NewPage(movements, limit, func(m repo.InventoryMovement) utils.Cursor {
+		return utils.Cursor{ID: m.ID}
+	}), nil
+}
+
+// Reconcile reports every product and variant whose stock differs from its ledger.
+// With apply set the stock column is rebuilt from the ledger, the ledger itself is never changed
+func (s *Service) Reconcile(ctx context.Context, apply bool) ([]Drift, error) {
+	rows, err := s.repo.ListStockDrift(ctx)
+	if err != nil {
+		return nil, &utils.DatabaseError{Query: "ListStockDrift", Err: err}
+	}
+
+	drift := make([]Drift, 0, len(rows))
+	for _, row := range rows {
+		drift = append(drift, Drift{
+			ProductID:   row.ProductID,
+			Stock:       row.Stock,
+			LedgerStock: row.LedgerStock,
+			Difference:  row.Stock - row.LedgerStock,
+		})
+
+		if !apply {
+			continue
+		}
+		_, err := s.repo.SetProductStock(ctx, repo.SetProductStockParams{
+			Stock: row.LedgerStock,
+			ID:    row.ProductID,
+		})
+		if err != nil {
+			return nil, &utils.DatabaseError{Query: "SetProductStock", Err: err}
+		}
+	}
+
+	variantRows, err := s.repo.ListVariantStockDrift(ctx)
+	if err != nil {
+		return nil, &utils.DatabaseError{Query: "ListVariantStockDrift", Err: err}
+	}
+
+	for _, row := range variantRows {
+		variantID := row.VariantID
+		drift = append(drift, Drift{
+			ProductID:   row.ProductID,
+			VariantID:   &variantID,
+			Stock:       row.Stock,
+			LedgerStock: row.LedgerStock,
+			Difference:  row.Stock - row.LedgerStock,
+		})
+
+		if !apply {
+			continue
+		}
+		_, err := s.repo.SetVariantStock(ctx, repo.SetVariantStockParams{
+			Stock: row.LedgerStock,
+			ID:    row.VariantID,
+		})
+		if err != nil {
+			return nil, &utils.DatabaseError{Query: "SetVariantStock", Err: err}
+		}
+	}
+
+	return drift, nil
+}
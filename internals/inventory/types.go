@@ -0,0 +1,30 @@
+package inventory
+
+// reasons recorded by the system, manual adjustments use the reason codes of the products package
+const (
+	ReasonInitialStock   = "initial_stock"
+	ReasonOpeningBalance = "opening_balance"
+	ReasonOrderPaid      = "order_paid"
+	ReasonOrderCancelled = "order_cancelled"
+	ReasonReturned       = "returned"
+	ReasonImport         = "import"
+)
+
+// what a movement refers to
+const (
+	ReferenceOrder   = "order"
+	ReferenceProduct = "product"
+	ReferenceReturn  = "return"
+)
+
+// ActorSystem is recorded for changes made by background jobs and commands
+const ActorSystem = "system"
+
+// Drift is a product, or a variant of it, whose stock column disagrees with the sum of its ledger
+type Drift struct {
+	ProductID   int64  `json:"product_id"`
+	VariantID   *int64 `json:"variant_id,omitempty"`
+	Stock       int32  `json:"stock"`
+	LedgerStock int32  `json:"ledger_stock"`
+	Difference  int32  `json:"difference"`
+}
@@ -0,0 +1,236 @@
+package media
+
+import (
+	"ecomApis/internals/utils"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// room for the multipart boundaries, headers and text fields around the file
+const multipartOverhead = 64 << 10
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(s *Service) *Handler {
+	return &Handler{
+		service: s,
+	}
+}
+
+func (h *Handler) ListImages(w http.ResponseWriter, r *http.Request) {
+	productID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid product ID"})
+		return
+	}
+
+	images, err := h.service.ListImages(r.Context(), productID)
+	if err != nil {
+		writeMediaError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, images)
+}
+
+// UploadImage handles a multipart/form-data POST with the image in "file" and
+// optional "alt_text" and "primary" fields
+func (h *Handler) UploadImage(w http.ResponseWriter, r *http.Request) {
+	productID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid product ID"})
+		return
+	}
+
+	maxBytes := h.service.MaxUploadBytes()
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes+multipartOverhead)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "expected a multipart/form-data body"})
+		return
+	}
+
+	var upload Upload
+	var hasFile bool
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err == nil {
+			switch part.FormName() {
+			case "file":
+				hasFile = true
+				upload.ContentType = part.Header.Get("Content-Type")
+				// one byte over the limit is enough to reject it
+				upload.Data, err = io.ReadAll(io.LimitReader(part, maxBytes+1))
+			case "alt_text":
+				var text []byte
+				text, err = io.ReadAll(io.LimitReader(part, 1024))
+				upload.AltText = string(text)
+			case "primary":
+				var value []byte
+				value, err = io.ReadAll(io.LimitReader(part, 16))
+				if err == nil {
+					upload.Primary, err = strconv.ParseBool(string(value))
+					if err != nil {
+						part.Close()
+						utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "primary must be true or false"})
+						return
+					}
+				}
+			}
+			part.Close()
+		}
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				utils.WriteJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": "request body too large"})
+				return
+			}
+			utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid multipart body"})
+			return
+		}
+	}
+	if !hasFile {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "missing file field"})
+		return
+	}
+
+	image, err := h.service.UploadImage(r.Context(), productID, upload)
+	if err != nil {
+		writeMediaError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, image)
+}
+
+func (h *Handler) UpdateImage(w http.ResponseWriter, r *http.Request) {
+	productID, imageID, ok := parseImagePath(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateImageRequest
+	err := utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	image, err := h.service.UpdateImage(r.Context(), productID, imageID, req)
+	if err != nil {
+		writeMediaError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, image)
+}
+
+func (h *Handler) ReorderImages(w http.ResponseWriter, r *http.Request) {
+	productID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid product ID"})
+		return
+	}
+
+	var req ReorderImagesRequest
+	err = utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	images, err := h.service.ReorderImages(r.Context(), productID, req)
+	if err != nil {
+		writeMediaError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, images)
+}
+
+func (h *Handler) DeleteImage(w http.ResponseWriter, r *http.Request) {
+	productID, imageID, ok := parseImagePath(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.service.DeleteImage(r.Context(), productID, imageID)
+	if err != nil {
+		writeMediaError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+// ServeFile handles GET /media/*, streaming a file from the blob store. File names are
+// never reused, so responses can be cached for good
+func (h *Handler) ServeFile(w http.ResponseWriter, r *http.Request) {
+	key := chi.URLParam(r, "*")
+
+	body, err := h.service.Store().Get(r.Context(), key)
+	if err != nil {
+		if errors.Is(err, ErrBlobNotFound) {
+			utils.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "file not found"})
+			return
+		}
+		writeMediaError(w, &utils.ExternalServiceError{Service: h.service.Store().Name(), Err: err})
+		return
+	}
+	defer body.Close()
+
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = io.Copy(w, body)
+	if err != nil {
+		slog.Warn("failed to stream media file", "key", key, "error", err)
+	}
+}
+
+// parseImagePath reads /products/{id}/images/{imageId}, writing the error response when either is invalid
+func parseImagePath(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	productID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid product ID"})
+		return 0, 0, false
+	}
+	imageID, err := strconv.ParseInt(chi.URLParam(r, "imageId"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid image ID"})
+		return 0, 0, false
+	}
+	return productID, imageID, true
+}
+
+func writeMediaError(w http.ResponseWriter, err error) {
+	switch e := err.(type) {
+	case *utils.ValidationError:
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": e.Error()})
+	case *utils.NotFoundError:
+		utils.WriteJSON(w, http.StatusNotFound, map[string]string{"error": e.Error()})
+	case *utils.ExternalServiceError:
+		utils.WriteJSON(w, http.StatusBadGateway, map[string]string{"error": e.Error()})
+	case *utils.DatabaseError:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": e.Error()})
+	default:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,128 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestThumbnailSize(t *testing.T) {
+	tests := []struct {
+		name         string
+		w, h         int
+		size         int
+		wantW, wantH int
+	}{
+		{name: "landscape", w: 1000, h: 500, size: 320, wantW: 320, wantH: 160},
+		{name: "portrait", w: 600, h: 1200, size: 320, wantW: 160, wantH: 320},
+		{name: "square", w: 800, h: 800, size: 320, wantW: 320, wantH: 320},
+		{name: "smaller than the box", w: 200, h: 100, size: 320, wantW: 200, wantH: 100},
+		{name: "one side too large", w: 400, h: 100, size: 320, wantW: 320, wantH: 80},
+		{name: "thin strip", w: 2000, h: 1, size: 320, wantW: 320, wantH: 1},
+		{name: "rounds down", w: 1000, h: 333, size: 100, wantW: 100, wantH: 33},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := thumbnail(image.NewRGBA(image.Rect(0, 0, tt.w, tt.h)), tt.size).Bounds()
+			if got.Min != (image.Point{}) || got.Dx() != tt.wantW || got.Dy() != tt.wantH {
+				t.Errorf("thumbnail of %dx%d = %v, want %dx%d at the origin", tt.w, tt.h, got, tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestThumbnailAveragesPixels(t *testing.T) {
+	// a 4x2 image, the left half a checkerboard of black and white, the right half red
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	src.Set(0, 0, color.White)
+	src.Set(1, 0, color.Black)
+	src.Set(0, 1, color.Black)
+	src.Set(1, 1, color.White)
+	for y := 0; y < 2; y++ {
+		for x := 2; x < 4; x++ {
+			src.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+
+	dst := thumbnail(src, 2)
+	if dst.Bounds().Dx() != 2 || dst.Bounds().Dy() != 1 {
+		t.Fatalf("thumbnail size = %v, want 2x1", dst.Bounds())
+	}
+	if got, want := dst.RGBAAt(0, 0), (color.RGBA{R: 127, G: 127, B: 127, A: 255}); got != want {
+		t.Errorf("checkerboard pixel = %v, want %v", got, want)
+	}
+	if got, want := dst.RGBAAt(1, 0), (color.RGBA{R: 255, A: 255}); got != want {
+		t.Errorf("red pixel = %v, want %v", got, want)
+	}
+}
+
+func TestThumbnailTransparency(t *testing.T) {
+	// half opaque blue and half fully transparent averages to half transparent blue
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	src.SetNRGBA(0, 0, color.NRGBA{B: 255, A: 255})
+	src.SetNRGBA(1, 0, color.NRGBA{R: 255, G: 255, A: 0})
+
+	got := thumbnail(src, 1).RGBAAt(0, 0)
+	if want := (color.RGBA{B: 127, A: 127}); got != want {
+		t.Errorf("pixel = %v, want %v, transparent pixels must not add their color", got, want)
+	}
+}
+
+func TestThumbnailOffsetBounds(t *testing.T) {
+	// a sub image does not start at the origin
+	full := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			full.Set(x, y, color.Black)
+		}
+	}
+	full.Set(2, 2, color.White)
+	full.Set(3, 2, color.White)
+	full.Set(2, 3, color.White)
+	full.Set(3, 3, color.White)
+
+	got := thumbnail(full.SubImage(image.Rect(2, 2, 4, 4)), 1).RGBAAt(0, 0)
+	if want := (color.RGBA{R: 255, G: 255, B: 255, A: 255}); got != want {
+		t.Errorf("pixel = %v, want %v from the sub image only", got, want)
+	}
+}
+
+func TestEncodeThumbnail(t *testing.T) {
+	img := thumbnail(image.NewRGBA(image.Rect(0, 0, 10, 10)), 5)
+
+	tests := []struct {
+		sourceType string
+		want       string
+	}{
+		{"image/jpeg", "image/jpeg"},
+		{"image/png", "image/png"},
+		{"image/gif", "image/png"},
+	}
+	for _, tt := range tests {
+		data, contentType, err := encodeThumbnail(img, tt.sourceType)
+		if err != nil {
+			t.Fatalf("encodeThumbnail(%s): %v", tt.sourceType, err)
+		}
+		if contentType != tt.want {
+			t.Errorf("encodeThumbnail(%s) type = %s, want %s", tt.sourceType, contentType, tt.want)
+		}
+		if sniffed, ok := sniffImage(data); !ok || sniffed != tt.want {
+			t.Errorf("encodeThumbnail(%s) wrote %s", tt.sourceType, sniffed)
+		}
+	}
+}
+
+func TestSniffImage(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	if contentType, ok := sniffImage(buf.Bytes()); !ok || contentType != "image/png" {
+		t.Errorf("sniffImage(png) = %s, %v", contentType, ok)
+	}
+	if contentType, ok := sniffImage([]byte("%PDF-1.7 not an image")); ok {
+		t.Errorf("sniffImage(pdf) = %s accepted", contentType)
+	}
+}
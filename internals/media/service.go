@@ -0,0 +1,427 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"log/slog"
+	"mime"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	defaultMaxUploadBytes = 10 << 20
+	defaultThumbnailSize  = 320
+)
+
+type Service struct {
+	repo   *repo.Queries
+	db     *pgxpool.Pool
+	store  BlobStore
+	config Config
+}
+
+func NewService(r *repo.Queries, db *pgxpool.Pool, store BlobStore, cfg Config) *Service {
+	if cfg.MaxUploadBytes <= 0 {
+		cfg.MaxUploadBytes = defaultMaxUploadBytes
+	}
+	if cfg.ThumbnailSize <= 0 {
+		cfg.ThumbnailSize = defaultThumbnailSize
+	}
+
+	return &Service{
+		repo:   r,
+		db:     db,
+		store:  store,
+		config: cfg,
+	}
+}
+
+// MaxUploadBytes is the largest image the service accepts
+func (s *Service) MaxUploadBytes() int64 {
+	return s.config.MaxUploadBytes
+}
+
+// Store is where the images are kept, the media route serves files from it
+func (s *Service) Store() BlobStore {
+	return s.store
+}
+
+// ListImages returns the images of a product in display order
+func (s *Service) ListImages(ctx context.Context, productID int64) ([]Image, error) {
+	_, err := s.repo.FindProductByID(ctx, productID)
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return nil, &utils.NotFoundError{Resource: "Product", ID: strconv.FormatInt(productID, 10)}
+		}
+		return nil, &utils.DatabaseError{Query: "FindProductByID", Err: err}
+	}
+
+	return s.ProductImages(ctx, productID)
+}
+
+// ProductImages returns the images of a product without checking that it exists
+func (s *Service) ProductImages(ctx context.Context, productID int64) ([]Image, error) {
+	rows, err := s.repo.ListProductImages(ctx, productID)
+	if err != nil {
+		return nil, &utils.DatabaseError{Query: "ListProductImages", Err: err}
+	}
+
+	images := make([]Image, 0, len(rows))
+	for _, row := range rows {
+		images = append(images, newImage(s.store, row))
+	}
+	return images, nil
+}
+
+// UploadImage validates the image, stores it with a thumbnail and adds it after the existing
+// images. The first image of a product always becomes its primary image
+func (s *Service) UploadImage(ctx context.Context, productID int64, upload Upload) (Image, error) {
+	if len(upload.Data) == 0 {
+		return Image{}, &utils.ValidationError{Field: "file", Message: "cannot be empty"}
+	}
+	if int64(len(upload.Data)) > s.config.MaxUploadBytes {
+		return Image{}, &utils.ValidationError{
+			Field:   "file",
+			Message: fmt.Sprintf("must be at most %d bytes", s.config.MaxUploadBytes),
+		}
+	}
+
+	contentType, ok := sniffImage(upload.Data)
+	if !ok {
+		return Image{}, &utils.ValidationError{Field: "file", Message: "must be a JPEG, PNG or GIF image"}
+	}
+	if declared, _, err := mime.ParseMediaType(upload.ContentType); err == nil &&
+		declared != "application/octet-stream" && declared != contentType {
+		return Image{}, &utils.ValidationError{
+			Field:   "file",
+			Message: fmt.Sprintf("is declared as %s but contains %s", declared, contentType),
+		}
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(upload.Data))
+	if err != nil || cfg.Width <= 0 || cfg.Height <= 0 {
+		return Image{}, &utils.ValidationError{Field: "file", Message: "is not a readable image"}
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return Image{}, &utils.ValidationError{
+			Field:   "file",
+			Message: fmt.Sprintf("must have at most %d pixels", maxImagePixels),
+		}
+	}
+	img, _, err := image.Decode(bytes.NewReader(upload.Data))
+	if err != nil {
+		return Image{}, &utils.ValidationError{Field: "file", Message: "is not a readable image"}
+	}
+
+	thumb, thumbType, err := encodeThumbnail(thumbnail(img, s.config.ThumbnailSize), contentType)
+	if err != nil {
+		return Image{}, fmt.Errorf("encode thumbnail: %w", err)
+	}
+
+	// no files for products that do not exist
+	_, err = s.repo.FindProductByID(ctx, productID)
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return Image{}, &utils.NotFoundError{Resource: "Product", ID: strconv.FormatInt(productID, 10)}
+		}
+		return Image{}, &utils.DatabaseError{Query: "FindProductByID", Err: err}
+	}
+
+	name, err := randomName()
+	if err != nil {
+		return Image{}, err
+	}
+	prefix := "products/" + strconv.FormatInt(productID, 10) + "/" + name
+	key := prefix + imageExtensions[contentType]
+	thumbKey := prefix + "_thumb" + imageExtensions[thumbType]
+
+	err = s.store.Put(ctx, key, bytes.NewReader(upload.Data), int64(len(upload.Data)), contentType)
+	if err != nil {
+		return Image{}, &utils.ExternalServiceError{Service: s.store.Name(), Err: err}
+	}
+	err = s.store.Put(ctx, thumbKey, bytes.NewReader(thumb), int64(len(thumb)), thumbType)
+	if err != nil {
+		s.removeBlobs(ctx, key)
+		return Image{}, &utils.ExternalServiceError{Service: s.store.Name(), Err: err}
+	}
+
+	row, err := s.addImage(ctx, repo.CreateProductImageParams{
+		ProductID:    productID,
+		StorageKey:   key,
+		ThumbnailKey: thumbKey,
+		ContentType:  contentType,
+		SizeBytes:    int64(len(upload.Data)),
+		Width:        int32(cfg.Width),
+		Height:       int32(cfg.Height),
+		AltText:      upload.AltText,
+		IsPrimary:    upload.Primary,
+	})
+	if err != nil {
+		// the row was never written, so nothing points at the files
+		s.removeBlobs(ctx, key, thumbKey)
+		return Image{}, err
+	}
+
+	slog.Info("product image uploaded",
+		"product_id", productID,
+		"image_id", row.ID,
+		"content_type", contentType,
+		"size_bytes", row.SizeBytes,
+	)
+
+	return newImage(s.store, row), nil
+}
+
+// addImage writes the image row behind the existing images, the product row lock keeps
+// concurrent uploads from taking the same position or both becoming primary
+func (s *Service) addImage(ctx context.Context, arg repo.CreateProductImageParams) (repo.ProductImage, error) {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return repo.ProductImage{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	err = lockProduct(ctx, qtx, arg.ProductID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.ProductImage{}, err
+	}
+
+	arg.Position, err = qtx.NextProductImagePosition(ctx, arg.ProductID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.ProductImage{}, &utils.DatabaseError{Query: "NextProductImagePosition", Err: err}
+	}
+
+	hasPrimary, err := qtx.ProductHasPrimaryImage(ctx, arg.ProductID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.ProductImage{}, &utils.DatabaseError{Query: "ProductHasPrimaryImage", Err: err}
+	}
+	if arg.IsPrimary && hasPrimary {
+		err = qtx.ClearPrimaryProductImage(ctx, arg.ProductID)
+		if err != nil {
+			tx.Rollback(ctx)
+			return repo.ProductImage{}, &utils.DatabaseError{Query: "ClearPrimaryProductImage", Err: err}
+		}
+	}
+	arg.IsPrimary = arg.IsPrimary || !hasPrimary
+
+	row, err := qtx.CreateProductImage(ctx, arg)
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.ProductImage{}, &utils.DatabaseError{Query: "CreateProductImage", Err: err}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return repo.ProductImage{}, fmt.Errorf("commit tx: %w", err)
+	}
+	return row, nil
+}
+
+// UpdateImage changes the alt text, position or primary flag of an image
+func (s *Service) UpdateImage(ctx context.Context, productID, imageID int64, req UpdateImageRequest) (Image, error) {
+	if req.Primary != nil && !*req.Primary {
+		return Image{}, &utils.ValidationError{Field: "primary", Message: "make another image primary instead"}
+	}
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return Image{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	err = lockProduct(ctx, qtx, productID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return Image{}, err
+	}
+
+	row, err := qtx.GetProductImage(ctx, repo.GetProductImageParams{ID: imageID, ProductID: productID})
+	if err != nil {
+		tx.Rollback(ctx)
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return Image{}, &utils.NotFoundError{Resource: "ProductImage", ID: strconv.FormatInt(imageID, 10)}
+		}
+		return Image{}, &utils.DatabaseError{Query: "GetProductImage", Err: err}
+	}
+
+	arg := repo.UpdateProductImageParams{
+		AltText:   row.AltText,
+		Position:  row.Position,
+		IsPrimary: row.IsPrimary,
+		ID:        row.ID,
+	}
+	if req.AltText != nil {
+		arg.AltText = *req.AltText
+	}
+	if req.Position != nil {
+		arg.Position = *req.Position
+	}
+	if req.Primary != nil && !row.IsPrimary {
+		err = qtx.ClearPrimaryProductImage(ctx, productID)
+		if err != nil {
+			tx.Rollback(ctx)
+			return Image{}, &utils.DatabaseError{Query: "ClearPrimaryProductImage", Err: err}
+		}
+		arg.IsPrimary = true
+	}
+
+	row, err = qtx.UpdateProductImage(ctx, arg)
+	if err != nil {
+		tx.Rollback(ctx)
+		return Image{}, &utils.DatabaseError{Query: "UpdateProductImage", Err: err}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return Image{}, fmt.Errorf("commit tx: %w", err)
+	}
+	return newImage(s.store, row), nil
+}
+
+// ReorderImages numbers the images of a product in the given order, the list must name
+// every image exactly once
+func (s *Service) ReorderImages(ctx context.Context, productID int64, req ReorderImagesRequest) ([]Image, error) {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	err = lockProduct(ctx, qtx, productID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return nil, err
+	}
+
+	rows, err := qtx.ListProductImages(ctx, productID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return nil, &utils.DatabaseError{Query: "ListProductImages", Err: err}
+	}
+
+	remaining := make(map[int64]bool, len(rows))
+	for _, row := range rows {
+		remaining[row.ID] = true
+	}
+	for _, id := range req.ImageIDs {
+		if !remaining[id] {
+			tx.Rollback(ctx)
+			return nil, &utils.ValidationError{
+				Field:   "image_ids",
+				Message: fmt.Sprintf("image %d is not an image of product %d or is listed twice", id, productID),
+			}
+		}
+		delete(remaining, id)
+	}
+	if len(remaining) > 0 {
+		tx.Rollback(ctx)
+		return nil, &utils.ValidationError{Field: "image_ids", Message: "must list every image of the product"}
+	}
+
+	for i, id := range req.ImageIDs {
+		err = qtx.SetProductImagePosition(ctx, repo.SetProductImagePositionParams{Position: int32(i), ID: id})
+		if err != nil {
+			tx.Rollback(ctx)
+			return nil, &utils.DatabaseError{Query: "SetProductImagePosition", Err: err}
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return s.ProductImages(ctx, productID)
+}
+
+// DeleteImage removes an image and its files. When it was the primary image the first
+// remaining image takes its place
+func (s *Service) DeleteImage(ctx context.Context, productID, imageID int64) error {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	err = lockProduct(ctx, qtx, productID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return err
+	}
+
+	row, err := qtx.DeleteProductImage(ctx, repo.DeleteProductImageParams{ID: imageID, ProductID: productID})
+	if err != nil {
+		tx.Rollback(ctx)
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return &utils.NotFoundError{Resource: "ProductImage", ID: strconv.FormatInt(imageID, 10)}
+		}
+		return &utils.DatabaseError{Query: "DeleteProductImage", Err: err}
+	}
+
+	if row.IsPrimary {
+		err = qtx.PromoteFirstProductImage(ctx, productID)
+		if err != nil {
+			tx.Rollback(ctx)
+			return &utils.DatabaseError{Query: "PromoteFirstProductImage", Err: err}
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	s.RemoveFiles(ctx, []repo.ProductImage{row})
+	return nil
+}
+
+// RemoveFiles deletes the files of image rows that are already gone from the database.
+// Failures are only logged, an orphaned file is harmless
+func (s *Service) RemoveFiles(ctx context.Context, rows []repo.ProductImage) {
+	for _, row := range rows {
+		s.removeBlobs(ctx, row.StorageKey, row.ThumbnailKey)
+	}
+}
+
+func (s *Service) removeBlobs(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		err := s.store.Delete(ctx, key)
+		if err != nil {
+			slog.Warn("failed to delete blob", "store", s.store.Name(), "key", key, "error", err)
+		}
+	}
+}
+
+func lockProduct(ctx context.Context, q *repo.Queries, productID int64) error {
+	_, err := q.GetProductForUpdate(ctx, productID)
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return &utils.NotFoundError{Resource: "Product", ID: strconv.FormatInt(productID, 10)}
+		}
+		return &utils.DatabaseError{Query: "GetProductForUpdate", Err: err}
+	}
+	return nil
+}
+
+// randomName makes the file names unguessable and unique, so stored files never change
+// and can be cached forever
+func randomName() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("generate file name: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
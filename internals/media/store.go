@@ -0,0 +1,66 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// BlobStore keeps the uploaded files. Keys are slash separated paths such as
+// "products/12/3f9c.jpg", URL is where clients download a key from
+type BlobStore interface {
+	Name() string
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Get returns ErrBlobNotFound when nothing is stored under the key
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete does not fail when the key is already gone
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+var ErrBlobNotFound = errors.New("blob not found")
+
+// blob store backends
+const (
+	LocalStoreName = "local"
+	S3StoreName    = "s3"
+)
+
+// Config selects the blob store and the limits of uploaded images
+type Config struct {
+	// Store is "local" or "s3"
+	Store string
+	// LocalDir is where the local store writes files, BaseURL is the prefix of their URLs,
+	// "/media" when the API serves them itself
+	LocalDir string
+	BaseURL  string
+	S3       S3Config
+
+	// MaxUploadBytes caps the size of one image, ThumbnailSize is the longest side of thumbnails
+	MaxUploadBytes int64
+	ThumbnailSize  int
+}
+
+// NewBlobStore builds the configured store
+func NewBlobStore(cfg Config) (BlobStore, error) {
+	switch cfg.Store {
+	case "", LocalStoreName:
+		return NewLocalStore(cfg.LocalDir, cfg.BaseURL)
+	case S3StoreName:
+		return NewS3Store(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown blob store %q", cfg.Store)
+	}
+}
+
+// validKey rejects keys that are empty, absolute or climb out of the store
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	clean := path.Clean(key)
+	return clean == key && clean != ".." && !strings.HasPrefix(clean, "../")
+}
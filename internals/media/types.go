@@ -0,0 +1,62 @@
+package media
+
+import (
+	"ecomApis/internals/repo"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Upload is one image file posted to a product
+type Upload struct {
+	Data []byte
+	// ContentType is what the client declared, the stored type comes from the data itself
+	ContentType string
+	AltText     string
+	Primary     bool
+}
+
+// UpdateImageRequest only touches the fields that were sent. An image stops being primary
+// when another one is made primary
+type UpdateImageRequest struct {
+	AltText  *string `json:"alt_text"`
+	Position *int32  `json:"position"`
+	Primary  *bool   `json:"primary"`
+}
+
+// ReorderImagesRequest lists every image of a product in its new order
+type ReorderImagesRequest struct {
+	ImageIDs []int64 `json:"image_ids"`
+}
+
+// Image is a product image with the URLs of the file and its thumbnail
+type Image struct {
+	ID           int64            `json:"id"`
+	ProductID    int64            `json:"product_id"`
+	URL          string           `json:"url"`
+	ThumbnailURL string           `json:"thumbnail_url"`
+	ContentType  string           `json:"content_type"`
+	SizeBytes    int64            `json:"size_bytes"`
+	Width        int32            `json:"width"`
+	Height       int32            `json:"height"`
+	AltText      string           `json:"alt_text"`
+	Position     int32            `json:"position"`
+	Primary      bool             `json:"primary"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+}
+
+func newImage(store BlobStore, row repo.ProductImage) Image {
+	return Image{
+		ID:           row.ID,
+		ProductID:    row.ProductID,
+		URL:          store.URL(row.StorageKey),
+		ThumbnailURL: store.URL(row.ThumbnailKey),
+		ContentType:  row.ContentType,
+		SizeBytes:    row.SizeBytes,
+		Width:        row.Width,
+		Height:       row.Height,
+		AltText:      row.AltText,
+		Position:     row.Position,
+		Primary:      row.IsPrimary,
+		CreatedAt:    row.CreatedAt,
+	}
+}
@@ -0,0 +1,111 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var ErrInvalidRate = errors.New("invalid exchange rate")
+
+// RoundingMode decides what happens to the fraction of a minor unit left after a conversion
+type RoundingMode string
+
+const (
+	RoundHalfUp   RoundingMode = "half_up"
+	RoundHalfEven RoundingMode = "half_even"
+	RoundUp       RoundingMode = "up"
+	RoundDown     RoundingMode = "down"
+)
+
+func IsValidRoundingMode(mode RoundingMode) bool {
+	switch mode {
+	case RoundHalfUp, RoundHalfEven, RoundUp, RoundDown:
+		return true
+	}
+	return false
+}
+
+// Rounding is how converted amounts are rounded. Increment rounds to a multiple of that many
+// minor units, e.g. 5 for CHF prices that end in 0.05 steps
+type Rounding struct {
+	Mode      RoundingMode
+	Increment int64
+}
+
+// ParseRate parses a decimal exchange rate such as "1.0845", floats are never involved
+func ParseRate(s string) (*big.Rat, error) {
+	rate, ok := new(big.Rat).SetString(s)
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("%w '%s'", ErrInvalidRate, s)
+	}
+	return rate, nil
+}
+
+// Convert returns m in currency to, where rate is the price of one unit of m's currency in to
+func (m Money) Convert(to string, rate *big.Rat, rounding Rounding) (Money, error) {
+	if rate == nil || rate.Sign() <= 0 {
+		return Money{}, ErrInvalidRate
+	}
+	if !IsValidCurrency(to) {
+		return Money{}, fmt.Errorf("%w '%s'", ErrInvalidCurrency, to)
+	}
+	increment := rounding.Increment
+	if increment <= 0 {
+		increment = 1
+	}
+
+	// amount * rate * 10^(digits of to - digits of from), in minor units of to
+	value := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Amount), rate)
+	shift := MinorUnits(to) - MinorUnits(m.Currency)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(shift))), nil))
+	if shift >= 0 {
+		value.Mul(value, scale)
+	} else {
+		value.Quo(value, scale)
+	}
+
+	steps := round(value.Quo(value, new(big.Rat).SetInt64(increment)), rounding.Mode)
+	result := steps.Mul(steps, big.NewInt(increment))
+	if !result.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: result.Int64(), Currency: to}, nil
+}
+
+// round turns r into an integer according to mode, up and down are away from and towards zero
+func round(r *big.Rat, mode RoundingMode) *big.Int {
+	q, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if rem.Sign() == 0 {
+		return q
+	}
+
+	away := false
+	switch mode {
+	case RoundUp:
+		away = true
+	case RoundDown:
+		away = false
+	default:
+		// compare the remainder with half of the denominator
+		cmp := new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(r.Denom())
+		switch {
+		case cmp > 0:
+			away = true
+		case cmp == 0:
+			away = mode == RoundHalfUp || q.Bit(0) == 1
+		}
+	}
+
+	if away {
+		q.Add(q, big.NewInt(int64(r.Sign())))
+	}
+	return q
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
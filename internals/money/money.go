@@ -0,0 +1,125 @@
+// Package money represents amounts as integer minor units with an ISO-4217 currency.
+// All arithmetic is checked, it fails on overflow and on mixed currencies instead of guessing.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrOverflow         = errors.New("amount overflows")
+	ErrInvalidCurrency  = errors.New("invalid currency")
+)
+
+// Money is an amount in the minor unit of its currency, 1999 USD is $19.99
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// New returns an amount in the given currency, the code is normalized and validated
+func New(amount int64, currency string) (Money, error) {
+	currency = NormalizeCurrency(currency)
+	if !IsValidCurrency(currency) {
+		return Money{}, fmt.Errorf("%w '%s'", ErrInvalidCurrency, currency)
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// Zero returns a zero amount in the given currency
+func Zero(currency string) Money {
+	return Money{Currency: currency}
+}
+
+// Normalize validates the currency of m and returns it with the code normalized
+func (m Money) Normalize() (Money, error) {
+	return New(m.Amount, m.Currency)
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// SameCurrency reports whether both amounts are in the same currency
+func (m Money) SameCurrency(o Money) bool {
+	return m.Currency == o.Currency
+}
+
+// Add returns m + o
+func (m Money) Add(o Money) (Money, error) {
+	if !m.SameCurrency(o) {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	if (o.Amount > 0 && m.Amount > math.MaxInt64-o.Amount) || (o.Amount < 0 && m.Amount < math.MinInt64-o.Amount) {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}, nil
+}
+
+// Sub returns m - o
+func (m Money) Sub(o Money) (Money, error) {
+	if o.Amount == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return m.Add(Money{Amount: -o.Amount, Currency: o.Currency})
+}
+
+// Mul returns m * n, used for line totals
+func (m Money) Mul(n int64) (Money, error) {
+	if m.Amount == 0 || n == 0 {
+		return Money{Currency: m.Currency}, nil
+	}
+	result := m.Amount * n
+	if result/n != m.Amount || (m.Amount == -1 && n == math.MinInt64) || (n == -1 && m.Amount == math.MinInt64) {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: result, Currency: m.Currency}, nil
+}
+
+// Sum adds up amounts that must all be in currency
+func Sum(currency string, amounts ...Money) (Money, error) {
+	total := Zero(currency)
+	for _, a := range amounts {
+		var err error
+		total, err = total.Add(a)
+		if err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// String formats the amount in major units, e.g. "19.99 USD"
+func (m Money) String() string {
+	digits := MinorUnits(m.Currency)
+
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+	}
+	abs := strconv.FormatUint(absUint(amount), 10)
+	if digits == 0 {
+		return sign + abs + " " + m.Currency
+	}
+	if len(abs) <= digits {
+		abs = strings.Repeat("0", digits-len(abs)+1) + abs
+	}
+	return sign + abs[:len(abs)-digits] + "." + abs[len(abs)-digits:] + " " + m.Currency
+}
+
+func absUint(n int64) uint64 {
+	if n < 0 {
+		return uint64(-(n + 1)) + 1
+	}
+	return uint64(n)
+}
@@ -0,0 +1,204 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	m, err := New(1999, " usd ")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if m != (Money{Amount: 1999, Currency: "USD"}) {
+		t.Errorf("New = %+v, want 1999 USD", m)
+	}
+
+	if _, err := New(1, "XXX"); !errors.Is(err, ErrInvalidCurrency) {
+		t.Errorf("New with unknown currency: err = %v, want ErrInvalidCurrency", err)
+	}
+}
+
+func TestAddSub(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    Money
+		add     int64
+		sub     int64
+		wantErr error
+	}{
+		{name: "plain", a: Money{1000, "USD"}, b: Money{250, "USD"}, add: 1250, sub: 750},
+		{name: "negative", a: Money{-100, "EUR"}, b: Money{-50, "EUR"}, add: -150, sub: -50},
+		{name: "mixed currencies", a: Money{100, "USD"}, b: Money{100, "EUR"}, wantErr: ErrCurrencyMismatch},
+		{name: "overflow", a: Money{math.MaxInt64, "USD"}, b: Money{1, "USD"}, wantErr: ErrOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sum, err := tt.a.Add(tt.b)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Add: err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Add: %v", err)
+			}
+			if sum.Amount != tt.add || sum.Currency != tt.a.Currency {
+				t.Errorf("Add = %v, want %d %s", sum, tt.add, tt.a.Currency)
+			}
+
+			diff, err := tt.a.Sub(tt.b)
+			if err != nil {
+				t.Fatalf("Sub: %v", err)
+			}
+			if diff.Amount != tt.sub {
+				t.Errorf("Sub = %v, want %d", diff, tt.sub)
+			}
+		})
+	}
+}
+
+func TestSubOverflow(t *testing.T) {
+	if _, err := (Money{0, "USD"}).Sub(Money{math.MinInt64, "USD"}); !errors.Is(err, ErrOverflow) {
+		t.Errorf("0 - MinInt64: err = %v, want ErrOverflow", err)
+	}
+	if _, err := (Money{math.MinInt64, "USD"}).Sub(Money{1, "USD"}); !errors.Is(err, ErrOverflow) {
+		t.Errorf("MinInt64 - 1: err = %v, want ErrOverflow", err)
+	}
+}
+
+func TestMul(t *testing.T) {
+	tests := []struct {
+		amount  int64
+		n       int64
+		want    int64
+		wantErr bool
+	}{
+		{amount: 1999, n: 3, want: 5997},
+		{amount: 1999, n: 0, want: 0},
+		{amount: -250, n: 4, want: -1000},
+		{amount: math.MaxInt64, n: 2, wantErr: true},
+		{amount: -1, n: math.MinInt64, wantErr: true},
+		{amount: math.MinInt64, n: -1, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Money{tt.amount, "USD"}.Mul(tt.n)
+		if tt.wantErr {
+			if !errors.Is(err, ErrOverflow) {
+				t.Errorf("%d * %d: err = %v, want ErrOverflow", tt.amount, tt.n, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d * %d: %v", tt.amount, tt.n, err)
+			continue
+		}
+		if got.Amount != tt.want || got.Currency != "USD" {
+			t.Errorf("%d * %d = %v, want %d USD", tt.amount, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestSum(t *testing.T) {
+	total, err := Sum("USD", Money{100, "USD"}, Money{250, "USD"}, Money{-50, "USD"})
+	if err != nil {
+		t.Fatalf("Sum: %v", err)
+	}
+	if total != (Money{300, "USD"}) {
+		t.Errorf("Sum = %v, want 3.00 USD", total)
+	}
+
+	if _, err := Sum("USD", Money{100, "USD"}, Money{100, "EUR"}); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Sum of mixed currencies: err = %v, want ErrCurrencyMismatch", err)
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{Money{1999, "USD"}, "19.99 USD"},
+		{Money{5, "USD"}, "0.05 USD"},
+		{Money{-5, "EUR"}, "-0.05 EUR"},
+		{Money{1500, "JPY"}, "1500 JPY"},
+		{Money{1234, "KWD"}, "1.234 KWD"},
+		{Money{math.MinInt64, "USD"}, "-92233720368547758.08 USD"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.String(); got != tt.want {
+			t.Errorf("String(%d %s) = %q, want %q", tt.m.Amount, tt.m.Currency, got, tt.want)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     Money
+		to       string
+		rate     string
+		rounding Rounding
+		want     int64
+	}{
+		{name: "exact", from: Money{1000, "USD"}, to: "EUR", rate: "0.9", want: 900},
+		{name: "half up", from: Money{1, "USD"}, to: "EUR", rate: "2.5", rounding: Rounding{Mode: RoundHalfUp}, want: 3},
+		{name: "half even down", from: Money{1, "USD"}, to: "EUR", rate: "2.5", rounding: Rounding{Mode: RoundHalfEven}, want: 2},
+		{name: "half even up", from: Money{1, "USD"}, to: "EUR", rate: "3.5", rounding: Rounding{Mode: RoundHalfEven}, want: 4},
+		{name: "up", from: Money{1, "USD"}, to: "EUR", rate: "2.01", rounding: Rounding{Mode: RoundUp}, want: 3},
+		{name: "down", from: Money{1, "USD"}, to: "EUR", rate: "2.99", rounding: Rounding{Mode: RoundDown}, want: 2},
+		{name: "negative half up", from: Money{-1, "USD"}, to: "EUR", rate: "2.5", rounding: Rounding{Mode: RoundHalfUp}, want: -3},
+		{name: "negative down", from: Money{-1, "USD"}, to: "EUR", rate: "2.99", rounding: Rounding{Mode: RoundDown}, want: -2},
+		{name: "to zero digits", from: Money{1999, "USD"}, to: "JPY", rate: "150", rounding: Rounding{Mode: RoundHalfUp}, want: 2999},
+		{name: "from zero digits", from: Money{1000, "JPY"}, to: "USD", rate: "0.0067", rounding: Rounding{Mode: RoundHalfUp}, want: 670},
+		{name: "to three digits", from: Money{100, "USD"}, to: "KWD", rate: "0.3075", rounding: Rounding{Mode: RoundHalfUp}, want: 308},
+		{name: "increment", from: Money{1000, "EUR"}, to: "CHF", rate: "0.9412", rounding: Rounding{Mode: RoundHalfUp, Increment: 5}, want: 940},
+		{name: "increment up", from: Money{1000, "EUR"}, to: "CHF", rate: "0.9412", rounding: Rounding{Mode: RoundUp, Increment: 5}, want: 945},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := ParseRate(tt.rate)
+			if err != nil {
+				t.Fatalf("ParseRate: %v", err)
+			}
+			got, err := tt.from.Convert(tt.to, rate, tt.rounding)
+			if err != nil {
+				t.Fatalf("Convert: %v", err)
+			}
+			if got.Amount != tt.want || got.Currency != tt.to {
+				t.Errorf("Convert = %d %s, want %d %s", got.Amount, got.Currency, tt.want, tt.to)
+			}
+		})
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	m := Money{100, "USD"}
+	if _, err := m.Convert("EUR", nil, Rounding{}); !errors.Is(err, ErrInvalidRate) {
+		t.Errorf("nil rate: err = %v, want ErrInvalidRate", err)
+	}
+	if _, err := m.Convert("EUR", big.NewRat(-1, 1), Rounding{}); !errors.Is(err, ErrInvalidRate) {
+		t.Errorf("negative rate: err = %v, want ErrInvalidRate", err)
+	}
+	if _, err := m.Convert("XXX", big.NewRat(1, 1), Rounding{}); !errors.Is(err, ErrInvalidCurrency) {
+		t.Errorf("unknown currency: err = %v, want ErrInvalidCurrency", err)
+	}
+	if _, err := (Money{math.MaxInt64, "USD"}).Convert("EUR", big.NewRat(2, 1), Rounding{}); !errors.Is(err, ErrOverflow) {
+		t.Errorf("too large: err = %v, want ErrOverflow", err)
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	for _, s := range []string{"0", "-1.5", "abc", ""} {
+		if _, err := ParseRate(s); !errors.Is(err, ErrInvalidRate) {
+			t.Errorf("ParseRate(%q): err = %v, want ErrInvalidRate", s, err)
+		}
+	}
+	rate, err := ParseRate("1.0845")
+	if err != nil || rate.Cmp(big.NewRat(10845, 10000)) != 0 {
+		t.Errorf("ParseRate(1.0845) = %v, %v", rate, err)
+	}
+}
@@ -1,6 +1,8 @@
 package orders
 
 import (
+	"ecomApis/internals/auth"
+	"ecomApis/internals/repo"
 	"ecomApis/internals/utils"
 	"net/http"
 	"strconv"
@@ -28,12 +30,26 @@ func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	order, items, err := h.service.CreateOrder(ctx, req.CustomerRef, req.Items)
+	// customers can only place orders for themselves
+	p, _ := auth.FromContext(ctx)
+	if req.CustomerRef == "" && p.Role == auth.RoleCustomer {
+		req.CustomerRef = p.CustomerRef
+	}
+	if !p.CanAccessCustomer(req.CustomerRef) {
+		auth.WriteError(w, &utils.AuthorizationError{Action: "create order for customer " + req.CustomerRef})
+		return
+	}
+
+	order, items, err := h.service.CreateOrder(ctx, req)
 	if err != nil {
 		if ve, ok := err.(*utils.ValidationError); ok {
 			utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": ve.Error()})
 			return
 		}
+		if ne, ok := err.(*utils.NotFoundError); ok {
+			utils.WriteJSON(w, http.StatusNotFound, map[string]string{"error": ne.Error()})
+			return
+		}
 		if de, ok := err.(*utils.DatabaseError); ok {
 			utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": de.Error()})
 			return
@@ -52,8 +68,18 @@ func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 func (h *OrderHandler) GetAllOrders(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	orders, err := h.service.GetAllOrders(ctx)
+	limit, err := utils.ParseLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	page, err := h.service.GetAllOrders(ctx, limit, r.URL.Query().Get("cursor"))
 	if err != nil {
+		if ve, ok := err.(*utils.ValidationError); ok {
+			utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": ve.Error()})
+			return
+		}
 		if de, ok := err.(*utils.DatabaseError); ok {
 			utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": de.Error()})
 			return
@@ -62,7 +88,7 @@ func (h *OrderHandler) GetAllOrders(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	utils.WriteJSON(w, http.StatusOK, orders)
+	utils.WriteJSON(w, http.StatusOK, page)
 }
 
 func (h *OrderHandler) GetOrderByID(w http.ResponseWriter, r *http.Request) {
@@ -75,8 +101,15 @@ func (h *OrderHandler) GetOrderByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	order, order_items, err := h.service.GetOrder(ctx, id)
+	details, err := h.service.GetOrder(ctx, id)
+	if err == nil && !canAccessOrder(r, details.Order) {
+		err = &utils.AuthorizationError{Action: "view order " + idParam}
+	}
 	if err != nil {
+		if ae, ok := err.(*utils.AuthorizationError); ok {
+			auth.WriteError(w, ae)
+			return
+		}
 		if ne, ok := err.(*utils.NotFoundError); ok {
 			utils.WriteJSON(w, http.StatusNotFound, map[string]string{"error": ne.Error()})
 			return
@@ -88,12 +121,51 @@ func (h *OrderHandler) GetOrderByID(w http.ResponseWriter, r *http.Request) {
 		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
 		return
 	}
-	respose := map[string]interface{}{
-		"order":       order,
-		"order_items": order_items,
+
+	utils.WriteJSON(w, http.StatusOK, details)
+}
+
+// TransitionOrder handles POST /orders/{id}/transitions
+func (h *OrderHandler) TransitionOrder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idParam := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid order ID"})
+		return
+	}
+
+	var req TransitionRequest
+	err = utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	p, _ := auth.FromContext(ctx)
+	order, entry, err := h.service.TransitionOrder(ctx, id, req.Status, req.Note, p.Subject)
+	if err != nil {
+		switch e := err.(type) {
+		case *utils.ValidationError:
+			utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": e.Error()})
+		case *utils.NotFoundError:
+			utils.WriteJSON(w, http.StatusNotFound, map[string]string{"error": e.Error()})
+		case *utils.InvalidTransitionError:
+			utils.WriteJSON(w, http.StatusConflict, map[string]string{"error": e.Error()})
+		case *utils.DatabaseError:
+			utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": e.Error()})
+		default:
+			utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		}
+		return
 	}
 
-	utils.WriteJSON(w, http.StatusOK, respose)
+	response := map[string]interface{}{
+		"order":      order,
+		"transition": entry,
+	}
+	utils.WriteJSON(w, http.StatusOK, response)
 }
 
 func (h *OrderHandler) GetOrdersByCustomerRef(w http.ResponseWriter, r *http.Request) {
@@ -105,8 +177,23 @@ func (h *OrderHandler) GetOrdersByCustomerRef(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	orders, err := h.service.GetOrdersByCustomerRef(ctx, customerRef)
+	if p, _ := auth.FromContext(ctx); !p.CanAccessCustomer(customerRef) {
+		auth.WriteError(w, &utils.AuthorizationError{Action: "view orders of customer " + customerRef})
+		return
+	}
+
+	limit, err := utils.ParseLimit(r.URL.Query().Get("limit"))
 	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	page, err := h.service.GetOrdersByCustomerRef(ctx, customerRef, limit, r.URL.Query().Get("cursor"))
+	if err != nil {
+		if ve, ok := err.(*utils.ValidationError); ok {
+			utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": ve.Error()})
+			return
+		}
 		if ne, ok := err.(*utils.NotFoundError); ok {
 			utils.WriteJSON(w, http.StatusNotFound, map[string]string{"error": ne.Error()})
 			return
@@ -119,7 +206,57 @@ func (h *OrderHandler) GetOrdersByCustomerRef(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	utils.WriteJSON(w, http.StatusOK, orders)
+	utils.WriteJSON(w, http.StatusOK, page)
+}
+
+// CancelOrder handles POST /orders/{id}/cancel
+func (h *OrderHandler) CancelOrder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idParam := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid order ID"})
+		return
+	}
+
+	var req CancelOrderRequest
+	err = utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	// check the caller owns the order before touching it
+	details, err := h.service.GetOrder(ctx, id)
+	if err == nil && !canAccessOrder(r, details.Order) {
+		err = &utils.AuthorizationError{Action: "cancel order " + idParam}
+	}
+
+	var order repo.Order
+	if err == nil {
+		p, _ := auth.FromContext(ctx)
+		order, err = h.service.CancelOrder(ctx, id, p.Subject, req.Reason)
+	}
+	if err != nil {
+		switch e := err.(type) {
+		case *utils.AuthorizationError:
+			auth.WriteError(w, e)
+		case *utils.ValidationError:
+			utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": e.Error()})
+		case *utils.NotFoundError:
+			utils.WriteJSON(w, http.StatusNotFound, map[string]string{"error": e.Error()})
+		case *utils.InvalidTransitionError:
+			utils.WriteJSON(w, http.StatusConflict, map[string]string{"error": e.Error()})
+		case *utils.DatabaseError:
+			utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": e.Error()})
+		default:
+			utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		}
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, order)
 }
 
 func (h *OrderHandler) DeleteOrder(w http.ResponseWriter, r *http.Request) {
@@ -148,3 +285,9 @@ func (h *OrderHandler) DeleteOrder(w http.ResponseWriter, r *http.Request) {
 
 	utils.WriteJSON(w, http.StatusNoContent, nil)
 }
+
+// canAccessOrder reports whether the caller is an admin or the customer who placed the order
+func canAccessOrder(r *http.Request, order repo.Order) bool {
+	p, _ := auth.FromContext(r.Context())
+	return p.CanAccessCustomer(order.CustomerRef)
+}
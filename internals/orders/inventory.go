@@ -0,0 +1,264 @@
+package orders
+
+import (
+	"context"
+	"database/sql"
+	"ecomApis/internals/inventory"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// how many orders the sweeper handles per run
+const reservationSweepBatch = 100
+
+// commitOrderStock makes the reservations of a paid order permanent by taking the stock off the shelf
+func commitOrderStock(ctx context.Context, qtx *repo.Queries, orderID int64, actor string) error {
+	committed, err := qtx.CommitOrderReservations(ctx, orderID)
+	if err != nil {
+		return &utils.DatabaseError{Query: "CommitOrderReservations", Err: err}
+	}
+
+	for _, r := range committed {
+		if r.VariantID.Valid {
+			variant, err := qtx.AdjustVariantStock(ctx, repo.AdjustVariantStockParams{
+				Delta: -r.Quantity,
+				ID:    r.VariantID.Int64,
+			})
+			if err != nil {
+				if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+					return &utils.ValidationError{
+						Field:   "stock",
+						Message: fmt.Sprintf("not enough stock on hand for variant %d", r.VariantID.Int64),
+					}
+				}
+				return &utils.DatabaseError{Query: "AdjustVariantStock", Err: err}
+			}
+
+			err = inventory.Record(ctx, qtx, repo.AddInventoryMovementParams{
+				ProductID:     r.ProductID,
+				VariantID:     r.VariantID,
+				Quantity:      -r.Quantity,
+				StockAfter:    variant.Stock,
+				Reason:        inventory.ReasonOrderPaid,
+				Actor:         actor,
+				ReferenceType: inventory.ReferenceOrder,
+				ReferenceID:   strconv.FormatInt(orderID, 10),
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		product, err := qtx.UpdateProductStock(ctx, repo.UpdateProductStockParams{
+			Stock: r.Quantity,
+			ID:    r.ProductID,
+		})
+		if err != nil {
+			if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+				return &utils.ValidationError{
+					Field:   "stock",
+					Message: fmt.Sprintf("not enough stock on hand for product %d", r.ProductID),
+				}
+			}
+			return &utils.DatabaseError{Query: "UpdateProductStock", Err: err}
+		}
+
+		err = inventory.Record(ctx, qtx, repo.AddInventoryMovementParams{
+			ProductID:     r.ProductID,
+			Quantity:      -r.Quantity,
+			StockAfter:    product.Stock,
+			Reason:        inventory.ReasonOrderPaid,
+			Actor:         actor,
+			ReferenceType: inventory.ReferenceOrder,
+			ReferenceID:   strconv.FormatInt(orderID, 10),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseOrderStock frees whatever an order holds. Active reservations are just released,
+// committed ones already left the shelf so their stock is added back
+func releaseOrderStock(ctx context.Context, qtx *repo.Queries, orderID int64, actor string) error {
+	reservations, err := qtx.ListOrderReservations(ctx, orderID)
+	if err != nil {
+		return &utils.DatabaseError{Query: "ListOrderReservations", Err: err}
+	}
+
+	// orders placed before reservations existed took their stock straight away
+	if len(reservations) == 0 {
+		items, err := qtx.ListOrderItems(ctx, orderID)
+		if err != nil {
+			return &utils.DatabaseError{Query: "ListOrderItems", Err: err}
+		}
+		for _, item := range items {
+			err = restock(ctx, qtx, orderID, item.ProductID, item.VariantID, item.Quantity, actor)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	_, err = qtx.ReleaseActiveOrderReservations(ctx, orderID)
+	if err != nil {
+		return &utils.DatabaseError{Query: "ReleaseActiveOrderReservations", Err: err}
+	}
+
+	committed, err := qtx.ReleaseCommittedOrderReservations(ctx, orderID)
+	if err != nil {
+		return &utils.DatabaseError{Query: "ReleaseCommittedOrderReservations", Err: err}
+	}
+	for _, r := range committed {
+		err = restock(ctx, qtx, orderID, r.ProductID, r.VariantID, r.Quantity, actor)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restock puts the stock of a cancelled order back on the shelf and records it in the ledger
+func restock(ctx context.Context, qtx *repo.Queries, orderID, productID int64, variantID pgtype.Int8, quantity int32, actor string) error {
+	if variantID.Valid {
+		variant, err := qtx.AdjustVariantStock(ctx, repo.AdjustVariantStockParams{
+			Delta: quantity,
+			ID:    variantID.Int64,
+		})
+		if err != nil {
+			return &utils.DatabaseError{Query: "AdjustVariantStock", Err: err}
+		}
+
+		return inventory.Record(ctx, qtx, repo.AddInventoryMovementParams{
+			ProductID:     productID,
+			VariantID:     variantID,
+			Quantity:      quantity,
+			StockAfter:    variant.Stock,
+			Reason:        inventory.ReasonOrderCancelled,
+			Actor:         actor,
+			ReferenceType: inventory.ReferenceOrder,
+			ReferenceID:   strconv.FormatInt(orderID, 10),
+		})
+	}
+
+	product, err := qtx.AdjustProductStock(ctx, repo.AdjustProductStockParams{
+		Delta: quantity,
+		ID:    productID,
+	})
+	if err != nil {
+		return &utils.DatabaseError{Query: "AdjustProductStock", Err: err}
+	}
+
+	return inventory.Record(ctx, qtx, repo.AddInventoryMovementParams{
+		ProductID:     productID,
+		Quantity:      quantity,
+		StockAfter:    product.Stock,
+		Reason:        inventory.ReasonOrderCancelled,
+		Actor:         actor,
+		ReferenceType: inventory.ReferenceOrder,
+		ReferenceID:   strconv.FormatInt(orderID, 10),
+	})
+}
+
+// RunReservationSweeper releases expired reservations every interval until ctx is cancelled.
+// Orders that are still pending when their reservations expire are cancelled, unless a payment
+// for them is authorized or was started in the last 15 minutes, those keep their stock until the
+// payment settles. A payment that settles after its order was cancelled is given back
+func (s *OrderService) RunReservationSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			orderIDs, err := s.repo.ListOrdersWithExpiredReservations(ctx, reservationSweepBatch)
+			if err != nil {
+				slog.Error("failed to list expired reservations", "error", err)
+				continue
+			}
+			for _, id := range orderIDs {
+				if err := s.expireOrderReservations(ctx, id); err != nil {
+					slog.Error("failed to release expired reservations", "order_id", id, "error", err)
+				}
+			}
+		}
+	}
+}
+
+func (s *OrderService) expireOrderReservations(ctx context.Context, orderID int64) error {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	order, err := qtx.GetOrderForUpdate(ctx, orderID)
+	if err != nil && err != pgx.ErrNoRows && err != sql.ErrNoRows {
+		tx.Rollback(ctx)
+		return &utils.DatabaseError{Query: "GetOrderForUpdate", Err: err}
+	}
+
+	released, err := qtx.ReleaseExpiredOrderReservations(ctx, orderID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return &utils.DatabaseError{Query: "ReleaseExpiredOrderReservations", Err: err}
+	}
+
+	// an unpaid order without its stock cannot be fulfilled any more
+	if released > 0 && order.Status == StatusPending {
+		const reason = "stock reservation expired before payment"
+
+		_, err = qtx.ReleaseActiveOrderReservations(ctx, orderID)
+		if err != nil {
+			tx.Rollback(ctx)
+			return &utils.DatabaseError{Query: "ReleaseActiveOrderReservations", Err: err}
+		}
+
+		cancelled, err := qtx.CancelOrder(ctx, repo.CancelOrderParams{
+			CancelledBy:  pgtype.Text{String: inventory.ActorSystem, Valid: true},
+			CancelReason: pgtype.Text{String: reason, Valid: true},
+			ID:           orderID,
+		})
+		if err != nil {
+			tx.Rollback(ctx)
+			return &utils.DatabaseError{Query: "CancelOrder", Err: err}
+		}
+
+		_, err = qtx.AddOrderStatusHistory(ctx, repo.AddOrderStatusHistoryParams{
+			OrderID:    orderID,
+			FromStatus: pgtype.Text{String: order.Status, Valid: true},
+			ToStatus:   StatusCancelled,
+			Note:       reason,
+		})
+		if err != nil {
+			tx.Rollback(ctx)
+			return &utils.DatabaseError{Query: "AddOrderStatusHistory", Err: err}
+		}
+
+		if err := writeStatusEvents(ctx, qtx, cancelled, order.Status); err != nil {
+			tx.Rollback(ctx)
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	if released > 0 {
+		slog.Info("released expired reservations", "order_id", orderID, "reservations", released)
+	}
+	return nil
+}
@@ -3,36 +3,78 @@ package orders
 import (
 	"context"
 	"database/sql"
+	"ecomApis/internals/money"
+	"ecomApis/internals/outbox"
+	"ecomApis/internals/pricing"
+	"ecomApis/internals/promotions"
 	"ecomApis/internals/repo"
+	"ecomApis/internals/shipping"
+	"ecomApis/internals/tax"
 	"ecomApis/internals/utils"
+	"sort"
 	"strconv"
+	"time"
 
+	"errors"
 	"fmt"
+	"math/big"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type OrderService struct {
-	repo *repo.Queries
-	db   *pgx.Conn
+	repo    *repo.Queries
+	db      *pgxpool.Pool
+	config  Config
+	pricing *pricing.Service
+	tax     tax.TaxCalculator
 }
 
-func NewOrderService(r *repo.Queries, db *pgx.Conn) *OrderService {
+func NewOrderService(r *repo.Queries, db *pgxpool.Pool, cfg Config, prices *pricing.Service, taxes tax.TaxCalculator) *OrderService {
+	// only forward statuses make sense as a cutoff
+	if _, ok := statusProgress[cfg.CancelCutoffStatus]; !ok {
+		cfg.CancelCutoffStatus = StatusPaid
+	}
+	if cfg.ReservationTTL <= 0 {
+		cfg.ReservationTTL = 30 * time.Minute
+	}
 	return &OrderService{
-		repo: r,
-		db:   db,
+		repo:    r,
+		db:      db,
+		config:  cfg,
+		pricing: prices,
+		tax:     taxes,
 	}
 }
 
 // Placing an order process:
-// 1. get customer_ref (this is just any string that identifies the customer) and order items (product IDs and quantities)
-// 2. calculate total price by fetching product prices from the products table
-// 3. create order in orders table
-// 4. create order items in order_items table
-// 5. update product stock in products table
+// 1. get customer_ref (must belong to an existing customer) and order items (product IDs and quantities)
+// 2. calculate total price by fetching product prices from the products table, converted to the requested currency if any.
+//    A variant with its own price replaces the product price
+// 3. take off the coupon discount, if a coupon code is given
+// 4. work out the tax of every line for the jurisdiction the order ships to
+// 5. add the charge of the chosen shipping method
+// 6. create order in orders table with its addresses
+// 7. create order items in order_items table with their share of the discount and their tax
+// 8. reserve the stock for each item, or for its variant, it is only taken off the shelf once the order is paid
+// 9. count the coupon use against its limits
 // We rollback if any step fails
 
-func (s *OrderService) CreateOrder(ctx context.Context, customerRef string, items []OrderItemRequest) (repo.Order, []repo.OrderItem, error) {
+func (s *OrderService) CreateOrder(ctx context.Context, req CreateOrderRequest) (repo.Order, []repo.OrderItem, error) {
+	return s.CreateOrderWith(ctx, req, nil)
+}
+
+// BeforeCommitFunc runs inside the order transaction once the order is written.
+// Returning an error rolls the whole order back
+type BeforeCommitFunc func(ctx context.Context, qtx *repo.Queries, order repo.Order) error
+
+// CreateOrderWith places an order like CreateOrder and lets the caller write its own
+// changes in the same transaction, e.g. marking a cart as checked out.
+// With an empty currency every product must already be priced in the same currency
+func (s *OrderService) CreateOrderWith(ctx context.Context, req CreateOrderRequest, beforeCommit BeforeCommitFunc) (repo.Order, []repo.OrderItem, error) {
+	customerRef, currency, items := req.CustomerRef, req.Currency, req.Items
 
 	if customerRef == "" {
 		return repo.Order{}, nil, &utils.ValidationError{
@@ -48,6 +90,22 @@ func (s *OrderService) CreateOrder(ctx context.Context, customerRef string, item
 		}
 	}
 
+	shippingAddress, billingAddress := req.ShippingAddress, req.BillingAddress
+	if shippingAddress != nil {
+		a, err := shippingAddress.Normalize("shipping_address")
+		if err != nil {
+			return repo.Order{}, nil, err
+		}
+		shippingAddress = &a
+	}
+	if billingAddress != nil {
+		a, err := billingAddress.Normalize("billing_address")
+		if err != nil {
+			return repo.Order{}, nil, err
+		}
+		billingAddress = &a
+	}
+
 	// start transaction wth current context
 	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
 
@@ -56,15 +114,49 @@ func (s *OrderService) CreateOrder(ctx context.Context, customerRef string, item
 	}
 	qtx := s.repo.WithTx(tx)
 
-	// create order
-	order, err := qtx.CreateOrder(ctx, customerRef)
+	// the customer must exist, free-form refs are no longer accepted
+	customer, err := qtx.GetCustomerByRef(ctx, customerRef)
 	if err != nil {
 		tx.Rollback(ctx)
-		return repo.Order{}, nil, &utils.DatabaseError{Query: "CreateOrder", Err: err}
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return repo.Order{}, nil, &utils.NotFoundError{
+				Resource: "Customer",
+				ID:       customerRef,
+			}
+		}
+		return repo.Order{}, nil, &utils.DatabaseError{Query: "GetCustomerByRef", Err: err}
 	}
 
-	var total int32 = 0
-	orderItems := []repo.OrderItem{}
+	// without addresses the order goes to the customer's own address, and is billed where it ships
+	if shippingAddress == nil {
+		shippingAddress = shipping.CustomerAddress(customer)
+	}
+	if billingAddress == nil {
+		billingAddress = shippingAddress
+	}
+	if req.ShippingMethod != "" && shippingAddress == nil {
+		tx.Rollback(ctx)
+		return repo.Order{}, nil, &utils.ValidationError{
+			Field:   "shipping_address",
+			Message: "is required to ship the order, the customer has no complete address",
+		}
+	}
+
+	// lock products, then their variants, in id order so two orders for the same products cannot deadlock
+	items = append([]OrderItemRequest(nil), items...)
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].ProductID != items[j].ProductID {
+			return items[i].ProductID < items[j].ProductID
+		}
+		return variantKey(items[i]) < variantKey(items[j])
+	})
+
+	products := make([]repo.Product, 0, len(items))
+	// the variant of each item, the zero value for products without variants
+	variants := make([]repo.ProductVariant, 0, len(items))
+	// how much of each product or variant the items before this one take, so several lines
+	// for the same one are checked against its stock together
+	ordered := map[stockKey]int32{}
 
 	// each item in the order
 	for _, item := range items {
@@ -77,8 +169,8 @@ func (s *OrderService) CreateOrder(ctx context.Context, customerRef string, item
 			}
 		}
 
-		// Fetch product
-		product, err := qtx.FindProductByID(ctx, item.ProductID)
+		// Fetch and lock product, concurrent orders for it wait until we commit
+		product, err := qtx.GetProductForUpdate(ctx, item.ProductID)
 		if err != nil {
 			if err == pgx.ErrNoRows || err == sql.ErrNoRows {
 				tx.Rollback(ctx)
@@ -88,41 +180,313 @@ func (s *OrderService) CreateOrder(ctx context.Context, customerRef string, item
 				}
 			}
 			tx.Rollback(ctx)
-			return repo.Order{}, nil, &utils.DatabaseError{Query: "FindProductByID", Err: err}
+			return repo.Order{}, nil, &utils.DatabaseError{Query: "GetProductForUpdate", Err: err}
+		}
+		variant, err := lockItemVariant(ctx, qtx, item)
+		if err != nil {
+			tx.Rollback(ctx)
+			return repo.Order{}, nil, err
+		}
+
+		// Check available stock, on hand minus what other unpaid orders hold
+		if item.VariantID != nil {
+			reserved, err := qtx.GetVariantReservedQuantity(ctx, pgtype.Int8{Int64: variant.ID, Valid: true})
+			if err != nil {
+				tx.Rollback(ctx)
+				return repo.Order{}, nil, &utils.DatabaseError{Query: "GetVariantReservedQuantity", Err: err}
+			}
+			key := stockKey{productID: item.ProductID, variantID: variant.ID}
+			ordered[key] += item.Quantity
+			if variant.Stock-reserved < ordered[key] {
+				tx.Rollback(ctx)
+				return repo.Order{}, nil, &utils.ValidationError{
+					Field:   "stock",
+					Message: fmt.Sprintf("not enough stock for variant %s of product %d", variant.Sku, item.ProductID),
+				}
+			}
+		} else {
+			reserved, err := qtx.GetReservedQuantity(ctx, product.ID)
+			if err != nil {
+				tx.Rollback(ctx)
+				return repo.Order{}, nil, &utils.DatabaseError{Query: "GetReservedQuantity", Err: err}
+			}
+			key := stockKey{productID: item.ProductID}
+			ordered[key] += item.Quantity
+			if product.Stock-reserved < ordered[key] {
+				tx.Rollback(ctx)
+				return repo.Order{}, nil, &utils.ValidationError{
+					Field:   "stock",
+					Message: fmt.Sprintf("not enough stock for product %d", item.ProductID),
+				}
+			}
+		}
+
+		products = append(products, product)
+		variants = append(variants, variant)
+	}
+
+	// price every item, in the requested currency when there is one
+	quotes := make([]pricing.Quote, 0, len(products))
+	if currency == "" {
+		for _, product := range products {
+			quotes = append(quotes, pricing.Quote{Price: product.PriceMoney()})
+		}
+	} else {
+		quotes, err = s.pricing.PriceIn(ctx, qtx, products, currency)
+		if err != nil {
+			tx.Rollback(ctx)
+			return repo.Order{}, nil, err
 		}
-		if product.Price <= 0 {
+	}
+	for i, variant := range variants {
+		if !variant.Price.Valid {
+			continue
+		}
+		quotes[i], err = s.pricing.VariantQuote(products[i], variant.Price.Int64, quotes[i])
+		if err != nil {
+			tx.Rollback(ctx)
+			return repo.Order{}, nil, err
+		}
+	}
+	// check the price each item is charged at, a variant's own price stands in for its product's
+	for i, item := range items {
+		if quotes[i].Price.Amount <= 0 {
 			tx.Rollback(ctx)
 			return repo.Order{}, nil, &utils.ValidationError{
 				Field:   "price",
 				Message: fmt.Sprintf("invalid price for product %d", item.ProductID),
 			}
 		}
-		// Check stock
-		if product.Stock < item.Quantity {
+	}
+
+	// Accumulate total, the first item decides the currency of the order
+	var total money.Money
+	var rate *big.Rat
+	lineTotals := make([]money.Money, 0, len(items))
+	for i, item := range items {
+		unitPrice := quotes[i].Price
+		if i == 0 {
+			total = money.Zero(unitPrice.Currency)
+		}
+		if quotes[i].Rate != nil {
+			rate = quotes[i].Rate
+		}
+
+		line, err := unitPrice.Mul(int64(item.Quantity))
+		if err == nil {
+			total, err = total.Add(line)
+		}
+		lineTotals = append(lineTotals, line)
+		if err != nil {
 			tx.Rollback(ctx)
+			if errors.Is(err, money.ErrCurrencyMismatch) {
+				return repo.Order{}, nil, &utils.ValidationError{
+					Field:   "currency",
+					Message: fmt.Sprintf("product %d is priced in %s but the order is in %s", item.ProductID, unitPrice.Currency, total.Currency),
+				}
+			}
 			return repo.Order{}, nil, &utils.ValidationError{
-				Field:   "stock",
-				Message: fmt.Sprintf("not enough stock for product %d", item.ProductID),
+				Field:   "total",
+				Message: "order total is too large",
 			}
 		}
+	}
 
-		// Decrement stock
+	// apply the coupon to the priced lines
+	var discount promotions.Discount
+	var couponCode pgtype.Text
+	var promotionID pgtype.Int8
+	discountTotal := money.Zero(total.Currency)
+	if req.CouponCode != "" {
+		lines := make([]promotions.Line, 0, len(items))
+		for i, item := range items {
+			lines = append(lines, promotions.Line{
+				ProductID: item.ProductID,
+				Quantity:  item.Quantity,
+				UnitPrice: quotes[i].Price,
+			})
+		}
+		discount, err = promotions.Apply(ctx, qtx, req.CouponCode, customerRef, lines)
+		if err != nil {
+			tx.Rollback(ctx)
+			return repo.Order{}, nil, err
+		}
+		discountTotal = discount.Total
+		couponCode = pgtype.Text{String: discount.Promotion.Code, Valid: true}
+		promotionID = pgtype.Int8{Int64: discount.Promotion.ID, Valid: true}
+	}
 
-		_, err = qtx.UpdateProductStock(ctx, repo.UpdateProductStockParams{
-			Stock: item.Quantity,
-			ID:    product.ID,
+	// tax the discounted lines, total_price is the grand total including tax
+	taxLines := make([]tax.Line, 0, len(items))
+	for i, product := range products {
+		amount := lineTotals[i]
+		if discount.Lines != nil {
+			amount, err = amount.Sub(discount.Lines[i])
+			if err != nil {
+				tx.Rollback(ctx)
+				return repo.Order{}, nil, err
+			}
+		}
+		taxLines = append(taxLines, tax.Line{
+			ProductID: product.ID,
+			TaxClass:  product.TaxClass,
+			Amount:    amount,
 		})
+	}
+	jurisdiction := tax.Jurisdiction(customer.Country, customer.Region)
+	if shippingAddress != nil {
+		jurisdiction = tax.Jurisdiction(shippingAddress.Country, shippingAddress.Region)
+	}
+	taxes, err := s.tax.Calculate(ctx, tax.Request{
+		Jurisdiction: jurisdiction,
+		Currency:     total.Currency,
+		Lines:        taxLines,
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Order{}, nil, err
+	}
+	var taxJurisdiction pgtype.Text
+	if taxes.Jurisdiction != "" {
+		taxJurisdiction = pgtype.Text{String: taxes.Jurisdiction, Valid: true}
+	}
+
+	// shipping is charged on top of the taxed goods
+	grandTotal := taxes.Total
+	shippingTotal := money.Zero(total.Currency)
+	var shippingMethodID pgtype.Int8
+	if req.ShippingMethod != "" {
+		lines := make([]shipping.Line, 0, len(items))
+		for i, item := range items {
+			lines = append(lines, shipping.Line{
+				Product:  products[i],
+				Quantity: item.Quantity,
+				Amount:   taxLines[i].Amount,
+			})
+		}
+		quote, err := shipping.QuoteMethod(ctx, qtx, req.ShippingMethod, shippingAddress.Country, lines)
+		if err != nil {
+			tx.Rollback(ctx)
+			return repo.Order{}, nil, err
+		}
+		grandTotal, err = grandTotal.Add(quote.Amount)
 		if err != nil {
 			tx.Rollback(ctx)
-			return repo.Order{}, nil, &utils.DatabaseError{Query: "UpdateProductStock", Err: err}
+			return repo.Order{}, nil, &utils.ValidationError{
+				Field:   "total",
+				Message: "order total is too large",
+			}
+		}
+		shippingTotal = quote.Amount
+		shippingMethodID = pgtype.Int8{Int64: quote.MethodID, Valid: true}
+	}
+
+	// keep the rate the converted prices were based on
+	var rateBase pgtype.Text
+	if rate != nil {
+		rateBase = pgtype.Text{String: s.pricing.BaseCurrency(), Valid: true}
+	}
+
+	// create order
+	order, err := qtx.CreateOrder(ctx, repo.CreateOrderParams{
+		CustomerRef:      customerRef,
+		TotalPrice:       grandTotal.Amount,
+		Currency:         total.Currency,
+		ExchangeRate:     pricing.NumericFromRate(rate),
+		ExchangeRateBase: rateBase,
+		DiscountTotal:    discountTotal.Amount,
+		CouponCode:       couponCode,
+		PromotionID:      promotionID,
+		Subtotal:         taxes.Subtotal.Amount,
+		TaxTotal:         taxes.Tax.Amount,
+		TaxJurisdiction:  taxJurisdiction,
+		PricesIncludeTax: taxes.PricesIncludeTax,
+		ShippingMethodID: shippingMethodID,
+		ShippingTotal:    shippingTotal.Amount,
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Order{}, nil, &utils.DatabaseError{Query: "CreateOrder", Err: err}
+	}
+
+	// every order starts its history as pending
+	_, err = qtx.AddOrderStatusHistory(ctx, repo.AddOrderStatusHistoryParams{
+		OrderID:  order.ID,
+		ToStatus: order.Status,
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Order{}, nil, &utils.DatabaseError{Query: "AddOrderStatusHistory", Err: err}
+	}
+
+	for _, a := range []struct {
+		kind    string
+		address *shipping.Address
+	}{
+		{shipping.AddressShipping, shippingAddress},
+		{shipping.AddressBilling, billingAddress},
+	} {
+		if a.address == nil {
+			continue
+		}
+		_, err = qtx.AddOrderAddress(ctx, repo.AddOrderAddressParams{
+			OrderID:      order.ID,
+			Kind:         a.kind,
+			Name:         a.address.Name,
+			Phone:        a.address.Phone,
+			AddressLine1: a.address.AddressLine1,
+			AddressLine2: a.address.AddressLine2,
+			City:         a.address.City,
+			Region:       a.address.Region,
+			PostalCode:   a.address.PostalCode,
+			Country:      a.address.Country,
+		})
+		if err != nil {
+			tx.Rollback(ctx)
+			return repo.Order{}, nil, &utils.DatabaseError{Query: "AddOrderAddress", Err: err}
+		}
+	}
+
+	orderItems := []repo.OrderItem{}
+
+	for i, item := range items {
+		product, variant := products[i], variants[i]
+		unitPrice := quotes[i].Price
+		var lineDiscount int64
+		if discount.Lines != nil {
+			lineDiscount = discount.Lines[i].Amount
+		}
+		var variantID pgtype.Int8
+		if variant.ID != 0 {
+			variantID = pgtype.Int8{Int64: variant.ID, Valid: true}
+		}
+
+		// Reserve stock
+		_, err = qtx.CreateReservation(ctx, repo.CreateReservationParams{
+			ProductID:  product.ID,
+			VariantID:  variantID,
+			OrderID:    order.ID,
+			Quantity:   item.Quantity,
+			TtlSeconds: int32(s.config.ReservationTTL.Seconds()),
+		})
+		if err != nil {
+			tx.Rollback(ctx)
+			return repo.Order{}, nil, &utils.DatabaseError{Query: "CreateReservation", Err: err}
 		}
 
 		// add items to order_items table
 		oi, err := qtx.AddOrderItem(ctx, repo.AddOrderItemParams{
 			OrderID:   order.ID,
 			ProductID: product.ID,
+			VariantID: variantID,
+			Sku:       variant.Sku,
 			Quantity:  item.Quantity,
-			UnitPrice: product.Price,
+			UnitPrice: unitPrice.Amount,
+			Currency:  unitPrice.Currency,
+			Discount:  lineDiscount,
+			TaxClass:  product.TaxClass,
+			TaxRate:   pricing.NumericFromRate(taxes.Lines[i].Rate),
+			TaxAmount: taxes.Lines[i].Tax.Amount,
 		})
 		if err != nil {
 			tx.Rollback(ctx)
@@ -130,15 +494,26 @@ func (s *OrderService) CreateOrder(ctx context.Context, customerRef string, item
 		}
 
 		orderItems = append(orderItems, oi)
+	}
 
-		// Accumulate total
-		total += product.Price * item.Quantity
+	// the usage counters move in this transaction, so a rolled back order never uses up a coupon
+	if promotionID.Valid {
+		if err := promotions.Redeem(ctx, qtx, discount, order.ID, customerRef); err != nil {
+			tx.Rollback(ctx)
+			return repo.Order{}, nil, err
+		}
 	}
-	// Update order total
-	_, err = tx.Exec(ctx, "UPDATE orders SET total_price = $1 WHERE id = $2", total, order.ID)
-	if err != nil {
+
+	if err := outbox.Write(ctx, qtx, outbox.OrderCreatedEvent(order, orderItems)); err != nil {
 		tx.Rollback(ctx)
-		return repo.Order{}, nil, &utils.DatabaseError{Query: "UpdateOrderTotal", Err: err}
+		return repo.Order{}, nil, err
+	}
+
+	if beforeCommit != nil {
+		if err := beforeCommit(ctx, qtx, order); err != nil {
+			tx.Rollback(ctx)
+			return repo.Order{}, nil, err
+		}
 	}
 
 	// commit transaction
@@ -146,31 +521,86 @@ func (s *OrderService) CreateOrder(ctx context.Context, customerRef string, item
 		return repo.Order{}, nil, fmt.Errorf("commit tx: %w", err)
 	}
 
-	order.TotalPrice = total
 	return order, orderItems, nil
 }
 
-func (s *OrderService) GetAllOrders(ctx context.Context) ([]repo.Order, error) {
-	orders, err := s.repo.GetAllOrders(ctx)
+// stockKey names what an item takes stock from, variantID is 0 for products without variants
+type stockKey struct {
+	productID int64
+	variantID int64
+}
+
+// variantKey orders the items of one product, items without a variant first
+func variantKey(item OrderItemRequest) int64 {
+	if item.VariantID == nil {
+		return 0
+	}
+	return *item.VariantID
+}
+
+// lockItemVariant locks the variant an item orders. Items of products with variants must name one,
+// the zero variant is returned for products without variants
+func lockItemVariant(ctx context.Context, qtx *repo.Queries, item OrderItemRequest) (repo.ProductVariant, error) {
+	if item.VariantID == nil {
+		hasVariants, err := qtx.ProductHasVariants(ctx, item.ProductID)
+		if err != nil {
+			return repo.ProductVariant{}, &utils.DatabaseError{Query: "ProductHasVariants", Err: err}
+		}
+		if hasVariants {
+			return repo.ProductVariant{}, &utils.ValidationError{
+				Field:   "variant_id",
+				Message: fmt.Sprintf("product %d has variants, choose one", item.ProductID),
+			}
+		}
+		return repo.ProductVariant{}, nil
+	}
+
+	variant, err := qtx.GetVariantForUpdate(ctx, *item.VariantID)
+	if err != nil && err != pgx.ErrNoRows && err != sql.ErrNoRows {
+		return repo.ProductVariant{}, &utils.DatabaseError{Query: "GetVariantForUpdate", Err: err}
+	}
+	if err != nil || variant.ProductID != item.ProductID {
+		return repo.ProductVariant{}, &utils.NotFoundError{
+			Resource: "Variant",
+			ID:       strconv.FormatInt(*item.VariantID, 10),
+		}
+	}
+	return variant, nil
+}
+
+// timestamps in cursors keep microsecond precision to match postgres
+const cursorTimeLayout = "2006-01-02T15:04:05.999999"
+
+func (s *OrderService) GetAllOrders(ctx context.Context, limit int32, cursor string) (utils.Page[repo.Order], error) {
+	params := repo.ListOrdersPageParams{PageLimit: limit + 1}
+
+	createdAt, id, err := decodeOrderCursor(cursor)
+	if err != nil {
+		return utils.Page[repo.Order]{}, err
+	}
+	params.CursorCreatedAt = createdAt
+	params.CursorID = id
+
+	orders, err := s.repo.ListOrdersPage(ctx, params)
 	if err != nil {
-		return nil, &utils.DatabaseError{
-			Query: "GetAllOrders",
+		return utils.Page[repo.Order]{}, &utils.DatabaseError{
+			Query: "ListOrdersPage",
 			Err:   err,
 		}
 	}
-	return orders, nil
+	return utils.NewPage(orders, limit, orderCursor), nil
 }
 
-func (s *OrderService) GetOrder(ctx context.Context, id int64) (repo.Order, []repo.OrderItem, error) {
+func (s *OrderService) GetOrder(ctx context.Context, id int64) (OrderDetails, error) {
 	order, err := s.repo.GetOrder(ctx, id)
 	if err != nil {
 		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
-			return repo.Order{}, nil, &utils.NotFoundError{
+			return OrderDetails{}, &utils.NotFoundError{
 				Resource: "Order",
 				ID:       strconv.FormatInt(id, 10),
 			}
 		}
-		return repo.Order{}, nil, &utils.DatabaseError{
+		return OrderDetails{}, &utils.DatabaseError{
 			Query: "GetOrder",
 			Err:   err,
 		}
@@ -179,30 +609,280 @@ func (s *OrderService) GetOrder(ctx context.Context, id int64) (repo.Order, []re
 	// get order items
 	items, err := s.repo.ListOrderItems(ctx, order.ID)
 	if err != nil {
-		return repo.Order{}, nil, &utils.DatabaseError{
+		return OrderDetails{}, &utils.DatabaseError{
 			Query: "ListOrderItems",
 			Err:   err,
 		}
 	}
 
-	return order, items, nil
+	// get status history
+	history, err := s.repo.ListOrderStatusHistory(ctx, order.ID)
+	if err != nil {
+		return OrderDetails{}, &utils.DatabaseError{
+			Query: "ListOrderStatusHistory",
+			Err:   err,
+		}
+	}
+
+	addresses, err := s.repo.ListOrderAddresses(ctx, order.ID)
+	if err != nil {
+		return OrderDetails{}, &utils.DatabaseError{
+			Query: "ListOrderAddresses",
+			Err:   err,
+		}
+	}
+	if addresses == nil {
+		addresses = []repo.OrderAddress{}
+	}
+
+	return OrderDetails{
+		Order:         order,
+		Items:         items,
+		StatusHistory: history,
+		Addresses:     addresses,
+	}, nil
 }
 
-func (s *OrderService) GetOrdersByCustomerRef(ctx context.Context, customerRef string) ([]repo.Order, error) {
-	orders, err := s.repo.GetOrdersByCustomerRef(ctx, customerRef)
+// TransitionOrder moves an order to a new status and records the change in its history.
+// The order row is locked so two concurrent transitions cannot both succeed.
+// actor is recorded against any stock the transition moves. Cancelling goes through
+// CancelOrder, which enforces the cancel cutoff and records who cancelled and why
+func (s *OrderService) TransitionOrder(ctx context.Context, id int64, toStatus, note, actor string) (repo.Order, repo.OrderStatusHistory, error) {
+	if !IsValidStatus(toStatus) {
+		return repo.Order{}, repo.OrderStatusHistory{}, &utils.ValidationError{
+			Field:   "status",
+			Message: fmt.Sprintf("unknown status '%s'", toStatus),
+		}
+	}
+	if toStatus == StatusCancelled {
+		return repo.Order{}, repo.OrderStatusHistory{}, &utils.ValidationError{
+			Field:   "status",
+			Message: "orders are cancelled through POST /orders/{id}/cancel",
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return repo.Order{}, repo.OrderStatusHistory{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	order, err := qtx.GetOrderForUpdate(ctx, id)
 	if err != nil {
+		tx.Rollback(ctx)
 		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
-			return nil, &utils.NotFoundError{
-				Resource: "Orders for CustomerRef",
-				ID:       customerRef,
+			return repo.Order{}, repo.OrderStatusHistory{}, &utils.NotFoundError{
+				Resource: "Order",
+				ID:       strconv.FormatInt(id, 10),
 			}
 		}
-		return nil, &utils.DatabaseError{
-			Query: "GetOrdersByCustomerRef",
+		return repo.Order{}, repo.OrderStatusHistory{}, &utils.DatabaseError{Query: "GetOrderForUpdate", Err: err}
+	}
+
+	updated, entry, err := Transition(ctx, qtx, order, toStatus, note, actor)
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Order{}, repo.OrderStatusHistory{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Order{}, repo.OrderStatusHistory{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return updated, entry, nil
+}
+
+// Transition moves an order that is locked in the caller's transaction to a new status,
+// adjusting its stock and recording the change in its history
+func Transition(ctx context.Context, qtx *repo.Queries, order repo.Order, toStatus, note, actor string) (repo.Order, repo.OrderStatusHistory, error) {
+	if !CanTransition(order.Status, toStatus) {
+		return repo.Order{}, repo.OrderStatusHistory{}, &utils.InvalidTransitionError{
+			Resource: "Order",
+			From:     order.Status,
+			To:       toStatus,
+		}
+	}
+
+	// paying turns the reservations into real stock decrements, cancelling gives the stock back
+	var err error
+	switch toStatus {
+	case StatusPaid:
+		err = commitOrderStock(ctx, qtx, order.ID, actor)
+	case StatusCancelled:
+		err = releaseOrderStock(ctx, qtx, order.ID, actor)
+	}
+	if err != nil {
+		return repo.Order{}, repo.OrderStatusHistory{}, err
+	}
+
+	updated, err := qtx.UpdateOrderStatus(ctx, repo.UpdateOrderStatusParams{
+		ToStatus:   toStatus,
+		ID:         order.ID,
+		FromStatus: order.Status,
+	})
+	if err != nil {
+		return repo.Order{}, repo.OrderStatusHistory{}, &utils.DatabaseError{Query: "UpdateOrderStatus", Err: err}
+	}
+
+	entry, err := qtx.AddOrderStatusHistory(ctx, repo.AddOrderStatusHistoryParams{
+		OrderID:    order.ID,
+		FromStatus: pgtype.Text{String: order.Status, Valid: true},
+		ToStatus:   toStatus,
+		Note:       note,
+	})
+	if err != nil {
+		return repo.Order{}, repo.OrderStatusHistory{}, &utils.DatabaseError{Query: "AddOrderStatusHistory", Err: err}
+	}
+
+	if err := writeStatusEvents(ctx, qtx, updated, order.Status); err != nil {
+		return repo.Order{}, repo.OrderStatusHistory{}, err
+	}
+
+	return updated, entry, nil
+}
+
+// writeStatusEvents queues order.status_changed for an order that moved on from fromStatus,
+// and order.cancelled as well when it was cancelled
+func writeStatusEvents(ctx context.Context, qtx *repo.Queries, order repo.Order, fromStatus string) error {
+	if err := outbox.Write(ctx, qtx, outbox.OrderStatusChangedEvent(order, fromStatus)); err != nil {
+		return err
+	}
+	if order.Status == StatusCancelled {
+		return outbox.Write(ctx, qtx, outbox.OrderCancelledEvent(order, fromStatus))
+	}
+	return nil
+}
+
+// CancelOrder cancels an order and puts the stock of every item back in one transaction.
+// Cancelling an already cancelled order returns it unchanged, so retries never restock twice
+func (s *OrderService) CancelOrder(ctx context.Context, id int64, cancelledBy, reason string) (repo.Order, error) {
+	if cancelledBy == "" {
+		return repo.Order{}, &utils.ValidationError{
+			Field:   "cancelled_by",
+			Message: "cannot be empty",
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return repo.Order{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	// lock the order so a concurrent cancel waits for us and then sees it cancelled
+	order, err := qtx.GetOrderForUpdate(ctx, id)
+	if err != nil {
+		tx.Rollback(ctx)
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return repo.Order{}, &utils.NotFoundError{
+				Resource: "Order",
+				ID:       strconv.FormatInt(id, 10),
+			}
+		}
+		return repo.Order{}, &utils.DatabaseError{Query: "GetOrderForUpdate", Err: err}
+	}
+
+	if order.Status == StatusCancelled {
+		tx.Rollback(ctx)
+		return order, nil
+	}
+
+	if !s.cancellable(order.Status) {
+		tx.Rollback(ctx)
+		return repo.Order{}, &utils.InvalidTransitionError{
+			Resource: "Order",
+			From:     order.Status,
+			To:       StatusCancelled,
+		}
+	}
+
+	// give the stock back
+	err = releaseOrderStock(ctx, qtx, order.ID, cancelledBy)
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Order{}, err
+	}
+
+	cancelled, err := qtx.CancelOrder(ctx, repo.CancelOrderParams{
+		CancelledBy:  pgtype.Text{String: cancelledBy, Valid: true},
+		CancelReason: pgtype.Text{String: reason, Valid: reason != ""},
+		ID:           order.ID,
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Order{}, &utils.DatabaseError{Query: "CancelOrder", Err: err}
+	}
+
+	_, err = qtx.AddOrderStatusHistory(ctx, repo.AddOrderStatusHistoryParams{
+		OrderID:    order.ID,
+		FromStatus: pgtype.Text{String: order.Status, Valid: true},
+		ToStatus:   StatusCancelled,
+		Note:       fmt.Sprintf("cancelled by %s: %s", cancelledBy, reason),
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Order{}, &utils.DatabaseError{Query: "AddOrderStatusHistory", Err: err}
+	}
+
+	if err := writeStatusEvents(ctx, qtx, cancelled, order.Status); err != nil {
+		tx.Rollback(ctx)
+		return repo.Order{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Order{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return cancelled, nil
+}
+
+// cancellable reports whether an order in status can still be cancelled, it must not be past
+// the configured cutoff
+func (s *OrderService) cancellable(status string) bool {
+	return !IsPast(status, s.config.CancelCutoffStatus) && CanTransition(status, StatusCancelled)
+}
+
+func (s *OrderService) GetOrdersByCustomerRef(ctx context.Context, customerRef string, limit int32, cursor string) (utils.Page[repo.Order], error) {
+	params := repo.ListOrdersByCustomerRefPageParams{
+		CustomerRef: customerRef,
+		PageLimit:   limit + 1,
+	}
+
+	createdAt, id, err := decodeOrderCursor(cursor)
+	if err != nil {
+		return utils.Page[repo.Order]{}, err
+	}
+	params.CursorCreatedAt = createdAt
+	params.CursorID = id
+
+	orders, err := s.repo.ListOrdersByCustomerRefPage(ctx, params)
+	if err != nil {
+		return utils.Page[repo.Order]{}, &utils.DatabaseError{
+			Query: "ListOrdersByCustomerRefPage",
 			Err:   err,
 		}
 	}
-	return orders, nil
+	return utils.NewPage(orders, limit, orderCursor), nil
+}
+
+// orders are always listed newest first, so the cursor is the (created_at, id) of the last order
+func orderCursor(o repo.Order) utils.Cursor {
+	return utils.Cursor{Value: o.CreatedAt.Time.Format(cursorTimeLayout), ID: o.ID}
+}
+
+func decodeOrderCursor(raw string) (pgtype.Timestamp, pgtype.Int8, error) {
+	if raw == "" {
+		return pgtype.Timestamp{}, pgtype.Int8{}, nil
+	}
+	cursor, err := utils.DecodeCursor(raw)
+	if err != nil {
+		return pgtype.Timestamp{}, pgtype.Int8{}, err
+	}
+	createdAt, err := time.Parse(cursorTimeLayout, cursor.Value)
+	if err != nil {
+		return pgtype.Timestamp{}, pgtype.Int8{}, &utils.ValidationError{Field: "cursor", Message: "malformed cursor"}
+	}
+	return pgtype.Timestamp{Time: createdAt, Valid: true}, pgtype.Int8{Int64: cursor.ID, Valid: true}, nil
 }
 
 func (s *OrderService) DeleteOrder(ctx context.Context, id int64) error {
@@ -0,0 +1,53 @@
+package orders
+
+import (
+	"context"
+	"ecomApis/internals/utils"
+	"errors"
+	"testing"
+)
+
+func TestTransitionOrderRefusesCancel(t *testing.T) {
+	// the database is never reached, cancelling is refused before the order is loaded
+	s := NewOrderService(nil, nil, Config{CancelCutoffStatus: StatusPaid}, nil, nil)
+
+	_, _, err := s.TransitionOrder(context.Background(), 1, StatusCancelled, "too late", "admin")
+	var validationErr *utils.ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Field != "status" {
+		t.Fatalf("TransitionOrder to cancelled: err = %v, want a status validation error", err)
+	}
+}
+
+func TestTransitionOrderRefusesUnknownStatus(t *testing.T) {
+	s := NewOrderService(nil, nil, Config{}, nil, nil)
+
+	_, _, err := s.TransitionOrder(context.Background(), 1, "lost", "", "admin")
+	var validationErr *utils.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("TransitionOrder to lost: err = %v, want a validation error", err)
+	}
+}
+
+func TestCancellable(t *testing.T) {
+	tests := []struct {
+		cutoff string
+		status string
+		want   bool
+	}{
+		{StatusPaid, StatusPending, true},
+		{StatusPaid, StatusPaid, true},
+		{StatusPaid, StatusFulfilled, false},
+		{StatusFulfilled, StatusFulfilled, true},
+		{StatusFulfilled, StatusShipped, false},
+		{StatusPending, StatusPaid, false},
+		{StatusShipped, StatusShipped, false},
+		{StatusPaid, StatusCancelled, false},
+		{StatusPaid, StatusRefunded, false},
+	}
+	for _, tt := range tests {
+		s := NewOrderService(nil, nil, Config{CancelCutoffStatus: tt.cutoff}, nil, nil)
+		if got := s.cancellable(tt.status); got != tt.want {
+			t.Errorf("cancellable(%s) with cutoff %s = %v, want %v", tt.status, tt.cutoff, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package orders
+
+// order lifecycle statuses
+const (
+	StatusPending   = "pending"
+	StatusPaid      = "paid"
+	StatusFulfilled = "fulfilled"
+	StatusShipped   = "shipped"
+	StatusDelivered = "delivered"
+	StatusCancelled = "cancelled"
+	StatusRefunded  = "refunded"
+)
+
+// allowedTransitions lists where an order can go from each status.
+// cancelled and refunded are terminal
+var allowedTransitions = map[string][]string{
+	StatusPending:   {StatusPaid, StatusCancelled},
+	StatusPaid:      {StatusFulfilled, StatusCancelled, StatusRefunded},
+	StatusFulfilled: {StatusShipped, StatusCancelled, StatusRefunded},
+	StatusShipped:   {StatusDelivered, StatusRefunded},
+	StatusDelivered: {StatusRefunded},
+	StatusCancelled: {},
+	StatusRefunded:  {},
+}
+
+func IsValidStatus(status string) bool {
+	_, ok := allowedTransitions[status]
+	return ok
+}
+
+func CanTransition(from, to string) bool {
+	for _, next := range allowedTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// statusProgress orders the forward statuses so we can tell how far along an order is
+var statusProgress = map[string]int{
+	StatusPending:   0,
+	StatusPaid:      1,
+	StatusFulfilled: 2,
+	StatusShipped:   3,
+	StatusDelivered: 4,
+}
+
+// IsPast reports whether status is further along the lifecycle than cutoff
+func IsPast(status, cutoff string) bool {
+	progress, ok := statusProgress[status]
+	if !ok {
+		// cancelled and refunded are not on the forward path
+		return true
+	}
+	return progress > statusProgress[cutoff]
+}
@@ -0,0 +1,67 @@
+package orders
+
+import "testing"
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{StatusPending, StatusPaid, true},
+		{StatusPending, StatusCancelled, true},
+		{StatusPending, StatusFulfilled, false},
+		{StatusPending, StatusRefunded, false},
+		{StatusPaid, StatusFulfilled, true},
+		{StatusPaid, StatusCancelled, true},
+		{StatusPaid, StatusRefunded, true},
+		{StatusPaid, StatusPending, false},
+		{StatusFulfilled, StatusShipped, true},
+		{StatusFulfilled, StatusCancelled, true},
+		{StatusShipped, StatusDelivered, true},
+		{StatusShipped, StatusCancelled, false},
+		{StatusDelivered, StatusRefunded, true},
+		{StatusDelivered, StatusShipped, false},
+		{StatusCancelled, StatusPending, false},
+		{StatusCancelled, StatusRefunded, false},
+		{StatusRefunded, StatusPaid, false},
+		{StatusPaid, StatusPaid, false},
+		{"unknown", StatusPaid, false},
+		{StatusPending, "unknown", false},
+	}
+	for _, tt := range tests {
+		if got := CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestIsValidStatus(t *testing.T) {
+	for _, status := range []string{StatusPending, StatusPaid, StatusFulfilled, StatusShipped, StatusDelivered, StatusCancelled, StatusRefunded} {
+		if !IsValidStatus(status) {
+			t.Errorf("IsValidStatus(%s) = false", status)
+		}
+	}
+	if IsValidStatus("lost") {
+		t.Error("IsValidStatus(lost) = true")
+	}
+}
+
+func TestIsPast(t *testing.T) {
+	tests := []struct {
+		status, cutoff string
+		want           bool
+	}{
+		{StatusPending, StatusPaid, false},
+		{StatusPaid, StatusPaid, false},
+		{StatusFulfilled, StatusPaid, true},
+		{StatusDelivered, StatusShipped, true},
+		{StatusShipped, StatusDelivered, false},
+		{StatusCancelled, StatusPending, true},
+		{StatusRefunded, StatusDelivered, true},
+	}
+	for _, tt := range tests {
+		if got := IsPast(tt.status, tt.cutoff); got != tt.want {
+			t.Errorf("IsPast(%s, %s) = %v, want %v", tt.status, tt.cutoff, got, tt.want)
+		}
+	}
+}
@@ -1,11 +1,54 @@
 package orders
 
+import (
+	"ecomApis/internals/repo"
+	"ecomApis/internals/shipping"
+	"time"
+)
+
+// OrderItemRequest orders a product, products with variants also need the variant to order
 type OrderItemRequest struct {
-	ProductID int64 `json:"product_id"`
-	Quantity  int32 `json:"quantity"`
+	ProductID int64  `json:"product_id"`
+	VariantID *int64 `json:"variant_id"`
+	Quantity  int32  `json:"quantity"`
 }
 
 type CreateOrderRequest struct {
-	CustomerRef string             `json:"customer_ref"`
-	Items       []OrderItemRequest `json:"items"`
+	CustomerRef string `json:"customer_ref"`
+	// Currency is optional, prices are converted to it when set
+	Currency string             `json:"currency"`
+	Items    []OrderItemRequest `json:"items"`
+	// CouponCode is optional, the promotion's discount is taken off the order total
+	CouponCode string `json:"coupon_code"`
+	// ShippingMethod is the code of a shipping method, its charge is added to the total
+	ShippingMethod string `json:"shipping_method"`
+	// the addresses default to the customer's address, billing defaults to shipping
+	ShippingAddress *shipping.Address `json:"shipping_address"`
+	BillingAddress  *shipping.Address `json:"billing_address"`
+}
+
+type TransitionRequest struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
+
+// OrderDetails is everything GET /orders/{id} returns about a single order
+type OrderDetails struct {
+	Order         repo.Order                `json:"order"`
+	Items         []repo.OrderItem          `json:"order_items"`
+	StatusHistory []repo.OrderStatusHistory `json:"status_history"`
+	Addresses     []repo.OrderAddress       `json:"addresses"`
+}
+
+// CancelOrderRequest carries why the order is cancelled, who cancelled it comes from the caller's credentials
+type CancelOrderRequest struct {
+	Reason string `json:"reason"`
+}
+
+// Config holds the tunable order rules
+type Config struct {
+	// CancelCutoffStatus is the last status an order can still be cancelled from
+	CancelCutoffStatus string
+	// ReservationTTL is how long an unpaid order holds its stock
+	ReservationTTL time.Duration
 }
@@ -0,0 +1,177 @@
+package outbox
+
+import (
+	"context"
+	"ecomApis/internals/money"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// event types
+const (
+	EventOrderCreated        = "order.created"
+	EventOrderStatusChanged  = "order.status_changed"
+	EventOrderCancelled      = "order.cancelled"
+	EventProductStockChanged = "product.stock_changed"
+	EventProductPriceChanged = "product.price_changed"
+)
+
+// EventTypes lists every event type
+var EventTypes = []string{
+	EventOrderCreated,
+	EventOrderStatusChanged,
+	EventOrderCancelled,
+	EventProductStockChanged,
+	EventProductPriceChanged,
+}
+
+// what an event is about, events of the same aggregate are published in order
+const (
+	AggregateOrder   = "order"
+	AggregateProduct = "product"
+)
+
+// Event is a domain event waiting to be written to the outbox. Data is encoded as JSON
+type Event struct {
+	Type          string
+	AggregateType string
+	AggregateID   string
+	Data          any
+}
+
+// OrderCreated is the data of order.created
+type OrderCreated struct {
+	Order repo.Order       `json:"order"`
+	Items []repo.OrderItem `json:"items"`
+}
+
+// OrderStatusChanged is the data of order.status_changed, the order carries the new status
+type OrderStatusChanged struct {
+	Order      repo.Order `json:"order"`
+	FromStatus string     `json:"from_status"`
+}
+
+// OrderCancelled is the data of order.cancelled, FromStatus is the status it was cancelled from
+type OrderCancelled struct {
+	Order      repo.Order `json:"order"`
+	FromStatus string     `json:"from_status"`
+}
+
+// StockChanged is the data of product.stock_changed, one per inventory ledger movement.
+// Quantity is the signed change and Stock the stock after it
+type StockChanged struct {
+	ProductID     int64  `json:"product_id"`
+	VariantID     *int64 `json:"variant_id,omitempty"`
+	Quantity      int32  `json:"quantity"`
+	Stock         int32  `json:"stock"`
+	Reason        string `json:"reason"`
+	Actor         string `json:"actor"`
+	ReferenceType string `json:"reference_type,omitempty"`
+	ReferenceID   string `json:"reference_id,omitempty"`
+}
+
+// PriceChanged is the data of product.price_changed. Without a variant it is the product price,
+// which is also the price of every variant without a price of its own
+type PriceChanged struct {
+	ProductID     int64       `json:"product_id"`
+	VariantID     *int64      `json:"variant_id,omitempty"`
+	PreviousPrice money.Money `json:"previous_price"`
+	Price         money.Money `json:"price"`
+}
+
+func OrderCreatedEvent(order repo.Order, items []repo.OrderItem) Event {
+	return Event{
+		Type:          EventOrderCreated,
+		AggregateType: AggregateOrder,
+		AggregateID:   strconv.FormatInt(order.ID, 10),
+		Data:          OrderCreated{Order: order, Items: items},
+	}
+}
+
+func OrderStatusChangedEvent(order repo.Order, fromStatus string) Event {
+	return Event{
+		Type:          EventOrderStatusChanged,
+		AggregateType: AggregateOrder,
+		AggregateID:   strconv.FormatInt(order.ID, 10),
+		Data:          OrderStatusChanged{Order: order, FromStatus: fromStatus},
+	}
+}
+
+func OrderCancelledEvent(order repo.Order, fromStatus string) Event {
+	return Event{
+		Type:          EventOrderCancelled,
+		AggregateType: AggregateOrder,
+		AggregateID:   strconv.FormatInt(order.ID, 10),
+		Data:          OrderCancelled{Order: order, FromStatus: fromStatus},
+	}
+}
+
+// StockChangedEvent is keyed by product, so the events of its variants stay in order with its own
+func StockChangedEvent(data StockChanged) Event {
+	return Event{
+		Type:          EventProductStockChanged,
+		AggregateType: AggregateProduct,
+		AggregateID:   strconv.FormatInt(data.ProductID, 10),
+		Data:          data,
+	}
+}
+
+func PriceChangedEvent(data PriceChanged) Event {
+	return Event{
+		Type:          EventProductPriceChanged,
+		AggregateType: AggregateProduct,
+		AggregateID:   strconv.FormatInt(data.ProductID, 10),
+		Data:          data,
+	}
+}
+
+// Write adds an event to the outbox. Pass the queries of the transaction that made the change so
+// the event is only published if the change commits. Write after the aggregate's row is locked
+// or changed, that keeps the outbox ids of one aggregate in commit order
+func Write(ctx context.Context, q *repo.Queries, event Event) error {
+	payload, err := json.Marshal(event.Data)
+	if err != nil {
+		return fmt.Errorf("encode %s event: %w", event.Type, err)
+	}
+
+	err = q.AddOutboxEvent(ctx, repo.AddOutboxEventParams{
+		EventType:     event.Type,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		Payload:       payload,
+	})
+	if err != nil {
+		return &utils.DatabaseError{Query: "AddOutboxEvent", Err: err}
+	}
+	return nil
+}
+
+// Copy adds many events with a single COPY, in the order given
+func Copy(ctx context.Context, q *repo.Queries, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	rows := make([]repo.CopyOutboxEventsParams, 0, len(events))
+	for _, event := range events {
+		payload, err := json.Marshal(event.Data)
+		if err != nil {
+			return fmt.Errorf("encode %s event: %w", event.Type, err)
+		}
+		rows = append(rows, repo.CopyOutboxEventsParams{
+			EventType:     event.Type,
+			AggregateType: event.AggregateType,
+			AggregateID:   event.AggregateID,
+			Payload:       payload,
+		})
+	}
+
+	_, err := q.CopyOutboxEvents(ctx, rows)
+	if err != nil {
+		return &utils.DatabaseError{Query: "CopyOutboxEvents", Err: err}
+	}
+	return nil
+}
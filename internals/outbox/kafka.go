@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaConfig lists the brokers to bootstrap from
+type KafkaConfig struct {
+	Brokers []string
+}
+
+// KafkaPublisher publishes to the topic <prefix>.<aggregate type>, e.g. ecom.order, with the
+// aggregate id as the message key. Every message of a key lands on the same partition, and as
+// the relay publishes one message at a time they keep their order there
+type KafkaPublisher struct {
+	writer *kafka.Writer
+	prefix string
+}
+
+func NewKafkaPublisher(cfg KafkaConfig, prefix string) (*KafkaPublisher, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, fmt.Errorf("the kafka publisher needs at least one broker")
+	}
+
+	return &KafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(cfg.Brokers...),
+			Balancer: &kafka.Hash{},
+			// wait for every in-sync replica, an acknowledged event must not be lost
+			RequiredAcks: kafka.RequireAll,
+			// write each message as soon as it is handed over instead of waiting for a batch
+			BatchSize:              1,
+			AllowAutoTopicCreation: true,
+		},
+		prefix: prefix,
+	}, nil
+}
+
+func (p *KafkaPublisher) Name() string {
+	return KafkaPublisherName
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, msg Message) error {
+	var headers []kafka.Header
+	for k, v := range msg.headers() {
+		headers = append(headers, kafka.Header{Key: k, Value: []byte(v)})
+	}
+
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Topic:   p.prefix + "." + msg.AggregateType,
+		Key:     []byte(msg.Key),
+		Value:   msg.Body,
+		Headers: headers,
+	})
+}
+
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
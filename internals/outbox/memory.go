@@ -0,0 +1,59 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryPublisher hands messages to in-process subscribers. It is meant for local development
+// and tests, nothing outside the process sees the events
+type MemoryPublisher struct {
+	mu          sync.Mutex
+	messages    []Message
+	subscribers []func(Message)
+}
+
+// messages kept for Messages, older ones are dropped
+const memoryPublisherHistory = 1000
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Name() string {
+	return MemoryPublisherName
+}
+
+// Publish calls every subscriber in turn before returning
+func (p *MemoryPublisher) Publish(ctx context.Context, msg Message) error {
+	p.mu.Lock()
+	p.messages = append(p.messages, msg)
+	if len(p.messages) > memoryPublisherHistory {
+		p.messages = p.messages[len(p.messages)-memoryPublisherHistory:]
+	}
+	subscribers := p.subscribers
+	p.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(msg)
+	}
+	return nil
+}
+
+// Subscribe registers fn for every message published from now on
+func (p *MemoryPublisher) Subscribe(fn func(Message)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.subscribers = append(p.subscribers, fn)
+}
+
+// Messages returns the most recent messages, oldest first
+func (p *MemoryPublisher) Messages() []Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Message(nil), p.messages...)
+}
+
+func (p *MemoryPublisher) Close() error {
+	return nil
+}
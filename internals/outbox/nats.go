@@ -0,0 +1,84 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// NATSConfig points at a NATS server with JetStream enabled
+type NATSConfig struct {
+	URL string
+	// Stream is created if it does not exist and captures every subject under the topic prefix
+	Stream string
+}
+
+// the stream drops a message with an id it has seen within this window, so a
+// relay that republishes after a lost acknowledgement does not store it twice
+const natsDuplicateWindow = 10 * time.Minute
+
+// NATSPublisher publishes to JetStream, on the subject <prefix>.<event type>, e.g.
+// ecom.order.created. The outbox id is the message id, so JetStream drops redeliveries
+type NATSPublisher struct {
+	conn   *nats.Conn
+	js     jetstream.JetStream
+	prefix string
+}
+
+func NewNATSPublisher(ctx context.Context, cfg NATSConfig, prefix string) (*NATSPublisher, error) {
+	if cfg.URL == "" || cfg.Stream == "" {
+		return nil, fmt.Errorf("the nats publisher needs a url and a stream")
+	}
+
+	conn, err := nats.Connect(cfg.URL, nats.Name("ecom-outbox"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("connect to nats: %w", err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("open jetstream: %w", err)
+	}
+
+	_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:       cfg.Stream,
+		Subjects:   []string{prefix + ".>"},
+		Storage:    jetstream.FileStorage,
+		Duplicates: natsDuplicateWindow,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("create stream %s: %w", cfg.Stream, err)
+	}
+
+	return &NATSPublisher{
+		conn:   conn,
+		js:     js,
+		prefix: prefix,
+	}, nil
+}
+
+func (p *NATSPublisher) Name() string {
+	return NATSPublisherName
+}
+
+func (p *NATSPublisher) Publish(ctx context.Context, msg Message) error {
+	m := nats.NewMsg(p.prefix + "." + msg.Type)
+	m.Data = msg.Body
+	for k, v := range msg.headers() {
+		m.Header.Set(k, v)
+	}
+
+	_, err := p.js.PublishMsg(ctx, m, jetstream.WithMsgID(strconv.FormatInt(msg.ID, 10)))
+	return err
+}
+
+// Close flushes pending messages and closes the connection
+func (p *NATSPublisher) Close() error {
+	return p.conn.Drain()
+}
@@ -0,0 +1,92 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	MemoryPublisherName = "memory"
+	NATSPublisherName   = "nats"
+	KafkaPublisherName  = "kafka"
+)
+
+// Publisher sends events to a message broker. Publish returns once the broker has accepted the
+// message, the relay only marks an event published after that. Delivery is at-least-once, so a
+// message can be sent again after a crash or a lost acknowledgement
+type Publisher interface {
+	Name() string
+	Publish(ctx context.Context, msg Message) error
+	Close() error
+}
+
+// Message is an outbox event on its way to the broker
+type Message struct {
+	// ID is the outbox id of the event, the same on every delivery, consumers dedupe on it
+	ID            int64
+	Type          string
+	AggregateType string
+	// Key is the aggregate id, messages with the same key are published in order
+	Key  string
+	Body []byte
+}
+
+// Envelope is the JSON body of every message
+type Envelope struct {
+	ID            int64           `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// headers sent with every message, next to the broker's own
+const (
+	HeaderEventID   = "Event-Id"
+	HeaderEventType = "Event-Type"
+	HeaderEventKey  = "Event-Key"
+)
+
+func (m Message) headers() map[string]string {
+	return map[string]string{
+		HeaderEventID:   strconv.FormatInt(m.ID, 10),
+		HeaderEventType: m.Type,
+		HeaderEventKey:  m.Key,
+	}
+}
+
+// Config selects the publisher and tunes the relay
+type Config struct {
+	// Publisher is memory, nats or kafka
+	Publisher string
+	// TopicPrefix starts every NATS subject and Kafka topic
+	TopicPrefix string
+	NATS        NATSConfig
+	Kafka       KafkaConfig
+	// BatchSize is how many events the relay reads at a time
+	BatchSize int
+	// PollInterval is how long the relay waits once the outbox is drained
+	PollInterval time.Duration
+	// PublishTimeout bounds a single publish
+	PublishTimeout time.Duration
+	// Retention is how long published events are kept in the table
+	Retention time.Duration
+}
+
+// NewPublisher connects to the configured broker
+func NewPublisher(ctx context.Context, cfg Config) (Publisher, error) {
+	switch cfg.Publisher {
+	case "", MemoryPublisherName:
+		return NewMemoryPublisher(), nil
+	case NATSPublisherName:
+		return NewNATSPublisher(ctx, cfg.NATS, cfg.TopicPrefix)
+	case KafkaPublisherName:
+		return NewKafkaPublisher(cfg.Kafka, cfg.TopicPrefix)
+	default:
+		return nil, fmt.Errorf("unknown outbox publisher %q", cfg.Publisher)
+	}
+}
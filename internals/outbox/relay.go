@@ -0,0 +1,233 @@
+package outbox
+
+import (
+	"cmp"
+	"context"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// failed events are retried after 1s, 2s, 4s and so on, up to this
+const maxRetryDelay = 5 * time.Minute
+
+// how long a relay works through the events it claimed before it hands the rest back. The
+// claim lasts a PublishTimeout longer, so no other relay takes them while one is in flight
+const relayLease = time.Minute
+
+// Relay moves events from the outbox table to the publisher
+type Relay struct {
+	repo      *repo.Queries
+	db        *pgxpool.Pool
+	publisher Publisher
+	config    Config
+}
+
+func NewRelay(r *repo.Queries, db *pgxpool.Pool, publisher Publisher, cfg Config) *Relay {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.PublishTimeout <= 0 {
+		cfg.PublishTimeout = 10 * time.Second
+	}
+	return &Relay{
+		repo:      r,
+		db:        db,
+		publisher: publisher,
+		config:    cfg,
+	}
+}
+
+// Run publishes due events until ctx is cancelled. It keeps going while batches come back full
+// and waits PollInterval once the outbox is drained. Published events older than the retention
+// are deleted once an hour, once their webhook deliveries are queued as well
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+	lastCleanup := time.Now()
+
+	for {
+		full, err := r.RelayBatch(ctx)
+		if err != nil {
+			slog.Error("failed to relay outbox events", "error", err)
+		}
+
+		if r.config.Retention > 0 && time.Since(lastCleanup) >= time.Hour {
+			lastCleanup = time.Now()
+			deleted, err := r.repo.DeletePublishedOutboxEvents(ctx, int32(r.config.Retention.Seconds()))
+			if err != nil {
+				slog.Error("failed to delete published outbox events", "error", err)
+			} else if deleted > 0 {
+				slog.Info("deleted published outbox events", "count", deleted)
+			}
+		}
+
+		if full && err == nil {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+				continue
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayBatch publishes one batch of due events in outbox order and reports whether the batch
+// was full. The batch is claimed for a lease under a lock only one relay holds at a time, so
+// relays never claim the events of one aggregate side by side, and no transaction is open
+// while the events are published. Each event is marked on its own once the publisher took it
+// or it failed. When an event fails, the later events of its aggregate wait in the table until
+// it is retried, so the events of an aggregate are always published in order
+func (r *Relay) RelayBatch(ctx context.Context) (bool, error) {
+	start := time.Now()
+	events, err := r.claim(ctx)
+	if err != nil || len(events) == 0 {
+		return false, err
+	}
+
+	var skipped []int64
+	blocked := map[string]bool{}
+	for _, event := range events {
+		key := event.AggregateType + "/" + event.AggregateID
+		if blocked[key] || time.Since(start) >= relayLease || ctx.Err() != nil {
+			skipped = append(skipped, event.ID)
+			continue
+		}
+
+		msg, err := NewMessage(event)
+		if err == nil {
+			err = r.publish(ctx, msg)
+		}
+		if err == nil {
+			err = r.repo.MarkOutboxEventPublished(ctx, msg.ID)
+			if err == nil {
+				continue
+			}
+			slog.Error("failed to mark outbox event published", "id", event.ID, "error", err)
+		}
+
+		if ctx.Err() != nil {
+			// shutting down, the event is handed back and published again
+			skipped = append(skipped, event.ID)
+			continue
+		}
+
+		blocked[key] = true
+		delay := retryDelay(event.Attempts)
+		slog.Warn("failed to publish outbox event",
+			"id", event.ID,
+			"type", event.EventType,
+			"attempts", event.Attempts+1,
+			"retry_in", delay,
+			"error", err,
+		)
+		err = r.repo.RecordOutboxFailure(ctx, repo.RecordOutboxFailureParams{
+			LastError:    err.Error(),
+			RetrySeconds: int32(delay.Seconds()),
+			ID:           event.ID,
+		})
+		if err != nil {
+			// the claim runs out and the event is published again
+			slog.Error("failed to record outbox failure", "id", event.ID, "error", err)
+		}
+	}
+
+	// handed back right away, events behind a failed one keep waiting for it
+	if len(skipped) > 0 {
+		err = r.repo.ReleaseOutboxEvents(context.WithoutCancel(ctx), skipped)
+		if err != nil {
+			return false, &utils.DatabaseError{Query: "ReleaseOutboxEvents", Err: err}
+		}
+	}
+	return len(events) == r.config.BatchSize, nil
+}
+
+// claim takes the next due events for relayLease, in outbox order. It returns none while
+// another relay is claiming
+func (r *Relay) claim(ctx context.Context) ([]repo.Outbox, error) {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := r.repo.WithTx(tx)
+
+	locked, err := qtx.TryLockOutboxRelay(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return nil, &utils.DatabaseError{Query: "TryLockOutboxRelay", Err: err}
+	}
+	if !locked {
+		tx.Rollback(ctx)
+		return nil, nil
+	}
+
+	events, err := qtx.ClaimOutboxEvents(ctx, repo.ClaimOutboxEventsParams{
+		LeaseSeconds: int32((relayLease + r.config.PublishTimeout).Seconds()),
+		Limit:        int32(r.config.BatchSize),
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		return nil, &utils.DatabaseError{Query: "ClaimOutboxEvents", Err: err}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	slices.SortFunc(events, func(a, b repo.Outbox) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return events, nil
+}
+
+func (r *Relay) publish(ctx context.Context, msg Message) error {
+	ctx, cancel := context.WithTimeout(ctx, r.config.PublishTimeout)
+	defer cancel()
+
+	return r.publisher.Publish(ctx, msg)
+}
+
+// NewMessage wraps an outbox row in its envelope
+func NewMessage(event repo.Outbox) (Message, error) {
+	body, err := json.Marshal(Envelope{
+		ID:            event.ID,
+		Type:          event.EventType,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		OccurredAt:    event.CreatedAt.Time,
+		Data:          event.Payload,
+	})
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		ID:            event.ID,
+		Type:          event.EventType,
+		AggregateType: event.AggregateType,
+		Key:           event.AggregateID,
+		Body:          body,
+	}, nil
+}
+
+func retryDelay(attempts int32) time.Duration {
+	if attempts >= 9 {
+		return maxRetryDelay
+	}
+	return min(time.Second<<attempts, maxRetryDelay)
+}
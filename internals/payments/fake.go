@@ -0,0 +1,252 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"ecomApis/internals/money"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	FakeGatewayName = "fake"
+	// FakeSignatureHeader carries "t=<unix seconds>,v1=<hex hmac-sha256 of "<t>.<body>">"
+	FakeSignatureHeader = "X-Fake-Signature"
+	// webhooks signed longer ago than this are rejected so captured requests cannot be replayed
+	fakeSignatureTolerance = 5 * time.Minute
+)
+
+// test payment methods of the fake gateway, any other "tok_" token is approved
+const (
+	FakeTokenDeclined = "tok_declined"
+	FakeTokenPending  = "tok_pending"
+	FakeTokenError    = "tok_error"
+)
+
+// FakeGateway is an in-process gateway for local development and tests. It keeps its
+// charges in memory, so they are lost on restart
+type FakeGateway struct {
+	secret []byte
+
+	mu      sync.Mutex
+	next    int64
+	charges map[string]*fakeCharge
+	// the charge made for each payment id and the answer to each refund key, for repeated calls
+	payments map[int64]string
+	refunds  map[string]Result
+}
+
+type fakeCharge struct {
+	status   string
+	amount   money.Money
+	refunded int64
+}
+
+func NewFakeGateway(webhookSecret string) *FakeGateway {
+	return &FakeGateway{
+		secret:   []byte(webhookSecret),
+		charges:  map[string]*fakeCharge{},
+		payments: map[int64]string{},
+		refunds:  map[string]Result{},
+	}
+}
+
+func (g *FakeGateway) Name() string {
+	return FakeGatewayName
+}
+
+func (g *FakeGateway) Authorize(ctx context.Context, req AuthorizeRequest) (Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch {
+	case req.PaymentMethod == FakeTokenError:
+		return Result{}, &Error{Code: "unavailable", Message: "the fake gateway is simulating an outage"}
+	case !strings.HasPrefix(req.PaymentMethod, "tok_"):
+		return Result{Status: StatusFailed, FailureCode: "invalid_payment_method", FailureMessage: "unknown payment method"}, nil
+	}
+
+	if ref, ok := g.payments[req.PaymentID]; ok {
+		return g.chargeResult(ref), nil
+	}
+
+	g.next++
+	ref := "fake_" + strconv.FormatInt(g.next, 10)
+	charge := &fakeCharge{status: StatusAuthorized, amount: req.Amount}
+	switch {
+	case req.PaymentMethod == FakeTokenDeclined:
+		charge.status = StatusFailed
+	case req.PaymentMethod == FakeTokenPending:
+		// settles later through a webhook
+		charge.status = StatusPending
+	case req.Capture:
+		charge.status = StatusCaptured
+	}
+	g.charges[ref] = charge
+	g.payments[req.PaymentID] = ref
+
+	return g.chargeResult(ref), nil
+}
+
+// chargeResult describes a charge as Authorize answers, g.mu must be held
+func (g *FakeGateway) chargeResult(ref string) Result {
+	charge := g.charges[ref]
+	if charge.status == StatusFailed {
+		return Result{Reference: ref, Status: StatusFailed, FailureCode: "card_declined", FailureMessage: "the card was declined"}
+	}
+	return Result{Reference: ref, Status: charge.status}
+}
+
+func (g *FakeGateway) Capture(ctx context.Context, reference string, amount money.Money) (Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	charge, err := g.charge(reference, StatusAuthorized, StatusCaptured)
+	if err != nil {
+		return Result{}, err
+	}
+	if amount != charge.amount {
+		return Result{}, &Error{Code: "invalid_amount", Message: "only the full authorized amount can be captured"}
+	}
+	charge.status = StatusCaptured
+	return Result{Reference: reference, Status: StatusCaptured}, nil
+}
+
+func (g *FakeGateway) Void(ctx context.Context, reference string) (Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	charge, err := g.charge(reference, StatusAuthorized, StatusVoided)
+	if err != nil {
+		return Result{}, err
+	}
+	charge.status = StatusVoided
+	return Result{Reference: reference, Status: StatusVoided}, nil
+}
+
+func (g *FakeGateway) Refund(ctx context.Context, reference string, amount money.Money, idempotencyKey string) (Result, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if res, ok := g.refunds[idempotencyKey]; ok {
+		return res, nil
+	}
+
+	charge, err := g.charge(reference, StatusCaptured, StatusPartiallyRefunded)
+	if err != nil {
+		return Result{}, err
+	}
+	if amount.Currency != charge.amount.Currency || amount.Amount <= 0 || charge.refunded+amount.Amount > charge.amount.Amount {
+		return Result{}, &Error{Code: "invalid_amount", Message: "refund exceeds the captured amount"}
+	}
+	charge.refunded += amount.Amount
+	charge.status = StatusPartiallyRefunded
+	if charge.refunded == charge.amount.Amount {
+		charge.status = StatusRefunded
+	}
+	res := Result{Reference: reference, Status: charge.status}
+	if idempotencyKey != "" {
+		g.refunds[idempotencyKey] = res
+	}
+	return res, nil
+}
+
+// charge finds a charge that is in one of the given statuses, g.mu must be held
+func (g *FakeGateway) charge(reference string, statuses ...string) (*fakeCharge, error) {
+	charge, ok := g.charges[reference]
+	if !ok {
+		return nil, &Error{Code: "not_found", Message: fmt.Sprintf("no charge %s", reference)}
+	}
+	for _, status := range statuses {
+		if charge.status == status {
+			return charge, nil
+		}
+	}
+	return nil, &Error{Code: "invalid_state", Message: fmt.Sprintf("charge %s is %s", reference, charge.status)}
+}
+
+// fakeWebhook is the body of a fake gateway callback
+type fakeWebhook struct {
+	ID             string      `json:"id"`
+	Type           string      `json:"type"`
+	Reference      string      `json:"reference"`
+	Amount         money.Money `json:"amount"`
+	FailureCode    string      `json:"failure_code"`
+	FailureMessage string      `json:"failure_message"`
+}
+
+func (g *FakeGateway) ParseWebhook(header http.Header, body []byte) (WebhookEvent, error) {
+	if !g.validSignature(header.Get(FakeSignatureHeader), body, time.Now()) {
+		return WebhookEvent{}, ErrInvalidSignature
+	}
+
+	var hook fakeWebhook
+	if err := json.Unmarshal(body, &hook); err != nil {
+		return WebhookEvent{}, fmt.Errorf("decode webhook: %w", err)
+	}
+	if hook.ID == "" || hook.Reference == "" || !IsValidStatus(hook.Type) {
+		return WebhookEvent{}, fmt.Errorf("decode webhook: id, reference and a payment status type are required")
+	}
+
+	return WebhookEvent{
+		ID:             hook.ID,
+		Type:           hook.Type,
+		Reference:      hook.Reference,
+		Amount:         hook.Amount,
+		FailureCode:    hook.FailureCode,
+		FailureMessage: hook.FailureMessage,
+	}, nil
+}
+
+// SignWebhook returns the signature header value for body, for tools that simulate callbacks
+func (g *FakeGateway) SignWebhook(body []byte, at time.Time) string {
+	t := strconv.FormatInt(at.Unix(), 10)
+	return "t=" + t + ",v1=" + hex.EncodeToString(g.mac(t, body))
+}
+
+func (g *FakeGateway) validSignature(header string, body []byte, now time.Time) bool {
+	// without a secret nothing can be verified, so nothing is accepted
+	if len(g.secret) == 0 {
+		return false
+	}
+
+	var t, v1 string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			t = value
+		case "v1":
+			v1 = value
+		}
+	}
+
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > fakeSignatureTolerance || age < -fakeSignatureTolerance {
+		return false
+	}
+
+	signature, err := hex.DecodeString(v1)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(signature, g.mac(t, body))
+}
+
+func (g *FakeGateway) mac(t string, body []byte) []byte {
+	h := hmac.New(sha256.New, g.secret)
+	h.Write([]byte(t))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
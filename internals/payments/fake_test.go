@@ -0,0 +1,158 @@
+package payments
+
+import (
+	"context"
+	"ecomApis/internals/money"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFakeGatewaySignature(t *testing.T) {
+	g := NewFakeGateway("whsec_test")
+	body := []byte(`{"id":"evt_1","type":"captured","reference":"fake_1"}`)
+	now := time.Unix(1700000000, 0)
+	header := g.SignWebhook(body, now)
+
+	tests := []struct {
+		name   string
+		header string
+		body   []byte
+		now    time.Time
+		want   bool
+	}{
+		{name: "valid", header: header, body: body, now: now, want: true},
+		{name: "spaces after comma", header: strings.ReplaceAll(header, ",", ", "), body: body, now: now, want: true},
+		{name: "within tolerance", header: header, body: body, now: now.Add(fakeSignatureTolerance), want: true},
+		{name: "too old", header: header, body: body, now: now.Add(fakeSignatureTolerance + time.Second), want: false},
+		{name: "from the future", header: header, body: body, now: now.Add(-fakeSignatureTolerance - time.Second), want: false},
+		{name: "changed body", header: header, body: []byte(`{"id":"evt_1","type":"refunded","reference":"fake_1"}`), now: now, want: false},
+		{name: "changed timestamp", header: strings.Replace(header, "t=1700000000", "t=1700000001", 1), body: body, now: now, want: false},
+		{name: "missing signature", header: "t=1700000000", body: body, now: now, want: false},
+		{name: "missing timestamp", header: header[strings.Index(header, "v1="):], body: body, now: now, want: false},
+		{name: "not hex", header: "t=1700000000,v1=zz", body: body, now: now, want: false},
+		{name: "empty", header: "", body: body, now: now, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := g.validSignature(tt.header, tt.body, tt.now); got != tt.want {
+				t.Errorf("validSignature(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+
+	other := NewFakeGateway("another_secret")
+	if other.validSignature(header, body, now) {
+		t.Error("signature accepted with another secret")
+	}
+	if NewFakeGateway("").validSignature(NewFakeGateway("").SignWebhook(body, now), body, now) {
+		t.Error("signature accepted without a secret")
+	}
+}
+
+func TestFakeGatewayParseWebhook(t *testing.T) {
+	g := NewFakeGateway("whsec_test")
+	body := []byte(`{"id":"evt_1","type":"partially_refunded","reference":"fake_1","amount":{"amount":500,"currency":"USD"}}`)
+
+	header := http.Header{}
+	header.Set(FakeSignatureHeader, g.SignWebhook(body, time.Now()))
+	event, err := g.ParseWebhook(header, body)
+	if err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+	want := WebhookEvent{ID: "evt_1", Type: StatusPartiallyRefunded, Reference: "fake_1", Amount: money.Money{Amount: 500, Currency: "USD"}}
+	if event != want {
+		t.Errorf("ParseWebhook = %+v, want %+v", event, want)
+	}
+
+	header.Set(FakeSignatureHeader, g.SignWebhook(body, time.Now().Add(-time.Hour)))
+	if _, err := g.ParseWebhook(header, body); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("stale signature: err = %v, want ErrInvalidSignature", err)
+	}
+
+	bad := []byte(`{"id":"evt_2","type":"disputed","reference":"fake_1"}`)
+	header.Set(FakeSignatureHeader, g.SignWebhook(bad, time.Now()))
+	if _, err := g.ParseWebhook(header, bad); err == nil || errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("unknown type: err = %v, want a decode error", err)
+	}
+}
+
+func TestFakeGatewayRepeatedCalls(t *testing.T) {
+	ctx := context.Background()
+	g := NewFakeGateway("whsec_test")
+	amount := money.Money{Amount: 1000, Currency: "USD"}
+
+	req := AuthorizeRequest{PaymentID: 7, OrderID: 3, Amount: amount, PaymentMethod: "tok_visa", Capture: true}
+	first, err := g.Authorize(ctx, req)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if first.Status != StatusCaptured {
+		t.Fatalf("Authorize status = %s, want %s", first.Status, StatusCaptured)
+	}
+	again, err := g.Authorize(ctx, req)
+	if err != nil {
+		t.Fatalf("Authorize again: %v", err)
+	}
+	if again != first {
+		t.Errorf("Authorize again = %+v, want the first charge %+v", again, first)
+	}
+
+	refund := money.Money{Amount: 400, Currency: "USD"}
+	for i := range 2 {
+		res, err := g.Refund(ctx, first.Reference, refund, "payment_refund_1")
+		if err != nil {
+			t.Fatalf("Refund %d: %v", i, err)
+		}
+		if res.Status != StatusPartiallyRefunded {
+			t.Errorf("Refund %d status = %s, want %s", i, res.Status, StatusPartiallyRefunded)
+		}
+	}
+	res, err := g.Refund(ctx, first.Reference, money.Money{Amount: 600, Currency: "USD"}, "payment_refund_2")
+	if err != nil {
+		t.Fatalf("Refund of the rest: %v", err)
+	}
+	if res.Status != StatusRefunded {
+		t.Errorf("Refund of the rest status = %s, want %s, the repeated refund was counted twice", res.Status, StatusRefunded)
+	}
+}
+
+func TestFakeGatewayTokens(t *testing.T) {
+	ctx := context.Background()
+	g := NewFakeGateway("whsec_test")
+	amount := money.Money{Amount: 1000, Currency: "USD"}
+
+	tests := []struct {
+		token   string
+		capture bool
+		want    string
+		wantErr bool
+	}{
+		{token: "tok_visa", capture: false, want: StatusAuthorized},
+		{token: "tok_visa", capture: true, want: StatusCaptured},
+		{token: FakeTokenDeclined, capture: true, want: StatusFailed},
+		{token: FakeTokenPending, capture: true, want: StatusPending},
+		{token: FakeTokenError, capture: true, wantErr: true},
+		{token: "card_123", capture: true, want: StatusFailed},
+	}
+	for i, tt := range tests {
+		t.Run(tt.token+"/"+strconv.FormatBool(tt.capture), func(t *testing.T) {
+			res, err := g.Authorize(ctx, AuthorizeRequest{PaymentID: int64(i + 1), Amount: amount, PaymentMethod: tt.token, Capture: tt.capture})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Authorize = %+v, want an error", res)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authorize: %v", err)
+			}
+			if res.Status != tt.want {
+				t.Errorf("Authorize status = %s, want %s", res.Status, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package payments
+
+import (
+	"context"
+	"ecomApis/internals/money"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Gateway is a payment provider. Declines are not errors, they come back as a Result with
+// StatusFailed, errors mean the gateway could not be reached or refused the request.
+//
+// Every call must be safe to repeat, so an attempt whose answer was never stored can be sent
+// again: Authorize returns the charge already made for a PaymentID, Refund the refund already
+// made for an idempotency key, and Capture and Void succeed on a charge already in that state
+type Gateway interface {
+	Name() string
+	Authorize(ctx context.Context, req AuthorizeRequest) (Result, error)
+	Capture(ctx context.Context, reference string, amount money.Money) (Result, error)
+	Void(ctx context.Context, reference string) (Result, error)
+	Refund(ctx context.Context, reference string, amount money.Money, idempotencyKey string) (Result, error)
+	// ParseWebhook verifies the signature of a callback and decodes it,
+	// a bad signature returns ErrInvalidSignature
+	ParseWebhook(header http.Header, body []byte) (WebhookEvent, error)
+}
+
+// AuthorizeRequest asks the gateway to hold Amount on PaymentMethod, a token the client got
+// from the gateway. With Capture the money is taken right away
+type AuthorizeRequest struct {
+	PaymentID     int64
+	OrderID       int64
+	Amount        money.Money
+	PaymentMethod string
+	Capture       bool
+}
+
+// Result is the outcome of a gateway call, Status is one of the payment statuses
+type Result struct {
+	Reference      string
+	Status         string
+	FailureCode    string
+	FailureMessage string
+}
+
+// WebhookEvent is a status change the gateway reports on its own. Type is the payment status
+// it moved to, and for refunds Amount is the total refunded so far
+type WebhookEvent struct {
+	ID             string
+	Type           string
+	Reference      string
+	Amount         money.Money
+	FailureCode    string
+	FailureMessage string
+}
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Error is a request the gateway turned down, Code is the gateway's own error code
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Config selects the gateway
+type Config struct {
+	// Gateway is the name of the gateway, only "fake" is built in
+	Gateway       string
+	WebhookSecret string
+}
+
+// NewGateway builds the configured gateway
+func NewGateway(cfg Config) (Gateway, error) {
+	switch cfg.Gateway {
+	case "", FakeGatewayName:
+		return NewFakeGateway(cfg.WebhookSecret), nil
+	default:
+		return nil, fmt.Errorf("unknown payment gateway %q", cfg.Gateway)
+	}
+}
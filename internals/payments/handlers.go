@@ -0,0 +1,196 @@
+package payments
+
+import (
+	"ecomApis/internals/auth"
+	"ecomApis/internals/utils"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// webhook bodies larger than this are rejected before the signature is checked
+const maxWebhookBody = 1 << 20
+
+type PaymentHandler struct {
+	service *PaymentService
+}
+
+func NewPaymentHandler(s *PaymentService) *PaymentHandler {
+	return &PaymentHandler{
+		service: s,
+	}
+}
+
+// CreatePayment handles POST /orders/{id}/payments, a declined payment answers 402
+func (h *PaymentHandler) CreatePayment(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid order ID"})
+		return
+	}
+
+	var req CreatePaymentRequest
+	err = utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	p, _ := auth.FromContext(r.Context())
+	payment, err := h.service.CreatePayment(r.Context(), orderID, req, p)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	if payment.Status == StatusFailed {
+		utils.WriteJSON(w, http.StatusPaymentRequired, map[string]interface{}{
+			"error":   "payment failed: " + payment.FailureMessage.String,
+			"payment": payment,
+		})
+		return
+	}
+	utils.WriteJSON(w, http.StatusCreated, payment)
+}
+
+// ListOrderPayments handles GET /orders/{id}/payments
+func (h *PaymentHandler) ListOrderPayments(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid order ID"})
+		return
+	}
+
+	p, _ := auth.FromContext(r.Context())
+	payments, err := h.service.ListOrderPayments(r.Context(), orderID, p)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, payments)
+}
+
+func (h *PaymentHandler) GetPayment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payment ID"})
+		return
+	}
+
+	details, err := h.service.GetPayment(r.Context(), id)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, details)
+}
+
+// CapturePayment handles POST /payments/{id}/capture
+func (h *PaymentHandler) CapturePayment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payment ID"})
+		return
+	}
+
+	p, _ := auth.FromContext(r.Context())
+	payment, err := h.service.Capture(r.Context(), id, p.Subject)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, payment)
+}
+
+// VoidPayment handles POST /payments/{id}/void
+func (h *PaymentHandler) VoidPayment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payment ID"})
+		return
+	}
+
+	p, _ := auth.FromContext(r.Context())
+	payment, err := h.service.Void(r.Context(), id, p.Subject)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, payment)
+}
+
+// RefundPayment handles POST /payments/{id}/refund, an empty object refunds everything left
+func (h *PaymentHandler) RefundPayment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payment ID"})
+		return
+	}
+
+	var req RefundRequest
+	err = utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	p, _ := auth.FromContext(r.Context())
+	payment, err := h.service.Refund(r.Context(), id, req, p.Subject)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, payment)
+}
+
+// HandleWebhook handles POST /webhooks/payments. It answers 2xx only once the event is stored,
+// anything else makes the gateway deliver it again
+func (h *PaymentHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.WriteJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": "request body too large"})
+			return
+		}
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	err = h.service.HandleWebhook(r.Context(), r.Header, body)
+	if err != nil {
+		writePaymentError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+func writePaymentError(w http.ResponseWriter, err error) {
+	switch e := err.(type) {
+	case *utils.ValidationError:
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": e.Error()})
+	case *utils.AuthenticationError, *utils.AuthorizationError:
+		auth.WriteError(w, e)
+	case *utils.NotFoundError:
+		utils.WriteJSON(w, http.StatusNotFound, map[string]string{"error": e.Error()})
+	case *utils.AlreadyExistsError:
+		utils.WriteJSON(w, http.StatusConflict, map[string]string{"error": e.Error()})
+	case *utils.InvalidTransitionError:
+		utils.WriteJSON(w, http.StatusConflict, map[string]string{"error": e.Error()})
+	case *utils.ExternalServiceError:
+		utils.WriteJSON(w, http.StatusBadGateway, map[string]string{"error": e.Error()})
+	case *utils.DatabaseError:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": e.Error()})
+	default:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+}
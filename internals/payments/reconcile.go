@@ -0,0 +1,82 @@
+package payments
+
+import (
+	"context"
+	"ecomApis/internals/inventory"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"log/slog"
+	"time"
+)
+
+// payments and refunds without an answer for this long are sent to the gateway again
+const reconcileAfter = 2 * time.Minute
+
+// how many payments the reconciler handles per step and run
+const reconcileBatch = 100
+
+// RunReconciler calls Reconcile every interval until ctx is cancelled
+func (s *PaymentService) RunReconciler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Reconcile(ctx); err != nil {
+				slog.Error("failed to reconcile payments", "error", err)
+			}
+		}
+	}
+}
+
+// Reconcile finishes what was interrupted between the gateway and the database. Payments and
+// refunds still pending after reconcileAfter are sent to the gateway again, which answers with
+// what it already did, and orders that did not follow their payment are moved along or have
+// their money given back. Claiming a payment or refund pushes it back by reconcileAfter, so
+// other instances leave it alone meanwhile
+func (s *PaymentService) Reconcile(ctx context.Context) error {
+	payments, err := s.repo.ClaimStalePendingPayments(ctx, repo.ClaimStalePendingPaymentsParams{
+		OlderThanSeconds: int32(reconcileAfter.Seconds()),
+		Limit:            reconcileBatch,
+	})
+	if err != nil {
+		return &utils.DatabaseError{Query: "ClaimStalePendingPayments", Err: err}
+	}
+	for _, payment := range payments {
+		if _, err := s.authorize(ctx, payment, inventory.ActorSystem); err != nil {
+			slog.Error("failed to reconcile pending payment", "payment_id", payment.ID, "error", err)
+		}
+	}
+
+	refunds, err := s.repo.ClaimStalePaymentRefunds(ctx, repo.ClaimStalePaymentRefundsParams{
+		OlderThanSeconds: int32(reconcileAfter.Seconds()),
+		Limit:            reconcileBatch,
+	})
+	if err != nil {
+		return &utils.DatabaseError{Query: "ClaimStalePaymentRefunds", Err: err}
+	}
+	for _, refund := range refunds {
+		payment, err := s.repo.GetPayment(ctx, refund.PaymentID)
+		if err != nil {
+			slog.Error("failed to reconcile pending refund", "refund_id", refund.ID, "error", err)
+			continue
+		}
+		if _, err := s.sendRefund(ctx, payment, refund, inventory.ActorSystem); err != nil {
+			slog.Error("failed to reconcile pending refund", "refund_id", refund.ID, "payment_id", payment.ID, "error", err)
+		}
+	}
+
+	outOfSync, err := s.repo.ListPaymentsOutOfSync(ctx, reconcileBatch)
+	if err != nil {
+		return &utils.DatabaseError{Query: "ListPaymentsOutOfSync", Err: err}
+	}
+	for _, payment := range outOfSync {
+		if err := s.syncOrder(ctx, payment.ID, inventory.ActorSystem); err != nil {
+			slog.Error("failed to reconcile order of payment", "payment_id", payment.ID, "order_id", payment.OrderID, "error", err)
+		}
+	}
+	return nil
+}
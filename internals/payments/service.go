@@ -0,0 +1,750 @@
+package payments
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"ecomApis/internals/auth"
+	"ecomApis/internals/money"
+	"ecomApis/internals/orders"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PaymentService struct {
+	repo    *repo.Queries
+	db      *pgxpool.Pool
+	gateway Gateway
+}
+
+func NewPaymentService(r *repo.Queries, db *pgxpool.Pool, gw Gateway) *PaymentService {
+	return &PaymentService{
+		repo:    r,
+		db:      db,
+		gateway: gw,
+	}
+}
+
+// CreatePayment charges the total of a pending order. The payment row is stored before the
+// gateway is called, the gateway's answer is stored in a transaction of its own, and only then
+// does the order move. No transaction is held open across the call, and whichever step is
+// interrupted the reconciler picks the payment up again, see Reconcile.
+// A declined payment is returned with StatusFailed, not as an error
+func (s *PaymentService) CreatePayment(ctx context.Context, orderID int64, req CreatePaymentRequest, p auth.Principal) (repo.Payment, error) {
+	if req.PaymentMethod == "" {
+		return repo.Payment{}, &utils.ValidationError{Field: "payment_method", Message: "cannot be empty"}
+	}
+	capture := req.Capture == nil || *req.Capture
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return repo.Payment{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	order, err := qtx.GetOrderForUpdate(ctx, orderID)
+	if err != nil {
+		tx.Rollback(ctx)
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return repo.Payment{}, &utils.NotFoundError{Resource: "Order", ID: strconv.FormatInt(orderID, 10)}
+		}
+		return repo.Payment{}, &utils.DatabaseError{Query: "GetOrderForUpdate", Err: err}
+	}
+	if !p.CanAccessCustomer(order.CustomerRef) {
+		tx.Rollback(ctx)
+		return repo.Payment{}, &utils.AuthorizationError{Action: "pay for order " + strconv.FormatInt(orderID, 10)}
+	}
+	if order.Status != orders.StatusPending {
+		tx.Rollback(ctx)
+		return repo.Payment{}, &utils.InvalidTransitionError{Resource: "Order", From: order.Status, To: orders.StatusPaid}
+	}
+
+	// one payment at a time, a new attempt is only allowed once the previous one failed or was voided
+	open, err := qtx.CountOpenPayments(ctx, orderID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Payment{}, &utils.DatabaseError{Query: "CountOpenPayments", Err: err}
+	}
+	if open > 0 {
+		tx.Rollback(ctx)
+		return repo.Payment{}, &utils.AlreadyExistsError{Resource: "Open payment for order", ID: strconv.FormatInt(orderID, 10)}
+	}
+
+	payment, err := qtx.CreatePayment(ctx, repo.CreatePaymentParams{
+		OrderID:       orderID,
+		Gateway:       s.gateway.Name(),
+		Amount:        order.TotalPrice,
+		Currency:      order.Currency,
+		PaymentMethod: req.PaymentMethod,
+		Capture:       capture,
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Payment{}, &utils.DatabaseError{Query: "CreatePayment", Err: err}
+	}
+
+	_, err = qtx.AddPaymentStatusHistory(ctx, repo.AddPaymentStatusHistoryParams{
+		PaymentID: payment.ID,
+		ToStatus:  StatusPending,
+		Amount:    payment.Amount,
+		Actor:     p.Subject,
+		Note:      "payment created",
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Payment{}, &utils.DatabaseError{Query: "AddPaymentStatusHistory", Err: err}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Payment{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return s.authorize(ctx, payment, p.Subject)
+}
+
+// authorize sends a stored pending payment to the gateway and records the answer. The gateway
+// answers a payment it has seen before with the charge it already made
+func (s *PaymentService) authorize(ctx context.Context, payment repo.Payment, actor string) (repo.Payment, error) {
+	res, gwErr := s.gateway.Authorize(ctx, AuthorizeRequest{
+		PaymentID:     payment.ID,
+		OrderID:       payment.OrderID,
+		Amount:        payment.AmountMoney(),
+		PaymentMethod: payment.PaymentMethod,
+		Capture:       payment.Capture,
+	})
+	if gwErr != nil {
+		// the attempt is over either way, so a new one can be started
+		res = Result{Status: StatusFailed, FailureCode: gatewayCode(gwErr), FailureMessage: gwErr.Error()}
+	}
+
+	payment, err := s.recordAuthorization(ctx, payment.ID, res, actor)
+	if err != nil {
+		return repo.Payment{}, err
+	}
+	s.followOrder(ctx, payment, actor)
+
+	if gwErr != nil {
+		return repo.Payment{}, s.externalError(gwErr)
+	}
+	return payment, nil
+}
+
+// recordAuthorization stores the gateway's answer to a pending payment, without touching the
+// order. A payment that already has its answer is returned as it is
+func (s *PaymentService) recordAuthorization(ctx context.Context, id int64, res Result, actor string) (repo.Payment, error) {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return repo.Payment{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	payment, err := lockPayment(ctx, qtx, id)
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Payment{}, err
+	}
+	if payment.Status != StatusPending || payment.GatewayRef.Valid {
+		tx.Rollback(ctx)
+		return payment, nil
+	}
+
+	if res.Reference != "" {
+		payment, err = qtx.SetPaymentGatewayRef(ctx, repo.SetPaymentGatewayRefParams{
+			GatewayRef: pgtype.Text{String: res.Reference, Valid: true},
+			ID:         payment.ID,
+		})
+		if err != nil {
+			tx.Rollback(ctx)
+			return repo.Payment{}, &utils.DatabaseError{Query: "SetPaymentGatewayRef", Err: err}
+		}
+	}
+
+	payment, err = applyStatus(ctx, qtx, payment, res, payment.RefundedAmount, actor, "")
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Payment{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Payment{}, fmt.Errorf("commit tx: %w", err)
+	}
+	return payment, nil
+}
+
+// Capture takes the money of an authorized payment. The payment stays locked while the
+// gateway is called so it cannot be captured and voided at the same time. The order is
+// marked paid once the capture is stored
+func (s *PaymentService) Capture(ctx context.Context, id int64, actor string) (repo.Payment, error) {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return repo.Payment{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	payment, err := lockPayment(ctx, qtx, id)
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Payment{}, err
+	}
+	if !CanTransition(payment.Status, StatusCaptured) {
+		tx.Rollback(ctx)
+		return repo.Payment{}, &utils.InvalidTransitionError{Resource: "Payment", From: payment.Status, To: StatusCaptured}
+	}
+
+	res, err := s.gateway.Capture(ctx, payment.GatewayRef.String, payment.AmountMoney())
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Payment{}, s.externalError(err)
+	}
+
+	payment, err = applyStatus(ctx, qtx, payment, res, payment.RefundedAmount, actor, "")
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Payment{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Payment{}, fmt.Errorf("commit tx: %w", err)
+	}
+	s.followOrder(ctx, payment, actor)
+	return payment, nil
+}
+
+// Void releases an authorized payment without taking any money
+func (s *PaymentService) Void(ctx context.Context, id int64, actor string) (repo.Payment, error) {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return repo.Payment{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	payment, err := lockPayment(ctx, qtx, id)
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Payment{}, err
+	}
+	if !CanTransition(payment.Status, StatusVoided) {
+		tx.Rollback(ctx)
+		return repo.Payment{}, &utils.InvalidTransitionError{Resource: "Payment", From: payment.Status, To: StatusVoided}
+	}
+
+	res, err := s.gateway.Void(ctx, payment.GatewayRef.String)
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Payment{}, s.externalError(err)
+	}
+
+	payment, err = applyStatus(ctx, qtx, payment, res, payment.RefundedAmount, actor, "")
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Payment{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Payment{}, fmt.Errorf("commit tx: %w", err)
+	}
+	return payment, nil
+}
+
+// Refund gives back part or all of a captured payment. The refund is stored as pending before
+// the gateway is called and completed once it answers, so a refund whose answer was never
+// stored is sent again by the reconciler under the same idempotency key. A refund sent again
+// with the same Reference is not made twice. Once everything is refunded the order moves to
+// refunded as well
+func (s *PaymentService) Refund(ctx context.Context, id int64, req RefundRequest, actor string) (repo.Payment, error) {
+	reference := req.Reference
+	if reference == "" {
+		var err error
+		reference, err = newRefundReference()
+		if err != nil {
+			return repo.Payment{}, err
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return repo.Payment{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	payment, err := lockPayment(ctx, qtx, id)
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Payment{}, err
+	}
+
+	var amount money.Money
+	if req.Amount != nil {
+		amount, err = req.Amount.Normalize()
+		if err != nil || amount.Currency != payment.Currency {
+			tx.Rollback(ctx)
+			return repo.Payment{}, &utils.ValidationError{Field: "amount", Message: "must be in the currency of the payment, " + payment.Currency}
+		}
+	}
+
+	refund, err := qtx.GetPaymentRefundByReference(ctx, repo.GetPaymentRefundByReferenceParams{
+		PaymentID: payment.ID,
+		Reference: reference,
+	})
+	switch {
+	case err == nil:
+		// the same refund again, it is only sent once it did not go through
+		if req.Amount != nil && amount.Amount != refund.Amount {
+			tx.Rollback(ctx)
+			return repo.Payment{}, &utils.ValidationError{Field: "reference", Message: "was already used for a refund of another amount"}
+		}
+		if refund.Status == RefundSucceeded {
+			tx.Rollback(ctx)
+			return payment, nil
+		}
+		if refund.Status == RefundFailed {
+			refund, err = qtx.UpdatePaymentRefundStatus(ctx, repo.UpdatePaymentRefundStatusParams{Status: RefundPending, ID: refund.ID})
+			if err != nil {
+				tx.Rollback(ctx)
+				return repo.Payment{}, &utils.DatabaseError{Query: "UpdatePaymentRefundStatus", Err: err}
+			}
+		}
+	case err == pgx.ErrNoRows || err == sql.ErrNoRows:
+		if !CanTransition(payment.Status, StatusRefunded) {
+			tx.Rollback(ctx)
+			return repo.Payment{}, &utils.InvalidTransitionError{Resource: "Payment", From: payment.Status, To: StatusRefunded}
+		}
+
+		// refunds still waiting for the gateway count as given back
+		pending, err := qtx.SumPaymentRefunds(ctx, repo.SumPaymentRefundsParams{PaymentID: payment.ID, Status: RefundPending})
+		if err != nil {
+			tx.Rollback(ctx)
+			return repo.Payment{}, &utils.DatabaseError{Query: "SumPaymentRefunds", Err: err}
+		}
+		remaining := payment.Amount - payment.RefundedAmount - pending
+		if remaining <= 0 {
+			tx.Rollback(ctx)
+			return repo.Payment{}, &utils.ValidationError{Field: "amount", Message: "nothing is left to refund"}
+		}
+		if req.Amount == nil {
+			amount = payment.AmountMoney()
+			amount.Amount = remaining
+		} else if amount.Amount <= 0 || amount.Amount > remaining {
+			tx.Rollback(ctx)
+			return repo.Payment{}, &utils.ValidationError{Field: "amount", Message: fmt.Sprintf("must be between 1 and %d", remaining)}
+		}
+
+		refund, err = qtx.CreatePaymentRefund(ctx, repo.CreatePaymentRefundParams{
+			PaymentID: payment.ID,
+			Reference: reference,
+			Amount:    amount.Amount,
+			Currency:  amount.Currency,
+			Actor:     actor,
+			Note:      req.Note,
+		})
+		if err != nil {
+			tx.Rollback(ctx)
+			return repo.Payment{}, &utils.DatabaseError{Query: "CreatePaymentRefund", Err: err}
+		}
+	default:
+		tx.Rollback(ctx)
+		return repo.Payment{}, &utils.DatabaseError{Query: "GetPaymentRefundByReference", Err: err}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Payment{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return s.sendRefund(ctx, payment, refund, actor)
+}
+
+// sendRefund sends a stored pending refund to the gateway and records the answer. The gateway
+// answers a key it has seen before with the refund it already made
+func (s *PaymentService) sendRefund(ctx context.Context, payment repo.Payment, refund repo.PaymentRefund, actor string) (repo.Payment, error) {
+	_, gwErr := s.gateway.Refund(ctx, payment.GatewayRef.String, refund.AmountMoney(), refundKey(refund))
+
+	payment, err := s.recordRefund(ctx, refund, gwErr, actor)
+	if err != nil {
+		return repo.Payment{}, err
+	}
+	if gwErr != nil {
+		return repo.Payment{}, s.externalError(gwErr)
+	}
+
+	s.followOrder(ctx, payment, actor)
+	return payment, nil
+}
+
+// recordRefund stores the gateway's answer to a pending refund and adds a refund that went
+// through to the payment. A refund that already has its answer is left as it is
+func (s *PaymentService) recordRefund(ctx context.Context, refund repo.PaymentRefund, gwErr error, actor string) (repo.Payment, error) {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return repo.Payment{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	// the payment is locked before its refund, as in Refund
+	payment, err := lockPayment(ctx, qtx, refund.PaymentID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Payment{}, err
+	}
+	refund, err = qtx.GetPaymentRefundForUpdate(ctx, refund.ID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Payment{}, &utils.DatabaseError{Query: "GetPaymentRefundForUpdate", Err: err}
+	}
+	if refund.Status != RefundPending {
+		tx.Rollback(ctx)
+		return payment, nil
+	}
+
+	update := repo.UpdatePaymentRefundStatusParams{Status: RefundSucceeded, ID: refund.ID}
+	if gwErr != nil {
+		update = repo.UpdatePaymentRefundStatusParams{
+			Status:         RefundFailed,
+			FailureMessage: pgtype.Text{String: gwErr.Error(), Valid: true},
+			ID:             refund.ID,
+		}
+	}
+	_, err = qtx.UpdatePaymentRefundStatus(ctx, update)
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Payment{}, &utils.DatabaseError{Query: "UpdatePaymentRefundStatus", Err: err}
+	}
+
+	if gwErr == nil {
+		succeeded, err := qtx.SumPaymentRefunds(ctx, repo.SumPaymentRefundsParams{PaymentID: payment.ID, Status: RefundSucceeded})
+		if err != nil {
+			tx.Rollback(ctx)
+			return repo.Payment{}, &utils.DatabaseError{Query: "SumPaymentRefunds", Err: err}
+		}
+
+		// a refund webhook may have counted this refund already
+		refunded := max(payment.RefundedAmount, succeeded)
+		if refunded > payment.RefundedAmount {
+			res := Result{Status: refundStatus(payment, refunded)}
+			payment, err = applyStatus(ctx, qtx, payment, res, refunded, actor, refund.Note)
+			if err != nil {
+				tx.Rollback(ctx)
+				return repo.Payment{}, err
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Payment{}, fmt.Errorf("commit tx: %w", err)
+	}
+	return payment, nil
+}
+
+// HandleWebhook applies a status change the gateway sent on its own. Every event is stored
+// by its id so redelivered events are acknowledged without being applied twice, and events
+// for a status the payment already reached or cannot reach any more are ignored
+func (s *PaymentService) HandleWebhook(ctx context.Context, header http.Header, body []byte) error {
+	event, err := s.gateway.ParseWebhook(header, body)
+	if err != nil {
+		if errors.Is(err, ErrInvalidSignature) {
+			return &utils.AuthenticationError{Message: err.Error()}
+		}
+		return &utils.ValidationError{Field: "body", Message: err.Error()}
+	}
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	recorded, err := qtx.RecordPaymentWebhookEvent(ctx, repo.RecordPaymentWebhookEventParams{
+		Gateway:   s.gateway.Name(),
+		EventID:   event.ID,
+		EventType: event.Type,
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		return &utils.DatabaseError{Query: "RecordPaymentWebhookEvent", Err: err}
+	}
+	if recorded == 0 {
+		tx.Rollback(ctx)
+		return nil
+	}
+
+	// unknown references are not acknowledged, the gateway retries them and the
+	// payment may have its reference by then
+	payment, err := qtx.GetPaymentByGatewayRefForUpdate(ctx, repo.GetPaymentByGatewayRefForUpdateParams{
+		Gateway:    s.gateway.Name(),
+		GatewayRef: pgtype.Text{String: event.Reference, Valid: true},
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return &utils.NotFoundError{Resource: "Payment", ID: event.Reference}
+		}
+		return &utils.DatabaseError{Query: "GetPaymentByGatewayRefForUpdate", Err: err}
+	}
+
+	// refund events carry the total refunded so far, so an older event never undoes a newer one
+	res := Result{Status: event.Type, FailureCode: event.FailureCode, FailureMessage: event.FailureMessage}
+	refunded := payment.RefundedAmount
+	if event.Type == StatusPartiallyRefunded || event.Type == StatusRefunded {
+		refunded = event.Amount.Amount
+		if event.Amount.Currency != payment.Currency || refunded <= payment.RefundedAmount || refunded > payment.Amount {
+			res.Status = payment.Status
+		} else {
+			res.Status = refundStatus(payment, refunded)
+		}
+	}
+
+	applied := false
+	if (res.Status == payment.Status && res.Status != StatusPartiallyRefunded) || !CanTransition(payment.Status, res.Status) {
+		slog.Info("ignoring payment webhook", "event_id", event.ID, "type", event.Type, "payment_id", payment.ID, "status", payment.Status)
+	} else {
+		payment, err = applyStatus(ctx, qtx, payment, res, refunded, s.gateway.Name(), "webhook "+event.ID)
+		if err != nil {
+			tx.Rollback(ctx)
+			return err
+		}
+		applied = true
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	if applied {
+		s.followOrder(ctx, payment, s.gateway.Name())
+	}
+	return nil
+}
+
+func (s *PaymentService) GetPayment(ctx context.Context, id int64) (PaymentDetails, error) {
+	payment, err := s.repo.GetPayment(ctx, id)
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return PaymentDetails{}, &utils.NotFoundError{Resource: "Payment", ID: strconv.FormatInt(id, 10)}
+		}
+		return PaymentDetails{}, &utils.DatabaseError{Query: "GetPayment", Err: err}
+	}
+
+	history, err := s.repo.ListPaymentStatusHistory(ctx, id)
+	if err != nil {
+		return PaymentDetails{}, &utils.DatabaseError{Query: "ListPaymentStatusHistory", Err: err}
+	}
+	if history == nil {
+		history = []repo.PaymentStatusHistory{}
+	}
+
+	refunds, err := s.repo.ListPaymentRefunds(ctx, id)
+	if err != nil {
+		return PaymentDetails{}, &utils.DatabaseError{Query: "ListPaymentRefunds", Err: err}
+	}
+	if refunds == nil {
+		refunds = []repo.PaymentRefund{}
+	}
+
+	return PaymentDetails{Payment: payment, History: history, Refunds: refunds}, nil
+}
+
+// ListOrderPayments returns every payment attempt of an order, oldest first
+func (s *PaymentService) ListOrderPayments(ctx context.Context, orderID int64, p auth.Principal) ([]repo.Payment, error) {
+	order, err := s.repo.GetOrder(ctx, orderID)
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return nil, &utils.NotFoundError{Resource: "Order", ID: strconv.FormatInt(orderID, 10)}
+		}
+		return nil, &utils.DatabaseError{Query: "GetOrder", Err: err}
+	}
+	if !p.CanAccessCustomer(order.CustomerRef) {
+		return nil, &utils.AuthorizationError{Action: "view payments of order " + strconv.FormatInt(orderID, 10)}
+	}
+
+	payments, err := s.repo.ListOrderPayments(ctx, orderID)
+	if err != nil {
+		return nil, &utils.DatabaseError{Query: "ListOrderPayments", Err: err}
+	}
+	if payments == nil {
+		payments = []repo.Payment{}
+	}
+	return payments, nil
+}
+
+func lockPayment(ctx context.Context, qtx *repo.Queries, id int64) (repo.Payment, error) {
+	payment, err := qtx.GetPaymentForUpdate(ctx, id)
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return repo.Payment{}, &utils.NotFoundError{Resource: "Payment", ID: strconv.FormatInt(id, 10)}
+		}
+		return repo.Payment{}, &utils.DatabaseError{Query: "GetPaymentForUpdate", Err: err}
+	}
+	return payment, nil
+}
+
+// applyStatus moves a locked payment to the status of res and records it in the history.
+// The order follows in syncOrder once the payment is committed, so a failure there never
+// loses what the gateway did
+func applyStatus(ctx context.Context, qtx *repo.Queries, payment repo.Payment, res Result, refunded int64, actor, note string) (repo.Payment, error) {
+	if res.Status == payment.Status && res.Status != StatusPartiallyRefunded {
+		return payment, nil
+	}
+	if !CanTransition(payment.Status, res.Status) {
+		return repo.Payment{}, &utils.InvalidTransitionError{Resource: "Payment", From: payment.Status, To: res.Status}
+	}
+
+	updated, err := qtx.UpdatePaymentStatus(ctx, repo.UpdatePaymentStatusParams{
+		ToStatus:       res.Status,
+		RefundedAmount: refunded,
+		FailureCode:    pgtype.Text{String: res.FailureCode, Valid: res.FailureCode != ""},
+		FailureMessage: pgtype.Text{String: res.FailureMessage, Valid: res.FailureMessage != ""},
+		ID:             payment.ID,
+		FromStatus:     payment.Status,
+	})
+	if err != nil {
+		return repo.Payment{}, &utils.DatabaseError{Query: "UpdatePaymentStatus", Err: err}
+	}
+
+	// the amount that moved with this change
+	amount := payment.Amount
+	switch res.Status {
+	case StatusPartiallyRefunded, StatusRefunded:
+		amount = refunded - payment.RefundedAmount
+	case StatusFailed:
+		amount = 0
+	}
+	if note == "" && res.FailureCode != "" {
+		note = res.FailureCode
+	}
+
+	_, err = qtx.AddPaymentStatusHistory(ctx, repo.AddPaymentStatusHistoryParams{
+		PaymentID:  payment.ID,
+		FromStatus: pgtype.Text{String: payment.Status, Valid: true},
+		ToStatus:   res.Status,
+		Amount:     amount,
+		Actor:      actor,
+		Note:       note,
+	})
+	if err != nil {
+		return repo.Payment{}, &utils.DatabaseError{Query: "AddPaymentStatusHistory", Err: err}
+	}
+
+	return updated, nil
+}
+
+// followOrder moves the order along with a committed payment. A failure is only logged, the
+// payment is stored and the reconciler moves the order later
+func (s *PaymentService) followOrder(ctx context.Context, payment repo.Payment, actor string) {
+	if err := s.syncOrder(ctx, payment.ID, actor); err != nil {
+		slog.Error("failed to update the order of a payment", "payment_id", payment.ID, "order_id", payment.OrderID, "error", err)
+	}
+}
+
+// syncOrder brings the order in line with its payment. A capture marks a pending order paid
+// and a full refund marks the order refunded where its status allows it, anything further
+// along is left for an admin to sort out. When the order was cancelled before the payment
+// could pay for it the money is given back, an authorization is voided and a capture refunded
+func (s *PaymentService) syncOrder(ctx context.Context, paymentID int64, actor string) error {
+	payment, err := s.repo.GetPayment(ctx, paymentID)
+	if err != nil {
+		return &utils.DatabaseError{Query: "GetPayment", Err: err}
+	}
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	// the order is locked before its payment, as when the payment was created
+	order, err := qtx.GetOrderForUpdate(ctx, payment.OrderID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return &utils.DatabaseError{Query: "GetOrderForUpdate", Err: err}
+	}
+	payment, err = lockPayment(ctx, qtx, paymentID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return err
+	}
+
+	var orderStatus, giveBack string
+	switch {
+	case payment.Status == StatusCaptured && order.Status == orders.StatusPending:
+		orderStatus = orders.StatusPaid
+	case payment.Status == StatusRefunded && orders.CanTransition(order.Status, orders.StatusRefunded):
+		orderStatus = orders.StatusRefunded
+	case payment.Status == StatusAuthorized && order.Status == orders.StatusCancelled:
+		giveBack = StatusVoided
+	case (payment.Status == StatusCaptured || payment.Status == StatusPartiallyRefunded) && order.Status == orders.StatusCancelled:
+		// a paid order that was cancelled later is refunded by an admin
+		paid, err := qtx.OrderReachedStatus(ctx, repo.OrderReachedStatusParams{OrderID: order.ID, ToStatus: orders.StatusPaid})
+		if err != nil {
+			tx.Rollback(ctx)
+			return &utils.DatabaseError{Query: "OrderReachedStatus", Err: err}
+		}
+		if !paid {
+			giveBack = StatusRefunded
+		}
+	}
+
+	if orderStatus != "" {
+		_, _, err = orders.Transition(ctx, qtx, order, orderStatus, fmt.Sprintf("payment %d %s", payment.ID, payment.Status), actor)
+		if err != nil {
+			tx.Rollback(ctx)
+			return err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+
+	switch giveBack {
+	case StatusVoided:
+		_, err = s.Void(ctx, payment.ID, actor)
+	case StatusRefunded:
+		_, err = s.Refund(ctx, payment.ID, RefundRequest{
+			Reference: "order-cancelled",
+			Note:      "the order was cancelled before it was paid",
+		}, actor)
+	}
+	return err
+}
+
+// refundStatus is the status of payment once refunded in total has been given back
+func refundStatus(payment repo.Payment, refunded int64) string {
+	if refunded >= payment.Amount {
+		return StatusRefunded
+	}
+	return StatusPartiallyRefunded
+}
+
+// gatewayCode is the gateway's own code for err, if it sent one
+func gatewayCode(err error) string {
+	var gwErr *Error
+	if errors.As(err, &gwErr) {
+		return gwErr.Code
+	}
+	return ""
+}
+
+// refundKey is the idempotency key a refund is sent to the gateway with, the same on every retry
+func refundKey(refund repo.PaymentRefund) string {
+	return "payment_refund_" + strconv.FormatInt(refund.ID, 10)
+}
+
+func newRefundReference() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate refund reference: %w", err)
+	}
+	return "refund_" + hex.EncodeToString(b), nil
+}
+
+func (s *PaymentService) externalError(err error) error {
+	return &utils.ExternalServiceError{Service: s.gateway.Name(), Code: gatewayCode(err), Err: err}
+}
@@ -0,0 +1,45 @@
+package payments
+
+// payment statuses
+const (
+	StatusPending           = "pending"
+	StatusAuthorized        = "authorized"
+	StatusCaptured          = "captured"
+	StatusPartiallyRefunded = "partially_refunded"
+	StatusRefunded          = "refunded"
+	StatusVoided            = "voided"
+	StatusFailed            = "failed"
+)
+
+// refund statuses
+const (
+	RefundPending   = "pending"
+	RefundSucceeded = "succeeded"
+	RefundFailed    = "failed"
+)
+
+// allowedTransitions lists every status a payment may move to from its current one.
+// A partially refunded payment can be refunded again until nothing is left
+var allowedTransitions = map[string][]string{
+	StatusPending:           {StatusAuthorized, StatusCaptured, StatusFailed},
+	StatusAuthorized:        {StatusCaptured, StatusVoided, StatusFailed},
+	StatusCaptured:          {StatusPartiallyRefunded, StatusRefunded},
+	StatusPartiallyRefunded: {StatusPartiallyRefunded, StatusRefunded},
+	StatusRefunded:          {},
+	StatusVoided:            {},
+	StatusFailed:            {},
+}
+
+func IsValidStatus(status string) bool {
+	_, ok := allowedTransitions[status]
+	return ok
+}
+
+func CanTransition(from, to string) bool {
+	for _, next := range allowedTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
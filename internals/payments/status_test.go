@@ -0,0 +1,46 @@
+package payments
+
+import "testing"
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{StatusPending, StatusAuthorized, true},
+		{StatusPending, StatusCaptured, true},
+		{StatusPending, StatusFailed, true},
+		{StatusPending, StatusRefunded, false},
+		{StatusAuthorized, StatusCaptured, true},
+		{StatusAuthorized, StatusVoided, true},
+		{StatusAuthorized, StatusFailed, true},
+		{StatusAuthorized, StatusRefunded, false},
+		{StatusCaptured, StatusPartiallyRefunded, true},
+		{StatusCaptured, StatusRefunded, true},
+		{StatusCaptured, StatusVoided, false},
+		{StatusCaptured, StatusCaptured, false},
+		{StatusPartiallyRefunded, StatusPartiallyRefunded, true},
+		{StatusPartiallyRefunded, StatusRefunded, true},
+		{StatusPartiallyRefunded, StatusCaptured, false},
+		{StatusRefunded, StatusPartiallyRefunded, false},
+		{StatusVoided, StatusCaptured, false},
+		{StatusFailed, StatusPending, false},
+		{"unknown", StatusCaptured, false},
+	}
+	for _, tt := range tests {
+		if got := CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestIsValidStatus(t *testing.T) {
+	for _, status := range []string{StatusPending, StatusAuthorized, StatusCaptured, StatusPartiallyRefunded, StatusRefunded, StatusVoided, StatusFailed} {
+		if !IsValidStatus(status) {
+			t.Errorf("IsValidStatus(%s) = false", status)
+		}
+	}
+	if IsValidStatus("disputed") {
+		t.Error("IsValidStatus(disputed) = true")
+	}
+}
@@ -0,0 +1,30 @@
+package payments
+
+import (
+	"ecomApis/internals/money"
+	"ecomApis/internals/repo"
+)
+
+// CreatePaymentRequest starts paying for an order. PaymentMethod is a token the client got
+// from the gateway. Capture defaults to true, false only authorizes the amount so an admin
+// can capture it later
+type CreatePaymentRequest struct {
+	PaymentMethod string `json:"payment_method"`
+	Capture       *bool  `json:"capture"`
+}
+
+// RefundRequest refunds part of a captured payment, without an amount whatever is left is refunded.
+// Reference is an optional key of the caller's, a refund sent again with the same one is not
+// made twice
+type RefundRequest struct {
+	Amount    *money.Money `json:"amount"`
+	Note      string       `json:"note"`
+	Reference string       `json:"reference"`
+}
+
+// PaymentDetails is a payment with every status it went through and every refund sent for it
+type PaymentDetails struct {
+	Payment repo.Payment                `json:"payment"`
+	History []repo.PaymentStatusHistory `json:"history"`
+	Refunds []repo.PaymentRefund        `json:"refunds"`
+}
@@ -0,0 +1,131 @@
+package pricing
+
+import (
+	"ecomApis/internals/auth"
+	"ecomApis/internals/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(s *Service) *Handler {
+	return &Handler{
+		service: s,
+	}
+}
+
+func (h *Handler) ListExchangeRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.service.ListExchangeRates(r.Context())
+	if err != nil {
+		writePricingError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, rates)
+}
+
+// SetExchangeRate handles PUT /exchange-rates/{base}/{quote}
+func (h *Handler) SetExchangeRate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req SetExchangeRateRequest
+	err := utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	p, _ := auth.FromContext(ctx)
+	rate, err := h.service.SetExchangeRate(ctx, chi.URLParam(r, "base"), chi.URLParam(r, "quote"), req, p.Subject)
+	if err != nil {
+		writePricingError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, rate)
+}
+
+func (h *Handler) DeleteExchangeRate(w http.ResponseWriter, r *http.Request) {
+	err := h.service.DeleteExchangeRate(r.Context(), chi.URLParam(r, "base"), chi.URLParam(r, "quote"))
+	if err != nil {
+		writePricingError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+// ListProductPrices handles GET /products/{id}/prices
+func (h *Handler) ListProductPrices(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid product id"})
+		return
+	}
+
+	prices, err := h.service.ListProductPrices(r.Context(), id)
+	if err != nil {
+		writePricingError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, prices)
+}
+
+// SetProductPrice handles PUT /products/{id}/prices/{currency}
+func (h *Handler) SetProductPrice(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid product id"})
+		return
+	}
+
+	var req SetProductPriceRequest
+	err = utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	price, err := h.service.SetProductPrice(r.Context(), id, chi.URLParam(r, "currency"), req)
+	if err != nil {
+		writePricingError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, price)
+}
+
+func (h *Handler) DeleteProductPrice(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid product id"})
+		return
+	}
+
+	err = h.service.DeleteProductPrice(r.Context(), id, chi.URLParam(r, "currency"))
+	if err != nil {
+		writePricingError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+func writePricingError(w http.ResponseWriter, err error) {
+	switch e := err.(type) {
+	case *utils.ValidationError:
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": e.Error()})
+	case *utils.NotFoundError:
+		utils.WriteJSON(w, http.StatusNotFound, map[string]string{"error": e.Error()})
+	case *utils.DatabaseError:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": e.Error()})
+	default:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,319 @@
+package pricing
+
+import (
+	"context"
+	"database/sql"
+	"ecomApis/internals/money"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// postgres error code for foreign key violations
+const foreignKeyViolation = "23503"
+
+type Service struct {
+	repo   *repo.Queries
+	config Config
+}
+
+func NewService(r *repo.Queries, cfg Config) *Service {
+	cfg.BaseCurrency = money.NormalizeCurrency(cfg.BaseCurrency)
+	if !money.IsValidCurrency(cfg.BaseCurrency) {
+		cfg.BaseCurrency = "USD"
+	}
+	if !money.IsValidRoundingMode(cfg.Rounding.Mode) {
+		cfg.Rounding.Mode = money.RoundHalfUp
+	}
+	if cfg.Rounding.Increment <= 0 {
+		cfg.Rounding.Increment = 1
+	}
+
+	return &Service{
+		repo:   r,
+		config: cfg,
+	}
+}
+
+func (s *Service) BaseCurrency() string {
+	return s.config.BaseCurrency
+}
+
+// PriceIn prices every product in the target currency. A product priced in the target currency,
+// or with an explicit price entry for it, keeps that price. Otherwise its base currency price is
+// converted with the exchange rate and rounding rules. q lets callers price inside a transaction
+func (s *Service) PriceIn(ctx context.Context, q *repo.Queries, products []repo.Product, target string) ([]Quote, error) {
+	target, err := normalizeCurrency("currency", target)
+	if err != nil {
+		return nil, err
+	}
+	base := s.config.BaseCurrency
+
+	ids := make([]int64, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+
+	entries, err := q.ListProductPricesIn(ctx, repo.ListProductPricesInParams{
+		ProductIds: ids,
+		Currencies: []string{target, base},
+	})
+	if err != nil {
+		return nil, &utils.DatabaseError{Query: "ListProductPricesIn", Err: err}
+	}
+
+	type key struct {
+		productID int64
+		currency  string
+	}
+	prices := make(map[key]int64, len(entries))
+	for _, e := range entries {
+		prices[key{e.ProductID, e.Currency}] = e.Amount
+	}
+
+	// only looked up once some product actually needs converting
+	var rate *big.Rat
+
+	quotes := make([]Quote, 0, len(products))
+	for _, p := range products {
+		if p.Currency == target {
+			quotes = append(quotes, Quote{Price: p.PriceMoney()})
+			continue
+		}
+		if amount, ok := prices[key{p.ID, target}]; ok {
+			quotes = append(quotes, Quote{Price: money.Money{Amount: amount, Currency: target}})
+			continue
+		}
+
+		basePrice := p.PriceMoney()
+		if p.Currency != base {
+			amount, ok := prices[key{p.ID, base}]
+			if !ok {
+				return nil, &utils.ValidationError{
+					Field:   "currency",
+					Message: fmt.Sprintf("product %d has no price in %s or in the base currency %s", p.ID, target, base),
+				}
+			}
+			basePrice = money.Money{Amount: amount, Currency: base}
+		}
+
+		if rate == nil {
+			rate, err = s.exchangeRate(ctx, q, base, target)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		converted, err := basePrice.Convert(target, rate, s.config.Rounding)
+		if err != nil {
+			return nil, &utils.ValidationError{
+				Field:   "currency",
+				Message: fmt.Sprintf("cannot convert the price of product %d to %s", p.ID, target),
+			}
+		}
+		quotes = append(quotes, Quote{Price: converted, Rate: rate})
+	}
+
+	return quotes, nil
+}
+
+// VariantQuote prices a variant with its own price from the quote of its product. In the product's
+// currency the variant price is used as is, in any other currency the product quote is scaled by the
+// ratio of the two prices so explicit prices and conversions carry over to the variant
+func (s *Service) VariantQuote(product repo.Product, variantPrice int64, quote Quote) (Quote, error) {
+	if quote.Price.Currency == product.Currency {
+		return Quote{Price: money.Money{Amount: variantPrice, Currency: product.Currency}, Rate: quote.Rate}, nil
+	}
+
+	ratio := big.NewRat(variantPrice, product.Price)
+	scaled, err := quote.Price.Convert(quote.Price.Currency, ratio, s.config.Rounding)
+	if err != nil {
+		return Quote{}, &utils.ValidationError{
+			Field:   "currency",
+			Message: fmt.Sprintf("cannot convert the variant price of product %d to %s", product.ID, quote.Price.Currency),
+		}
+	}
+	return Quote{Price: scaled, Rate: quote.Rate}, nil
+}
+
+func (s *Service) exchangeRate(ctx context.Context, q *repo.Queries, base, quote string) (*big.Rat, error) {
+	row, err := q.GetExchangeRate(ctx, repo.GetExchangeRateParams{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return nil, &utils.ValidationError{
+				Field:   "currency",
+				Message: fmt.Sprintf("no exchange rate from %s to %s", base, quote),
+			}
+		}
+		return nil, &utils.DatabaseError{Query: "GetExchangeRate", Err: err}
+	}
+	return RateFromNumeric(row.Rate), nil
+}
+
+// RateFromNumeric turns a NUMERIC column into an exact rational rate
+func RateFromNumeric(n pgtype.Numeric) *big.Rat {
+	if !n.Valid || n.Int == nil {
+		return nil
+	}
+	rate := new(big.Rat).SetInt(n.Int)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(absExp(n.Exp))), nil))
+	if n.Exp >= 0 {
+		return rate.Mul(rate, scale)
+	}
+	return rate.Quo(rate, scale)
+}
+
+// NumericFromRate is the reverse of RateFromNumeric, nil gives SQL NULL
+func NumericFromRate(rate *big.Rat) pgtype.Numeric {
+	var n pgtype.Numeric
+	if rate == nil {
+		return n
+	}
+	// the columns are NUMERIC(20, 10)
+	_ = n.Scan(rate.FloatString(10))
+	return n
+}
+
+func absExp(exp int32) int32 {
+	if exp < 0 {
+		return -exp
+	}
+	return exp
+}
+
+func (s *Service) ListExchangeRates(ctx context.Context) ([]repo.ExchangeRate, error) {
+	rates, err := s.repo.ListExchangeRates(ctx)
+	if err != nil {
+		return nil, &utils.DatabaseError{Query: "ListExchangeRates", Err: err}
+	}
+	if rates == nil {
+		rates = []repo.ExchangeRate{}
+	}
+	return rates, nil
+}
+
+// SetExchangeRate creates or replaces the rate of one unit of base in quote
+func (s *Service) SetExchangeRate(ctx context.Context, base, quote string, req SetExchangeRateRequest, actor string) (repo.ExchangeRate, error) {
+	base, err := normalizeCurrency("base", base)
+	if err != nil {
+		return repo.ExchangeRate{}, err
+	}
+	quote, err = normalizeCurrency("quote", quote)
+	if err != nil {
+		return repo.ExchangeRate{}, err
+	}
+	if base == quote {
+		return repo.ExchangeRate{}, &utils.ValidationError{Field: "quote", Message: "must differ from the base currency"}
+	}
+
+	rate, err := money.ParseRate(req.Rate)
+	if err != nil {
+		return repo.ExchangeRate{}, &utils.ValidationError{Field: "rate", Message: "must be a positive decimal number"}
+	}
+
+	row, err := s.repo.UpsertExchangeRate(ctx, repo.UpsertExchangeRateParams{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          NumericFromRate(rate),
+		UpdatedBy:     actor,
+	})
+	if err != nil {
+		return repo.ExchangeRate{}, &utils.DatabaseError{Query: "UpsertExchangeRate", Err: err}
+	}
+	return row, nil
+}
+
+func (s *Service) DeleteExchangeRate(ctx context.Context, base, quote string) error {
+	base, quote = money.NormalizeCurrency(base), money.NormalizeCurrency(quote)
+
+	deleted, err := s.repo.DeleteExchangeRate(ctx, repo.DeleteExchangeRateParams{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+	})
+	if err != nil {
+		return &utils.DatabaseError{Query: "DeleteExchangeRate", Err: err}
+	}
+	if deleted == 0 {
+		return &utils.NotFoundError{Resource: "ExchangeRate", ID: base + "/" + quote}
+	}
+	return nil
+}
+
+func (s *Service) ListProductPrices(ctx context.Context, productID int64) ([]repo.ProductPrice, error) {
+	prices, err := s.repo.ListProductPrices(ctx, productID)
+	if err != nil {
+		return nil, &utils.DatabaseError{Query: "ListProductPrices", Err: err}
+	}
+	if prices == nil {
+		prices = []repo.ProductPrice{}
+	}
+	return prices, nil
+}
+
+// SetProductPrice creates or replaces the price of a product in one currency
+func (s *Service) SetProductPrice(ctx context.Context, productID int64, currency string, req SetProductPriceRequest) (repo.ProductPrice, error) {
+	currency, err := normalizeCurrency("currency", currency)
+	if err != nil {
+		return repo.ProductPrice{}, err
+	}
+	if req.Amount <= 0 {
+		return repo.ProductPrice{}, &utils.ValidationError{Field: "amount", Message: "must be positive"}
+	}
+
+	price, err := s.repo.UpsertProductPrice(ctx, repo.UpsertProductPriceParams{
+		ProductID: productID,
+		Currency:  currency,
+		Amount:    req.Amount,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			return repo.ProductPrice{}, &utils.NotFoundError{
+				Resource: "Product",
+				ID:       strconv.FormatInt(productID, 10),
+			}
+		}
+		return repo.ProductPrice{}, &utils.DatabaseError{Query: "UpsertProductPrice", Err: err}
+	}
+	return price, nil
+}
+
+func (s *Service) DeleteProductPrice(ctx context.Context, productID int64, currency string) error {
+	currency = money.NormalizeCurrency(currency)
+
+	deleted, err := s.repo.DeleteProductPrice(ctx, repo.DeleteProductPriceParams{
+		ProductID: productID,
+		Currency:  currency,
+	})
+	if err != nil {
+		return &utils.DatabaseError{Query: "DeleteProductPrice", Err: err}
+	}
+	if deleted == 0 {
+		return &utils.NotFoundError{
+			Resource: "ProductPrice",
+			ID:       strconv.FormatInt(productID, 10) + "/" + currency,
+		}
+	}
+	return nil
+}
+
+func normalizeCurrency(field, code string) (string, error) {
+	normalized := money.NormalizeCurrency(code)
+	if !money.IsValidCurrency(normalized) {
+		return "", &utils.ValidationError{
+			Field:   field,
+			Message: fmt.Sprintf("'%s' is not a supported ISO-4217 currency", code),
+		}
+	}
+	return normalized, nil
+}
@@ -0,0 +1,29 @@
+package pricing
+
+import (
+	"ecomApis/internals/money"
+	"math/big"
+)
+
+// Config controls the fallback conversion used when a product has no price in a currency
+type Config struct {
+	// BaseCurrency is the currency prices are converted from
+	BaseCurrency string
+	Rounding     money.Rounding
+}
+
+// SetExchangeRateRequest takes the rate as a decimal string so it is never parsed as a float
+type SetExchangeRateRequest struct {
+	Rate string `json:"rate"`
+}
+
+type SetProductPriceRequest struct {
+	Amount int64 `json:"amount"`
+}
+
+// Quote is the price of a product in a requested currency.
+// Rate is set when the price was converted from the base currency
+type Quote struct {
+	Price money.Money
+	Rate  *big.Rat
+}
@@ -0,0 +1,122 @@
+package products
+
+import (
+	"context"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// products are read and written this many at a time
+const exportPageSize = 500
+
+// ExportProducts streams every product in id order in the import format. The pages are read in
+// one read-only snapshot, and flush is called after each so the client receives the catalog while
+// it is read. Once rows have been written an error can only cut the stream short
+func (s *ProductService) ExportProducts(ctx context.Context, format string, w io.Writer, flush func()) error {
+	var write func(repo.Product) error
+	var endPage func() error
+
+	switch format {
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(recordColumns); err != nil {
+			return err
+		}
+		write = func(p repo.Product) error {
+			return writer.Write(csvRecord(p))
+		}
+		endPage = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	case FormatNDJSON:
+		encoder := json.NewEncoder(w)
+		write = func(p repo.Product) error {
+			return encoder.Encode(exportRecord(p))
+		}
+		endPage = func() error { return nil }
+	default:
+		return &utils.ValidationError{
+			Field:   "format",
+			Message: fmt.Sprintf("must be %s or %s", FormatCSV, FormatNDJSON),
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	// nothing is written, so the snapshot is always rolled back
+	defer tx.Rollback(ctx)
+	qtx := s.repo.WithTx(tx)
+
+	var afterID int64
+	for {
+		products, err := qtx.ListProductsAfter(ctx, repo.ListProductsAfterParams{
+			ID:    afterID,
+			Limit: exportPageSize,
+		})
+		if err != nil {
+			return &utils.DatabaseError{Query: "ListProductsAfter", Err: err}
+		}
+
+		for _, p := range products {
+			if err := write(p); err != nil {
+				return err
+			}
+		}
+		if err := endPage(); err != nil {
+			return err
+		}
+		flush()
+
+		if len(products) < exportPageSize {
+			return nil
+		}
+		afterID = products[len(products)-1].ID
+	}
+}
+
+// exportRecord is the NDJSON line of a product
+func exportRecord(p repo.Product) ProductRecord {
+	price := p.PriceMoney()
+	record := ProductRecord{
+		Name:        &p.Name,
+		Description: &p.Description,
+		Price:       &price,
+		Stock:       &p.Stock,
+		TaxClass:    &p.TaxClass,
+		WeightGrams: &p.WeightGrams,
+		LengthMm:    &p.LengthMm,
+		WidthMm:     &p.WidthMm,
+		HeightMm:    &p.HeightMm,
+	}
+	if p.Sku.Valid {
+		record.SKU = &p.Sku.String
+	}
+	return record
+}
+
+// csvRecord is the CSV row of a product, in the order of recordColumns
+func csvRecord(p repo.Product) []string {
+	return []string{
+		p.Sku.String,
+		p.Name,
+		p.Description,
+		strconv.FormatInt(p.Price, 10),
+		p.Currency,
+		strconv.FormatInt(int64(p.Stock), 10),
+		p.TaxClass,
+		strconv.FormatInt(int64(p.WeightGrams), 10),
+		strconv.FormatInt(int64(p.LengthMm), 10),
+		strconv.FormatInt(int64(p.WidthMm), 10),
+		strconv.FormatInt(int64(p.HeightMm), 10),
+	}
+}
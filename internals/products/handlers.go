@@ -1,12 +1,17 @@
 package products
 
 import (
+	"ecomApis/internals/auth"
 	"ecomApis/internals/repo"
 	"ecomApis/internals/utils"
+	"errors"
 	"fmt"
+	"log/slog"
+	"mime"
 	"net/http"
 
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -24,7 +29,7 @@ func NewProductHandler(s *ProductService) *ProductHandler {
 func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	var req repo.CreateProductParams
+	var req CreateProductRequest
 
 	err := utils.ParseJSON(r.Body, &req)
 	if err != nil {
@@ -32,7 +37,19 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	product, err := h.service.CreateProduct(ctx, req)
+	p, _ := auth.FromContext(ctx)
+	product, err := h.service.CreateProduct(ctx, repo.CreateProductParams{
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price.Amount,
+		Currency:    req.Price.Currency,
+		Stock:       req.Stock,
+		TaxClass:    req.TaxClass,
+		WeightGrams: req.WeightGrams,
+		LengthMm:    req.LengthMm,
+		WidthMm:     req.WidthMm,
+		HeightMm:    req.HeightMm,
+	}, p.Subject)
 	if err != nil {
 		if ve, ok := err.(*utils.ValidationError); ok {
 			utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": ve.Error()})
@@ -56,13 +73,153 @@ func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 func (h *ProductHandler) ListAllProducts(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	products, err := h.service.ListAllProducts(ctx)
+	query, err := parseListProductsQuery(r)
 	if err != nil {
-		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		writeProductError(w, err)
+		return
+	}
+
+	page, err := h.service.ListProducts(ctx, query)
+	if err != nil {
+		writeProductError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, page)
+}
+
+// ListCategoryProducts handles GET /categories/{id}/products, it takes the same filters as the
+// product list and ?include_descendants=true adds the products of every subcategory
+func (h *ProductHandler) ListCategoryProducts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid category ID"})
+		return
+	}
+
+	query, err := parseListProductsQuery(r)
+	if err != nil {
+		writeProductError(w, err)
 		return
 	}
+	query.CategoryID = &id
 
-	utils.WriteJSON(w, http.StatusOK, products)
+	page, err := h.service.ListProducts(ctx, query)
+	if err != nil {
+		writeProductError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, page)
+}
+
+// parseListProductsQuery reads ?limit=&cursor=&sort=&order=&min_price=&max_price=&in_stock=
+// &created_after=&created_before=&category=&include_descendants=
+func parseListProductsQuery(r *http.Request) (ListProductsQuery, error) {
+	values := r.URL.Query()
+
+	limit, err := utils.ParseLimit(values.Get("limit"))
+	if err != nil {
+		return ListProductsQuery{}, err
+	}
+
+	query := ListProductsQuery{
+		Limit:  limit,
+		Cursor: values.Get("cursor"),
+		SortBy: values.Get("sort"),
+	}
+
+	switch values.Get("order") {
+	case "", "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		return ListProductsQuery{}, &utils.ValidationError{Field: "order", Message: "must be asc or desc"}
+	}
+
+	// price bounds are in minor units of the currency filter
+	query.Currency = values.Get("currency")
+	query.TargetCurrency = values.Get("target_currency")
+
+	for _, p := range []struct {
+		name string
+		dest **int64
+	}{{"min_price", &query.MinPrice}, {"max_price", &query.MaxPrice}} {
+		raw := values.Get(p.name)
+		if raw == "" {
+			continue
+		}
+		price, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return ListProductsQuery{}, &utils.ValidationError{Field: p.name, Message: "must be an integer"}
+		}
+		*p.dest = &price
+	}
+
+	if raw := values.Get("in_stock"); raw != "" {
+		inStock, err := strconv.ParseBool(raw)
+		if err != nil {
+			return ListProductsQuery{}, &utils.ValidationError{Field: "in_stock", Message: "must be true or false"}
+		}
+		query.InStockOnly = inStock
+	}
+
+	if raw := values.Get("category"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return ListProductsQuery{}, &utils.ValidationError{Field: "category", Message: "must be a category ID"}
+		}
+		query.CategoryID = &id
+	}
+	if raw := values.Get("include_descendants"); raw != "" {
+		descendants, err := strconv.ParseBool(raw)
+		if err != nil {
+			return ListProductsQuery{}, &utils.ValidationError{Field: "include_descendants", Message: "must be true or false"}
+		}
+		query.IncludeDescendants = descendants
+	}
+
+	for _, p := range []struct {
+		name string
+		dest **time.Time
+	}{{"created_after", &query.CreatedAfter}, {"created_before", &query.CreatedBefore}} {
+		raw := values.Get(p.name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return ListProductsQuery{}, &utils.ValidationError{Field: p.name, Message: "must be an RFC3339 timestamp"}
+		}
+		t = t.UTC()
+		*p.dest = &t
+	}
+
+	return query, nil
+}
+
+// SearchProducts handles GET /products/search?q=&limit=
+func (h *ProductHandler) SearchProducts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	limit, err := utils.ParseLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		writeProductError(w, err)
+		return
+	}
+
+	results, err := h.service.SearchProducts(ctx, r.URL.Query().Get("q"), limit)
+	if err != nil {
+		writeProductError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"data":  results,
+		"limit": limit,
+	})
 }
 
 func (h *ProductHandler) GetProductById(w http.ResponseWriter, r *http.Request) {
@@ -76,9 +233,101 @@ func (h *ProductHandler) GetProductById(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	product, err := h.service.FindProductByID(ctx, id)
+	product, err := h.service.GetProductDetails(ctx, id)
 	if err != nil {
-		utils.WriteJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		writeProductError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, product)
+}
+
+// UpdateProduct replaces the product details with the request body
+func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idParam := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid product id"})
+		return
+	}
+
+	var req UpdateProductRequest
+	err = utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	product, err := h.service.UpdateProductDetails(ctx, repo.UpdateProductDetailsParams{
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price.Amount,
+		Currency:    req.Price.Currency,
+		TaxClass:    req.TaxClass,
+		WeightGrams: req.WeightGrams,
+		LengthMm:    req.LengthMm,
+		WidthMm:     req.WidthMm,
+		HeightMm:    req.HeightMm,
+		ID:          id,
+	})
+	if err != nil {
+		writeProductError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, product)
+}
+
+// PatchProduct updates only the fields present in the request body
+func (h *ProductHandler) PatchProduct(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idParam := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid product id"})
+		return
+	}
+
+	var req PatchProductRequest
+	err = utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	product, err := h.service.PatchProduct(ctx, id, req)
+	if err != nil {
+		writeProductError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, product)
+}
+
+func (h *ProductHandler) AdjustStock(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idParam := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid product id"})
+		return
+	}
+
+	var req StockAdjustmentRequest
+	err = utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	p, _ := auth.FromContext(ctx)
+	product, err := h.service.AdjustStock(ctx, id, req, p.Subject)
+	if err != nil {
+		writeProductError(w, err)
 		return
 	}
 
@@ -112,3 +361,270 @@ func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 		"message": fmt.Sprintf("product with id %d deleted", id),
 	})
 }
+
+// ListOptionTypes handles GET /option-types
+func (h *ProductHandler) ListOptionTypes(w http.ResponseWriter, r *http.Request) {
+	optionTypes, err := h.service.ListOptionTypes(r.Context())
+	if err != nil {
+		writeProductError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, optionTypes)
+}
+
+func (h *ProductHandler) CreateOptionType(w http.ResponseWriter, r *http.Request) {
+	var req CreateOptionTypeRequest
+	err := utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	optionType, err := h.service.CreateOptionType(r.Context(), req)
+	if err != nil {
+		writeProductError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, optionType)
+}
+
+func (h *ProductHandler) DeleteOptionType(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid option type id"})
+		return
+	}
+
+	err = h.service.DeleteOptionType(r.Context(), id)
+	if err != nil {
+		writeProductError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+// ListVariants handles GET /products/{id}/variants
+func (h *ProductHandler) ListVariants(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid product id"})
+		return
+	}
+
+	variants, err := h.service.ListVariants(r.Context(), id)
+	if err != nil {
+		writeProductError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, variants)
+}
+
+func (h *ProductHandler) CreateVariant(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid product id"})
+		return
+	}
+
+	var req CreateVariantRequest
+	err = utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	p, _ := auth.FromContext(ctx)
+	variant, err := h.service.CreateVariant(ctx, id, req, p.Subject)
+	if err != nil {
+		writeProductError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, variant)
+}
+
+func (h *ProductHandler) UpdateVariant(w http.ResponseWriter, r *http.Request) {
+	id, variantID, ok := parseVariantPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateVariantRequest
+	err := utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	variant, err := h.service.UpdateVariant(r.Context(), id, variantID, req)
+	if err != nil {
+		writeProductError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, variant)
+}
+
+func (h *ProductHandler) DeleteVariant(w http.ResponseWriter, r *http.Request) {
+	id, variantID, ok := parseVariantPath(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.service.DeleteVariant(r.Context(), id, variantID)
+	if err != nil {
+		writeProductError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+// AdjustVariantStock handles POST /products/{id}/variants/{variantId}/stock
+func (h *ProductHandler) AdjustVariantStock(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, variantID, ok := parseVariantPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req StockAdjustmentRequest
+	err := utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	p, _ := auth.FromContext(ctx)
+	variant, err := h.service.AdjustVariantStock(ctx, id, variantID, req, p.Subject)
+	if err != nil {
+		writeProductError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, variant)
+}
+
+// parseVariantPath reads the product and variant ids, writing the error response when one is invalid
+func parseVariantPath(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid product id"})
+		return 0, 0, false
+	}
+	variantID, err := strconv.ParseInt(chi.URLParam(r, "variantId"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid variant id"})
+		return 0, 0, false
+	}
+	return id, variantID, true
+}
+
+// writeProductError maps service errors to their http status codes
+// largest import body we read
+const maxImportBytes = 64 << 20
+
+// content types of the bulk formats, the first one of each is used for exports
+var formatContentTypes = map[string][]string{
+	FormatCSV:    {"text/csv"},
+	FormatNDJSON: {"application/x-ndjson", "application/ndjson", "application/jsonl"},
+}
+
+// ImportProducts handles POST /products/import. The format comes from ?format= or the
+// Content-Type, and ?dry_run=true only validates the file
+func (h *ProductHandler) ImportProducts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		for f, types := range formatContentTypes {
+			for _, t := range types {
+				if t == contentType {
+					format = f
+				}
+			}
+		}
+	}
+
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(v)
+		if err != nil {
+			utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "dry_run must be true or false"})
+			return
+		}
+	}
+
+	// big catalogs take longer to upload than the server's read timeout
+	http.NewResponseController(w).SetReadDeadline(time.Time{})
+	body := http.MaxBytesReader(w, r.Body, maxImportBytes)
+
+	p, _ := auth.FromContext(ctx)
+	report, err := h.service.ImportProducts(ctx, format, body, dryRun, p.Subject)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			utils.WriteJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": "request body too large"})
+			return
+		}
+		writeProductError(w, err)
+		return
+	}
+
+	// a failed import wrote nothing, a dry run reports its errors as its result
+	status := http.StatusOK
+	if !dryRun && len(report.Errors) > 0 {
+		status = http.StatusBadRequest
+	}
+	utils.WriteJSON(w, status, report)
+}
+
+// ExportProducts handles GET /products/export?format=csv|ndjson, csv by default
+func (h *ProductHandler) ExportProducts(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = FormatCSV
+	}
+	types, ok := formatContentTypes[format]
+	if !ok {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("format must be %s or %s", FormatCSV, FormatNDJSON)})
+		return
+	}
+
+	w.Header().Set("Content-Type", types[0])
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="products.%s"`, format))
+	w.WriteHeader(http.StatusOK)
+
+	// the response streams for as long as the catalog takes to read
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+
+	err := h.service.ExportProducts(r.Context(), format, w, func() { rc.Flush() })
+	if err != nil {
+		slog.Error("product export failed", "format", format, "error", err)
+	}
+}
+
+func writeProductError(w http.ResponseWriter, err error) {
+	switch e := err.(type) {
+	case *utils.ValidationError:
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": e.Error()})
+	case *utils.NotFoundError:
+		utils.WriteJSON(w, http.StatusNotFound, map[string]string{"error": e.Error()})
+	case *utils.AlreadyExistsError:
+		utils.WriteJSON(w, http.StatusConflict, map[string]string{"error": e.Error()})
+	case *utils.DatabaseError:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": e.Error()})
+	default:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+}
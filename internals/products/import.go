@@ -0,0 +1,676 @@
+package products
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"ecomApis/internals/inventory"
+	"ecomApis/internals/outbox"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/tax"
+	"ecomApis/internals/utils"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// rows are copied and updated this many at a time
+const importBatchSize = 1000
+
+// longest NDJSON line we read
+const maxImportLineBytes = 1 << 20
+
+// the columns of the CSV format, exports write them in this order
+var recordColumns = []string{
+	"sku", "name", "description", "price", "currency", "stock",
+	"tax_class", "weight_grams", "length_mm", "width_mm", "height_mm",
+}
+
+// importRow is one parsed row of an import, nil fields were left out
+type importRow struct {
+	line        int
+	sku         *string
+	name        *string
+	description *string
+	price       *int64
+	currency    *string
+	stock       *int32
+	taxClass    *string
+	weightGrams *int32
+	lengthMm    *int32
+	widthMm     *int32
+	heightMm    *int32
+}
+
+// importChange is a product an import creates or updates, current is the zero product for creates
+type importChange struct {
+	line    int
+	current repo.Product
+	next    repo.Product
+}
+
+// ImportProducts creates or updates products from a CSV or NDJSON body. Rows are matched to
+// existing products by SKU, then by name. Either every row is valid and the whole file is written
+// in one transaction, or nothing is written and the report lists the errors per line
+func (s *ProductService) ImportProducts(ctx context.Context, format string, body io.Reader, dryRun bool, actor string) (ImportReport, error) {
+	rows, rowErrors, err := parseImport(format, body)
+	if err != nil {
+		return ImportReport{}, err
+	}
+	if len(rows) == 0 && len(rowErrors) == 0 {
+		return ImportReport{}, &utils.ValidationError{Field: "body", Message: "has no products"}
+	}
+
+	report := ImportReport{
+		DryRun: dryRun,
+		Rows:   len(rows) + len(rowErrors),
+		Errors: rowErrors,
+	}
+
+	duplicates := findDuplicateRows(rows)
+	report.Errors = append(report.Errors, duplicates...)
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return ImportReport{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	creates, updates, unchanged, errs, err := planImport(ctx, qtx, rows, duplicates)
+	if err != nil {
+		tx.Rollback(ctx)
+		return ImportReport{}, err
+	}
+	report.Errors = append(report.Errors, errs...)
+	report.Created = len(creates)
+	report.Updated = len(updates)
+	report.Unchanged = unchanged
+
+	if dryRun || len(report.Errors) > 0 {
+		tx.Rollback(ctx)
+		if report.Errors == nil {
+			report.Errors = []ImportRowError{}
+		}
+		sort.SliceStable(report.Errors, func(i, j int) bool {
+			return report.Errors[i].Line < report.Errors[j].Line
+		})
+		return report, nil
+	}
+
+	if actor == "" {
+		actor = inventory.ActorSystem
+	}
+	err = applyImport(ctx, qtx, creates, updates, actor)
+	if err != nil {
+		tx.Rollback(ctx)
+		return ImportReport{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return ImportReport{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	slog.Info("products imported",
+		"format", format,
+		"rows", report.Rows,
+		"created", report.Created,
+		"updated", report.Updated,
+		"unchanged", report.Unchanged,
+		"actor", actor,
+	)
+
+	report.Errors = []ImportRowError{}
+	return report, nil
+}
+
+// planImport matches every row to the product it updates and works out the new values.
+// The matched products stay locked until the transaction ends
+func planImport(ctx context.Context, qtx *repo.Queries, rows []importRow, skip []ImportRowError) (creates, updates []importChange, unchanged int, errs []ImportRowError, err error) {
+	skipped := make(map[int]bool, len(skip))
+	for _, e := range skip {
+		skipped[e.Line] = true
+	}
+
+	var names, skus []string
+	for _, row := range rows {
+		if row.name != nil {
+			names = append(names, *row.name)
+		}
+		if row.sku != nil {
+			skus = append(skus, *row.sku)
+		}
+	}
+
+	existing, err := qtx.ListProductsForImport(ctx, repo.ListProductsForImportParams{Names: names, Skus: skus})
+	if err != nil {
+		return nil, nil, 0, nil, &utils.DatabaseError{Query: "ListProductsForImport", Err: err}
+	}
+
+	byName := make(map[string][]repo.Product, len(existing))
+	bySKU := make(map[string]repo.Product, len(existing))
+	ids := make([]int64, 0, len(existing))
+	for _, p := range existing {
+		byName[p.Name] = append(byName[p.Name], p)
+		if p.Sku.Valid {
+			bySKU[p.Sku.String] = p
+		}
+		ids = append(ids, p.ID)
+	}
+
+	// what stands in the way of changing stock or currency
+	summaries, err := qtx.ListProductVariantSummaries(ctx, ids)
+	if err != nil {
+		return nil, nil, 0, nil, &utils.DatabaseError{Query: "ListProductVariantSummaries", Err: err}
+	}
+	hasVariants := make(map[int64]bool, len(summaries))
+	hasOwnPrices := make(map[int64]bool, len(summaries))
+	for _, v := range summaries {
+		hasVariants[v.ProductID] = true
+		hasOwnPrices[v.ProductID] = v.HasOwnPrices
+	}
+
+	reservations, err := qtx.ListReservedQuantities(ctx, ids)
+	if err != nil {
+		return nil, nil, 0, nil, &utils.DatabaseError{Query: "ListReservedQuantities", Err: err}
+	}
+	reserved := make(map[int64]int32, len(reservations))
+	for _, r := range reservations {
+		reserved[r.ProductID] = r.Reserved
+	}
+
+	// a product can only be updated by one row
+	matched := make(map[int64]int, len(existing))
+
+	for _, row := range rows {
+		if skipped[row.line] {
+			continue
+		}
+
+		current, found, rowErrs := matchImportRow(row, byName, bySKU)
+		if len(rowErrs) > 0 {
+			errs = append(errs, rowErrs...)
+			continue
+		}
+		if found {
+			if first, ok := matched[current.ID]; ok {
+				errs = append(errs, ImportRowError{Line: row.line, Message: fmt.Sprintf("updates product %d like line %d", current.ID, first)})
+				continue
+			}
+			matched[current.ID] = row.line
+		}
+
+		next, rowErrs := mergeImportRow(row, current, found)
+		if found {
+			id := current.ID
+			if next.Stock != current.Stock && hasVariants[id] {
+				rowErrs = append(rowErrs, ImportRowError{Line: row.line, Field: "stock", Message: fmt.Sprintf("product %d has variants, their stock is kept per variant", id)})
+			} else if next.Stock != current.Stock && next.Stock < reserved[id] {
+				rowErrs = append(rowErrs, ImportRowError{Line: row.line, Field: "stock", Message: fmt.Sprintf("%d units of product %d are reserved", reserved[id], id)})
+			}
+			if next.Currency != current.Currency && hasOwnPrices[id] {
+				rowErrs = append(rowErrs, ImportRowError{Line: row.line, Field: "currency", Message: fmt.Sprintf("variants of product %d have their own prices in %s, change those first", id, current.Currency)})
+			}
+		}
+		if len(rowErrs) > 0 {
+			errs = append(errs, rowErrs...)
+			continue
+		}
+
+		switch {
+		case !found:
+			creates = append(creates, importChange{line: row.line, next: next})
+		case next == current:
+			unchanged++
+		default:
+			updates = append(updates, importChange{line: row.line, current: current, next: next})
+		}
+	}
+
+	return creates, updates, unchanged, errs, nil
+}
+
+// matchImportRow finds the product a row updates, by SKU first and then by name
+func matchImportRow(row importRow, byName map[string][]repo.Product, bySKU map[string]repo.Product) (repo.Product, bool, []ImportRowError) {
+	var current repo.Product
+	found := false
+
+	if row.sku != nil {
+		current, found = bySKU[*row.sku]
+	}
+	if !found && row.name != nil {
+		matches := byName[*row.name]
+		if len(matches) > 1 {
+			return repo.Product{}, false, []ImportRowError{{Line: row.line, Field: "name", Message: fmt.Sprintf("matches %d products, add a sku to pick one", len(matches))}}
+		}
+		if len(matches) == 1 {
+			current, found = matches[0], true
+			if row.sku != nil && current.Sku.Valid {
+				return repo.Product{}, false, []ImportRowError{{Line: row.line, Field: "sku", Message: fmt.Sprintf("product %d with this name has the SKU '%s'", current.ID, current.Sku.String)}}
+			}
+		}
+	}
+
+	// renaming must not take the name of another product
+	if found && row.name != nil && *row.name != current.Name {
+		for _, p := range byName[*row.name] {
+			if p.ID != current.ID {
+				return repo.Product{}, false, []ImportRowError{{Line: row.line, Field: "name", Message: fmt.Sprintf("product %d already has this name", p.ID)}}
+			}
+		}
+	}
+	return current, found, nil
+}
+
+// mergeImportRow applies the fields of a row to the current product and validates the result
+// with the same rules as CreateProduct
+func mergeImportRow(row importRow, current repo.Product, found bool) (repo.Product, []ImportRowError) {
+	var errs []ImportRowError
+	if !found {
+		for _, required := range []struct {
+			field string
+			set   bool
+		}{{"name", row.name != nil}, {"price", row.price != nil}, {"currency", row.currency != nil}} {
+			if !required.set {
+				errs = append(errs, ImportRowError{Line: row.line, Field: required.field, Message: "is required for new products"})
+			}
+		}
+		if len(errs) > 0 {
+			return repo.Product{}, errs
+		}
+	}
+
+	next := current
+	if row.sku != nil {
+		next.Sku = pgtype.Text{String: *row.sku, Valid: true}
+	}
+	setIf(&next.Name, row.name)
+	setIf(&next.Description, row.description)
+	setIf(&next.Price, row.price)
+	setIf(&next.Currency, row.currency)
+	setIf(&next.Stock, row.stock)
+	setIf(&next.TaxClass, row.taxClass)
+	setIf(&next.WeightGrams, row.weightGrams)
+	setIf(&next.LengthMm, row.lengthMm)
+	setIf(&next.WidthMm, row.widthMm)
+	setIf(&next.HeightMm, row.heightMm)
+
+	if strings.TrimSpace(next.Name) == "" {
+		errs = append(errs, ImportRowError{Line: row.line, Field: "name", Message: "cannot be empty"})
+	}
+
+	currency, err := validatePrice(next.Price, next.Currency)
+	if err != nil {
+		errs = append(errs, importError(row.line, err))
+	}
+	next.Currency = currency
+
+	taxClass, err := tax.NormalizeClass(next.TaxClass)
+	if err != nil {
+		errs = append(errs, importError(row.line, err))
+	}
+	next.TaxClass = taxClass
+
+	if err := validateDimensions(next.WeightGrams, next.LengthMm, next.WidthMm, next.HeightMm); err != nil {
+		errs = append(errs, importError(row.line, err))
+	}
+	if next.Stock < 0 {
+		errs = append(errs, ImportRowError{Line: row.line, Field: "stock", Message: "cannot be negative"})
+	}
+
+	return next, errs
+}
+
+func setIf[T any](dst *T, value *T) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+// importError turns a validation error into a row error
+func importError(line int, err error) ImportRowError {
+	var ve *utils.ValidationError
+	if errors.As(err, &ve) {
+		return ImportRowError{Line: line, Field: strings.ToLower(ve.Field), Message: ve.Message}
+	}
+	return ImportRowError{Line: line, Message: err.Error()}
+}
+
+// findDuplicateRows reports rows that name the same SKU or product name as an earlier row
+func findDuplicateRows(rows []importRow) []ImportRowError {
+	var errs []ImportRowError
+	skus := make(map[string]int, len(rows))
+	names := make(map[string]int, len(rows))
+
+	for _, row := range rows {
+		if row.sku != nil {
+			if first, ok := skus[*row.sku]; ok {
+				errs = append(errs, ImportRowError{Line: row.line, Field: "sku", Message: fmt.Sprintf("is already used on line %d", first)})
+				continue
+			}
+			skus[*row.sku] = row.line
+		}
+		if row.name != nil {
+			if first, ok := names[*row.name]; ok {
+				errs = append(errs, ImportRowError{Line: row.line, Field: "name", Message: fmt.Sprintf("is already used on line %d", first)})
+				continue
+			}
+			names[*row.name] = row.line
+		}
+	}
+	return errs
+}
+
+// applyImport copies the new products and updates the changed ones in batches, recording
+// every stock change in the inventory ledger and queueing the price change events
+func applyImport(ctx context.Context, qtx *repo.Queries, creates, updates []importChange, actor string) error {
+	for start := 0; start < len(creates); start += importBatchSize {
+		batch := creates[start:min(start+importBatchSize, len(creates))]
+
+		// ids are taken up front so the ledger rows can be copied without reading the products back
+		ids, err := qtx.NextProductIDs(ctx, int32(len(batch)))
+		if err != nil {
+			return &utils.DatabaseError{Query: "NextProductIDs", Err: err}
+		}
+
+		products := make([]repo.CopyProductsParams, 0, len(batch))
+		var movements []repo.CopyInventoryMovementsParams
+		for i, c := range batch {
+			p := c.next
+			products = append(products, repo.CopyProductsParams{
+				ID:          ids[i],
+				Sku:         p.Sku,
+				Name:        p.Name,
+				Description: p.Description,
+				Price:       p.Price,
+				Currency:    p.Currency,
+				Stock:       p.Stock,
+				TaxClass:    p.TaxClass,
+				WeightGrams: p.WeightGrams,
+				LengthMm:    p.LengthMm,
+				WidthMm:     p.WidthMm,
+				HeightMm:    p.HeightMm,
+			})
+			if p.Stock != 0 {
+				movements = append(movements, repo.CopyInventoryMovementsParams{
+					ProductID:  ids[i],
+					Quantity:   p.Stock,
+					StockAfter: p.Stock,
+					Reason:     inventory.ReasonInitialStock,
+					Actor:      actor,
+					Note:       fmt.Sprintf("import line %d", c.line),
+				})
+			}
+		}
+
+		_, err = qtx.CopyProducts(ctx, products)
+		if err != nil {
+			return &utils.DatabaseError{Query: "CopyProducts", Err: err}
+		}
+		if err := inventory.CopyMovements(ctx, qtx, movements); err != nil {
+			return err
+		}
+	}
+
+	for start := 0; start < len(updates); start += importBatchSize {
+		batch := updates[start:min(start+importBatchSize, len(updates))]
+
+		var arg repo.UpdateImportedProductsParams
+		var movements []repo.CopyInventoryMovementsParams
+		var events []outbox.Event
+		for _, c := range batch {
+			p := c.next
+			arg.Ids = append(arg.Ids, p.ID)
+			arg.Skus = append(arg.Skus, p.Sku)
+			arg.Names = append(arg.Names, p.Name)
+			arg.Descriptions = append(arg.Descriptions, p.Description)
+			arg.Prices = append(arg.Prices, p.Price)
+			arg.Currencies = append(arg.Currencies, p.Currency)
+			arg.Stocks = append(arg.Stocks, p.Stock)
+			arg.TaxClasses = append(arg.TaxClasses, p.TaxClass)
+			arg.Weights = append(arg.Weights, p.WeightGrams)
+			arg.Lengths = append(arg.Lengths, p.LengthMm)
+			arg.Widths = append(arg.Widths, p.WidthMm)
+			arg.Heights = append(arg.Heights, p.HeightMm)
+
+			if event, ok := priceChangedEvent(c.current, p); ok {
+				events = append(events, event)
+			}
+			if delta := p.Stock - c.current.Stock; delta != 0 {
+				movements = append(movements, repo.CopyInventoryMovementsParams{
+					ProductID:     p.ID,
+					Quantity:      delta,
+					StockAfter:    p.Stock,
+					Reason:        inventory.ReasonImport,
+					Actor:         actor,
+					ReferenceType: inventory.ReferenceProduct,
+					ReferenceID:   strconv.FormatInt(p.ID, 10),
+					Note:          fmt.Sprintf("import line %d", c.line),
+				})
+			}
+		}
+
+		err := qtx.UpdateImportedProducts(ctx, arg)
+		if err != nil {
+			return &utils.DatabaseError{Query: "UpdateImportedProducts", Err: err}
+		}
+		if err := inventory.CopyMovements(ctx, qtx, movements); err != nil {
+			return err
+		}
+		if err := outbox.Copy(ctx, qtx, events); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseImport reads the rows of the body. Problems with single rows are returned as row errors,
+// an unreadable body or header as an error
+func parseImport(format string, body io.Reader) ([]importRow, []ImportRowError, error) {
+	switch format {
+	case FormatCSV:
+		return parseCSVImport(body)
+	case FormatNDJSON:
+		return parseNDJSONImport(body)
+	default:
+		return nil, nil, &utils.ValidationError{
+			Field:   "format",
+			Message: fmt.Sprintf("must be %s or %s", FormatCSV, FormatNDJSON),
+		}
+	}
+}
+
+// parseCSVImport reads a CSV file with a header row naming the columns. Empty cells are left out
+func parseCSVImport(body io.Reader) ([]importRow, []ImportRowError, error) {
+	reader := csv.NewReader(body)
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, nil
+	}
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, nil, &utils.ValidationError{Field: "header", Message: parseErr.Error()}
+		}
+		return nil, nil, err
+	}
+
+	known := make(map[string]bool, len(recordColumns))
+	for _, c := range recordColumns {
+		known[c] = true
+	}
+	columns := make([]string, len(header))
+	seen := make(map[string]bool, len(header))
+	for i, h := range header {
+		name := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		if !known[name] {
+			return nil, nil, &utils.ValidationError{Field: "header", Message: fmt.Sprintf("unknown column '%s'", h)}
+		}
+		if seen[name] {
+			return nil, nil, &utils.ValidationError{Field: "header", Message: fmt.Sprintf("column '%s' appears twice", name)}
+		}
+		seen[name] = true
+		columns[i] = name
+	}
+	if !seen["name"] && !seen["sku"] {
+		return nil, nil, &utils.ValidationError{Field: "header", Message: "needs a name or sku column"}
+	}
+
+	var rows []importRow
+	var rowErrors []ImportRowError
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, err
+			}
+			if errors.Is(parseErr.Err, csv.ErrFieldCount) {
+				rowErrors = append(rowErrors, ImportRowError{
+					Line:    parseErr.StartLine,
+					Message: fmt.Sprintf("has %d fields, the header has %d", len(fields), len(columns)),
+				})
+				continue
+			}
+			// the reader cannot find the next row after broken quoting
+			rowErrors = append(rowErrors, ImportRowError{Line: parseErr.StartLine, Message: parseErr.Err.Error()})
+			break
+		}
+
+		line, _ := reader.FieldPos(0)
+		row, errs := csvRow(line, columns, fields)
+		if len(errs) > 0 {
+			rowErrors = append(rowErrors, errs...)
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, rowErrors, nil
+}
+
+func csvRow(line int, columns, fields []string) (importRow, []ImportRowError) {
+	row := importRow{line: line}
+	var errs []ImportRowError
+
+	for i, column := range columns {
+		value := strings.TrimSpace(fields[i])
+		if value == "" {
+			continue
+		}
+
+		switch column {
+		case "sku":
+			row.sku = &value
+		case "name":
+			row.name = &value
+		case "description":
+			row.description = &value
+		case "currency":
+			row.currency = &value
+		case "tax_class":
+			row.taxClass = &value
+		case "price":
+			amount, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				errs = append(errs, ImportRowError{Line: line, Field: column, Message: "must be a whole amount in minor units"})
+				continue
+			}
+			row.price = &amount
+		default:
+			n, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				errs = append(errs, ImportRowError{Line: line, Field: column, Message: "must be a whole number"})
+				continue
+			}
+			v := int32(n)
+			switch column {
+			case "stock":
+				row.stock = &v
+			case "weight_grams":
+				row.weightGrams = &v
+			case "length_mm":
+				row.lengthMm = &v
+			case "width_mm":
+				row.widthMm = &v
+			case "height_mm":
+				row.heightMm = &v
+			}
+		}
+	}
+	return row, errs
+}
+
+// parseNDJSONImport reads one product record per line, blank lines are skipped
+func parseNDJSONImport(body io.Reader) ([]importRow, []ImportRowError, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineBytes)
+
+	var rows []importRow
+	var rowErrors []ImportRowError
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		var record ProductRecord
+		decoder := json.NewDecoder(bytes.NewReader(text))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&record); err != nil {
+			rowErrors = append(rowErrors, ImportRowError{Line: line, Message: "invalid JSON: " + err.Error()})
+			continue
+		}
+
+		row := importRow{
+			line:        line,
+			sku:         nonEmpty(record.SKU),
+			name:        record.Name,
+			description: record.Description,
+			stock:       record.Stock,
+			taxClass:    nonEmpty(record.TaxClass),
+			weightGrams: record.WeightGrams,
+			lengthMm:    record.LengthMm,
+			widthMm:     record.WidthMm,
+			heightMm:    record.HeightMm,
+		}
+		if record.Price != nil {
+			row.price = &record.Price.Amount
+			row.currency = nonEmpty(&record.Price.Currency)
+		}
+		rows = append(rows, row)
+	}
+
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, nil, &utils.ValidationError{
+				Field:   "body",
+				Message: fmt.Sprintf("line %d is longer than %d bytes", line+1, maxImportLineBytes),
+			}
+		}
+		return nil, nil, err
+	}
+	return rows, rowErrors, nil
+}
+
+// nonEmpty treats an empty string like a missing field
+func nonEmpty(s *string) *string {
+	if s == nil || strings.TrimSpace(*s) == "" {
+		return nil
+	}
+	v := strings.TrimSpace(*s)
+	return &v
+}
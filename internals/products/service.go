@@ -3,24 +3,77 @@ package products
 import (
 	"context"
 	"database/sql"
+	"ecomApis/internals/inventory"
+	"ecomApis/internals/media"
+	"ecomApis/internals/money"
+	"ecomApis/internals/outbox"
+	"ecomApis/internals/pricing"
 	"ecomApis/internals/repo"
+	"ecomApis/internals/tax"
 	"ecomApis/internals/utils"
+	"fmt"
+	"log/slog"
 	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type ProductService struct {
-	repo *repo.Queries
+	repo    *repo.Queries
+	db      *pgxpool.Pool
+	pricing *pricing.Service
+	media   *media.Service
 }
 
-func NewProductService(r *repo.Queries) *ProductService {
+func NewProductService(r *repo.Queries, db *pgxpool.Pool, prices *pricing.Service, images *media.Service) *ProductService {
 	return &ProductService{
-		repo: r,
+		repo:    r,
+		db:      db,
+		pricing: prices,
+		media:   images,
 	}
 }
 
-func (s *ProductService) CreateProduct(ctx context.Context, arg repo.CreateProductParams) (repo.Product, error) {
+// validateDimensions rejects negative weights and sizes, 0 means unknown
+func validateDimensions(weight, length, width, height int32) error {
+	for _, d := range []struct {
+		field string
+		value int32
+	}{{"weight_grams", weight}, {"length_mm", length}, {"width_mm", width}, {"height_mm", height}} {
+		if d.value < 0 {
+			return &utils.ValidationError{Field: d.field, Message: "cannot be negative"}
+		}
+	}
+	return nil
+}
+
+// validatePrice checks an amount in minor units and returns its normalized currency code
+func validatePrice(amount int64, currency string) (string, error) {
+	if amount < 0 {
+		return "", &utils.ValidationError{
+			Field:   "Price",
+			Message: "cannot be negative",
+		}
+	}
+
+	price, err := money.New(amount, currency)
+	if err != nil {
+		return "", &utils.ValidationError{
+			Field:   "Currency",
+			Message: fmt.Sprintf("'%s' is not a supported ISO-4217 currency", currency),
+		}
+	}
+	return price.Currency, nil
+}
+
+// CreateProduct creates the product and records its initial stock in the inventory ledger
+func (s *ProductService) CreateProduct(ctx context.Context, arg repo.CreateProductParams, actor string) (repo.Product, error) {
 	// --- Validation ---
 	if arg.Name == "" {
 		return repo.Product{}, &utils.ValidationError{
@@ -29,11 +82,18 @@ func (s *ProductService) CreateProduct(ctx context.Context, arg repo.CreateProdu
 		}
 	}
 
-	if arg.Price < 0 {
-		return repo.Product{}, &utils.ValidationError{
-			Field:   "Price",
-			Message: "cannot be negative",
-		}
+	currency, err := validatePrice(arg.Price, arg.Currency)
+	if err != nil {
+		return repo.Product{}, err
+	}
+
+	taxClass, err := tax.NormalizeClass(arg.TaxClass)
+	if err != nil {
+		return repo.Product{}, err
+	}
+
+	if err := validateDimensions(arg.WeightGrams, arg.LengthMm, arg.WidthMm, arg.HeightMm); err != nil {
+		return repo.Product{}, err
 	}
 
 	if arg.Stock < 0 {
@@ -58,20 +118,49 @@ func (s *ProductService) CreateProduct(ctx context.Context, arg repo.CreateProdu
 		}
 	}
 
-	product, err := s.repo.CreateProduct(ctx, repo.CreateProductParams{
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return repo.Product{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	product, err := qtx.CreateProduct(ctx, repo.CreateProductParams{
 		Name:        arg.Name,
 		Description: arg.Description,
 		Price:       arg.Price,
+		Currency:    currency,
 		Stock:       arg.Stock,
+		TaxClass:    taxClass,
+		WeightGrams: arg.WeightGrams,
+		LengthMm:    arg.LengthMm,
+		WidthMm:     arg.WidthMm,
+		HeightMm:    arg.HeightMm,
 	})
 
 	if err != nil {
+		tx.Rollback(ctx)
 		return repo.Product{}, &utils.DatabaseError{
 			Query: "CreateProduct",
 			Err:   err,
 		}
 	}
 
+	err = inventory.Record(ctx, qtx, repo.AddInventoryMovementParams{
+		ProductID:  product.ID,
+		Quantity:   product.Stock,
+		StockAfter: product.Stock,
+		Reason:     inventory.ReasonInitialStock,
+		Actor:      actor,
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Product{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Product{}, fmt.Errorf("commit tx: %w", err)
+	}
+
 	return product, nil
 }
 
@@ -93,6 +182,57 @@ func (s *ProductService) FindProductByID(ctx context.Context, id int64) (repo.Pr
 	return product, nil
 }
 
+// GetProductDetails returns the product with its on hand, reserved and available stock, its variants and images
+func (s *ProductService) GetProductDetails(ctx context.Context, id int64) (ProductDetails, error) {
+	product, err := s.FindProductByID(ctx, id)
+	if err != nil {
+		return ProductDetails{}, err
+	}
+
+	reserved, err := s.repo.GetReservedQuantity(ctx, id)
+	if err != nil {
+		return ProductDetails{}, &utils.DatabaseError{
+			Query: "GetReservedQuantity",
+			Err:   err,
+		}
+	}
+
+	variants, err := variantsOf(ctx, s.repo, []repo.Product{product})
+	if err != nil {
+		return ProductDetails{}, err
+	}
+	for i, v := range variants[id] {
+		reserved, err := s.repo.GetVariantReservedQuantity(ctx, pgtype.Int8{Int64: v.ID, Valid: true})
+		if err != nil {
+			return ProductDetails{}, &utils.DatabaseError{
+				Query: "GetVariantReservedQuantity",
+				Err:   err,
+			}
+		}
+		variants[id][i].StockLevels = &StockLevels{
+			OnHand:    v.Stock,
+			Reserved:  reserved,
+			Available: v.Stock - reserved,
+		}
+	}
+
+	images, err := s.media.ProductImages(ctx, id)
+	if err != nil {
+		return ProductDetails{}, err
+	}
+
+	return ProductDetails{
+		Product: product,
+		StockLevels: StockLevels{
+			OnHand:    product.Stock,
+			Reserved:  reserved,
+			Available: product.Stock - reserved,
+		},
+		Variants: variants[id],
+		Images:   images,
+	}, nil
+}
+
 func (s *ProductService) UpdateProductDetails(ctx context.Context, arg repo.UpdateProductDetailsParams) (repo.Product, error) {
 	// --- Validation ---
 	if arg.Name == "" {
@@ -102,27 +242,307 @@ func (s *ProductService) UpdateProductDetails(ctx context.Context, arg repo.Upda
 		}
 	}
 
-	if arg.Price < 0 {
-		return repo.Product{}, &utils.ValidationError{
-			Field:   "Price",
-			Message: "cannot be negative",
-		}
+	currency, err := validatePrice(arg.Price, arg.Currency)
+	if err != nil {
+		return repo.Product{}, err
 	}
 
-	product, err := s.repo.UpdateProductDetails(ctx, repo.UpdateProductDetailsParams{
+	taxClass, err := tax.NormalizeClass(arg.TaxClass)
+	if err != nil {
+		return repo.Product{}, err
+	}
+
+	if err := validateDimensions(arg.WeightGrams, arg.LengthMm, arg.WidthMm, arg.HeightMm); err != nil {
+		return repo.Product{}, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return repo.Product{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	current, err := lockProduct(ctx, qtx, arg.ID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Product{}, err
+	}
+
+	if err := checkVariantCurrency(ctx, qtx, current, currency); err != nil {
+		tx.Rollback(ctx)
+		return repo.Product{}, err
+	}
+
+	product, err := qtx.UpdateProductDetails(ctx, repo.UpdateProductDetailsParams{
 		Name:        arg.Name,
 		Description: arg.Description,
 		Price:       arg.Price,
+		Currency:    currency,
+		TaxClass:    taxClass,
+		WeightGrams: arg.WeightGrams,
+		LengthMm:    arg.LengthMm,
+		WidthMm:     arg.WidthMm,
+		HeightMm:    arg.HeightMm,
 		ID:          arg.ID,
 	})
-
 	if err != nil {
+		tx.Rollback(ctx)
 		return repo.Product{}, &utils.DatabaseError{
 			Query: "UpdateProductDetails",
 			Err:   err,
 		}
 	}
 
+	if event, ok := priceChangedEvent(current, product); ok {
+		if err := outbox.Write(ctx, qtx, event); err != nil {
+			tx.Rollback(ctx)
+			return repo.Product{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Product{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return product, nil
+}
+
+// priceChangedEvent is the product.price_changed event of an update, ok is false when the price stayed the same
+func priceChangedEvent(before, after repo.Product) (outbox.Event, bool) {
+	if before.Price == after.Price && before.Currency == after.Currency {
+		return outbox.Event{}, false
+	}
+	return outbox.PriceChangedEvent(outbox.PriceChanged{
+		ProductID:     after.ID,
+		PreviousPrice: before.PriceMoney(),
+		Price:         after.PriceMoney(),
+	}), true
+}
+
+// PatchProduct updates only the supplied fields, nil fields are left as they are
+func (s *ProductService) PatchProduct(ctx context.Context, id int64, req PatchProductRequest) (repo.Product, error) {
+	// --- Validation ---
+	if req.Name == nil && req.Description == nil && req.Price == nil && req.TaxClass == nil &&
+		req.WeightGrams == nil && req.LengthMm == nil && req.WidthMm == nil && req.HeightMm == nil {
+		return repo.Product{}, &utils.ValidationError{
+			Field:   "body",
+			Message: "at least one field must be provided",
+		}
+	}
+
+	params := repo.PatchProductParams{ID: id}
+
+	if req.Name != nil {
+		if *req.Name == "" {
+			return repo.Product{}, &utils.ValidationError{
+				Field:   "Name",
+				Message: "cannot be empty",
+			}
+		}
+		params.Name = pgtype.Text{String: *req.Name, Valid: true}
+	}
+
+	if req.Description != nil {
+		params.Description = pgtype.Text{String: *req.Description, Valid: true}
+	}
+
+	// amount and currency always change together
+	if req.Price != nil {
+		currency, err := validatePrice(req.Price.Amount, req.Price.Currency)
+		if err != nil {
+			return repo.Product{}, err
+		}
+		params.Price = pgtype.Int8{Int64: req.Price.Amount, Valid: true}
+		params.Currency = pgtype.Text{String: currency, Valid: true}
+	}
+
+	if req.TaxClass != nil {
+		taxClass, err := tax.NormalizeClass(*req.TaxClass)
+		if err != nil {
+			return repo.Product{}, err
+		}
+		params.TaxClass = pgtype.Text{String: taxClass, Valid: true}
+	}
+
+	for _, d := range []struct {
+		field string
+		value *int32
+		param *pgtype.Int4
+	}{
+		{"weight_grams", req.WeightGrams, &params.WeightGrams},
+		{"length_mm", req.LengthMm, &params.LengthMm},
+		{"width_mm", req.WidthMm, &params.WidthMm},
+		{"height_mm", req.HeightMm, &params.HeightMm},
+	} {
+		if d.value == nil {
+			continue
+		}
+		if *d.value < 0 {
+			return repo.Product{}, &utils.ValidationError{Field: d.field, Message: "cannot be negative"}
+		}
+		*d.param = pgtype.Int4{Int32: *d.value, Valid: true}
+	}
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return repo.Product{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	current, err := lockProduct(ctx, qtx, id)
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Product{}, err
+	}
+
+	if params.Currency.Valid {
+		if err := checkVariantCurrency(ctx, qtx, current, params.Currency.String); err != nil {
+			tx.Rollback(ctx)
+			return repo.Product{}, err
+		}
+	}
+
+	product, err := qtx.PatchProduct(ctx, params)
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Product{}, &utils.DatabaseError{
+			Query: "PatchProduct",
+			Err:   err,
+		}
+	}
+
+	if event, ok := priceChangedEvent(current, product); ok {
+		if err := outbox.Write(ctx, qtx, event); err != nil {
+			tx.Rollback(ctx)
+			return repo.Product{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Product{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return product, nil
+}
+
+// AdjustStock applies a signed stock change and records it in the inventory ledger.
+// Stock is never allowed to go below what is reserved
+func (s *ProductService) AdjustStock(ctx context.Context, id int64, req StockAdjustmentRequest, actor string) (repo.Product, error) {
+	// --- Validation ---
+	if req.Quantity == 0 {
+		return repo.Product{}, &utils.ValidationError{
+			Field:   "Quantity",
+			Message: "cannot be zero",
+		}
+	}
+
+	if !validStockReasons[req.Reason] {
+		return repo.Product{}, &utils.ValidationError{
+			Field:   "Reason",
+			Message: fmt.Sprintf("unknown reason code '%s'", req.Reason),
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return repo.Product{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	// lock the product so orders cannot reserve it while we check
+	product, err := qtx.GetProductForUpdate(ctx, id)
+	if err != nil {
+		tx.Rollback(ctx)
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return repo.Product{}, &utils.NotFoundError{
+				Resource: "Product",
+				ID:       strconv.FormatInt(id, 10),
+			}
+		}
+		return repo.Product{}, &utils.DatabaseError{
+			Query: "GetProductForUpdate",
+			Err:   err,
+		}
+	}
+
+	// products with variants are stocked per variant
+	hasVariants, err := qtx.ProductHasVariants(ctx, id)
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Product{}, &utils.DatabaseError{
+			Query: "ProductHasVariants",
+			Err:   err,
+		}
+	}
+	if hasVariants {
+		tx.Rollback(ctx)
+		return repo.Product{}, &utils.ValidationError{
+			Field:   "Quantity",
+			Message: fmt.Sprintf("product %d has variants, adjust the stock of a variant instead", id),
+		}
+	}
+
+	// stock held by unpaid orders cannot be written off
+	reserved, err := qtx.GetReservedQuantity(ctx, id)
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Product{}, &utils.DatabaseError{
+			Query: "GetReservedQuantity",
+			Err:   err,
+		}
+	}
+
+	if product.Stock+req.Quantity < reserved {
+		tx.Rollback(ctx)
+		return repo.Product{}, &utils.ValidationError{
+			Field:   "Quantity",
+			Message: fmt.Sprintf("not enough unreserved stock for product %d, %d units are reserved", id, reserved),
+		}
+	}
+
+	product, err = qtx.AdjustProductStock(ctx, repo.AdjustProductStockParams{
+		Delta: req.Quantity,
+		ID:    id,
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		// the row is filtered out when the change would leave negative stock
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return repo.Product{}, &utils.ValidationError{
+				Field:   "Quantity",
+				Message: fmt.Sprintf("not enough stock for product %d", id),
+			}
+		}
+		return repo.Product{}, &utils.DatabaseError{
+			Query: "AdjustProductStock",
+			Err:   err,
+		}
+	}
+
+	err = inventory.Record(ctx, qtx, repo.AddInventoryMovementParams{
+		ProductID:  id,
+		Quantity:   req.Quantity,
+		StockAfter: product.Stock,
+		Reason:     req.Reason,
+		Actor:      actor,
+		Note:       req.Note,
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		return repo.Product{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Product{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	slog.Info("product stock adjusted",
+		"product_id", id,
+		"quantity", req.Quantity,
+		"reason", req.Reason,
+		"note", req.Note,
+	)
+
 	return product, nil
 }
 
@@ -144,6 +564,15 @@ func (s *ProductService) DeleteProduct(ctx context.Context, id int64) error {
 		}
 	}
 
+	// the image rows go with the product, their files have to be removed afterwards
+	images, err := s.repo.ListProductImages(ctx, id)
+	if err != nil {
+		return &utils.DatabaseError{
+			Query: "ListProductImages",
+			Err:   err,
+		}
+	}
+
 	// delete the product
 	err = s.repo.DeleteProduct(ctx, id)
 	if err != nil {
@@ -152,16 +581,279 @@ func (s *ProductService) DeleteProduct(ctx context.Context, id int64) error {
 			Err:   err,
 		}
 	}
+
+	s.media.RemoveFiles(ctx, images)
 	return nil
 }
 
-func (s *ProductService) ListAllProducts(ctx context.Context) ([]repo.Product, error) {
-	products, err := s.repo.ListProducts(ctx)
+// terms shorter than this are too short for full-text prefix matching and use ILIKE instead
+const minFullTextQueryLength = 3
+
+// SearchProducts ranks products by how well their name and description match the query
+func (s *ProductService) SearchProducts(ctx context.Context, query string, limit int32) ([]ProductSearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, &utils.ValidationError{
+			Field:   "q",
+			Message: "cannot be empty",
+		}
+	}
+
+	tsQuery := buildPrefixTSQuery(query)
+	if utf8.RuneCountInString(query) < minFullTextQueryLength || tsQuery == "" {
+		return s.searchProductsByName(ctx, query, limit)
+	}
+
+	rows, err := s.repo.SearchProducts(ctx, repo.SearchProductsParams{
+		Query:     tsQuery,
+		PageLimit: limit,
+	})
+	if err != nil {
+		return nil, &utils.DatabaseError{
+			Query: "SearchProducts",
+			Err:   err,
+		}
+	}
+
+	results := make([]ProductSearchResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, ProductSearchResult{
+			ID:                 row.ID,
+			Name:               row.Name,
+			Description:        row.Description,
+			Price:              money.Money{Amount: row.Price, Currency: row.Currency},
+			Stock:              row.Stock,
+			Rank:               row.Rank,
+			NameSnippet:        row.NameSnippet,
+			DescriptionSnippet: row.DescriptionSnippet,
+		})
+	}
+	return results, nil
+}
+
+// searchProductsByName is the ILIKE fallback for very short search terms
+func (s *ProductService) searchProductsByName(ctx context.Context, query string, limit int32) ([]ProductSearchResult, error) {
+	products, err := s.repo.SearchProductsByName(ctx, pgtype.Text{String: query, Valid: true})
 	if err != nil {
 		return nil, &utils.DatabaseError{
-			Query: "ListAllProducts",
+			Query: "SearchProductsByName",
+			Err:   err,
+		}
+	}
+
+	if int32(len(products)) > limit {
+		products = products[:limit]
+	}
+
+	results := make([]ProductSearchResult, 0, len(products))
+	for _, p := range products {
+		results = append(results, ProductSearchResult{
+			ID:                 p.ID,
+			Name:               p.Name,
+			Description:        p.Description,
+			Price:              p.PriceMoney(),
+			Stock:              p.Stock,
+			NameSnippet:        p.Name,
+			DescriptionSnippet: p.Description,
+		})
+	}
+	return results, nil
+}
+
+// buildPrefixTSQuery turns "red runn" into "red:* & runn:*" so partially typed words still match.
+// Anything that is not a letter or digit is dropped, so user input can never break the tsquery syntax
+func buildPrefixTSQuery(query string) string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		terms = append(terms, w+":*")
+	}
+	return strings.Join(terms, " & ")
+}
+
+// timestamps in cursors keep microsecond precision to match postgres
+const cursorTimeLayout = "2006-01-02T15:04:05.999999"
+
+// categoryIDs returns the category to filter by, with its whole subtree when descendants are included
+func (s *ProductService) categoryIDs(ctx context.Context, id int64, descendants bool) ([]int64, error) {
+	if descendants {
+		ids, err := s.repo.ListCategorySubtreeIDs(ctx, id)
+		if err != nil {
+			return nil, &utils.DatabaseError{Query: "ListCategorySubtreeIDs", Err: err}
+		}
+		if len(ids) == 0 {
+			return nil, &utils.NotFoundError{Resource: "Category", ID: strconv.FormatInt(id, 10)}
+		}
+		return ids, nil
+	}
+
+	_, err := s.repo.GetCategory(ctx, id)
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return nil, &utils.NotFoundError{Resource: "Category", ID: strconv.FormatInt(id, 10)}
+		}
+		return nil, &utils.DatabaseError{Query: "GetCategory", Err: err}
+	}
+	return []int64{id}, nil
+}
+
+// ListProducts returns one page of products using keyset pagination on (sort column, id)
+func (s *ProductService) ListProducts(ctx context.Context, q ListProductsQuery) (utils.Page[ProductListing], error) {
+	if q.SortBy == "" {
+		q.SortBy = "id"
+	}
+	if !repo.IsProductSortColumn(q.SortBy) {
+		return utils.Page[ProductListing]{}, &utils.ValidationError{
+			Field:   "sort",
+			Message: "must be one of id, price, created_at, name",
+		}
+	}
+	if q.MinPrice != nil && q.MaxPrice != nil && *q.MinPrice > *q.MaxPrice {
+		return utils.Page[ProductListing]{}, &utils.ValidationError{
+			Field:   "min_price",
+			Message: "cannot be greater than max_price",
+		}
+	}
+
+	params := repo.ListProductsPageParams{
+		SortBy:      q.SortBy,
+		Descending:  q.Descending,
+		InStockOnly: q.InStockOnly,
+		// fetch one extra row to know if there is a next page
+		PageLimit: q.Limit + 1,
+	}
+	if q.Currency != "" {
+		currency := money.NormalizeCurrency(q.Currency)
+		if !money.IsValidCurrency(currency) {
+			return utils.Page[ProductListing]{}, &utils.ValidationError{
+				Field:   "currency",
+				Message: fmt.Sprintf("unknown currency '%s'", q.Currency),
+			}
+		}
+		params.Currency = pgtype.Text{String: currency, Valid: true}
+	}
+	if q.MinPrice != nil {
+		params.MinPrice = pgtype.Int8{Int64: *q.MinPrice, Valid: true}
+	}
+	if q.MaxPrice != nil {
+		params.MaxPrice = pgtype.Int8{Int64: *q.MaxPrice, Valid: true}
+	}
+	if q.CreatedAfter != nil {
+		params.CreatedAfter = pgtype.Timestamp{Time: *q.CreatedAfter, Valid: true}
+	}
+	if q.CreatedBefore != nil {
+		params.CreatedBefore = pgtype.Timestamp{Time: *q.CreatedBefore, Valid: true}
+	}
+	if q.CategoryID != nil {
+		categoryIDs, err := s.categoryIDs(ctx, *q.CategoryID, q.IncludeDescendants)
+		if err != nil {
+			return utils.Page[ProductListing]{}, err
+		}
+		params.CategoryIDs = categoryIDs
+	}
+
+	if q.Cursor != "" {
+		cursor, err := utils.DecodeCursor(q.Cursor)
+		if err != nil {
+			return utils.Page[ProductListing]{}, err
+		}
+		if cursor.Sort != q.SortBy || cursor.Desc != q.Descending {
+			return utils.Page[ProductListing]{}, &utils.ValidationError{
+				Field:   "cursor",
+				Message: "cursor was issued for a different sort",
+			}
+		}
+		// the value is cast to the column type in the query, check it parses before it gets there
+		if !validSortValue(q.SortBy, cursor.Value) {
+			return utils.Page[ProductListing]{}, &utils.ValidationError{
+				Field:   "cursor",
+				Message: "malformed cursor",
+			}
+		}
+		params.CursorValue = pgtype.Text{String: cursor.Value, Valid: true}
+		params.CursorID = pgtype.Int8{Int64: cursor.ID, Valid: true}
+	}
+
+	products, err := s.repo.ListProductsPage(ctx, params)
+	if err != nil {
+		return utils.Page[ProductListing]{}, &utils.DatabaseError{
+			Query: "ListProductsPage",
 			Err:   err,
 		}
 	}
-	return products, nil
+
+	page := utils.NewPage(products, q.Limit, func(p repo.Product) utils.Cursor {
+		return utils.Cursor{Sort: q.SortBy, Desc: q.Descending, Value: productSortValue(p, q.SortBy), ID: p.ID}
+	})
+
+	variants, err := variantsOf(ctx, s.repo, page.Data)
+	if err != nil {
+		return utils.Page[ProductListing]{}, err
+	}
+
+	listings := make([]ProductListing, 0, len(page.Data))
+	for _, p := range page.Data {
+		listings = append(listings, ProductListing{Product: p, Variants: variants[p.ID]})
+	}
+
+	// convert after the cursor is built, it must keep the stored price
+	if q.TargetCurrency != "" && len(listings) > 0 {
+		quotes, err := s.pricing.PriceIn(ctx, s.repo, page.Data, q.TargetCurrency)
+		if err != nil {
+			return utils.Page[ProductListing]{}, err
+		}
+		for i, l := range listings {
+			for j, v := range l.Variants {
+				price := quotes[i]
+				if v.OwnPrice {
+					price, err = s.pricing.VariantQuote(l.Product, v.Price.Amount, quotes[i])
+					if err != nil {
+						return utils.Page[ProductListing]{}, err
+					}
+				}
+				listings[i].Variants[j].Price = price.Price
+			}
+			listings[i].Price = quotes[i].Price.Amount
+			listings[i].Currency = quotes[i].Price.Currency
+		}
+	}
+
+	return utils.Page[ProductListing]{
+		Data:       listings,
+		NextCursor: page.NextCursor,
+		Limit:      page.Limit,
+	}, nil
+}
+
+func productSortValue(p repo.Product, sortBy string) string {
+	switch sortBy {
+	case "price":
+		return strconv.FormatInt(p.Price, 10)
+	case "created_at":
+		return p.CreatedAt.Time.Format(cursorTimeLayout)
+	case "name":
+		return p.Name
+	default:
+		return ""
+	}
+}
+
+// validSortValue reports whether a cursor value parses as the type of the sort column
+func validSortValue(sortBy, value string) bool {
+	switch sortBy {
+	case "price":
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	case "created_at":
+		_, err := time.Parse(cursorTimeLayout, value)
+		return err == nil
+	case "name":
+		// postgres text cannot hold invalid utf-8 or NUL bytes
+		return utf8.ValidString(value) && !strings.ContainsRune(value, 0)
+	default:
+		return true
+	}
 }
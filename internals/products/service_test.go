@@ -0,0 +1,70 @@
+package products
+
+import (
+	"context"
+	"ecomApis/internals/utils"
+	"errors"
+	"testing"
+)
+
+func TestValidSortValue(t *testing.T) {
+	tests := []struct {
+		sortBy string
+		value  string
+		want   bool
+	}{
+		{"price", "1999", true},
+		{"price", "-5", true},
+		{"price", "19.99", false},
+		{"price", "1); DROP TABLE products", false},
+		{"created_at", "2026-01-02T15:04:05.123456", true},
+		{"created_at", "2026-01-02T15:04:05", true},
+		{"created_at", "yesterday", false},
+		{"name", "Blue shirt", true},
+		{"name", "bad\x00name", false},
+		{"name", "bad\xffname", false},
+		{"id", "", true},
+	}
+	for _, tt := range tests {
+		if got := validSortValue(tt.sortBy, tt.value); got != tt.want {
+			t.Errorf("validSortValue(%s, %q) = %v, want %v", tt.sortBy, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestListProductsRefusesBadCursor(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  ListProductsQuery
+		cursor utils.Cursor
+	}{
+		{
+			name:   "different sort",
+			query:  ListProductsQuery{SortBy: "price"},
+			cursor: utils.Cursor{Sort: "name", Value: "Blue shirt", ID: 3},
+		},
+		{
+			name:   "different order",
+			query:  ListProductsQuery{SortBy: "price", Descending: true},
+			cursor: utils.Cursor{Sort: "price", Value: "1999", ID: 3},
+		},
+		{
+			name:   "value of another type",
+			query:  ListProductsQuery{SortBy: "created_at"},
+			cursor: utils.Cursor{Sort: "created_at", Value: "1999", ID: 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.query.Limit = utils.DefaultPageLimit
+			tt.query.Cursor = utils.EncodeCursor(tt.cursor)
+
+			// the cursor is checked before the database is reached
+			_, err := NewProductService(nil, nil, nil, nil).ListProducts(context.Background(), tt.query)
+			var verr *utils.ValidationError
+			if !errors.As(err, &verr) || verr.Field != "cursor" {
+				t.Errorf("ListProducts: err = %v, want a cursor validation error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package products
+
+import (
+	"ecomApis/internals/media"
+	"ecomApis/internals/money"
+	"ecomApis/internals/repo"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CreateProductRequest takes the price as {amount, currency} with the amount in minor units
+type CreateProductRequest struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Price       money.Money `json:"price"`
+	Stock       int32       `json:"stock"`
+	// TaxClass picks the tax rate of the product, "standard" when empty
+	TaxClass string `json:"tax_class"`
+	Dimensions
+}
+
+// Dimensions are used to price shipping, weight in grams and sizes in millimetres
+type Dimensions struct {
+	WeightGrams int32 `json:"weight_grams"`
+	LengthMm    int32 `json:"length_mm"`
+	WidthMm     int32 `json:"width_mm"`
+	HeightMm    int32 `json:"height_mm"`
+}
+
+type UpdateProductRequest struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Price       money.Money `json:"price"`
+	TaxClass    string      `json:"tax_class"`
+	Dimensions
+}
+
+// PatchProductRequest only touches the fields that were sent
+type PatchProductRequest struct {
+	Name        *string      `json:"name"`
+	Description *string      `json:"description"`
+	Price       *money.Money `json:"price"`
+	TaxClass    *string      `json:"tax_class"`
+	WeightGrams *int32       `json:"weight_grams"`
+	LengthMm    *int32       `json:"length_mm"`
+	WidthMm     *int32       `json:"width_mm"`
+	HeightMm    *int32       `json:"height_mm"`
+}
+
+// stock adjustment reasons
+const (
+	StockReasonRestock    = "restock"
+	StockReasonCorrection = "correction"
+	StockReasonDamaged    = "damaged"
+	StockReasonLost       = "lost"
+	StockReasonReturned   = "returned"
+)
+
+var validStockReasons = map[string]bool{
+	StockReasonRestock:    true,
+	StockReasonCorrection: true,
+	StockReasonDamaged:    true,
+	StockReasonLost:       true,
+	StockReasonReturned:   true,
+}
+
+// StockAdjustmentRequest carries a signed quantity, positive adds stock and negative removes it
+type StockAdjustmentRequest struct {
+	Quantity int32  `json:"quantity"`
+	Reason   string `json:"reason"`
+	Note     string `json:"note"`
+}
+
+// ListProductsQuery holds the pagination, sorting and filter options of GET /products
+type ListProductsQuery struct {
+	Limit      int32
+	Cursor     string
+	SortBy     string
+	Descending bool
+	Currency   string
+	// TargetCurrency converts the returned prices, it does not filter
+	TargetCurrency string
+	MinPrice       *int64
+	MaxPrice       *int64
+	InStockOnly    bool
+	CreatedAfter   *time.Time
+	CreatedBefore  *time.Time
+	// CategoryID limits the list to one category, and to its whole subtree with IncludeDescendants
+	CategoryID         *int64
+	IncludeDescendants bool
+}
+
+// ProductSearchResult is a product with its relevance and highlighted matches
+type ProductSearchResult struct {
+	ID                 int64       `json:"id"`
+	Name               string      `json:"name"`
+	Description        string      `json:"description"`
+	Price              money.Money `json:"price"`
+	Stock              int32       `json:"stock"`
+	Rank               float32     `json:"rank"`
+	NameSnippet        string      `json:"name_snippet"`
+	DescriptionSnippet string      `json:"description_snippet"`
+}
+
+// StockLevels splits the stock of a product into what is on the shelf and what unpaid orders hold
+type StockLevels struct {
+	OnHand    int32 `json:"on_hand"`
+	Reserved  int32 `json:"reserved"`
+	Available int32 `json:"available"`
+}
+
+// ProductDetails is a product together with its stock levels, variants and images
+type ProductDetails struct {
+	repo.Product
+	StockLevels StockLevels   `json:"stock_levels"`
+	Variants    []Variant     `json:"variants"`
+	Images      []media.Image `json:"images"`
+}
+
+// MarshalJSON is needed because the embedded product's MarshalJSON would otherwise
+// be promoted and drop the other fields. They are appended to the product object
+func (d ProductDetails) MarshalJSON() ([]byte, error) {
+	return appendFields(d.Product, "stock_levels", d.StockLevels, "variants", d.Variants, "images", d.Images)
+}
+
+// ProductListing is a product of the product list with its variants grouped under it
+type ProductListing struct {
+	repo.Product
+	Variants []Variant `json:"variants"`
+}
+
+func (l ProductListing) MarshalJSON() ([]byte, error) {
+	return appendFields(l.Product, "variants", l.Variants)
+}
+
+// appendFields writes the product object followed by the given name, value pairs
+func appendFields(product repo.Product, fields ...any) ([]byte, error) {
+	out, err := json.Marshal(product)
+	if err != nil {
+		return nil, err
+	}
+	out = out[:len(out)-1]
+
+	for i := 0; i+1 < len(fields); i += 2 {
+		name, err := json.Marshal(fields[i])
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(fields[i+1])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ',')
+		out = append(out, name...)
+		out = append(out, ':')
+		out = append(out, value...)
+	}
+	return append(out, '}'), nil
+}
+
+type CreateOptionTypeRequest struct {
+	Name string `json:"name"`
+}
+
+// CreateVariantRequest adds a SKU to a product. Price overrides the product price and must be in
+// the product's currency, Options names a value for option types such as {"size": "M", "colour": "red"}
+type CreateVariantRequest struct {
+	SKU      string            `json:"sku"`
+	Price    *money.Money      `json:"price"`
+	Stock    int32             `json:"stock"`
+	Position int32             `json:"position"`
+	Options  map[string]string `json:"options"`
+}
+
+// UpdateVariantRequest replaces the details of a variant, its stock only changes through adjustments
+type UpdateVariantRequest struct {
+	SKU      string            `json:"sku"`
+	Price    *money.Money      `json:"price"`
+	Position int32             `json:"position"`
+	Options  map[string]string `json:"options"`
+}
+
+// Variant is a SKU of a product. Price is its own price, or the product's when OwnPrice is false.
+// StockLevels is only filled in for a single product
+type Variant struct {
+	ID          int64             `json:"id"`
+	ProductID   int64             `json:"product_id"`
+	SKU         string            `json:"sku"`
+	Price       money.Money       `json:"price"`
+	OwnPrice    bool              `json:"own_price"`
+	Stock       int32             `json:"stock"`
+	StockLevels *StockLevels      `json:"stock_levels,omitempty"`
+	Position    int32             `json:"position"`
+	Options     map[string]string `json:"options"`
+	CreatedAt   pgtype.Timestamp  `json:"created_at"`
+	UpdatedAt   pgtype.Timestamp  `json:"updated_at"`
+}
+
+// bulk import and export formats
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// ProductRecord is a product as it appears in imports and exports. In an import, fields that are
+// left out keep the current value of an existing product
+type ProductRecord struct {
+	SKU         *string      `json:"sku"`
+	Name        *string      `json:"name"`
+	Description *string      `json:"description"`
+	Price       *money.Money `json:"price"`
+	Stock       *int32       `json:"stock"`
+	TaxClass    *string      `json:"tax_class"`
+	WeightGrams *int32       `json:"weight_grams"`
+	LengthMm    *int32       `json:"length_mm"`
+	WidthMm     *int32       `json:"width_mm"`
+	HeightMm    *int32       `json:"height_mm"`
+}
+
+// ImportReport sums up an import. With errors nothing is written, a dry run never writes
+// and reports what an import would do
+type ImportReport struct {
+	DryRun    bool             `json:"dry_run"`
+	Rows      int              `json:"rows"`
+	Created   int              `json:"created"`
+	Updated   int              `json:"updated"`
+	Unchanged int              `json:"unchanged"`
+	Errors    []ImportRowError `json:"errors"`
+}
+
+// ImportRowError is a problem with one row, Line is the line of the file it starts on
+type ImportRowError struct {
+	Line    int    `json:"line"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,639 @@
+package products
+
+import (
+	"context"
+	"database/sql"
+	"ecomApis/internals/inventory"
+	"ecomApis/internals/money"
+	"ecomApis/internals/outbox"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// postgres error codes
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+// option type names are lowercase words joined by underscores, e.g. size or sleeve_length
+var optionTypePattern = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+
+func (s *ProductService) CreateOptionType(ctx context.Context, req CreateOptionTypeRequest) (repo.OptionType, error) {
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if !optionTypePattern.MatchString(name) {
+		return repo.OptionType{}, &utils.ValidationError{
+			Field:   "name",
+			Message: "must be lowercase letters and digits separated by single underscores",
+		}
+	}
+
+	optionType, err := s.repo.CreateOptionType(ctx, name)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return repo.OptionType{}, &utils.AlreadyExistsError{Resource: "Option type", ID: name}
+		}
+		return repo.OptionType{}, &utils.DatabaseError{Query: "CreateOptionType", Err: err}
+	}
+	return optionType, nil
+}
+
+func (s *ProductService) ListOptionTypes(ctx context.Context) ([]repo.OptionType, error) {
+	optionTypes, err := s.repo.ListOptionTypes(ctx)
+	if err != nil {
+		return nil, &utils.DatabaseError{Query: "ListOptionTypes", Err: err}
+	}
+	if optionTypes == nil {
+		optionTypes = []repo.OptionType{}
+	}
+	return optionTypes, nil
+}
+
+// DeleteOptionType removes an option type no variant uses
+func (s *ProductService) DeleteOptionType(ctx context.Context, id int64) error {
+	deleted, err := s.repo.DeleteOptionType(ctx, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			return &utils.ValidationError{Field: "id", Message: "option type is used by variants"}
+		}
+		return &utils.DatabaseError{Query: "DeleteOptionType", Err: err}
+	}
+	if deleted == 0 {
+		return &utils.NotFoundError{Resource: "Option type", ID: strconv.FormatInt(id, 10)}
+	}
+	return nil
+}
+
+// ListVariants returns the variants of a product sorted by position
+func (s *ProductService) ListVariants(ctx context.Context, productID int64) ([]Variant, error) {
+	product, err := s.FindProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := variantsOf(ctx, s.repo, []repo.Product{product})
+	if err != nil {
+		return nil, err
+	}
+	return variants[product.ID], nil
+}
+
+// CreateVariant adds a variant to a product and records its initial stock in the inventory ledger
+func (s *ProductService) CreateVariant(ctx context.Context, productID int64, req CreateVariantRequest, actor string) (Variant, error) {
+	sku := strings.TrimSpace(req.SKU)
+	if sku == "" {
+		return Variant{}, &utils.ValidationError{Field: "sku", Message: "cannot be empty"}
+	}
+	if req.Stock < 0 {
+		return Variant{}, &utils.ValidationError{Field: "stock", Message: "cannot be negative"}
+	}
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return Variant{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	// lock the product so two variants with the same options cannot be added at once
+	product, err := lockProduct(ctx, qtx, productID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return Variant{}, err
+	}
+
+	price, err := variantPrice(product, req.Price)
+	if err != nil {
+		tx.Rollback(ctx)
+		return Variant{}, err
+	}
+
+	variant, err := qtx.CreateVariant(ctx, repo.CreateVariantParams{
+		ProductID: product.ID,
+		Sku:       sku,
+		Price:     price,
+		Stock:     req.Stock,
+		Position:  req.Position,
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return Variant{}, &utils.AlreadyExistsError{Resource: "Variant", ID: sku}
+		}
+		return Variant{}, &utils.DatabaseError{Query: "CreateVariant", Err: err}
+	}
+
+	options, err := setVariantOptions(ctx, qtx, product.ID, variant.ID, req.Options)
+	if err != nil {
+		tx.Rollback(ctx)
+		return Variant{}, err
+	}
+
+	err = inventory.Record(ctx, qtx, repo.AddInventoryMovementParams{
+		ProductID:  product.ID,
+		VariantID:  pgtype.Int8{Int64: variant.ID, Valid: true},
+		Quantity:   variant.Stock,
+		StockAfter: variant.Stock,
+		Reason:     inventory.ReasonInitialStock,
+		Actor:      actor,
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		return Variant{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Variant{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return newVariant(product, variant, options), nil
+}
+
+// UpdateVariant replaces the SKU, price, position and options of a variant
+func (s *ProductService) UpdateVariant(ctx context.Context, productID, variantID int64, req UpdateVariantRequest) (Variant, error) {
+	sku := strings.TrimSpace(req.SKU)
+	if sku == "" {
+		return Variant{}, &utils.ValidationError{Field: "sku", Message: "cannot be empty"}
+	}
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return Variant{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	product, err := lockProduct(ctx, qtx, productID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return Variant{}, err
+	}
+
+	current, err := getVariant(ctx, qtx, productID, variantID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return Variant{}, err
+	}
+
+	price, err := variantPrice(product, req.Price)
+	if err != nil {
+		tx.Rollback(ctx)
+		return Variant{}, err
+	}
+
+	variant, err := qtx.UpdateVariant(ctx, repo.UpdateVariantParams{
+		Sku:      sku,
+		Price:    price,
+		Position: req.Position,
+		ID:       variantID,
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return Variant{}, &utils.AlreadyExistsError{Resource: "Variant", ID: sku}
+		}
+		return Variant{}, &utils.DatabaseError{Query: "UpdateVariant", Err: err}
+	}
+
+	err = qtx.DeleteVariantOptionValues(ctx, variantID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return Variant{}, &utils.DatabaseError{Query: "DeleteVariantOptionValues", Err: err}
+	}
+	options, err := setVariantOptions(ctx, qtx, product.ID, variantID, req.Options)
+	if err != nil {
+		tx.Rollback(ctx)
+		return Variant{}, err
+	}
+
+	// only the price a customer pays counts, dropping an override that matched the product price changes nothing
+	previousPrice, newPrice := newVariant(product, current, nil).Price, newVariant(product, variant, nil).Price
+	if previousPrice != newPrice {
+		err = outbox.Write(ctx, qtx, outbox.PriceChangedEvent(outbox.PriceChanged{
+			ProductID:     product.ID,
+			VariantID:     &variant.ID,
+			PreviousPrice: previousPrice,
+			Price:         newPrice,
+		}))
+		if err != nil {
+			tx.Rollback(ctx)
+			return Variant{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Variant{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return newVariant(product, variant, options), nil
+}
+
+// DeleteVariant removes a variant. Variants with active stock reservations cannot be deleted,
+// order lines and finished reservations keep their rows and lose the link to the variant
+func (s *ProductService) DeleteVariant(ctx context.Context, productID, variantID int64) error {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	// orders lock the product before its variants, so we do the same
+	if _, err := lockProduct(ctx, qtx, productID); err != nil {
+		tx.Rollback(ctx)
+		return err
+	}
+
+	variant, err := qtx.GetVariantForUpdate(ctx, variantID)
+	if err != nil && err != sql.ErrNoRows && err != pgx.ErrNoRows {
+		tx.Rollback(ctx)
+		return &utils.DatabaseError{Query: "GetVariantForUpdate", Err: err}
+	}
+	if err != nil || variant.ProductID != productID {
+		tx.Rollback(ctx)
+		return &utils.NotFoundError{Resource: "Variant", ID: strconv.FormatInt(variantID, 10)}
+	}
+
+	reserved, err := qtx.GetVariantReservedQuantity(ctx, pgtype.Int8{Int64: variantID, Valid: true})
+	if err != nil {
+		tx.Rollback(ctx)
+		return &utils.DatabaseError{Query: "GetVariantReservedQuantity", Err: err}
+	}
+	if reserved > 0 {
+		tx.Rollback(ctx)
+		return &utils.ValidationError{Field: "id", Message: "variant has active stock reservations"}
+	}
+
+	if _, err := qtx.DeleteVariant(ctx, variantID); err != nil {
+		tx.Rollback(ctx)
+		return &utils.DatabaseError{Query: "DeleteVariant", Err: err}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// AdjustVariantStock applies a signed stock change to a variant and records it in the inventory ledger.
+// Stock is never allowed to go below what is reserved
+func (s *ProductService) AdjustVariantStock(ctx context.Context, productID, variantID int64, req StockAdjustmentRequest, actor string) (Variant, error) {
+	// --- Validation ---
+	if req.Quantity == 0 {
+		return Variant{}, &utils.ValidationError{
+			Field:   "Quantity",
+			Message: "cannot be zero",
+		}
+	}
+
+	if !validStockReasons[req.Reason] {
+		return Variant{}, &utils.ValidationError{
+			Field:   "Reason",
+			Message: fmt.Sprintf("unknown reason code '%s'", req.Reason),
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return Variant{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	// orders lock the product before its variants, so we do the same
+	product, err := lockProduct(ctx, qtx, productID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return Variant{}, err
+	}
+
+	variant, err := qtx.GetVariantForUpdate(ctx, variantID)
+	if err != nil && err != sql.ErrNoRows && err != pgx.ErrNoRows {
+		tx.Rollback(ctx)
+		return Variant{}, &utils.DatabaseError{Query: "GetVariantForUpdate", Err: err}
+	}
+	if err != nil || variant.ProductID != productID {
+		tx.Rollback(ctx)
+		return Variant{}, &utils.NotFoundError{Resource: "Variant", ID: strconv.FormatInt(variantID, 10)}
+	}
+
+	// stock held by unpaid orders cannot be written off
+	reserved, err := qtx.GetVariantReservedQuantity(ctx, pgtype.Int8{Int64: variantID, Valid: true})
+	if err != nil {
+		tx.Rollback(ctx)
+		return Variant{}, &utils.DatabaseError{Query: "GetVariantReservedQuantity", Err: err}
+	}
+
+	if variant.Stock+req.Quantity < reserved {
+		tx.Rollback(ctx)
+		return Variant{}, &utils.ValidationError{
+			Field:   "Quantity",
+			Message: fmt.Sprintf("not enough unreserved stock for variant %d, %d units are reserved", variantID, reserved),
+		}
+	}
+
+	variant, err = qtx.AdjustVariantStock(ctx, repo.AdjustVariantStockParams{
+		Delta: req.Quantity,
+		ID:    variantID,
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return Variant{}, &utils.ValidationError{
+				Field:   "Quantity",
+				Message: fmt.Sprintf("not enough stock for variant %d", variantID),
+			}
+		}
+		return Variant{}, &utils.DatabaseError{Query: "AdjustVariantStock", Err: err}
+	}
+
+	err = inventory.Record(ctx, qtx, repo.AddInventoryMovementParams{
+		ProductID:  productID,
+		VariantID:  pgtype.Int8{Int64: variantID, Valid: true},
+		Quantity:   req.Quantity,
+		StockAfter: variant.Stock,
+		Reason:     req.Reason,
+		Actor:      actor,
+		Note:       req.Note,
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		return Variant{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Variant{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	slog.Info("variant stock adjusted",
+		"product_id", productID,
+		"variant_id", variantID,
+		"quantity", req.Quantity,
+		"reason", req.Reason,
+		"note", req.Note,
+	)
+
+	values, err := s.repo.ListVariantOptionValues(ctx, []int64{variantID})
+	if err != nil {
+		return Variant{}, &utils.DatabaseError{Query: "ListVariantOptionValues", Err: err}
+	}
+	options := make(map[string]string, len(values))
+	for _, v := range values {
+		options[v.Name] = v.Value
+	}
+	return newVariant(product, variant, options), nil
+}
+
+// checkVariantCurrency refuses to change the currency of a product whose variants have their own
+// prices, those are stored in the product's currency and would silently change meaning
+func checkVariantCurrency(ctx context.Context, q *repo.Queries, product repo.Product, currency string) error {
+	if product.Currency == currency {
+		return nil
+	}
+
+	variants, err := q.ListProductVariants(ctx, product.ID)
+	if err != nil {
+		return &utils.DatabaseError{Query: "ListProductVariants", Err: err}
+	}
+	for _, v := range variants {
+		if v.Price.Valid {
+			return &utils.ValidationError{
+				Field:   "Currency",
+				Message: fmt.Sprintf("variants of product %d have their own prices in %s, change those first", product.ID, product.Currency),
+			}
+		}
+	}
+	return nil
+}
+
+func lockProduct(ctx context.Context, qtx *repo.Queries, id int64) (repo.Product, error) {
+	product, err := qtx.GetProductForUpdate(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows || err == pgx.ErrNoRows {
+			return repo.Product{}, &utils.NotFoundError{Resource: "Product", ID: strconv.FormatInt(id, 10)}
+		}
+		return repo.Product{}, &utils.DatabaseError{Query: "GetProductForUpdate", Err: err}
+	}
+	return product, nil
+}
+
+// getVariant loads a variant and checks that it belongs to the product
+func getVariant(ctx context.Context, q *repo.Queries, productID, variantID int64) (repo.ProductVariant, error) {
+	variant, err := q.GetVariant(ctx, variantID)
+	if err != nil && err != sql.ErrNoRows && err != pgx.ErrNoRows {
+		return repo.ProductVariant{}, &utils.DatabaseError{Query: "GetVariant", Err: err}
+	}
+	if err != nil || variant.ProductID != productID {
+		return repo.ProductVariant{}, &utils.NotFoundError{Resource: "Variant", ID: strconv.FormatInt(variantID, 10)}
+	}
+	return variant, nil
+}
+
+// variantPrice checks a price override, it has to be in the product's currency
+func variantPrice(product repo.Product, price *money.Money) (pgtype.Int8, error) {
+	if price == nil {
+		return pgtype.Int8{}, nil
+	}
+	if price.Amount <= 0 {
+		return pgtype.Int8{}, &utils.ValidationError{Field: "price", Message: "must be a positive amount"}
+	}
+	if money.NormalizeCurrency(price.Currency) != product.Currency {
+		return pgtype.Int8{}, &utils.ValidationError{
+			Field:   "price",
+			Message: fmt.Sprintf("must be in the product's currency %s", product.Currency),
+		}
+	}
+	return pgtype.Int8{Int64: price.Amount, Valid: true}, nil
+}
+
+// setVariantOptions stores the option values of a variant. Every variant of a product has to use
+// the same option types, and no two variants can have the same values
+func setVariantOptions(ctx context.Context, qtx *repo.Queries, productID, variantID int64, options map[string]string) (map[string]string, error) {
+	normalized := make(map[string]string, len(options))
+	names := make([]string, 0, len(options))
+	for name, value := range options {
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return nil, &utils.ValidationError{Field: "options", Message: fmt.Sprintf("value of '%s' cannot be empty", name)}
+		}
+		normalized[name] = value
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	optionTypes, err := qtx.ListOptionTypesByNames(ctx, names)
+	if err != nil {
+		return nil, &utils.DatabaseError{Query: "ListOptionTypesByNames", Err: err}
+	}
+	if len(optionTypes) != len(names) {
+		known := make(map[string]bool, len(optionTypes))
+		for _, o := range optionTypes {
+			known[o.Name] = true
+		}
+		for _, name := range names {
+			if !known[name] {
+				return nil, &utils.NotFoundError{Resource: "Option type", ID: name}
+			}
+		}
+	}
+
+	siblings, err := qtx.ListProductVariants(ctx, productID)
+	if err != nil {
+		return nil, &utils.DatabaseError{Query: "ListProductVariants", Err: err}
+	}
+	siblingIDs := make([]int64, 0, len(siblings))
+	for _, v := range siblings {
+		if v.ID != variantID {
+			siblingIDs = append(siblingIDs, v.ID)
+		}
+	}
+	values, err := qtx.ListVariantOptionValues(ctx, siblingIDs)
+	if err != nil {
+		return nil, &utils.DatabaseError{Query: "ListVariantOptionValues", Err: err}
+	}
+	siblingOptions := make(map[int64]map[string]string, len(siblingIDs))
+	for _, id := range siblingIDs {
+		siblingOptions[id] = map[string]string{}
+	}
+	for _, v := range values {
+		siblingOptions[v.VariantID][v.Name] = v.Value
+	}
+
+	for _, other := range siblingOptions {
+		if !sameKeys(other, normalized) {
+			return nil, &utils.ValidationError{
+				Field:   "options",
+				Message: fmt.Sprintf("must set the same option types as the other variants of product %d", productID),
+			}
+		}
+		if sameValues(other, normalized) && len(normalized) > 0 {
+			return nil, &utils.AlreadyExistsError{Resource: "Variant with these options", ID: formatOptions(names, normalized)}
+		}
+	}
+
+	for _, o := range optionTypes {
+		err = qtx.AddVariantOptionValue(ctx, repo.AddVariantOptionValueParams{
+			VariantID:    variantID,
+			OptionTypeID: o.ID,
+			Value:        normalized[o.Name],
+		})
+		if err != nil {
+			return nil, &utils.DatabaseError{Query: "AddVariantOptionValue", Err: err}
+		}
+	}
+	return normalized, nil
+}
+
+func sameKeys(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func sameValues(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// formatOptions writes options as size=M,colour=red in the order of names
+func formatOptions(names []string, options map[string]string) string {
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+"="+options[name])
+	}
+	return strings.Join(parts, ",")
+}
+
+// variantsOf loads the variants of the products with their options, keyed by product id.
+// Every product gets an entry, an empty list when it has no variants
+func variantsOf(ctx context.Context, q *repo.Queries, products []repo.Product) (map[int64][]Variant, error) {
+	out := make(map[int64][]Variant, len(products))
+	if len(products) == 0 {
+		return out, nil
+	}
+
+	byID := make(map[int64]repo.Product, len(products))
+	ids := make([]int64, 0, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+		ids = append(ids, p.ID)
+		out[p.ID] = []Variant{}
+	}
+
+	variants, err := q.ListVariantsByProductIDs(ctx, ids)
+	if err != nil {
+		return nil, &utils.DatabaseError{Query: "ListVariantsByProductIDs", Err: err}
+	}
+	if len(variants) == 0 {
+		return out, nil
+	}
+
+	variantIDs := make([]int64, 0, len(variants))
+	for _, v := range variants {
+		variantIDs = append(variantIDs, v.ID)
+	}
+	values, err := q.ListVariantOptionValues(ctx, variantIDs)
+	if err != nil {
+		return nil, &utils.DatabaseError{Query: "ListVariantOptionValues", Err: err}
+	}
+	options := make(map[int64]map[string]string, len(variants))
+	for _, v := range values {
+		if options[v.VariantID] == nil {
+			options[v.VariantID] = map[string]string{}
+		}
+		options[v.VariantID][v.Name] = v.Value
+	}
+
+	for _, v := range variants {
+		o := options[v.ID]
+		if o == nil {
+			o = map[string]string{}
+		}
+		out[v.ProductID] = append(out[v.ProductID], newVariant(byID[v.ProductID], v, o))
+	}
+	return out, nil
+}
+
+func newVariant(product repo.Product, v repo.ProductVariant, options map[string]string) Variant {
+	price := product.PriceMoney()
+	if v.Price.Valid {
+		price.Amount = v.Price.Int64
+	}
+	return Variant{
+		ID:        v.ID,
+		ProductID: v.ProductID,
+		SKU:       v.Sku,
+		Price:     price,
+		OwnPrice:  v.Price.Valid,
+		Stock:     v.Stock,
+		Position:  v.Position,
+		Options:   options,
+		CreatedAt: v.CreatedAt,
+		UpdatedAt: v.UpdatedAt,
+	}
+}
@@ -0,0 +1,134 @@
+package promotions
+
+import (
+	"ecomApis/internals/money"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"fmt"
+	"math/big"
+)
+
+// calculate works out the discount of every line. scope holds the products the promotion is
+// limited to, a nil scope means every product is eligible
+func calculate(promo repo.Promotion, scope map[int64]bool, lines []Line, currency string) (Discount, error) {
+	discount := Discount{
+		Promotion: promo,
+		Lines:     make([]money.Money, len(lines)),
+		Total:     money.Zero(currency),
+	}
+	for i := range discount.Lines {
+		discount.Lines[i] = money.Zero(currency)
+	}
+
+	// fixed amounts and minimum spends only make sense in the promotion's currency
+	if (promo.AmountOff.Valid || promo.MinSpend.Valid) && promo.Currency.String != currency {
+		return Discount{}, &utils.ValidationError{
+			Field:   "coupon_code",
+			Message: fmt.Sprintf("coupon is only valid for orders in %s", promo.Currency.String),
+		}
+	}
+
+	lineTotals := make([]money.Money, len(lines))
+	eligible := make([]bool, len(lines))
+	subtotal := money.Zero(currency)
+	for i, line := range lines {
+		total, err := line.UnitPrice.Mul(int64(line.Quantity))
+		if err == nil && (scope == nil || scope[line.ProductID]) {
+			eligible[i] = true
+			subtotal, err = subtotal.Add(total)
+		}
+		if err != nil {
+			return Discount{}, &utils.ValidationError{Field: "total", Message: "order total is too large"}
+		}
+		lineTotals[i] = total
+	}
+
+	if subtotal.IsZero() {
+		return Discount{}, &utils.ValidationError{Field: "coupon_code", Message: "coupon does not apply to any item of the order"}
+	}
+	if promo.MinSpend.Valid && subtotal.Amount < promo.MinSpend.Int64 {
+		return Discount{}, &utils.ValidationError{
+			Field:   "coupon_code",
+			Message: fmt.Sprintf("coupon needs a minimum spend of %s on eligible items", money.Money{Amount: promo.MinSpend.Int64, Currency: currency}),
+		}
+	}
+
+	switch promo.Kind {
+	case KindPercentage:
+		// rounded down per line so the discount never exceeds the advertised percentage
+		rate := big.NewRat(int64(promo.PercentOff.Int32), 100)
+		for i, total := range lineTotals {
+			if !eligible[i] {
+				continue
+			}
+			off, err := total.Convert(currency, rate, money.Rounding{Mode: money.RoundDown})
+			if err != nil {
+				return Discount{}, &utils.ValidationError{Field: "total", Message: "order total is too large"}
+			}
+			discount.Lines[i] = off
+		}
+
+	case KindFixedAmount:
+		allocateFixed(discount.Lines, lineTotals, eligible, subtotal, promo.AmountOff.Int64)
+
+	case KindBuyXGetY:
+		// every full group of buy + get units of one product makes get units free
+		group := int64(promo.BuyQuantity.Int32 + promo.GetQuantity.Int32)
+		for i, line := range lines {
+			if !eligible[i] {
+				continue
+			}
+			free := int64(line.Quantity) / group * int64(promo.GetQuantity.Int32)
+			off, err := line.UnitPrice.Mul(free)
+			if err != nil {
+				return Discount{}, &utils.ValidationError{Field: "total", Message: "order total is too large"}
+			}
+			discount.Lines[i] = off
+		}
+	}
+
+	for _, off := range discount.Lines {
+		total, err := discount.Total.Add(off)
+		if err != nil {
+			return Discount{}, &utils.ValidationError{Field: "total", Message: "order total is too large"}
+		}
+		discount.Total = total
+	}
+
+	if discount.Total.IsZero() {
+		return Discount{}, &utils.ValidationError{Field: "coupon_code", Message: "coupon does not apply to this order"}
+	}
+	return discount, nil
+}
+
+// allocateFixed spreads a fixed discount over the eligible lines in proportion to their totals.
+// Shares are rounded down and the remaining minor units go to lines that still have room
+func allocateFixed(out, lineTotals []money.Money, eligible []bool, subtotal money.Money, amountOff int64) {
+	if amountOff > subtotal.Amount {
+		amountOff = subtotal.Amount
+	}
+
+	allocated := int64(0)
+	for i, total := range lineTotals {
+		if !eligible[i] {
+			continue
+		}
+		share := new(big.Int).Mul(big.NewInt(amountOff), big.NewInt(total.Amount))
+		share.Quo(share, big.NewInt(subtotal.Amount))
+		out[i].Amount = share.Int64()
+		allocated += share.Int64()
+	}
+
+	remaining := amountOff - allocated
+	for i, total := range lineTotals {
+		if remaining == 0 {
+			break
+		}
+		if !eligible[i] {
+			continue
+		}
+		extra := min(total.Amount-out[i].Amount, remaining)
+		out[i].Amount += extra
+		remaining -= extra
+	}
+}
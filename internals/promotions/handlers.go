@@ -0,0 +1,100 @@
+package promotions
+
+import (
+	"ecomApis/internals/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type PromotionHandler struct {
+	service *PromotionService
+}
+
+func NewPromotionHandler(s *PromotionService) *PromotionHandler {
+	return &PromotionHandler{
+		service: s,
+	}
+}
+
+func (h *PromotionHandler) CreatePromotion(w http.ResponseWriter, r *http.Request) {
+	var req CreatePromotionRequest
+	err := utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	promo, err := h.service.CreatePromotion(r.Context(), req)
+	if err != nil {
+		writePromotionError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, promo)
+}
+
+func (h *PromotionHandler) ListPromotions(w http.ResponseWriter, r *http.Request) {
+	limit, err := utils.ParseLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		writePromotionError(w, err)
+		return
+	}
+
+	page, err := h.service.ListPromotions(r.Context(), limit, r.URL.Query().Get("cursor"))
+	if err != nil {
+		writePromotionError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, page)
+}
+
+func (h *PromotionHandler) GetPromotion(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid promotion id"})
+		return
+	}
+
+	promo, err := h.service.GetPromotion(r.Context(), id)
+	if err != nil {
+		writePromotionError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, promo)
+}
+
+// DeactivatePromotion handles POST /promotions/{id}/deactivate
+func (h *PromotionHandler) DeactivatePromotion(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid promotion id"})
+		return
+	}
+
+	promo, err := h.service.DeactivatePromotion(r.Context(), id)
+	if err != nil {
+		writePromotionError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, promo)
+}
+
+func writePromotionError(w http.ResponseWriter, err error) {
+	switch e := err.(type) {
+	case *utils.ValidationError:
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": e.Error()})
+	case *utils.NotFoundError:
+		utils.WriteJSON(w, http.StatusNotFound, map[string]string{"error": e.Error()})
+	case *utils.AlreadyExistsError:
+		utils.WriteJSON(w, http.StatusConflict, map[string]string{"error": e.Error()})
+	case *utils.DatabaseError:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": e.Error()})
+	default:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,363 @@
+package promotions
+
+import (
+	"context"
+	"database/sql"
+	"ecomApis/internals/money"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// postgres error codes
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+type PromotionService struct {
+	repo *repo.Queries
+	db   *pgxpool.Pool
+}
+
+func NewPromotionService(r *repo.Queries, db *pgxpool.Pool) *PromotionService {
+	return &PromotionService{
+		repo: r,
+		db:   db,
+	}
+}
+
+// NormalizeCode makes coupon codes case-insensitive
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func (s *PromotionService) CreatePromotion(ctx context.Context, req CreatePromotionRequest) (PromotionDetails, error) {
+	params, err := validatePromotion(req)
+	if err != nil {
+		return PromotionDetails{}, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return PromotionDetails{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	promo, err := qtx.CreatePromotion(ctx, params)
+	if err != nil {
+		tx.Rollback(ctx)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return PromotionDetails{}, &utils.AlreadyExistsError{Resource: "Promotion", ID: params.Code}
+		}
+		return PromotionDetails{}, &utils.DatabaseError{Query: "CreatePromotion", Err: err}
+	}
+
+	for _, productID := range req.ProductIDs {
+		err = qtx.AddPromotionProduct(ctx, repo.AddPromotionProductParams{
+			PromotionID: promo.ID,
+			ProductID:   productID,
+		})
+		if err != nil {
+			tx.Rollback(ctx)
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+				return PromotionDetails{}, &utils.NotFoundError{Resource: "Product", ID: strconv.FormatInt(productID, 10)}
+			}
+			return PromotionDetails{}, &utils.DatabaseError{Query: "AddPromotionProduct", Err: err}
+		}
+	}
+
+	for _, categoryID := range req.CategoryIDs {
+		err = qtx.AddPromotionCategory(ctx, repo.AddPromotionCategoryParams{
+			PromotionID: promo.ID,
+			CategoryID:  categoryID,
+		})
+		if err != nil {
+			tx.Rollback(ctx)
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+				return PromotionDetails{}, &utils.NotFoundError{Resource: "Category", ID: strconv.FormatInt(categoryID, 10)}
+			}
+			return PromotionDetails{}, &utils.DatabaseError{Query: "AddPromotionCategory", Err: err}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return PromotionDetails{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	productIDs := req.ProductIDs
+	if productIDs == nil {
+		productIDs = []int64{}
+	}
+	categoryIDs := req.CategoryIDs
+	if categoryIDs == nil {
+		categoryIDs = []int64{}
+	}
+	return PromotionDetails{Promotion: promo, ProductIDs: productIDs, CategoryIDs: categoryIDs}, nil
+}
+
+// validatePromotion checks that the fields needed by the kind are set and builds the insert
+func validatePromotion(req CreatePromotionRequest) (repo.CreatePromotionParams, error) {
+	params := repo.CreatePromotionParams{
+		Code: NormalizeCode(req.Code),
+		Name: req.Name,
+		Kind: req.Kind,
+	}
+	if params.Code == "" {
+		return params, &utils.ValidationError{Field: "code", Message: "cannot be empty"}
+	}
+
+	// amount_off and min_spend share one currency column
+	setCurrency := func(field string, m money.Money) error {
+		normalized, err := m.Normalize()
+		if err != nil {
+			return &utils.ValidationError{Field: field, Message: fmt.Sprintf("'%s' is not a supported ISO-4217 currency", m.Currency)}
+		}
+		if params.Currency.Valid && params.Currency.String != normalized.Currency {
+			return &utils.ValidationError{Field: field, Message: "must use the same currency as amount_off"}
+		}
+		params.Currency = pgtype.Text{String: normalized.Currency, Valid: true}
+		return nil
+	}
+
+	switch req.Kind {
+	case KindPercentage:
+		if req.PercentOff == nil || *req.PercentOff < 1 || *req.PercentOff > 100 {
+			return params, &utils.ValidationError{Field: "percent_off", Message: "must be between 1 and 100"}
+		}
+		params.PercentOff = pgtype.Int4{Int32: *req.PercentOff, Valid: true}
+	case KindFixedAmount:
+		if req.AmountOff == nil || req.AmountOff.Amount <= 0 {
+			return params, &utils.ValidationError{Field: "amount_off", Message: "must be a positive amount"}
+		}
+		if err := setCurrency("amount_off", *req.AmountOff); err != nil {
+			return params, err
+		}
+		params.AmountOff = pgtype.Int8{Int64: req.AmountOff.Amount, Valid: true}
+	case KindBuyXGetY:
+		if req.BuyQuantity == nil || *req.BuyQuantity <= 0 {
+			return params, &utils.ValidationError{Field: "buy_quantity", Message: "must be positive"}
+		}
+		if req.GetQuantity == nil || *req.GetQuantity <= 0 {
+			return params, &utils.ValidationError{Field: "get_quantity", Message: "must be positive"}
+		}
+		params.BuyQuantity = pgtype.Int4{Int32: *req.BuyQuantity, Valid: true}
+		params.GetQuantity = pgtype.Int4{Int32: *req.GetQuantity, Valid: true}
+	default:
+		return params, &utils.ValidationError{
+			Field:   "kind",
+			Message: fmt.Sprintf("must be one of %s, %s, %s", KindPercentage, KindFixedAmount, KindBuyXGetY),
+		}
+	}
+
+	if req.MinSpend != nil {
+		if req.MinSpend.Amount <= 0 {
+			return params, &utils.ValidationError{Field: "min_spend", Message: "must be a positive amount"}
+		}
+		if err := setCurrency("min_spend", *req.MinSpend); err != nil {
+			return params, err
+		}
+		params.MinSpend = pgtype.Int8{Int64: req.MinSpend.Amount, Valid: true}
+	}
+
+	if req.StartsAt != nil {
+		params.StartsAt = pgtype.Timestamp{Time: req.StartsAt.UTC(), Valid: true}
+	}
+	if req.EndsAt != nil {
+		params.EndsAt = pgtype.Timestamp{Time: req.EndsAt.UTC(), Valid: true}
+	}
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return params, &utils.ValidationError{Field: "ends_at", Message: "must be after starts_at"}
+	}
+
+	if req.UsageLimit != nil {
+		if *req.UsageLimit <= 0 {
+			return params, &utils.ValidationError{Field: "usage_limit", Message: "must be positive"}
+		}
+		params.UsageLimit = pgtype.Int4{Int32: *req.UsageLimit, Valid: true}
+	}
+	if req.PerCustomerLimit != nil {
+		if *req.PerCustomerLimit <= 0 {
+			return params, &utils.ValidationError{Field: "per_customer_limit", Message: "must be positive"}
+		}
+		params.PerCustomerLimit = pgtype.Int4{Int32: *req.PerCustomerLimit, Valid: true}
+	}
+
+	return params, nil
+}
+
+func (s *PromotionService) GetPromotion(ctx context.Context, id int64) (PromotionDetails, error) {
+	promo, err := s.repo.GetPromotion(ctx, id)
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return PromotionDetails{}, &utils.NotFoundError{Resource: "Promotion", ID: strconv.FormatInt(id, 10)}
+		}
+		return PromotionDetails{}, &utils.DatabaseError{Query: "GetPromotion", Err: err}
+	}
+
+	productIDs, err := s.repo.ListPromotionProducts(ctx, id)
+	if err != nil {
+		return PromotionDetails{}, &utils.DatabaseError{Query: "ListPromotionProducts", Err: err}
+	}
+	if productIDs == nil {
+		productIDs = []int64{}
+	}
+
+	categoryIDs, err := s.repo.ListPromotionCategories(ctx, id)
+	if err != nil {
+		return PromotionDetails{}, &utils.DatabaseError{Query: "ListPromotionCategories", Err: err}
+	}
+	if categoryIDs == nil {
+		categoryIDs = []int64{}
+	}
+
+	return PromotionDetails{Promotion: promo, ProductIDs: productIDs, CategoryIDs: categoryIDs}, nil
+}
+
+func (s *PromotionService) ListPromotions(ctx context.Context, limit int32, cursor string) (utils.Page[repo.Promotion], error) {
+	params := repo.ListPromotionsPageParams{PageLimit: limit + 1}
+
+	if cursor != "" {
+		c, err := utils.DecodeCursor(cursor)
+		if err != nil {
+			return utils.Page[repo.Promotion]{}, err
+		}
+		params.CursorID = pgtype.Int8{Int64: c.ID, Valid: true}
+	}
+
+	promos, err := s.repo.ListPromotionsPage(ctx, params)
+	if err != nil {
+		return utils.Page[repo.Promotion]{}, &utils.DatabaseError{Query: "ListPromotionsPage", Err: err}
+	}
+
+	return utils.NewPage(promos, limit, func(p repo.Promotion) utils.Cursor {
+		return utils.Cursor{ID: p.ID}
+	}), nil
+}
+
+// DeactivatePromotion stops a coupon from being redeemed, past orders keep their discount
+func (s *PromotionService) DeactivatePromotion(ctx context.Context, id int64) (repo.Promotion, error) {
+	promo, err := s.repo.DeactivatePromotion(ctx, id)
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return repo.Promotion{}, &utils.NotFoundError{Resource: "Promotion", ID: strconv.FormatInt(id, 10)}
+		}
+		return repo.Promotion{}, &utils.DatabaseError{Query: "DeactivatePromotion", Err: err}
+	}
+	return promo, nil
+}
+
+// Apply looks up a coupon, checks it can still be used by the customer and works out the
+// discount of every line. It locks the promotion row, so it must run in the order transaction
+// and be followed by Redeem once the order exists
+func Apply(ctx context.Context, qtx *repo.Queries, code, customerRef string, lines []Line) (Discount, error) {
+	code = NormalizeCode(code)
+
+	promo, err := qtx.GetPromotionByCodeForUpdate(ctx, code)
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return Discount{}, &utils.ValidationError{Field: "coupon_code", Message: fmt.Sprintf("unknown coupon code '%s'", code)}
+		}
+		return Discount{}, &utils.DatabaseError{Query: "GetPromotionByCodeForUpdate", Err: err}
+	}
+
+	now := time.Now().UTC()
+	switch {
+	case !promo.IsActive:
+		return Discount{}, &utils.ValidationError{Field: "coupon_code", Message: "coupon is no longer active"}
+	case promo.StartsAt.Valid && now.Before(promo.StartsAt.Time):
+		return Discount{}, &utils.ValidationError{Field: "coupon_code", Message: "coupon is not valid yet"}
+	case promo.EndsAt.Valid && !now.Before(promo.EndsAt.Time):
+		return Discount{}, &utils.ValidationError{Field: "coupon_code", Message: "coupon has expired"}
+	case promo.UsageLimit.Valid && promo.TimesUsed >= promo.UsageLimit.Int32:
+		return Discount{}, &utils.ValidationError{Field: "coupon_code", Message: "coupon has reached its usage limit"}
+	}
+
+	if promo.PerCustomerLimit.Valid {
+		used, err := qtx.CountCustomerRedemptions(ctx, repo.CountCustomerRedemptionsParams{
+			PromotionID: promo.ID,
+			CustomerRef: customerRef,
+		})
+		if err != nil {
+			return Discount{}, &utils.DatabaseError{Query: "CountCustomerRedemptions", Err: err}
+		}
+		if used >= int64(promo.PerCustomerLimit.Int32) {
+			return Discount{}, &utils.ValidationError{Field: "coupon_code", Message: "coupon has already been used the maximum number of times"}
+		}
+	}
+
+	productIDs, err := qtx.ListPromotionProducts(ctx, promo.ID)
+	if err != nil {
+		return Discount{}, &utils.DatabaseError{Query: "ListPromotionProducts", Err: err}
+	}
+	categoryIDs, err := qtx.ListPromotionCategories(ctx, promo.ID)
+	if err != nil {
+		return Discount{}, &utils.DatabaseError{Query: "ListPromotionCategories", Err: err}
+	}
+
+	// a promotion without products or categories applies to everything
+	var scope map[int64]bool
+	if len(productIDs) > 0 || len(categoryIDs) > 0 {
+		scope = make(map[int64]bool, len(productIDs))
+		for _, id := range productIDs {
+			scope[id] = true
+		}
+	}
+	if len(categoryIDs) > 0 {
+		lineProducts := make([]int64, len(lines))
+		for i, line := range lines {
+			lineProducts[i] = line.ProductID
+		}
+		categoryProducts, err := qtx.ListPromotionCategoryProducts(ctx, repo.ListPromotionCategoryProductsParams{
+			PromotionID: promo.ID,
+			ProductIds:  lineProducts,
+		})
+		if err != nil {
+			return Discount{}, &utils.DatabaseError{Query: "ListPromotionCategoryProducts", Err: err}
+		}
+		for _, id := range categoryProducts {
+			scope[id] = true
+		}
+	}
+
+	currency := ""
+	if len(lines) > 0 {
+		currency = lines[0].UnitPrice.Currency
+	}
+	return calculate(promo, scope, lines, currency)
+}
+
+// Redeem counts the use of the coupon against its limits and records it for the order
+func Redeem(ctx context.Context, qtx *repo.Queries, discount Discount, orderID int64, customerRef string) error {
+	_, err := qtx.IncrementPromotionUsage(ctx, discount.Promotion.ID)
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return &utils.ValidationError{Field: "coupon_code", Message: "coupon has reached its usage limit"}
+		}
+		return &utils.DatabaseError{Query: "IncrementPromotionUsage", Err: err}
+	}
+
+	_, err = qtx.CreatePromotionRedemption(ctx, repo.CreatePromotionRedemptionParams{
+		PromotionID: discount.Promotion.ID,
+		OrderID:     orderID,
+		CustomerRef: customerRef,
+		Discount:    discount.Total.Amount,
+		Currency:    discount.Total.Currency,
+	})
+	if err != nil {
+		return &utils.DatabaseError{Query: "CreatePromotionRedemption", Err: err}
+	}
+	return nil
+}
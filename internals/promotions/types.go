@@ -0,0 +1,55 @@
+package promotions
+
+import (
+	"ecomApis/internals/money"
+	"ecomApis/internals/repo"
+	"time"
+)
+
+// promotion kinds
+const (
+	KindPercentage  = "percentage"
+	KindFixedAmount = "fixed_amount"
+	KindBuyXGetY    = "buy_x_get_y"
+)
+
+// CreatePromotionRequest holds the rule of a coupon, which fields are needed depends on Kind.
+// The promotion is limited to ProductIDs plus the products in CategoryIDs and their subcategories,
+// when both are empty it applies to every product
+type CreatePromotionRequest struct {
+	Code             string       `json:"code"`
+	Name             string       `json:"name"`
+	Kind             string       `json:"kind"`
+	PercentOff       *int32       `json:"percent_off"`
+	AmountOff        *money.Money `json:"amount_off"`
+	BuyQuantity      *int32       `json:"buy_quantity"`
+	GetQuantity      *int32       `json:"get_quantity"`
+	MinSpend         *money.Money `json:"min_spend"`
+	StartsAt         *time.Time   `json:"starts_at"`
+	EndsAt           *time.Time   `json:"ends_at"`
+	UsageLimit       *int32       `json:"usage_limit"`
+	PerCustomerLimit *int32       `json:"per_customer_limit"`
+	ProductIDs       []int64      `json:"product_ids"`
+	CategoryIDs      []int64      `json:"category_ids"`
+}
+
+// PromotionDetails is a promotion with the products and categories it is limited to
+type PromotionDetails struct {
+	Promotion   repo.Promotion `json:"promotion"`
+	ProductIDs  []int64        `json:"product_ids"`
+	CategoryIDs []int64        `json:"category_ids"`
+}
+
+// Line is one priced order line a coupon is applied to
+type Line struct {
+	ProductID int64
+	Quantity  int32
+	UnitPrice money.Money
+}
+
+// Discount is the result of applying a coupon, Lines holds the discount of each line in order
+type Discount struct {
+	Promotion repo.Promotion
+	Lines     []money.Money
+	Total     money.Money
+}
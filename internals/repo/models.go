@@ -8,30 +8,456 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-type Order struct {
+type ApiKey struct {
+	ID          int64            `json:"id"`
+	Name        string           `json:"name"`
+	KeyPrefix   string           `json:"key_prefix"`
+	KeyHash     string           `json:"-"`
+	Role        string           `json:"role"`
+	CustomerRef pgtype.Text      `json:"customer_ref"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	LastUsedAt  pgtype.Timestamp `json:"last_used_at"`
+	RevokedAt   pgtype.Timestamp `json:"revoked_at"`
+}
+
+type Cart struct {
 	ID          int64            `json:"id"`
 	CustomerRef string           `json:"customer_ref"`
-	TotalPrice  int32            `json:"total_price"`
+	Status      string           `json:"status"`
+	OrderID     pgtype.Int8      `json:"order_id"`
 	CreatedAt   pgtype.Timestamp `json:"created_at"`
-	IsDeleted   bool             `json:"is_deleted"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+	ExpiresAt   pgtype.Timestamp `json:"expires_at"`
 }
 
-type OrderItem struct {
+type CartItem struct {
 	ID        int64            `json:"id"`
-	OrderID   int64            `json:"order_id"`
+	CartID    int64            `json:"cart_id"`
 	ProductID int64            `json:"product_id"`
 	Quantity  int32            `json:"quantity"`
-	UnitPrice int32            `json:"unit_price"`
 	CreatedAt pgtype.Timestamp `json:"created_at"`
-	IsDeleted bool             `json:"is_deleted"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+	VariantID pgtype.Int8      `json:"variant_id"`
 }
 
-type Product struct {
+type Category struct {
 	ID          int64            `json:"id"`
+	ParentID    pgtype.Int8      `json:"parent_id"`
 	Name        string           `json:"name"`
+	Slug        string           `json:"slug"`
+	Description string           `json:"description"`
+	Position    int32            `json:"position"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+}
+
+type Customer struct {
+	ID           int64            `json:"id"`
+	CustomerRef  string           `json:"customer_ref"`
+	Email        pgtype.Text      `json:"email"`
+	Name         string           `json:"name"`
+	Phone        string           `json:"phone"`
+	AddressLine1 string           `json:"address_line1"`
+	AddressLine2 string           `json:"address_line2"`
+	City         string           `json:"city"`
+	Region       string           `json:"region"`
+	PostalCode   string           `json:"postal_code"`
+	Country      string           `json:"country"`
+	IsDeleted    bool             `json:"is_deleted"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	UpdatedAt    pgtype.Timestamp `json:"updated_at"`
+}
+
+type ExchangeRate struct {
+	BaseCurrency  string           `json:"base_currency"`
+	QuoteCurrency string           `json:"quote_currency"`
+	Rate          pgtype.Numeric   `json:"rate"`
+	UpdatedBy     string           `json:"updated_by"`
+	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
+}
+
+type IdempotencyKey struct {
+	ID             int64            `json:"id"`
+	Scope          string           `json:"scope"`
+	IdempotencyKey string           `json:"idempotency_key"`
+	RequestHash    string           `json:"request_hash"`
+	StatusCode     int32            `json:"status_code"`
+	ResponseBody   []byte           `json:"response_body"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	ExpiresAt      pgtype.Timestamp `json:"expires_at"`
+	Status         string           `json:"status"`
+}
+
+type InventoryMovement struct {
+	ID            int64            `json:"id"`
+	ProductID     int64            `json:"product_id"`
+	Quantity      int32            `json:"quantity"`
+	StockAfter    int32            `json:"stock_after"`
+	Reason        string           `json:"reason"`
+	Actor         string           `json:"actor"`
+	ReferenceType string           `json:"reference_type"`
+	ReferenceID   string           `json:"reference_id"`
+	Note          string           `json:"note"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	VariantID     pgtype.Int8      `json:"variant_id"`
+}
+
+type OptionType struct {
+	ID        int64            `json:"id"`
+	Name      string           `json:"name"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type Order struct {
+	ID               int64            `json:"id"`
+	CustomerRef      string           `json:"customer_ref"`
+	TotalPrice       int64            `json:"total_price"`
+	CreatedAt        pgtype.Timestamp `json:"created_at"`
+	IsDeleted        bool             `json:"is_deleted"`
+	Status           string           `json:"status"`
+	CancelledAt      pgtype.Timestamp `json:"cancelled_at"`
+	CancelledBy      pgtype.Text      `json:"cancelled_by"`
+	CancelReason     pgtype.Text      `json:"cancel_reason"`
+	Currency         string           `json:"-"`
+	ExchangeRate     pgtype.Numeric   `json:"exchange_rate"`
+	ExchangeRateBase pgtype.Text      `json:"exchange_rate_base"`
+	DiscountTotal    int64            `json:"discount_total"`
+	CouponCode       pgtype.Text      `json:"coupon_code"`
+	PromotionID      pgtype.Int8      `json:"promotion_id"`
+	Subtotal         int64            `json:"subtotal"`
+	TaxTotal         int64            `json:"tax_total"`
+	TaxJurisdiction  pgtype.Text      `json:"tax_jurisdiction"`
+	PricesIncludeTax bool             `json:"prices_include_tax"`
+	ShippingMethodID pgtype.Int8      `json:"shipping_method_id"`
+	ShippingTotal    int64            `json:"shipping_total"`
+	RefundedTotal    int64            `json:"refunded_total"`
+}
+
+type OrderAddress struct {
+	OrderID      int64  `json:"order_id"`
+	Kind         string `json:"kind"`
+	Name         string `json:"name"`
+	Phone        string `json:"phone"`
+	AddressLine1 string `json:"address_line1"`
+	AddressLine2 string `json:"address_line2"`
+	City         string `json:"city"`
+	Region       string `json:"region"`
+	PostalCode   string `json:"postal_code"`
+	Country      string `json:"country"`
+}
+
+type OrderItem struct {
+	ID               int64            `json:"id"`
+	OrderID          int64            `json:"order_id"`
+	ProductID        int64            `json:"product_id"`
+	Quantity         int32            `json:"quantity"`
+	UnitPrice        int64            `json:"unit_price"`
+	CreatedAt        pgtype.Timestamp `json:"created_at"`
+	IsDeleted        bool             `json:"is_deleted"`
+	Currency         string           `json:"-"`
+	Discount         int64            `json:"discount"`
+	TaxClass         string           `json:"tax_class"`
+	TaxRate          pgtype.Numeric   `json:"tax_rate"`
+	TaxAmount        int64            `json:"tax_amount"`
+	ReturnedQuantity int32            `json:"returned_quantity"`
+	RefundedAmount   int64            `json:"refunded_amount"`
+	VariantID        pgtype.Int8      `json:"variant_id"`
+	Sku              string           `json:"sku"`
+}
+
+type OrderStatusHistory struct {
+	ID         int64            `json:"id"`
+	OrderID    int64            `json:"order_id"`
+	FromStatus pgtype.Text      `json:"from_status"`
+	ToStatus   string           `json:"to_status"`
+	Note       string           `json:"note"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+}
+
+type Outbox struct {
+	ID               int64            `json:"id"`
+	EventType        string           `json:"event_type"`
+	AggregateType    string           `json:"aggregate_type"`
+	AggregateID      string           `json:"aggregate_id"`
+	Payload          []byte           `json:"payload"`
+	CreatedAt        pgtype.Timestamp `json:"created_at"`
+	PublishedAt      pgtype.Timestamp `json:"published_at"`
+	Attempts         int32            `json:"attempts"`
+	NextAttemptAt    pgtype.Timestamp `json:"next_attempt_at"`
+	LastError        string           `json:"last_error"`
+	WebhooksQueuedAt pgtype.Timestamp `json:"webhooks_queued_at"`
+}
+
+type Payment struct {
+	ID             int64            `json:"id"`
+	OrderID        int64            `json:"order_id"`
+	Gateway        string           `json:"gateway"`
+	GatewayRef     pgtype.Text      `json:"gateway_ref"`
+	Status         string           `json:"status"`
+	Amount         int64            `json:"amount"`
+	Currency       string           `json:"-"`
+	RefundedAmount int64            `json:"refunded_amount"`
+	PaymentMethod  string           `json:"payment_method"`
+	FailureCode    pgtype.Text      `json:"failure_code"`
+	FailureMessage pgtype.Text      `json:"failure_message"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+	Capture        bool             `json:"capture"`
+}
+
+type PaymentRefund struct {
+	ID             int64            `json:"id"`
+	PaymentID      int64            `json:"payment_id"`
+	Reference      string           `json:"reference"`
+	Amount         int64            `json:"amount"`
+	Currency       string           `json:"-"`
+	Status         string           `json:"status"`
+	Actor          string           `json:"actor"`
+	Note           string           `json:"note"`
+	FailureMessage pgtype.Text      `json:"failure_message"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+}
+
+type PaymentStatusHistory struct {
+	ID         int64            `json:"id"`
+	PaymentID  int64            `json:"payment_id"`
+	FromStatus pgtype.Text      `json:"from_status"`
+	ToStatus   string           `json:"to_status"`
+	Amount     int64            `json:"amount"`
+	Actor      string           `json:"actor"`
+	Note       string           `json:"note"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+}
+
+type PaymentWebhookEvent struct {
+	Gateway    string           `json:"gateway"`
+	EventID    string           `json:"event_id"`
+	EventType  string           `json:"event_type"`
+	ReceivedAt pgtype.Timestamp `json:"received_at"`
+}
+
+type Product struct {
+	ID           int64            `json:"id"`
+	Name         string           `json:"name"`
+	Description  string           `json:"description"`
+	Price        int64            `json:"price"`
+	Stock        int32            `json:"stock"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	UpdatedAt    pgtype.Timestamp `json:"updated_at"`
+	SearchVector string           `json:"-"`
+	Currency     string           `json:"-"`
+	TaxClass     string           `json:"tax_class"`
+	WeightGrams  int32            `json:"weight_grams"`
+	LengthMm     int32            `json:"length_mm"`
+	WidthMm      int32            `json:"width_mm"`
+	HeightMm     int32            `json:"height_mm"`
+	Sku          pgtype.Text      `json:"sku"`
+}
+
+type ProductCategory struct {
+	ProductID  int64 `json:"product_id"`
+	CategoryID int64 `json:"category_id"`
+}
+
+type ProductImage struct {
+	ID           int64            `json:"id"`
+	ProductID    int64            `json:"product_id"`
+	StorageKey   string           `json:"storage_key"`
+	ThumbnailKey string           `json:"thumbnail_key"`
+	ContentType  string           `json:"content_type"`
+	SizeBytes    int64            `json:"size_bytes"`
+	Width        int32            `json:"width"`
+	Height       int32            `json:"height"`
+	AltText      string           `json:"alt_text"`
+	Position     int32            `json:"position"`
+	IsPrimary    bool             `json:"is_primary"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+}
+
+type ProductPrice struct {
+	ProductID int64            `json:"product_id"`
+	Currency  string           `json:"currency"`
+	Amount    int64            `json:"amount"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
+
+type ProductVariant struct {
+	ID        int64            `json:"id"`
+	ProductID int64            `json:"product_id"`
+	Sku       string           `json:"sku"`
+	Price     pgtype.Int8      `json:"price"`
+	Stock     int32            `json:"stock"`
+	Position  int32            `json:"position"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
+
+type Promotion struct {
+	ID               int64            `json:"id"`
+	Code             string           `json:"code"`
+	Name             string           `json:"name"`
+	Kind             string           `json:"kind"`
+	PercentOff       pgtype.Int4      `json:"percent_off"`
+	AmountOff        pgtype.Int8      `json:"amount_off"`
+	BuyQuantity      pgtype.Int4      `json:"buy_quantity"`
+	GetQuantity      pgtype.Int4      `json:"get_quantity"`
+	MinSpend         pgtype.Int8      `json:"min_spend"`
+	Currency         pgtype.Text      `json:"currency"`
+	StartsAt         pgtype.Timestamp `json:"starts_at"`
+	EndsAt           pgtype.Timestamp `json:"ends_at"`
+	UsageLimit       pgtype.Int4      `json:"usage_limit"`
+	PerCustomerLimit pgtype.Int4      `json:"per_customer_limit"`
+	TimesUsed        int32            `json:"times_used"`
+	IsActive         bool             `json:"is_active"`
+	CreatedAt        pgtype.Timestamp `json:"created_at"`
+	UpdatedAt        pgtype.Timestamp `json:"updated_at"`
+}
+
+type PromotionCategory struct {
+	PromotionID int64 `json:"promotion_id"`
+	CategoryID  int64 `json:"category_id"`
+}
+
+type PromotionProduct struct {
+	PromotionID int64 `json:"promotion_id"`
+	ProductID   int64 `json:"product_id"`
+}
+
+type PromotionRedemption struct {
+	ID          int64            `json:"id"`
+	PromotionID int64            `json:"promotion_id"`
+	OrderID     int64            `json:"order_id"`
+	CustomerRef string           `json:"customer_ref"`
+	Discount    int64            `json:"discount"`
+	Currency    string           `json:"currency"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
+type Refund struct {
+	ID               int64            `json:"id"`
+	ReturnID         int64            `json:"return_id"`
+	OrderID          int64            `json:"order_id"`
+	PaymentID        pgtype.Int8      `json:"payment_id"`
+	Kind             string           `json:"kind"`
+	Status           string           `json:"status"`
+	Amount           int64            `json:"amount"`
+	Currency         string           `json:"-"`
+	FailureMessage   pgtype.Text      `json:"failure_message"`
+	CreatedAt        pgtype.Timestamp `json:"created_at"`
+	UpdatedAt        pgtype.Timestamp `json:"updated_at"`
+	PaymentReference pgtype.Text      `json:"payment_reference"`
+}
+
+type Reservation struct {
+	ID        int64            `json:"id"`
+	ProductID int64            `json:"product_id"`
+	OrderID   int64            `json:"order_id"`
+	Quantity  int32            `json:"quantity"`
+	Status    string           `json:"status"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+	VariantID pgtype.Int8      `json:"variant_id"`
+}
+
+type Return struct {
+	ID             int64            `json:"id"`
+	OrderID        int64            `json:"order_id"`
+	CustomerRef    string           `json:"customer_ref"`
+	Status         string           `json:"status"`
+	Reason         string           `json:"reason"`
+	RefundTotal    int64            `json:"refund_total"`
+	Currency       string           `json:"-"`
+	RequestedBy    string           `json:"requested_by"`
+	ResolvedBy     pgtype.Text      `json:"resolved_by"`
+	ResolutionNote string           `json:"resolution_note"`
+	Restocked      bool             `json:"restocked"`
+	ReceivedBy     pgtype.Text      `json:"received_by"`
+	ReceivedAt     pgtype.Timestamp `json:"received_at"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+}
+
+type ReturnItem struct {
+	ID           int64  `json:"id"`
+	ReturnID     int64  `json:"return_id"`
+	OrderItemID  int64  `json:"order_item_id"`
+	ProductID    int64  `json:"product_id"`
+	Quantity     int32  `json:"quantity"`
+	RefundAmount int64  `json:"refund_amount"`
+	Currency     string `json:"-"`
+}
+
+type ShippingMethod struct {
+	ID                int64            `json:"id"`
+	Code              string           `json:"code"`
+	Name              string           `json:"name"`
+	RateBasis         string           `json:"rate_basis"`
+	Currency          string           `json:"currency"`
+	Countries         []string         `json:"countries"`
+	VolumetricDivisor pgtype.Int4      `json:"volumetric_divisor"`
+	IsActive          bool             `json:"is_active"`
+	CreatedAt         pgtype.Timestamp `json:"created_at"`
+	UpdatedAt         pgtype.Timestamp `json:"updated_at"`
+}
+
+type ShippingRate struct {
+	MethodID int64       `json:"method_id"`
+	MinValue int64       `json:"min_value"`
+	MaxValue pgtype.Int8 `json:"max_value"`
+	Amount   int64       `json:"amount"`
+}
+
+type TaxRate struct {
+	Jurisdiction string           `json:"jurisdiction"`
+	TaxClass     string           `json:"tax_class"`
+	Rate         pgtype.Numeric   `json:"rate"`
+	Name         string           `json:"name"`
+	UpdatedBy    string           `json:"updated_by"`
+	UpdatedAt    pgtype.Timestamp `json:"updated_at"`
+}
+
+type VariantOptionValue struct {
+	VariantID    int64  `json:"variant_id"`
+	OptionTypeID int64  `json:"option_type_id"`
+	Value        string `json:"value"`
+}
+
+type WebhookDelivery struct {
+	ID             int64            `json:"id"`
+	SubscriptionID int64            `json:"subscription_id"`
+	EventID        int64            `json:"event_id"`
+	EventType      string           `json:"event_type"`
+	Payload        []byte           `json:"payload"`
+	Status         string           `json:"status"`
+	Attempts       int32            `json:"attempts"`
+	NextAttemptAt  pgtype.Timestamp `json:"next_attempt_at"`
+	LastError      string           `json:"last_error"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	DeliveredAt    pgtype.Timestamp `json:"delivered_at"`
+}
+
+type WebhookDeliveryAttempt struct {
+	ID           int64            `json:"id"`
+	DeliveryID   int64            `json:"delivery_id"`
+	Attempt      int32            `json:"attempt"`
+	StatusCode   pgtype.Int4      `json:"status_code"`
+	Error        string           `json:"error"`
+	ResponseBody string           `json:"response_body"`
+	DurationMs   int32            `json:"duration_ms"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+}
+
+type WebhookSubscription struct {
+	ID          int64            `json:"id"`
+	CustomerRef pgtype.Text      `json:"customer_ref"`
+	Url         string           `json:"url"`
+	Events      []string         `json:"events"`
+	Secret      string           `json:"secret"`
 	Description string           `json:"description"`
-	Price       int32            `json:"price"`
-	Stock       int32            `json:"stock"`
+	Active      bool             `json:"active"`
 	CreatedAt   pgtype.Timestamp `json:"created_at"`
 	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
 }
@@ -0,0 +1,150 @@
+package repo
+
+// This file is NOT generated by sqlc. Amounts are stored as a minor unit column plus a
+// currency column, these helpers pair them up and write them out as {amount, currency}.
+
+import (
+	"ecomApis/internals/money"
+	"encoding/json"
+)
+
+func (p Product) PriceMoney() money.Money {
+	return money.Money{Amount: p.Price, Currency: p.Currency}
+}
+
+func (o Order) TotalMoney() money.Money {
+	return money.Money{Amount: o.TotalPrice, Currency: o.Currency}
+}
+
+func (o Order) DiscountMoney() money.Money {
+	return money.Money{Amount: o.DiscountTotal, Currency: o.Currency}
+}
+
+func (o Order) SubtotalMoney() money.Money {
+	return money.Money{Amount: o.Subtotal, Currency: o.Currency}
+}
+
+func (o Order) TaxMoney() money.Money {
+	return money.Money{Amount: o.TaxTotal, Currency: o.Currency}
+}
+
+func (o Order) ShippingMoney() money.Money {
+	return money.Money{Amount: o.ShippingTotal, Currency: o.Currency}
+}
+
+func (o Order) RefundedMoney() money.Money {
+	return money.Money{Amount: o.RefundedTotal, Currency: o.Currency}
+}
+
+func (i OrderItem) UnitPriceMoney() money.Money {
+	return money.Money{Amount: i.UnitPrice, Currency: i.Currency}
+}
+
+func (i OrderItem) DiscountMoney() money.Money {
+	return money.Money{Amount: i.Discount, Currency: i.Currency}
+}
+
+func (i OrderItem) TaxMoney() money.Money {
+	return money.Money{Amount: i.TaxAmount, Currency: i.Currency}
+}
+
+func (i OrderItem) RefundedMoney() money.Money {
+	return money.Money{Amount: i.RefundedAmount, Currency: i.Currency}
+}
+
+func (p Payment) AmountMoney() money.Money {
+	return money.Money{Amount: p.Amount, Currency: p.Currency}
+}
+
+func (p Payment) RefundedMoney() money.Money {
+	return money.Money{Amount: p.RefundedAmount, Currency: p.Currency}
+}
+
+func (r PaymentRefund) AmountMoney() money.Money {
+	return money.Money{Amount: r.Amount, Currency: r.Currency}
+}
+
+func (r Return) RefundMoney() money.Money {
+	return money.Money{Amount: r.RefundTotal, Currency: r.Currency}
+}
+
+func (i ReturnItem) RefundMoney() money.Money {
+	return money.Money{Amount: i.RefundAmount, Currency: i.Currency}
+}
+
+func (r Refund) AmountMoney() money.Money {
+	return money.Money{Amount: r.Amount, Currency: r.Currency}
+}
+
+func (p Product) MarshalJSON() ([]byte, error) {
+	type product Product
+	return json.Marshal(struct {
+		product
+		Price money.Money `json:"price"`
+	}{product(p), p.PriceMoney()})
+}
+
+func (o Order) MarshalJSON() ([]byte, error) {
+	type order Order
+	return json.Marshal(struct {
+		order
+		TotalPrice    money.Money `json:"total_price"`
+		DiscountTotal money.Money `json:"discount_total"`
+		Subtotal      money.Money `json:"subtotal"`
+		TaxTotal      money.Money `json:"tax_total"`
+		ShippingTotal money.Money `json:"shipping_total"`
+		RefundedTotal money.Money `json:"refunded_total"`
+	}{order(o), o.TotalMoney(), o.DiscountMoney(), o.SubtotalMoney(), o.TaxMoney(), o.ShippingMoney(), o.RefundedMoney()})
+}
+
+func (i OrderItem) MarshalJSON() ([]byte, error) {
+	type orderItem OrderItem
+	return json.Marshal(struct {
+		orderItem
+		UnitPrice      money.Money `json:"unit_price"`
+		Discount       money.Money `json:"discount"`
+		TaxAmount      money.Money `json:"tax_amount"`
+		RefundedAmount money.Money `json:"refunded_amount"`
+	}{orderItem(i), i.UnitPriceMoney(), i.DiscountMoney(), i.TaxMoney(), i.RefundedMoney()})
+}
+
+func (p Payment) MarshalJSON() ([]byte, error) {
+	type payment Payment
+	return json.Marshal(struct {
+		payment
+		Amount         money.Money `json:"amount"`
+		RefundedAmount money.Money `json:"refunded_amount"`
+	}{payment(p), p.AmountMoney(), p.RefundedMoney()})
+}
+
+func (r PaymentRefund) MarshalJSON() ([]byte, error) {
+	type paymentRefund PaymentRefund
+	return json.Marshal(struct {
+		paymentRefund
+		Amount money.Money `json:"amount"`
+	}{paymentRefund(r), r.AmountMoney()})
+}
+
+func (r Return) MarshalJSON() ([]byte, error) {
+	type ret Return
+	return json.Marshal(struct {
+		ret
+		RefundTotal money.Money `json:"refund_total"`
+	}{ret(r), r.RefundMoney()})
+}
+
+func (i ReturnItem) MarshalJSON() ([]byte, error) {
+	type returnItem ReturnItem
+	return json.Marshal(struct {
+		returnItem
+		RefundAmount money.Money `json:"refund_amount"`
+	}{returnItem(i), i.RefundMoney()})
+}
+
+func (r Refund) MarshalJSON() ([]byte, error) {
+	type refund Refund
+	return json.Marshal(struct {
+		refund
+		Amount money.Money `json:"amount"`
+	}{refund(r), r.AmountMoney()})
+}
@@ -7,27 +7,43 @@ package repo
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const addOrderItem = `-- name: AddOrderItem :one
-INSERT INTO order_items (order_id, product_id, quantity, unit_price)
-VALUES ($1, $2, $3, $4)
-RETURNING id, order_id, product_id, quantity, unit_price, created_at, is_deleted
+INSERT INTO order_items (order_id, product_id, variant_id, sku, quantity, unit_price, currency, discount, tax_class, tax_rate, tax_amount)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, order_id, product_id, quantity, unit_price, created_at, is_deleted, currency, discount, tax_class, tax_rate, tax_amount, returned_quantity, refunded_amount, variant_id, sku
 `
 
 type AddOrderItemParams struct {
-	OrderID   int64 `json:"order_id"`
-	ProductID int64 `json:"product_id"`
-	Quantity  int32 `json:"quantity"`
-	UnitPrice int32 `json:"unit_price"`
+	OrderID   int64          `json:"order_id"`
+	ProductID int64          `json:"product_id"`
+	VariantID pgtype.Int8    `json:"variant_id"`
+	Sku       string         `json:"sku"`
+	Quantity  int32          `json:"quantity"`
+	UnitPrice int64          `json:"unit_price"`
+	Currency  string         `json:"currency"`
+	Discount  int64          `json:"discount"`
+	TaxClass  string         `json:"tax_class"`
+	TaxRate   pgtype.Numeric `json:"tax_rate"`
+	TaxAmount int64          `json:"tax_amount"`
 }
 
 func (q *Queries) AddOrderItem(ctx context.Context, arg AddOrderItemParams) (OrderItem, error) {
 	row := q.db.QueryRow(ctx, addOrderItem,
 		arg.OrderID,
 		arg.ProductID,
+		arg.VariantID,
+		arg.Sku,
 		arg.Quantity,
 		arg.UnitPrice,
+		arg.Currency,
+		arg.Discount,
+		arg.TaxClass,
+		arg.TaxRate,
+		arg.TaxAmount,
 	)
 	var i OrderItem
 	err := row.Scan(
@@ -38,18 +54,141 @@ func (q *Queries) AddOrderItem(ctx context.Context, arg AddOrderItemParams) (Ord
 		&i.UnitPrice,
 		&i.CreatedAt,
 		&i.IsDeleted,
+		&i.Currency,
+		&i.Discount,
+		&i.TaxClass,
+		&i.TaxRate,
+		&i.TaxAmount,
+		&i.ReturnedQuantity,
+		&i.RefundedAmount,
+		&i.VariantID,
+		&i.Sku,
+	)
+	return i, err
+}
+
+const addOrderStatusHistory = `-- name: AddOrderStatusHistory :one
+INSERT INTO order_status_history (order_id, from_status, to_status, note)
+VALUES ($1, $2, $3, $4)
+RETURNING id, order_id, from_status, to_status, note, created_at
+`
+
+type AddOrderStatusHistoryParams struct {
+	OrderID    int64       `json:"order_id"`
+	FromStatus pgtype.Text `json:"from_status"`
+	ToStatus   string      `json:"to_status"`
+	Note       string      `json:"note"`
+}
+
+func (q *Queries) AddOrderStatusHistory(ctx context.Context, arg AddOrderStatusHistoryParams) (OrderStatusHistory, error) {
+	row := q.db.QueryRow(ctx, addOrderStatusHistory,
+		arg.OrderID,
+		arg.FromStatus,
+		arg.ToStatus,
+		arg.Note,
+	)
+	var i OrderStatusHistory
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.FromStatus,
+		&i.ToStatus,
+		&i.Note,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const cancelOrder = `-- name: CancelOrder :one
+UPDATE orders
+SET status = 'cancelled', cancelled_at = NOW(), cancelled_by = $1, cancel_reason = $2
+WHERE id = $3 AND status <> 'cancelled' AND is_deleted = false
+RETURNING id, customer_ref, total_price, created_at, is_deleted, status, cancelled_at, cancelled_by, cancel_reason, currency, exchange_rate, exchange_rate_base, discount_total, coupon_code, promotion_id, subtotal, tax_total, tax_jurisdiction, prices_include_tax, shipping_method_id, shipping_total, refunded_total
+`
+
+type CancelOrderParams struct {
+	CancelledBy  pgtype.Text `json:"cancelled_by"`
+	CancelReason pgtype.Text `json:"cancel_reason"`
+	ID           int64       `json:"id"`
+}
+
+func (q *Queries) CancelOrder(ctx context.Context, arg CancelOrderParams) (Order, error) {
+	row := q.db.QueryRow(ctx, cancelOrder,
+		arg.CancelledBy,
+		arg.CancelReason,
+		arg.ID,
+	)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.CustomerRef,
+		&i.TotalPrice,
+		&i.CreatedAt,
+		&i.IsDeleted,
+		&i.Status,
+		&i.CancelledAt,
+		&i.CancelledBy,
+		&i.CancelReason,
+		&i.Currency,
+		&i.ExchangeRate,
+		&i.ExchangeRateBase,
+		&i.DiscountTotal,
+		&i.CouponCode,
+		&i.PromotionID,
+		&i.Subtotal,
+		&i.TaxTotal,
+		&i.TaxJurisdiction,
+		&i.PricesIncludeTax,
+		&i.ShippingMethodID,
+		&i.ShippingTotal,
+		&i.RefundedTotal,
 	)
 	return i, err
 }
 
 const createOrder = `-- name: CreateOrder :one
-INSERT INTO orders (customer_ref)
-VALUES ($1)
-RETURNING id, customer_ref, total_price, created_at, is_deleted
+INSERT INTO orders (
+    customer_ref, total_price, currency, exchange_rate, exchange_rate_base, discount_total, coupon_code,
+    promotion_id, subtotal, tax_total, tax_jurisdiction, prices_include_tax, shipping_method_id, shipping_total
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+RETURNING id, customer_ref, total_price, created_at, is_deleted, status, cancelled_at, cancelled_by, cancel_reason, currency, exchange_rate, exchange_rate_base, discount_total, coupon_code, promotion_id, subtotal, tax_total, tax_jurisdiction, prices_include_tax, shipping_method_id, shipping_total, refunded_total
 `
 
-func (q *Queries) CreateOrder(ctx context.Context, customerRef string) (Order, error) {
-	row := q.db.QueryRow(ctx, createOrder, customerRef)
+type CreateOrderParams struct {
+	CustomerRef      string         `json:"customer_ref"`
+	TotalPrice       int64          `json:"total_price"`
+	Currency         string         `json:"currency"`
+	ExchangeRate     pgtype.Numeric `json:"exchange_rate"`
+	ExchangeRateBase pgtype.Text    `json:"exchange_rate_base"`
+	DiscountTotal    int64          `json:"discount_total"`
+	CouponCode       pgtype.Text    `json:"coupon_code"`
+	PromotionID      pgtype.Int8    `json:"promotion_id"`
+	Subtotal         int64          `json:"subtotal"`
+	TaxTotal         int64          `json:"tax_total"`
+	TaxJurisdiction  pgtype.Text    `json:"tax_jurisdiction"`
+	PricesIncludeTax bool           `json:"prices_include_tax"`
+	ShippingMethodID pgtype.Int8    `json:"shipping_method_id"`
+	ShippingTotal    int64          `json:"shipping_total"`
+}
+
+func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order, error) {
+	row := q.db.QueryRow(ctx, createOrder,
+		arg.CustomerRef,
+		arg.TotalPrice,
+		arg.Currency,
+		arg.ExchangeRate,
+		arg.ExchangeRateBase,
+		arg.DiscountTotal,
+		arg.CouponCode,
+		arg.PromotionID,
+		arg.Subtotal,
+		arg.TaxTotal,
+		arg.TaxJurisdiction,
+		arg.PricesIncludeTax,
+		arg.ShippingMethodID,
+		arg.ShippingTotal,
+	)
 	var i Order
 	err := row.Scan(
 		&i.ID,
@@ -57,6 +196,23 @@ func (q *Queries) CreateOrder(ctx context.Context, customerRef string) (Order, e
 		&i.TotalPrice,
 		&i.CreatedAt,
 		&i.IsDeleted,
+		&i.Status,
+		&i.CancelledAt,
+		&i.CancelledBy,
+		&i.CancelReason,
+		&i.Currency,
+		&i.ExchangeRate,
+		&i.ExchangeRateBase,
+		&i.DiscountTotal,
+		&i.CouponCode,
+		&i.PromotionID,
+		&i.Subtotal,
+		&i.TaxTotal,
+		&i.TaxJurisdiction,
+		&i.PricesIncludeTax,
+		&i.ShippingMethodID,
+		&i.ShippingTotal,
+		&i.RefundedTotal,
 	)
 	return i, err
 }
@@ -84,7 +240,7 @@ func (q *Queries) DeleteOrderItemsByOrderID(ctx context.Context, orderID int64)
 }
 
 const getAllOrders = `-- name: GetAllOrders :many
-SELECT id, customer_ref, total_price, created_at, is_deleted FROM orders
+SELECT id, customer_ref, total_price, created_at, is_deleted, status, cancelled_at, cancelled_by, cancel_reason, currency, exchange_rate, exchange_rate_base, discount_total, coupon_code, promotion_id, subtotal, tax_total, tax_jurisdiction, prices_include_tax, shipping_method_id, shipping_total, refunded_total FROM orders
 WHERE is_deleted = false
 ORDER BY created_at DESC
 `
@@ -104,6 +260,23 @@ func (q *Queries) GetAllOrders(ctx context.Context) ([]Order, error) {
 			&i.TotalPrice,
 			&i.CreatedAt,
 			&i.IsDeleted,
+			&i.Status,
+			&i.CancelledAt,
+			&i.CancelledBy,
+			&i.CancelReason,
+			&i.Currency,
+			&i.ExchangeRate,
+			&i.ExchangeRateBase,
+			&i.DiscountTotal,
+			&i.CouponCode,
+			&i.PromotionID,
+			&i.Subtotal,
+			&i.TaxTotal,
+			&i.TaxJurisdiction,
+			&i.PricesIncludeTax,
+			&i.ShippingMethodID,
+			&i.ShippingTotal,
+			&i.RefundedTotal,
 		); err != nil {
 			return nil, err
 		}
@@ -116,7 +289,7 @@ func (q *Queries) GetAllOrders(ctx context.Context) ([]Order, error) {
 }
 
 const getOrder = `-- name: GetOrder :one
-SELECT id, customer_ref, total_price, created_at, is_deleted FROM orders
+SELECT id, customer_ref, total_price, created_at, is_deleted, status, cancelled_at, cancelled_by, cancel_reason, currency, exchange_rate, exchange_rate_base, discount_total, coupon_code, promotion_id, subtotal, tax_total, tax_jurisdiction, prices_include_tax, shipping_method_id, shipping_total, refunded_total FROM orders
 WHERE id = $1 and is_deleted = false
 `
 
@@ -129,12 +302,65 @@ func (q *Queries) GetOrder(ctx context.Context, id int64) (Order, error) {
 		&i.TotalPrice,
 		&i.CreatedAt,
 		&i.IsDeleted,
+		&i.Status,
+		&i.CancelledAt,
+		&i.CancelledBy,
+		&i.CancelReason,
+		&i.Currency,
+		&i.ExchangeRate,
+		&i.ExchangeRateBase,
+		&i.DiscountTotal,
+		&i.CouponCode,
+		&i.PromotionID,
+		&i.Subtotal,
+		&i.TaxTotal,
+		&i.TaxJurisdiction,
+		&i.PricesIncludeTax,
+		&i.ShippingMethodID,
+		&i.ShippingTotal,
+		&i.RefundedTotal,
+	)
+	return i, err
+}
+
+const getOrderForUpdate = `-- name: GetOrderForUpdate :one
+SELECT id, customer_ref, total_price, created_at, is_deleted, status, cancelled_at, cancelled_by, cancel_reason, currency, exchange_rate, exchange_rate_base, discount_total, coupon_code, promotion_id, subtotal, tax_total, tax_jurisdiction, prices_include_tax, shipping_method_id, shipping_total, refunded_total FROM orders
+WHERE id = $1 AND is_deleted = false
+FOR UPDATE
+`
+
+func (q *Queries) GetOrderForUpdate(ctx context.Context, id int64) (Order, error) {
+	row := q.db.QueryRow(ctx, getOrderForUpdate, id)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.CustomerRef,
+		&i.TotalPrice,
+		&i.CreatedAt,
+		&i.IsDeleted,
+		&i.Status,
+		&i.CancelledAt,
+		&i.CancelledBy,
+		&i.CancelReason,
+		&i.Currency,
+		&i.ExchangeRate,
+		&i.ExchangeRateBase,
+		&i.DiscountTotal,
+		&i.CouponCode,
+		&i.PromotionID,
+		&i.Subtotal,
+		&i.TaxTotal,
+		&i.TaxJurisdiction,
+		&i.PricesIncludeTax,
+		&i.ShippingMethodID,
+		&i.ShippingTotal,
+		&i.RefundedTotal,
 	)
 	return i, err
 }
 
 const getOrdersByCustomerRef = `-- name: GetOrdersByCustomerRef :many
-SELECT id, customer_ref, total_price, created_at, is_deleted FROM orders
+SELECT id, customer_ref, total_price, created_at, is_deleted, status, cancelled_at, cancelled_by, cancel_reason, currency, exchange_rate, exchange_rate_base, discount_total, coupon_code, promotion_id, subtotal, tax_total, tax_jurisdiction, prices_include_tax, shipping_method_id, shipping_total, refunded_total FROM orders
 WHERE customer_ref = $1 and is_deleted = false
 ORDER BY created_at DESC
 `
@@ -154,6 +380,23 @@ func (q *Queries) GetOrdersByCustomerRef(ctx context.Context, customerRef string
 			&i.TotalPrice,
 			&i.CreatedAt,
 			&i.IsDeleted,
+			&i.Status,
+			&i.CancelledAt,
+			&i.CancelledBy,
+			&i.CancelReason,
+			&i.Currency,
+			&i.ExchangeRate,
+			&i.ExchangeRateBase,
+			&i.DiscountTotal,
+			&i.CouponCode,
+			&i.PromotionID,
+			&i.Subtotal,
+			&i.TaxTotal,
+			&i.TaxJurisdiction,
+			&i.PricesIncludeTax,
+			&i.ShippingMethodID,
+			&i.ShippingTotal,
+			&i.RefundedTotal,
 		); err != nil {
 			return nil, err
 		}
@@ -166,7 +409,7 @@ func (q *Queries) GetOrdersByCustomerRef(ctx context.Context, customerRef string
 }
 
 const listOrderItems = `-- name: ListOrderItems :many
-SELECT id, order_id, product_id, quantity, unit_price, created_at, is_deleted FROM order_items
+SELECT id, order_id, product_id, quantity, unit_price, created_at, is_deleted, currency, discount, tax_class, tax_rate, tax_amount, returned_quantity, refunded_amount, variant_id, sku FROM order_items
 WHERE order_id = $1 and is_deleted = false
 ORDER BY created_at DESC
 `
@@ -188,6 +431,178 @@ func (q *Queries) ListOrderItems(ctx context.Context, orderID int64) ([]OrderIte
 			&i.UnitPrice,
 			&i.CreatedAt,
 			&i.IsDeleted,
+			&i.Currency,
+			&i.Discount,
+			&i.TaxClass,
+			&i.TaxRate,
+			&i.TaxAmount,
+			&i.ReturnedQuantity,
+			&i.RefundedAmount,
+			&i.VariantID,
+			&i.Sku,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrderStatusHistory = `-- name: ListOrderStatusHistory :many
+SELECT id, order_id, from_status, to_status, note, created_at FROM order_status_history
+WHERE order_id = $1
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListOrderStatusHistory(ctx context.Context, orderID int64) ([]OrderStatusHistory, error) {
+	rows, err := q.db.Query(ctx, listOrderStatusHistory, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OrderStatusHistory
+	for rows.Next() {
+		var i OrderStatusHistory
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderID,
+			&i.FromStatus,
+			&i.ToStatus,
+			&i.Note,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrdersByCustomerRefPage = `-- name: ListOrdersByCustomerRefPage :many
+SELECT id, customer_ref, total_price, created_at, is_deleted, status, cancelled_at, cancelled_by, cancel_reason, currency, exchange_rate, exchange_rate_base, discount_total, coupon_code, promotion_id, subtotal, tax_total, tax_jurisdiction, prices_include_tax, shipping_method_id, shipping_total, refunded_total FROM orders
+WHERE customer_ref = $1 AND is_deleted = false
+  AND (
+    $2::timestamp IS NULL
+    OR (created_at, id) < ($2::timestamp, $3::bigint)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type ListOrdersByCustomerRefPageParams struct {
+	CustomerRef     string           `json:"customer_ref"`
+	CursorCreatedAt pgtype.Timestamp `json:"cursor_created_at"`
+	CursorID        pgtype.Int8      `json:"cursor_id"`
+	PageLimit       int32            `json:"page_limit"`
+}
+
+func (q *Queries) ListOrdersByCustomerRefPage(ctx context.Context, arg ListOrdersByCustomerRefPageParams) ([]Order, error) {
+	rows, err := q.db.Query(ctx, listOrdersByCustomerRefPage,
+		arg.CustomerRef,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Order
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.ID,
+			&i.CustomerRef,
+			&i.TotalPrice,
+			&i.CreatedAt,
+			&i.IsDeleted,
+			&i.Status,
+			&i.CancelledAt,
+			&i.CancelledBy,
+			&i.CancelReason,
+			&i.Currency,
+			&i.ExchangeRate,
+			&i.ExchangeRateBase,
+			&i.DiscountTotal,
+			&i.CouponCode,
+			&i.PromotionID,
+			&i.Subtotal,
+			&i.TaxTotal,
+			&i.TaxJurisdiction,
+			&i.PricesIncludeTax,
+			&i.ShippingMethodID,
+			&i.ShippingTotal,
+			&i.RefundedTotal,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrdersPage = `-- name: ListOrdersPage :many
+SELECT id, customer_ref, total_price, created_at, is_deleted, status, cancelled_at, cancelled_by, cancel_reason, currency, exchange_rate, exchange_rate_base, discount_total, coupon_code, promotion_id, subtotal, tax_total, tax_jurisdiction, prices_include_tax, shipping_method_id, shipping_total, refunded_total FROM orders
+WHERE is_deleted = false
+  AND (
+    $1::timestamp IS NULL
+    OR (created_at, id) < ($1::timestamp, $2::bigint)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT $3
+`
+
+type ListOrdersPageParams struct {
+	CursorCreatedAt pgtype.Timestamp `json:"cursor_created_at"`
+	CursorID        pgtype.Int8      `json:"cursor_id"`
+	PageLimit       int32            `json:"page_limit"`
+}
+
+func (q *Queries) ListOrdersPage(ctx context.Context, arg ListOrdersPageParams) ([]Order, error) {
+	rows, err := q.db.Query(ctx, listOrdersPage,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Order
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.ID,
+			&i.CustomerRef,
+			&i.TotalPrice,
+			&i.CreatedAt,
+			&i.IsDeleted,
+			&i.Status,
+			&i.CancelledAt,
+			&i.CancelledBy,
+			&i.CancelReason,
+			&i.Currency,
+			&i.ExchangeRate,
+			&i.ExchangeRateBase,
+			&i.DiscountTotal,
+			&i.CouponCode,
+			&i.PromotionID,
+			&i.Subtotal,
+			&i.TaxTotal,
+			&i.TaxJurisdiction,
+			&i.PricesIncludeTax,
+			&i.ShippingMethodID,
+			&i.ShippingTotal,
+			&i.RefundedTotal,
 		); err != nil {
 			return nil, err
 		}
@@ -199,15 +614,81 @@ func (q *Queries) ListOrderItems(ctx context.Context, orderID int64) ([]OrderIte
 	return items, nil
 }
 
+const orderReachedStatus = `-- name: OrderReachedStatus :one
+SELECT EXISTS (
+    SELECT 1 FROM order_status_history
+    WHERE order_id = $1 AND to_status = $2
+) AS reached
+`
+
+type OrderReachedStatusParams struct {
+	OrderID  int64  `json:"order_id"`
+	ToStatus string `json:"to_status"`
+}
+
+func (q *Queries) OrderReachedStatus(ctx context.Context, arg OrderReachedStatusParams) (bool, error) {
+	row := q.db.QueryRow(ctx, orderReachedStatus, arg.OrderID, arg.ToStatus)
+	var reached bool
+	err := row.Scan(&reached)
+	return reached, err
+}
+
+const updateOrderStatus = `-- name: UpdateOrderStatus :one
+UPDATE orders
+SET status = $1
+WHERE id = $2 AND status = $3 AND is_deleted = false
+RETURNING id, customer_ref, total_price, created_at, is_deleted, status, cancelled_at, cancelled_by, cancel_reason, currency, exchange_rate, exchange_rate_base, discount_total, coupon_code, promotion_id, subtotal, tax_total, tax_jurisdiction, prices_include_tax, shipping_method_id, shipping_total, refunded_total
+`
+
+type UpdateOrderStatusParams struct {
+	ToStatus   string `json:"to_status"`
+	ID         int64  `json:"id"`
+	FromStatus string `json:"from_status"`
+}
+
+func (q *Queries) UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusParams) (Order, error) {
+	row := q.db.QueryRow(ctx, updateOrderStatus,
+		arg.ToStatus,
+		arg.ID,
+		arg.FromStatus,
+	)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.CustomerRef,
+		&i.TotalPrice,
+		&i.CreatedAt,
+		&i.IsDeleted,
+		&i.Status,
+		&i.CancelledAt,
+		&i.CancelledBy,
+		&i.CancelReason,
+		&i.Currency,
+		&i.ExchangeRate,
+		&i.ExchangeRateBase,
+		&i.DiscountTotal,
+		&i.CouponCode,
+		&i.PromotionID,
+		&i.Subtotal,
+		&i.TaxTotal,
+		&i.TaxJurisdiction,
+		&i.PricesIncludeTax,
+		&i.ShippingMethodID,
+		&i.ShippingTotal,
+		&i.RefundedTotal,
+	)
+	return i, err
+}
+
 const updateOrderTotalPrice = `-- name: UpdateOrderTotalPrice :one
 UPDATE orders
 SET total_price = $1, created_at = NOW()
 WHERE id = $2 and is_deleted = false
-RETURNING id, customer_ref, total_price, created_at, is_deleted
+RETURNING id, customer_ref, total_price, created_at, is_deleted, status, cancelled_at, cancelled_by, cancel_reason, currency, exchange_rate, exchange_rate_base, discount_total, coupon_code, promotion_id, subtotal, tax_total, tax_jurisdiction, prices_include_tax, shipping_method_id, shipping_total, refunded_total
 `
 
 type UpdateOrderTotalPriceParams struct {
-	TotalPrice int32 `json:"total_price"`
+	TotalPrice int64 `json:"total_price"`
 	ID         int64 `json:"id"`
 }
 
@@ -220,6 +701,23 @@ func (q *Queries) UpdateOrderTotalPrice(ctx context.Context, arg UpdateOrderTota
 		&i.TotalPrice,
 		&i.CreatedAt,
 		&i.IsDeleted,
+		&i.Status,
+		&i.CancelledAt,
+		&i.CancelledBy,
+		&i.CancelReason,
+		&i.Currency,
+		&i.ExchangeRate,
+		&i.ExchangeRateBase,
+		&i.DiscountTotal,
+		&i.CouponCode,
+		&i.PromotionID,
+		&i.Subtotal,
+		&i.TaxTotal,
+		&i.TaxJurisdiction,
+		&i.PricesIncludeTax,
+		&i.ShippingMethodID,
+		&i.ShippingTotal,
+		&i.RefundedTotal,
 	)
 	return i, err
 }
@@ -11,17 +11,58 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const adjustProductStock = `-- name: AdjustProductStock :one
+UPDATE products
+SET stock = stock + $1, updated_at = NOW()
+WHERE id = $2 AND stock + $1 >= 0
+RETURNING id, name, description, price, stock, created_at, updated_at, search_vector, currency, tax_class, weight_grams, length_mm, width_mm, height_mm, sku
+`
+
+type AdjustProductStockParams struct {
+	Delta int32 `json:"delta"`
+	ID    int64 `json:"id"`
+}
+
+func (q *Queries) AdjustProductStock(ctx context.Context, arg AdjustProductStockParams) (Product, error) {
+	row := q.db.QueryRow(ctx, adjustProductStock, arg.Delta, arg.ID)
+	var i Product
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Price,
+		&i.Stock,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SearchVector,
+		&i.Currency,
+		&i.TaxClass,
+		&i.WeightGrams,
+		&i.LengthMm,
+		&i.WidthMm,
+		&i.HeightMm,
+		&i.Sku,
+	)
+	return i, err
+}
+
 const createProduct = `-- name: CreateProduct :one
-INSERT INTO products (name, description, price, stock)
-VALUES ($1, $2, $3, $4)
-RETURNING id, name, description, price, stock, created_at, updated_at
+INSERT INTO products (name, description, price, currency, stock, tax_class, weight_grams, length_mm, width_mm, height_mm)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, name, description, price, stock, created_at, updated_at, search_vector, currency, tax_class, weight_grams, length_mm, width_mm, height_mm, sku
 `
 
 type CreateProductParams struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
-	Price       int32  `json:"price"`
+	Price       int64  `json:"price"`
+	Currency    string `json:"currency"`
 	Stock       int32  `json:"stock"`
+	TaxClass    string `json:"tax_class"`
+	WeightGrams int32  `json:"weight_grams"`
+	LengthMm    int32  `json:"length_mm"`
+	WidthMm     int32  `json:"width_mm"`
+	HeightMm    int32  `json:"height_mm"`
 }
 
 func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (Product, error) {
@@ -29,7 +70,13 @@ func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (P
 		arg.Name,
 		arg.Description,
 		arg.Price,
+		arg.Currency,
 		arg.Stock,
+		arg.TaxClass,
+		arg.WeightGrams,
+		arg.LengthMm,
+		arg.WidthMm,
+		arg.HeightMm,
 	)
 	var i Product
 	err := row.Scan(
@@ -40,6 +87,14 @@ func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (P
 		&i.Stock,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SearchVector,
+		&i.Currency,
+		&i.TaxClass,
+		&i.WeightGrams,
+		&i.LengthMm,
+		&i.WidthMm,
+		&i.HeightMm,
+		&i.Sku,
 	)
 	return i, err
 }
@@ -54,7 +109,7 @@ func (q *Queries) DeleteProduct(ctx context.Context, id int64) error {
 }
 
 const findProductByID = `-- name: FindProductByID :one
-SELECT id, name, description, price, stock, created_at, updated_at FROM products WHERE id = $1
+SELECT id, name, description, price, stock, created_at, updated_at, search_vector, currency, tax_class, weight_grams, length_mm, width_mm, height_mm, sku FROM products WHERE id = $1
 `
 
 func (q *Queries) FindProductByID(ctx context.Context, id int64) (Product, error) {
@@ -68,6 +123,14 @@ func (q *Queries) FindProductByID(ctx context.Context, id int64) (Product, error
 		&i.Stock,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SearchVector,
+		&i.Currency,
+		&i.TaxClass,
+		&i.WeightGrams,
+		&i.LengthMm,
+		&i.WidthMm,
+		&i.HeightMm,
+		&i.Sku,
 	)
 	return i, err
 }
@@ -78,8 +141,39 @@ FROM products
 WHERE name = $1
 `
 
-func (q *Queries) GetProductByName(ctx context.Context, name string) (Product, error) {
+type GetProductByNameRow struct {
+	ID          int64            `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Price       int64            `json:"price"`
+	Stock       int32            `json:"stock"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+}
+
+func (q *Queries) GetProductByName(ctx context.Context, name string) (GetProductByNameRow, error) {
 	row := q.db.QueryRow(ctx, getProductByName, name)
+	var i GetProductByNameRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Price,
+		&i.Stock,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getProductForUpdate = `-- name: GetProductForUpdate :one
+SELECT id, name, description, price, stock, created_at, updated_at, search_vector, currency, tax_class, weight_grams, length_mm, width_mm, height_mm, sku FROM products
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetProductForUpdate(ctx context.Context, id int64) (Product, error) {
+	row := q.db.QueryRow(ctx, getProductForUpdate, id)
 	var i Product
 	err := row.Scan(
 		&i.ID,
@@ -89,12 +183,20 @@ func (q *Queries) GetProductByName(ctx context.Context, name string) (Product, e
 		&i.Stock,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SearchVector,
+		&i.Currency,
+		&i.TaxClass,
+		&i.WeightGrams,
+		&i.LengthMm,
+		&i.WidthMm,
+		&i.HeightMm,
+		&i.Sku,
 	)
 	return i, err
 }
 
 const getProductsByIDs = `-- name: GetProductsByIDs :many
-SELECT id, name, description, price, stock, created_at, updated_at FROM products
+SELECT id, name, description, price, stock, created_at, updated_at, search_vector, currency, tax_class, weight_grams, length_mm, width_mm, height_mm, sku FROM products
 WHERE id = ANY($1)
 ORDER BY id
 `
@@ -116,6 +218,14 @@ func (q *Queries) GetProductsByIDs(ctx context.Context, id int64) ([]Product, er
 			&i.Stock,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.SearchVector,
+			&i.Currency,
+			&i.TaxClass,
+			&i.WeightGrams,
+			&i.LengthMm,
+			&i.WidthMm,
+			&i.HeightMm,
+			&i.Sku,
 		); err != nil {
 			return nil, err
 		}
@@ -128,7 +238,7 @@ func (q *Queries) GetProductsByIDs(ctx context.Context, id int64) ([]Product, er
 }
 
 const listProducts = `-- name: ListProducts :many
-SELECT id, name, description, price, stock, created_at, updated_at FROM products ORDER BY id
+SELECT id, name, description, price, stock, created_at, updated_at, search_vector, currency, tax_class, weight_grams, length_mm, width_mm, height_mm, sku FROM products ORDER BY id
 `
 
 func (q *Queries) ListProducts(ctx context.Context) ([]Product, error) {
@@ -148,6 +258,14 @@ func (q *Queries) ListProducts(ctx context.Context) ([]Product, error) {
 			&i.Stock,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.SearchVector,
+			&i.Currency,
+			&i.TaxClass,
+			&i.WeightGrams,
+			&i.LengthMm,
+			&i.WidthMm,
+			&i.HeightMm,
+			&i.Sku,
 		); err != nil {
 			return nil, err
 		}
@@ -159,6 +277,69 @@ func (q *Queries) ListProducts(ctx context.Context) ([]Product, error) {
 	return items, nil
 }
 
+const patchProduct = `-- name: PatchProduct :one
+UPDATE products
+SET name = COALESCE($1, name),
+    description = COALESCE($2, description),
+    price = COALESCE($3, price),
+    currency = COALESCE($4, currency),
+    tax_class = COALESCE($5, tax_class),
+    weight_grams = COALESCE($6, weight_grams),
+    length_mm = COALESCE($7, length_mm),
+    width_mm = COALESCE($8, width_mm),
+    height_mm = COALESCE($9, height_mm),
+    updated_at = NOW()
+WHERE id = $10
+RETURNING id, name, description, price, stock, created_at, updated_at, search_vector, currency, tax_class, weight_grams, length_mm, width_mm, height_mm, sku
+`
+
+type PatchProductParams struct {
+	Name        pgtype.Text `json:"name"`
+	Description pgtype.Text `json:"description"`
+	Price       pgtype.Int8 `json:"price"`
+	Currency    pgtype.Text `json:"currency"`
+	TaxClass    pgtype.Text `json:"tax_class"`
+	WeightGrams pgtype.Int4 `json:"weight_grams"`
+	LengthMm    pgtype.Int4 `json:"length_mm"`
+	WidthMm     pgtype.Int4 `json:"width_mm"`
+	HeightMm    pgtype.Int4 `json:"height_mm"`
+	ID          int64       `json:"id"`
+}
+
+func (q *Queries) PatchProduct(ctx context.Context, arg PatchProductParams) (Product, error) {
+	row := q.db.QueryRow(ctx, patchProduct,
+		arg.Name,
+		arg.Description,
+		arg.Price,
+		arg.Currency,
+		arg.TaxClass,
+		arg.WeightGrams,
+		arg.LengthMm,
+		arg.WidthMm,
+		arg.HeightMm,
+		arg.ID,
+	)
+	var i Product
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Price,
+		&i.Stock,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SearchVector,
+		&i.Currency,
+		&i.TaxClass,
+		&i.WeightGrams,
+		&i.LengthMm,
+		&i.WidthMm,
+		&i.HeightMm,
+		&i.Sku,
+	)
+	return i, err
+}
+
 const productExists = `-- name: ProductExists :one
 SELECT EXISTS(
     SELECT 1 FROM products WHERE name = $1
@@ -172,8 +353,70 @@ func (q *Queries) ProductExists(ctx context.Context, name string) (bool, error)
 	return exists, err
 }
 
+const searchProducts = `-- name: SearchProducts :many
+SELECT p.id, p.name, p.description, p.price, p.currency, p.stock, p.created_at, p.updated_at,
+    ts_rank(p.search_vector, q.query)::real AS rank,
+    ts_headline('english', p.name, q.query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true')::text AS name_snippet,
+    ts_headline('english', p.description, q.query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5')::text AS description_snippet
+FROM products p, to_tsquery('english', $1) AS q(query)
+WHERE p.search_vector @@ q.query
+ORDER BY rank DESC, p.id
+LIMIT $2
+`
+
+type SearchProductsParams struct {
+	Query     string `json:"query"`
+	PageLimit int32  `json:"page_limit"`
+}
+
+type SearchProductsRow struct {
+	ID                 int64            `json:"id"`
+	Name               string           `json:"name"`
+	Description        string           `json:"description"`
+	Price              int64            `json:"price"`
+	Currency           string           `json:"currency"`
+	Stock              int32            `json:"stock"`
+	CreatedAt          pgtype.Timestamp `json:"created_at"`
+	UpdatedAt          pgtype.Timestamp `json:"updated_at"`
+	Rank               float32          `json:"rank"`
+	NameSnippet        string           `json:"name_snippet"`
+	DescriptionSnippet string           `json:"description_snippet"`
+}
+
+func (q *Queries) SearchProducts(ctx context.Context, arg SearchProductsParams) ([]SearchProductsRow, error) {
+	rows, err := q.db.Query(ctx, searchProducts, arg.Query, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchProductsRow
+	for rows.Next() {
+		var i SearchProductsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.Price,
+			&i.Currency,
+			&i.Stock,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Rank,
+			&i.NameSnippet,
+			&i.DescriptionSnippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const searchProductsByName = `-- name: SearchProductsByName :many
-SELECT id, name, description, price, stock, created_at, updated_at FROM products
+SELECT id, name, description, price, stock, created_at, updated_at, search_vector, currency, tax_class, weight_grams, length_mm, width_mm, height_mm, sku FROM products
 WHERE name ILIKE '%' || $1 || '%'
 ORDER BY id
 `
@@ -195,6 +438,14 @@ func (q *Queries) SearchProductsByName(ctx context.Context, dollar_1 pgtype.Text
 			&i.Stock,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.SearchVector,
+			&i.Currency,
+			&i.TaxClass,
+			&i.WeightGrams,
+			&i.LengthMm,
+			&i.WidthMm,
+			&i.HeightMm,
+			&i.Sku,
 		); err != nil {
 			return nil, err
 		}
@@ -208,15 +459,22 @@ func (q *Queries) SearchProductsByName(ctx context.Context, dollar_1 pgtype.Text
 
 const updateProductDetails = `-- name: UpdateProductDetails :one
 UPDATE products
-SET name = $1, description = $2, price = $3, updated_at = NOW()
-WHERE id = $4
-RETURNING id, name, description, price, stock, created_at, updated_at
+SET name = $1, description = $2, price = $3, currency = $4, tax_class = $5,
+    weight_grams = $6, length_mm = $7, width_mm = $8, height_mm = $9, updated_at = NOW()
+WHERE id = $10
+RETURNING id, name, description, price, stock, created_at, updated_at, search_vector, currency, tax_class, weight_grams, length_mm, width_mm, height_mm, sku
 `
 
 type UpdateProductDetailsParams struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
-	Price       int32  `json:"price"`
+	Price       int64  `json:"price"`
+	Currency    string `json:"currency"`
+	TaxClass    string `json:"tax_class"`
+	WeightGrams int32  `json:"weight_grams"`
+	LengthMm    int32  `json:"length_mm"`
+	WidthMm     int32  `json:"width_mm"`
+	HeightMm    int32  `json:"height_mm"`
 	ID          int64  `json:"id"`
 }
 
@@ -225,6 +483,12 @@ func (q *Queries) UpdateProductDetails(ctx context.Context, arg UpdateProductDet
 		arg.Name,
 		arg.Description,
 		arg.Price,
+		arg.Currency,
+		arg.TaxClass,
+		arg.WeightGrams,
+		arg.LengthMm,
+		arg.WidthMm,
+		arg.HeightMm,
 		arg.ID,
 	)
 	var i Product
@@ -236,15 +500,23 @@ func (q *Queries) UpdateProductDetails(ctx context.Context, arg UpdateProductDet
 		&i.Stock,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SearchVector,
+		&i.Currency,
+		&i.TaxClass,
+		&i.WeightGrams,
+		&i.LengthMm,
+		&i.WidthMm,
+		&i.HeightMm,
+		&i.Sku,
 	)
 	return i, err
 }
 
 const updateProductStock = `-- name: UpdateProductStock :one
 UPDATE products
-SET stock = stock - $1
+SET stock = stock - $1, updated_at = NOW()
 WHERE id = $2 AND stock >= $1
-RETURNING id, name, description, price, stock, created_at, updated_at
+RETURNING id, name, description, price, stock, created_at, updated_at, search_vector, currency, tax_class, weight_grams, length_mm, width_mm, height_mm, sku
 `
 
 type UpdateProductStockParams struct {
@@ -263,6 +535,14 @@ func (q *Queries) UpdateProductStock(ctx context.Context, arg UpdateProductStock
 		&i.Stock,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SearchVector,
+		&i.Currency,
+		&i.TaxClass,
+		&i.WeightGrams,
+		&i.LengthMm,
+		&i.WidthMm,
+		&i.HeightMm,
+		&i.Sku,
 	)
 	return i, err
 }
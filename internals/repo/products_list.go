@@ -0,0 +1,114 @@
+package repo
+
+// This file is NOT generated by sqlc. The product listing picks its sort column
+// at request time, which sqlc cannot express, so the query is assembled here.
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// sortable product columns and the type used to cast the cursor value back
+var productSortColumns = map[string]string{
+	"id":         "bigint",
+	"price":      "bigint",
+	"created_at": "timestamp",
+	"name":       "text",
+}
+
+type ListProductsPageParams struct {
+	SortBy        string           `json:"sort_by"`
+	Descending    bool             `json:"descending"`
+	Currency      pgtype.Text      `json:"currency"`
+	MinPrice      pgtype.Int8      `json:"min_price"`
+	MaxPrice      pgtype.Int8      `json:"max_price"`
+	InStockOnly   bool             `json:"in_stock_only"`
+	CreatedAfter  pgtype.Timestamp `json:"created_after"`
+	CreatedBefore pgtype.Timestamp `json:"created_before"`
+	CategoryIDs   []int64          `json:"category_ids"`
+	CursorValue   pgtype.Text      `json:"cursor_value"`
+	CursorID      pgtype.Int8      `json:"cursor_id"`
+	PageLimit     int32            `json:"page_limit"`
+}
+
+func IsProductSortColumn(column string) bool {
+	_, ok := productSortColumns[column]
+	return ok
+}
+
+// ListProductsPage returns one keyset page of products ordered by (SortBy, id)
+func (q *Queries) ListProductsPage(ctx context.Context, arg ListProductsPageParams) ([]Product, error) {
+	castType, ok := productSortColumns[arg.SortBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported product sort column %q", arg.SortBy)
+	}
+
+	var conditions []string
+	var args []interface{}
+	addCondition := func(format string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, len(args)))
+	}
+
+	if arg.Currency.Valid {
+		addCondition("currency = $%d", arg.Currency.String)
+	}
+	if arg.MinPrice.Valid {
+		addCondition("price >= $%d", arg.MinPrice.Int64)
+	}
+	if arg.MaxPrice.Valid {
+		addCondition("price <= $%d", arg.MaxPrice.Int64)
+	}
+	if arg.InStockOnly {
+		// products with variants are in stock while any of their variants is
+		conditions = append(conditions, "(EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = products.id AND v.stock > 0)"+
+			" OR (stock > 0 AND NOT EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = products.id)))")
+	}
+	if arg.CreatedAfter.Valid {
+		addCondition("created_at >= $%d", arg.CreatedAfter)
+	}
+	if arg.CreatedBefore.Valid {
+		addCondition("created_at < $%d", arg.CreatedBefore)
+	}
+	if arg.CategoryIDs != nil {
+		addCondition("id IN (SELECT product_id FROM product_categories WHERE category_id = ANY($%d::bigint[]))", arg.CategoryIDs)
+	}
+
+	direction, op := "ASC", ">"
+	if arg.Descending {
+		direction, op = "DESC", "<"
+	}
+
+	if arg.CursorID.Valid {
+		if arg.SortBy == "id" {
+			addCondition("id "+op+" $%d", arg.CursorID.Int64)
+		} else {
+			args = append(args, arg.CursorValue.String, arg.CursorID.Int64)
+			conditions = append(conditions, fmt.Sprintf("(%s, id) %s ($%d::%s, $%d)",
+				arg.SortBy, op, len(args)-1, castType, len(args)))
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("SELECT * FROM products")
+	if len(conditions) > 0 {
+		sb.WriteString(" WHERE " + strings.Join(conditions, " AND "))
+	}
+	if arg.SortBy == "id" {
+		sb.WriteString(fmt.Sprintf(" ORDER BY id %s", direction))
+	} else {
+		sb.WriteString(fmt.Sprintf(" ORDER BY %s %s, id %s", arg.SortBy, direction, direction))
+	}
+	args = append(args, arg.PageLimit)
+	sb.WriteString(fmt.Sprintf(" LIMIT $%d", len(args)))
+
+	rows, err := q.db.Query(ctx, sb.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[Product])
+}
@@ -11,25 +11,225 @@ import (
 )
 
 type Querier interface {
+	AddCartItem(ctx context.Context, arg AddCartItemParams) (CartItem, error)
+	AddInventoryMovement(ctx context.Context, arg AddInventoryMovementParams) (InventoryMovement, error)
+	AddOrderAddress(ctx context.Context, arg AddOrderAddressParams) (OrderAddress, error)
 	AddOrderItem(ctx context.Context, arg AddOrderItemParams) (OrderItem, error)
-	CreateOrder(ctx context.Context, customerRef string) (Order, error)
+	AddOrderItemReturn(ctx context.Context, arg AddOrderItemReturnParams) (OrderItem, error)
+	AddOrderRefundedTotal(ctx context.Context, arg AddOrderRefundedTotalParams) (Order, error)
+	AddOrderStatusHistory(ctx context.Context, arg AddOrderStatusHistoryParams) (OrderStatusHistory, error)
+	AddOutboxEvent(ctx context.Context, arg AddOutboxEventParams) error
+	AddPaymentStatusHistory(ctx context.Context, arg AddPaymentStatusHistoryParams) (PaymentStatusHistory, error)
+	AddProductCategory(ctx context.Context, arg AddProductCategoryParams) error
+	AddPromotionCategory(ctx context.Context, arg AddPromotionCategoryParams) error
+	AddPromotionProduct(ctx context.Context, arg AddPromotionProductParams) error
+	AddReturnItem(ctx context.Context, arg AddReturnItemParams) (ReturnItem, error)
+	AddShippingRate(ctx context.Context, arg AddShippingRateParams) (ShippingRate, error)
+	AddVariantOptionValue(ctx context.Context, arg AddVariantOptionValueParams) error
+	AddWebhookDeliveryAttempt(ctx context.Context, arg AddWebhookDeliveryAttemptParams) (WebhookDeliveryAttempt, error)
+	AdjustProductStock(ctx context.Context, arg AdjustProductStockParams) (Product, error)
+	AdjustVariantStock(ctx context.Context, arg AdjustVariantStockParams) (ProductVariant, error)
+	ApproveReturn(ctx context.Context, arg ApproveReturnParams) (Return, error)
+	BackfillCustomersFromOrders(ctx context.Context) (int64, error)
+	CancelOrder(ctx context.Context, arg CancelOrderParams) (Order, error)
+	ClaimIdempotencyKey(ctx context.Context, arg ClaimIdempotencyKeyParams) (IdempotencyKey, error)
+	ClaimOutboxEvents(ctx context.Context, arg ClaimOutboxEventsParams) ([]Outbox, error)
+	ClaimStalePaymentRefunds(ctx context.Context, arg ClaimStalePaymentRefundsParams) ([]PaymentRefund, error)
+	ClaimStalePendingPayments(ctx context.Context, arg ClaimStalePendingPaymentsParams) ([]Payment, error)
+	ClaimWebhookDeliveries(ctx context.Context, arg ClaimWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ClearPrimaryProductImage(ctx context.Context, productID int64) error
+	CommitOrderReservations(ctx context.Context, orderID int64) ([]Reservation, error)
+	CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) (IdempotencyKey, error)
+	CopyInventoryMovements(ctx context.Context, arg []CopyInventoryMovementsParams) (int64, error)
+	CopyOutboxEvents(ctx context.Context, arg []CopyOutboxEventsParams) (int64, error)
+	CopyProducts(ctx context.Context, arg []CopyProductsParams) (int64, error)
+	CountCustomerRedemptions(ctx context.Context, arg CountCustomerRedemptionsParams) (int64, error)
+	CountOpenPayments(ctx context.Context, orderID int64) (int64, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
+	CreateCart(ctx context.Context, arg CreateCartParams) (Cart, error)
+	CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error)
+	CreateCustomer(ctx context.Context, arg CreateCustomerParams) (Customer, error)
+	CreateOptionType(ctx context.Context, name string) (OptionType, error)
+	CreateOrder(ctx context.Context, arg CreateOrderParams) (Order, error)
+	CreatePayment(ctx context.Context, arg CreatePaymentParams) (Payment, error)
+	CreatePaymentRefund(ctx context.Context, arg CreatePaymentRefundParams) (PaymentRefund, error)
 	CreateProduct(ctx context.Context, arg CreateProductParams) (Product, error)
+	CreateProductImage(ctx context.Context, arg CreateProductImageParams) (ProductImage, error)
+	CreatePromotion(ctx context.Context, arg CreatePromotionParams) (Promotion, error)
+	CreatePromotionRedemption(ctx context.Context, arg CreatePromotionRedemptionParams) (PromotionRedemption, error)
+	CreateRefund(ctx context.Context, arg CreateRefundParams) (Refund, error)
+	CreateReservation(ctx context.Context, arg CreateReservationParams) (Reservation, error)
+	CreateReturn(ctx context.Context, arg CreateReturnParams) (Return, error)
+	CreateShippingMethod(ctx context.Context, arg CreateShippingMethodParams) (ShippingMethod, error)
+	CreateVariant(ctx context.Context, arg CreateVariantParams) (ProductVariant, error)
+	CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error)
+	DeactivatePromotion(ctx context.Context, id int64) (Promotion, error)
+	DeactivateShippingMethod(ctx context.Context, id int64) (ShippingMethod, error)
+	DeleteCategory(ctx context.Context, id int64) (int64, error)
+	DeleteCustomer(ctx context.Context, id int64) error
+	DeleteExchangeRate(ctx context.Context, arg DeleteExchangeRateParams) (int64, error)
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+	DeleteOptionType(ctx context.Context, id int64) (int64, error)
 	DeleteOrder(ctx context.Context, id int64) error
 	DeleteOrderItemsByOrderID(ctx context.Context, orderID int64) error
 	DeleteProduct(ctx context.Context, id int64) error
+	DeleteProductCategories(ctx context.Context, productID int64) error
+	DeleteProductImage(ctx context.Context, arg DeleteProductImageParams) (ProductImage, error)
+	DeleteProductPrice(ctx context.Context, arg DeleteProductPriceParams) (int64, error)
+	DeletePublishedOutboxEvents(ctx context.Context, retentionSeconds int32) (int64, error)
+	DeleteTaxRate(ctx context.Context, arg DeleteTaxRateParams) (int64, error)
+	DeleteVariant(ctx context.Context, id int64) (int64, error)
+	DeleteVariantOptionValues(ctx context.Context, variantID int64) error
+	DeleteWebhookSubscription(ctx context.Context, id int64) (int64, error)
+	EnqueueWebhookDeliveries(ctx context.Context, arg EnqueueWebhookDeliveriesParams) (int64, error)
+	ExpireCarts(ctx context.Context) (int64, error)
 	FindProductByID(ctx context.Context, id int64) (Product, error)
+	GetActiveAPIKeyByHash(ctx context.Context, keyHash string) (ApiKey, error)
 	GetAllOrders(ctx context.Context) ([]Order, error)
+	GetCapturedOrderPayment(ctx context.Context, orderID int64) (Payment, error)
+	GetCart(ctx context.Context, id int64) (Cart, error)
+	GetCategory(ctx context.Context, id int64) (Category, error)
+	GetCustomerByID(ctx context.Context, id int64) (Customer, error)
+	GetCustomerByRef(ctx context.Context, customerRef string) (Customer, error)
+	GetExchangeRate(ctx context.Context, arg GetExchangeRateParams) (ExchangeRate, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
 	GetOrder(ctx context.Context, id int64) (Order, error)
+	GetOrderForUpdate(ctx context.Context, id int64) (Order, error)
 	GetOrdersByCustomerRef(ctx context.Context, customerRef string) ([]Order, error)
-	GetProductByName(ctx context.Context, name string) (Product, error)
+	GetPayment(ctx context.Context, id int64) (Payment, error)
+	GetPaymentByGatewayRefForUpdate(ctx context.Context, arg GetPaymentByGatewayRefForUpdateParams) (Payment, error)
+	GetPaymentForUpdate(ctx context.Context, id int64) (Payment, error)
+	GetPaymentRefundByReference(ctx context.Context, arg GetPaymentRefundByReferenceParams) (PaymentRefund, error)
+	GetPaymentRefundForUpdate(ctx context.Context, id int64) (PaymentRefund, error)
+	GetProductByName(ctx context.Context, name string) (GetProductByNameRow, error)
+	GetProductForUpdate(ctx context.Context, id int64) (Product, error)
+	GetProductImage(ctx context.Context, arg GetProductImageParams) (ProductImage, error)
 	GetProductsByIDs(ctx context.Context, id int64) ([]Product, error)
+	GetPromotion(ctx context.Context, id int64) (Promotion, error)
+	GetPromotionByCodeForUpdate(ctx context.Context, code string) (Promotion, error)
+	GetReservedQuantity(ctx context.Context, productID int64) (int32, error)
+	GetReturn(ctx context.Context, id int64) (Return, error)
+	GetReturnForUpdate(ctx context.Context, id int64) (Return, error)
+	GetReturnRefund(ctx context.Context, returnID int64) (Refund, error)
+	GetReturnRefundForUpdate(ctx context.Context, returnID int64) (Refund, error)
+	GetShippingMethod(ctx context.Context, id int64) (ShippingMethod, error)
+	GetShippingMethodByCode(ctx context.Context, code string) (ShippingMethod, error)
+	GetVariant(ctx context.Context, id int64) (ProductVariant, error)
+	GetVariantForUpdate(ctx context.Context, id int64) (ProductVariant, error)
+	GetVariantReservedQuantity(ctx context.Context, variantID pgtype.Int8) (int32, error)
+	GetWebhookDelivery(ctx context.Context, arg GetWebhookDeliveryParams) (WebhookDelivery, error)
+	GetWebhookSubscription(ctx context.Context, id int64) (WebhookSubscription, error)
+	IncrementPromotionUsage(ctx context.Context, id int64) (Promotion, error)
+	ListAPIKeys(ctx context.Context) ([]ApiKey, error)
+	ListActiveShippingMethods(ctx context.Context) ([]ShippingMethod, error)
+	ListCartItems(ctx context.Context, cartID int64) ([]CartItem, error)
+	ListCategories(ctx context.Context) ([]Category, error)
+	ListCategoryAncestors(ctx context.Context, id int64) ([]Category, error)
+	ListCategorySubtreeIDs(ctx context.Context, id int64) ([]int64, error)
+	ListChildCategories(ctx context.Context, parentID pgtype.Int8) ([]Category, error)
+	ListCustomersPage(ctx context.Context, arg ListCustomersPageParams) ([]Customer, error)
+	ListExchangeRates(ctx context.Context) ([]ExchangeRate, error)
+	ListInventoryMovementsPage(ctx context.Context, arg ListInventoryMovementsPageParams) ([]InventoryMovement, error)
+	ListOptionTypes(ctx context.Context) ([]OptionType, error)
+	ListOptionTypesByNames(ctx context.Context, names []string) ([]OptionType, error)
+	ListOrderAddresses(ctx context.Context, orderID int64) ([]OrderAddress, error)
 	ListOrderItems(ctx context.Context, orderID int64) ([]OrderItem, error)
+	ListOrderPayments(ctx context.Context, orderID int64) ([]Payment, error)
+	ListOrderReservations(ctx context.Context, orderID int64) ([]Reservation, error)
+	ListOrderReturns(ctx context.Context, orderID int64) ([]Return, error)
+	ListOrderStatusHistory(ctx context.Context, orderID int64) ([]OrderStatusHistory, error)
+	ListOrdersByCustomerRefPage(ctx context.Context, arg ListOrdersByCustomerRefPageParams) ([]Order, error)
+	ListOrdersPage(ctx context.Context, arg ListOrdersPageParams) ([]Order, error)
+	ListOrdersWithExpiredReservations(ctx context.Context, limit int32) ([]int64, error)
+	ListPaymentRefunds(ctx context.Context, paymentID int64) ([]PaymentRefund, error)
+	ListPaymentStatusHistory(ctx context.Context, paymentID int64) ([]PaymentStatusHistory, error)
+	ListPaymentsOutOfSync(ctx context.Context, limit int32) ([]Payment, error)
+	ListProductCategories(ctx context.Context, productID int64) ([]Category, error)
+	ListProductImages(ctx context.Context, productID int64) ([]ProductImage, error)
+	ListProductPrices(ctx context.Context, productID int64) ([]ProductPrice, error)
+	ListProductPricesIn(ctx context.Context, arg ListProductPricesInParams) ([]ProductPrice, error)
+	ListProductVariantSummaries(ctx context.Context, productIds []int64) ([]ListProductVariantSummariesRow, error)
+	ListProductVariants(ctx context.Context, productID int64) ([]ProductVariant, error)
 	ListProducts(ctx context.Context) ([]Product, error)
+	ListProductsAfter(ctx context.Context, arg ListProductsAfterParams) ([]Product, error)
+	ListProductsForImport(ctx context.Context, arg ListProductsForImportParams) ([]Product, error)
+	ListPromotionCategories(ctx context.Context, promotionID int64) ([]int64, error)
+	ListPromotionCategoryProducts(ctx context.Context, arg ListPromotionCategoryProductsParams) ([]int64, error)
+	ListPromotionProducts(ctx context.Context, promotionID int64) ([]int64, error)
+	ListPromotionsPage(ctx context.Context, arg ListPromotionsPageParams) ([]Promotion, error)
+	ListRequestedReturnQuantities(ctx context.Context, orderID int64) ([]ListRequestedReturnQuantitiesRow, error)
+	ListReservedQuantities(ctx context.Context, productIds []int64) ([]ListReservedQuantitiesRow, error)
+	ListReturnItems(ctx context.Context, returnID int64) ([]ReturnItem, error)
+	ListReturnsPage(ctx context.Context, arg ListReturnsPageParams) ([]Return, error)
+	ListShippingMethods(ctx context.Context) ([]ShippingMethod, error)
+	ListShippingRates(ctx context.Context, methodIds []int64) ([]ShippingRate, error)
+	ListStockDrift(ctx context.Context) ([]ListStockDriftRow, error)
+	ListTaxRates(ctx context.Context) ([]TaxRate, error)
+	ListTaxRatesIn(ctx context.Context, jurisdictions []string) ([]TaxRate, error)
+	ListUnqueuedOutboxEvents(ctx context.Context, limit int32) ([]Outbox, error)
+	ListVariantOptionValues(ctx context.Context, variantIds []int64) ([]ListVariantOptionValuesRow, error)
+	ListVariantStockDrift(ctx context.Context) ([]ListVariantStockDriftRow, error)
+	ListVariantsByProductIDs(ctx context.Context, productIds []int64) ([]ProductVariant, error)
+	ListWebhookDeliveriesPage(ctx context.Context, arg ListWebhookDeliveriesPageParams) ([]WebhookDelivery, error)
+	ListWebhookDeliveryAttempts(ctx context.Context, deliveryID int64) ([]WebhookDeliveryAttempt, error)
+	ListWebhookSubscriptions(ctx context.Context) ([]WebhookSubscription, error)
+	ListWebhookSubscriptionsByCustomer(ctx context.Context, customerRef pgtype.Text) ([]WebhookSubscription, error)
+	LockCategoryTree(ctx context.Context) error
+	MarkCartCheckedOut(ctx context.Context, arg MarkCartCheckedOutParams) (Cart, error)
+	MarkOutboxEventPublished(ctx context.Context, id int64) error
+	MarkOutboxEventsQueued(ctx context.Context, ids []int64) error
+	MarkWebhookDeliveryFailed(ctx context.Context, arg MarkWebhookDeliveryFailedParams) (WebhookDelivery, error)
+	MarkWebhookDeliverySucceeded(ctx context.Context, id int64) (WebhookDelivery, error)
+	MoveCategory(ctx context.Context, arg MoveCategoryParams) (Category, error)
+	NextProductIDs(ctx context.Context, count int32) ([]int64, error)
+	NextProductImagePosition(ctx context.Context, productID int64) (int32, error)
+	OrderReachedStatus(ctx context.Context, arg OrderReachedStatusParams) (bool, error)
+	PatchProduct(ctx context.Context, arg PatchProductParams) (Product, error)
 	ProductExists(ctx context.Context, name string) (bool, error)
+	ProductHasPrimaryImage(ctx context.Context, productID int64) (bool, error)
+	ProductHasVariants(ctx context.Context, productID int64) (bool, error)
+	PromoteFirstProductImage(ctx context.Context, productID int64) error
+	ReceiveReturn(ctx context.Context, arg ReceiveReturnParams) (Return, error)
+	RecordOutboxFailure(ctx context.Context, arg RecordOutboxFailureParams) error
+	RecordPaymentWebhookEvent(ctx context.Context, arg RecordPaymentWebhookEventParams) (int64, error)
+	RedeliverWebhookDelivery(ctx context.Context, arg RedeliverWebhookDeliveryParams) (WebhookDelivery, error)
+	RejectReturn(ctx context.Context, arg RejectReturnParams) (Return, error)
+	ReleaseActiveOrderReservations(ctx context.Context, orderID int64) (int64, error)
+	ReleaseCommittedOrderReservations(ctx context.Context, orderID int64) ([]Reservation, error)
+	ReleaseExpiredOrderReservations(ctx context.Context, orderID int64) (int64, error)
+	ReleaseIdempotencyKey(ctx context.Context, id int64) error
+	ReleaseOutboxEvents(ctx context.Context, ids []int64) error
+	RemoveCartItem(ctx context.Context, arg RemoveCartItemParams) (int64, error)
+	RevokeAPIKey(ctx context.Context, id int64) (ApiKey, error)
+	SearchProducts(ctx context.Context, arg SearchProductsParams) ([]SearchProductsRow, error)
 	SearchProductsByName(ctx context.Context, dollar_1 pgtype.Text) ([]Product, error)
+	SetCartItemQuantity(ctx context.Context, arg SetCartItemQuantityParams) (CartItem, error)
+	SetPaymentGatewayRef(ctx context.Context, arg SetPaymentGatewayRefParams) (Payment, error)
+	SetProductImagePosition(ctx context.Context, arg SetProductImagePositionParams) error
+	SetProductStock(ctx context.Context, arg SetProductStockParams) (Product, error)
+	SetRefundPayment(ctx context.Context, arg SetRefundPaymentParams) (Refund, error)
+	SetReturnItemRefund(ctx context.Context, arg SetReturnItemRefundParams) error
+	SetVariantStock(ctx context.Context, arg SetVariantStockParams) (ProductVariant, error)
+	SumPaymentRefunds(ctx context.Context, arg SumPaymentRefundsParams) (int64, error)
+	TouchAPIKey(ctx context.Context, id int64) error
+	TouchCart(ctx context.Context, arg TouchCartParams) (Cart, error)
+	TryLockOutboxRelay(ctx context.Context) (bool, error)
+	UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (Category, error)
+	UpdateCustomer(ctx context.Context, arg UpdateCustomerParams) (Customer, error)
+	UpdateImportedProducts(ctx context.Context, arg UpdateImportedProductsParams) error
+	UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusParams) (Order, error)
 	UpdateOrderTotalPrice(ctx context.Context, arg UpdateOrderTotalPriceParams) (Order, error)
+	UpdatePaymentRefundStatus(ctx context.Context, arg UpdatePaymentRefundStatusParams) (PaymentRefund, error)
+	UpdatePaymentStatus(ctx context.Context, arg UpdatePaymentStatusParams) (Payment, error)
 	UpdateProductDetails(ctx context.Context, arg UpdateProductDetailsParams) (Product, error)
+	UpdateProductImage(ctx context.Context, arg UpdateProductImageParams) (ProductImage, error)
 	UpdateProductStock(ctx context.Context, arg UpdateProductStockParams) (Product, error)
+	UpdateRefundStatus(ctx context.Context, arg UpdateRefundStatusParams) (Refund, error)
+	UpdateVariant(ctx context.Context, arg UpdateVariantParams) (ProductVariant, error)
+	UpdateWebhookSubscription(ctx context.Context, arg UpdateWebhookSubscriptionParams) (WebhookSubscription, error)
+	UpsertExchangeRate(ctx context.Context, arg UpsertExchangeRateParams) (ExchangeRate, error)
+	UpsertProductPrice(ctx context.Context, arg UpsertProductPriceParams) (ProductPrice, error)
+	UpsertTaxRate(ctx context.Context, arg UpsertTaxRateParams) (TaxRate, error)
 }
 
 var _ Querier = (*Queries)(nil)
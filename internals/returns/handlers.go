@@ -0,0 +1,207 @@
+package returns
+
+import (
+	"ecomApis/internals/auth"
+	"ecomApis/internals/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(s *Service) *Handler {
+	return &Handler{
+		service: s,
+	}
+}
+
+// CreateReturn handles POST /orders/{id}/returns
+func (h *Handler) CreateReturn(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid order ID"})
+		return
+	}
+
+	var req CreateReturnRequest
+	err = utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	p, _ := auth.FromContext(r.Context())
+	details, err := h.service.CreateReturn(r.Context(), orderID, req, p)
+	if err != nil {
+		writeReturnError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, details)
+}
+
+// ListOrderReturns handles GET /orders/{id}/returns
+func (h *Handler) ListOrderReturns(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid order ID"})
+		return
+	}
+
+	p, _ := auth.FromContext(r.Context())
+	rets, err := h.service.ListOrderReturns(r.Context(), orderID, p)
+	if err != nil {
+		writeReturnError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, rets)
+}
+
+// ListReturns handles GET /returns?status=
+func (h *Handler) ListReturns(w http.ResponseWriter, r *http.Request) {
+	limit, err := utils.ParseLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		writeReturnError(w, err)
+		return
+	}
+
+	page, err := h.service.ListReturns(r.Context(), r.URL.Query().Get("status"), limit, r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeReturnError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, page)
+}
+
+func (h *Handler) GetReturn(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid return ID"})
+		return
+	}
+
+	p, _ := auth.FromContext(r.Context())
+	details, err := h.service.GetReturn(r.Context(), id, p)
+	if err != nil {
+		writeReturnError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, details)
+}
+
+// ApproveReturn handles POST /returns/{id}/approve
+func (h *Handler) ApproveReturn(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid return ID"})
+		return
+	}
+
+	var req ResolveRequest
+	err = utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	p, _ := auth.FromContext(r.Context())
+	details, err := h.service.Approve(r.Context(), id, req, p.Subject)
+	if err != nil {
+		writeReturnError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, details)
+}
+
+// RejectReturn handles POST /returns/{id}/reject
+func (h *Handler) RejectReturn(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid return ID"})
+		return
+	}
+
+	var req ResolveRequest
+	err = utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	p, _ := auth.FromContext(r.Context())
+	details, err := h.service.Reject(r.Context(), id, req, p.Subject)
+	if err != nil {
+		writeReturnError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, details)
+}
+
+// ReceiveReturn handles POST /returns/{id}/receive
+func (h *Handler) ReceiveReturn(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid return ID"})
+		return
+	}
+
+	var req ReceiveRequest
+	err = utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	p, _ := auth.FromContext(r.Context())
+	details, err := h.service.Receive(r.Context(), id, req, p.Subject)
+	if err != nil {
+		writeReturnError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, details)
+}
+
+// RetryRefund handles POST /returns/{id}/refund
+func (h *Handler) RetryRefund(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid return ID"})
+		return
+	}
+
+	p, _ := auth.FromContext(r.Context())
+	details, err := h.service.RetryRefund(r.Context(), id, p.Subject)
+	if err != nil {
+		writeReturnError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, details)
+}
+
+func writeReturnError(w http.ResponseWriter, err error) {
+	switch e := err.(type) {
+	case *utils.ValidationError:
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": e.Error()})
+	case *utils.AuthorizationError:
+		auth.WriteError(w, e)
+	case *utils.NotFoundError:
+		utils.WriteJSON(w, http.StatusNotFound, map[string]string{"error": e.Error()})
+	case *utils.InvalidTransitionError:
+		utils.WriteJSON(w, http.StatusConflict, map[string]string{"error": e.Error()})
+	case *utils.DatabaseError:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": e.Error()})
+	default:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,149 @@
+package returns
+
+import (
+	"context"
+	"database/sql"
+	"ecomApis/internals/payments"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// lineRefund is what returning quantity more units of item is worth. The line is refunded at
+// what the customer paid for it, its unit price less its discount plus its tax, and every
+// return takes its share of the line so that returning all units refunds exactly the line total
+func lineRefund(item repo.OrderItem, pricesIncludeTax bool, quantity int32) int64 {
+	total := item.UnitPrice*int64(item.Quantity) - item.Discount
+	if !pricesIncludeTax {
+		total += item.TaxAmount
+	}
+
+	share := new(big.Int).Mul(big.NewInt(total), big.NewInt(int64(item.ReturnedQuantity+quantity)))
+	share.Quo(share, big.NewInt(int64(item.Quantity)))
+	return share.Int64() - item.RefundedAmount
+}
+
+// issueRefund sends the refund of an approved return back through the order's payment.
+// The payment and the reference the refund is sent under are written on the refund first, and
+// no transaction is held while the payment service talks to the gateway. A retry sends the same
+// reference to the same payment, which answers with the refund it already made instead of
+// making it twice. The outcome is stored on the refund, only database failures are returned.
+// Without a captured payment, e.g. for orders paid outside the gateway, the refund stays pending
+func (s *Service) issueRefund(ctx context.Context, returnID int64, actor string) error {
+	refund, err := s.prepareRefund(ctx, returnID)
+	if err != nil || refund.Status == RefundCompleted || !refund.PaymentID.Valid || s.payments == nil {
+		return err
+	}
+
+	amount := refund.AmountMoney()
+	_, err = s.payments.Refund(ctx, refund.PaymentID.Int64, payments.RefundRequest{
+		Amount:    &amount,
+		Note:      "return " + strconv.FormatInt(returnID, 10),
+		Reference: refund.PaymentReference.String,
+	}, actor)
+
+	update := repo.UpdateRefundStatusParams{Status: RefundCompleted, ID: refund.ID}
+	if err != nil {
+		update = repo.UpdateRefundStatusParams{
+			Status:         RefundFailed,
+			FailureMessage: pgtype.Text{String: err.Error(), Valid: true},
+			ID:             refund.ID,
+		}
+	}
+	return s.recordRefund(ctx, returnID, update)
+}
+
+// prepareRefund picks the payment the refund of a return goes back through and writes it on
+// the refund with the reference it is sent under. A refund that was sent before keeps its
+// payment and reference. A refund of nothing is completed right away, one without a payment
+// to go back through is returned without one
+func (s *Service) prepareRefund(ctx context.Context, returnID int64) (repo.Refund, error) {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return repo.Refund{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	refund, err := qtx.GetReturnRefundForUpdate(ctx, returnID)
+	if err != nil {
+		tx.Rollback(ctx)
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return repo.Refund{}, &utils.NotFoundError{Resource: "Refund of return", ID: strconv.FormatInt(returnID, 10)}
+		}
+		return repo.Refund{}, &utils.DatabaseError{Query: "GetReturnRefundForUpdate", Err: err}
+	}
+
+	switch {
+	case refund.Status == RefundCompleted || refund.PaymentID.Valid:
+		tx.Rollback(ctx)
+		return refund, nil
+	case refund.Amount == 0:
+		refund, err = qtx.UpdateRefundStatus(ctx, repo.UpdateRefundStatusParams{Status: RefundCompleted, ID: refund.ID})
+		if err != nil {
+			tx.Rollback(ctx)
+			return repo.Refund{}, &utils.DatabaseError{Query: "UpdateRefundStatus", Err: err}
+		}
+	case s.payments == nil:
+		tx.Rollback(ctx)
+		return refund, nil
+	default:
+		payment, err := qtx.GetCapturedOrderPayment(ctx, refund.OrderID)
+		if err != nil {
+			tx.Rollback(ctx)
+			if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+				return refund, nil
+			}
+			return repo.Refund{}, &utils.DatabaseError{Query: "GetCapturedOrderPayment", Err: err}
+		}
+
+		refund, err = qtx.SetRefundPayment(ctx, repo.SetRefundPaymentParams{
+			PaymentID:        pgtype.Int8{Int64: payment.ID, Valid: true},
+			PaymentReference: pgtype.Text{String: "return_" + strconv.FormatInt(returnID, 10), Valid: true},
+			ID:               refund.ID,
+		})
+		if err != nil {
+			tx.Rollback(ctx)
+			return repo.Refund{}, &utils.DatabaseError{Query: "SetRefundPayment", Err: err}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return repo.Refund{}, fmt.Errorf("commit tx: %w", err)
+	}
+	return refund, nil
+}
+
+// recordRefund stores how sending the refund went, unless it completed in the meantime
+func (s *Service) recordRefund(ctx context.Context, returnID int64, update repo.UpdateRefundStatusParams) error {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	refund, err := qtx.GetReturnRefundForUpdate(ctx, returnID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return &utils.DatabaseError{Query: "GetReturnRefundForUpdate", Err: err}
+	}
+	if refund.Status == RefundCompleted {
+		tx.Rollback(ctx)
+		return nil
+	}
+
+	_, err = qtx.UpdateRefundStatus(ctx, update)
+	if err != nil {
+		tx.Rollback(ctx)
+		return &utils.DatabaseError{Query: "UpdateRefundStatus", Err: err}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,553 @@
+package returns
+
+import (
+	"context"
+	"database/sql"
+	"ecomApis/internals/auth"
+	"ecomApis/internals/inventory"
+	"ecomApis/internals/orders"
+	"ecomApis/internals/payments"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// orders whose items can be sent back, unpaid orders are cancelled instead
+var returnableStatuses = map[string]bool{
+	orders.StatusPaid:      true,
+	orders.StatusFulfilled: true,
+	orders.StatusShipped:   true,
+	orders.StatusDelivered: true,
+}
+
+type Service struct {
+	repo     *repo.Queries
+	db       *pgxpool.Pool
+	payments *payments.PaymentService
+}
+
+// NewService builds the returns service, without a payment service refunds are only recorded
+func NewService(r *repo.Queries, db *pgxpool.Pool, payments *payments.PaymentService) *Service {
+	return &Service{
+		repo:     r,
+		db:       db,
+		payments: payments,
+	}
+}
+
+// CreateReturn opens a return for some items of an order. The order is locked so two requests
+// cannot both claim the same units, quantities already returned or waiting in another
+// request cannot be asked for again
+func (s *Service) CreateReturn(ctx context.Context, orderID int64, req CreateReturnRequest, p auth.Principal) (ReturnDetails, error) {
+	if len(req.Items) == 0 {
+		return ReturnDetails{}, &utils.ValidationError{Field: "items", Message: "cannot be empty"}
+	}
+	seen := map[int64]bool{}
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			return ReturnDetails{}, &utils.ValidationError{Field: "quantity", Message: "must be positive"}
+		}
+		if seen[item.OrderItemID] {
+			return ReturnDetails{}, &utils.ValidationError{
+				Field:   "items",
+				Message: fmt.Sprintf("order item %d is listed more than once", item.OrderItemID),
+			}
+		}
+		seen[item.OrderItemID] = true
+	}
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return ReturnDetails{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	order, err := qtx.GetOrderForUpdate(ctx, orderID)
+	if err != nil {
+		tx.Rollback(ctx)
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return ReturnDetails{}, &utils.NotFoundError{Resource: "Order", ID: strconv.FormatInt(orderID, 10)}
+		}
+		return ReturnDetails{}, &utils.DatabaseError{Query: "GetOrderForUpdate", Err: err}
+	}
+	if !p.CanAccessCustomer(order.CustomerRef) {
+		tx.Rollback(ctx)
+		return ReturnDetails{}, &utils.AuthorizationError{Action: "return items of order " + strconv.FormatInt(orderID, 10)}
+	}
+	if !returnableStatuses[order.Status] {
+		tx.Rollback(ctx)
+		return ReturnDetails{}, &utils.ValidationError{
+			Field:   "order",
+			Message: fmt.Sprintf("items of a %s order cannot be returned", order.Status),
+		}
+	}
+
+	items, err := qtx.ListOrderItems(ctx, orderID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return ReturnDetails{}, &utils.DatabaseError{Query: "ListOrderItems", Err: err}
+	}
+	byID := make(map[int64]repo.OrderItem, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+
+	requested, err := qtx.ListRequestedReturnQuantities(ctx, orderID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return ReturnDetails{}, &utils.DatabaseError{Query: "ListRequestedReturnQuantities", Err: err}
+	}
+	pending := make(map[int64]int64, len(requested))
+	for _, row := range requested {
+		pending[row.OrderItemID] = row.Quantity
+	}
+
+	ret, err := qtx.CreateReturn(ctx, repo.CreateReturnParams{
+		OrderID:     orderID,
+		CustomerRef: order.CustomerRef,
+		Reason:      req.Reason,
+		Currency:    order.Currency,
+		RequestedBy: p.Subject,
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		return ReturnDetails{}, &utils.DatabaseError{Query: "CreateReturn", Err: err}
+	}
+
+	returnItems := make([]repo.ReturnItem, 0, len(req.Items))
+	for _, reqItem := range req.Items {
+		item, ok := byID[reqItem.OrderItemID]
+		if !ok {
+			tx.Rollback(ctx)
+			return ReturnDetails{}, &utils.NotFoundError{Resource: "Order item", ID: strconv.FormatInt(reqItem.OrderItemID, 10)}
+		}
+
+		available := int64(item.Quantity-item.ReturnedQuantity) - pending[item.ID]
+		if int64(reqItem.Quantity) > available {
+			tx.Rollback(ctx)
+			return ReturnDetails{}, &utils.ValidationError{
+				Field:   "quantity",
+				Message: fmt.Sprintf("only %d of order item %d can still be returned", max(available, 0), item.ID),
+			}
+		}
+
+		returnItem, err := qtx.AddReturnItem(ctx, repo.AddReturnItemParams{
+			ReturnID:    ret.ID,
+			OrderItemID: item.ID,
+			ProductID:   item.ProductID,
+			Quantity:    reqItem.Quantity,
+			Currency:    item.Currency,
+		})
+		if err != nil {
+			tx.Rollback(ctx)
+			return ReturnDetails{}, &utils.DatabaseError{Query: "AddReturnItem", Err: err}
+		}
+		returnItems = append(returnItems, returnItem)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return ReturnDetails{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return ReturnDetails{Return: ret, Items: returnItems}, nil
+}
+
+// Approve accepts a return and grants its refund. The returned quantities and refunded
+// amounts are added to the order right away, the money goes back through the order's
+// payment once the approval is committed
+func (s *Service) Approve(ctx context.Context, id int64, req ResolveRequest, actor string) (ReturnDetails, error) {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return ReturnDetails{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	ret, err := lockReturn(ctx, qtx, id)
+	if err != nil {
+		tx.Rollback(ctx)
+		return ReturnDetails{}, err
+	}
+	if ret.Status != StatusRequested {
+		tx.Rollback(ctx)
+		return ReturnDetails{}, &utils.InvalidTransitionError{Resource: "Return", From: ret.Status, To: StatusApproved}
+	}
+
+	order, err := qtx.GetOrderForUpdate(ctx, ret.OrderID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return ReturnDetails{}, &utils.DatabaseError{Query: "GetOrderForUpdate", Err: err}
+	}
+	// the order may have been cancelled or refunded in full since the request
+	if !returnableStatuses[order.Status] {
+		tx.Rollback(ctx)
+		return ReturnDetails{}, &utils.ValidationError{
+			Field:   "order",
+			Message: fmt.Sprintf("items of a %s order cannot be returned", order.Status),
+		}
+	}
+
+	items, err := qtx.ListOrderItems(ctx, ret.OrderID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return ReturnDetails{}, &utils.DatabaseError{Query: "ListOrderItems", Err: err}
+	}
+	byID := make(map[int64]repo.OrderItem, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+
+	returnItems, err := qtx.ListReturnItems(ctx, id)
+	if err != nil {
+		tx.Rollback(ctx)
+		return ReturnDetails{}, &utils.DatabaseError{Query: "ListReturnItems", Err: err}
+	}
+
+	var total int64
+	for i, returnItem := range returnItems {
+		item, ok := byID[returnItem.OrderItemID]
+		if !ok {
+			tx.Rollback(ctx)
+			return ReturnDetails{}, &utils.NotFoundError{Resource: "Order item", ID: strconv.FormatInt(returnItem.OrderItemID, 10)}
+		}
+		amount := lineRefund(item, order.PricesIncludeTax, returnItem.Quantity)
+
+		byID[item.ID], err = qtx.AddOrderItemReturn(ctx, repo.AddOrderItemReturnParams{
+			Quantity: returnItem.Quantity,
+			Amount:   amount,
+			ID:       item.ID,
+		})
+		if err != nil {
+			tx.Rollback(ctx)
+			return ReturnDetails{}, &utils.DatabaseError{Query: "AddOrderItemReturn", Err: err}
+		}
+
+		err = qtx.SetReturnItemRefund(ctx, repo.SetReturnItemRefundParams{
+			RefundAmount: amount,
+			ID:           returnItem.ID,
+		})
+		if err != nil {
+			tx.Rollback(ctx)
+			return ReturnDetails{}, &utils.DatabaseError{Query: "SetReturnItemRefund", Err: err}
+		}
+		returnItems[i].RefundAmount = amount
+		total += amount
+	}
+
+	kind := RefundFull
+	for _, item := range byID {
+		if item.ReturnedQuantity < item.Quantity {
+			kind = RefundPartial
+			break
+		}
+	}
+
+	_, err = qtx.AddOrderRefundedTotal(ctx, repo.AddOrderRefundedTotalParams{
+		RefundedTotal: total,
+		ID:            order.ID,
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		return ReturnDetails{}, &utils.DatabaseError{Query: "AddOrderRefundedTotal", Err: err}
+	}
+
+	ret, err = qtx.ApproveReturn(ctx, repo.ApproveReturnParams{
+		RefundTotal:    total,
+		ResolvedBy:     pgtype.Text{String: actor, Valid: true},
+		ResolutionNote: req.Note,
+		ID:             id,
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		return ReturnDetails{}, &utils.DatabaseError{Query: "ApproveReturn", Err: err}
+	}
+
+	_, err = qtx.CreateRefund(ctx, repo.CreateRefundParams{
+		ReturnID: id,
+		OrderID:  order.ID,
+		Kind:     kind,
+		Amount:   total,
+		Currency: order.Currency,
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		return ReturnDetails{}, &utils.DatabaseError{Query: "CreateRefund", Err: err}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return ReturnDetails{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	// the approval stands either way, a failed refund is kept on the refund and can be retried
+	if err := s.issueRefund(ctx, id, actor); err != nil {
+		slog.Error("failed to issue refund", "return_id", id, "error", err)
+	}
+
+	return s.details(ctx, ret)
+}
+
+func (s *Service) Reject(ctx context.Context, id int64, req ResolveRequest, actor string) (ReturnDetails, error) {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return ReturnDetails{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	ret, err := lockReturn(ctx, qtx, id)
+	if err != nil {
+		tx.Rollback(ctx)
+		return ReturnDetails{}, err
+	}
+	if ret.Status != StatusRequested {
+		tx.Rollback(ctx)
+		return ReturnDetails{}, &utils.InvalidTransitionError{Resource: "Return", From: ret.Status, To: StatusRejected}
+	}
+
+	ret, err = qtx.RejectReturn(ctx, repo.RejectReturnParams{
+		ResolvedBy:     pgtype.Text{String: actor, Valid: true},
+		ResolutionNote: req.Note,
+		ID:             id,
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		return ReturnDetails{}, &utils.DatabaseError{Query: "RejectReturn", Err: err}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return ReturnDetails{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return s.details(ctx, ret)
+}
+
+// Receive records that the items of an approved return are back. With Restock they go back
+// into stock and the ledger records them as returned
+func (s *Service) Receive(ctx context.Context, id int64, req ReceiveRequest, actor string) (ReturnDetails, error) {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return ReturnDetails{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	ret, err := lockReturn(ctx, qtx, id)
+	if err != nil {
+		tx.Rollback(ctx)
+		return ReturnDetails{}, err
+	}
+	if ret.Status != StatusApproved {
+		tx.Rollback(ctx)
+		return ReturnDetails{}, &utils.InvalidTransitionError{Resource: "Return", From: ret.Status, To: StatusReceived}
+	}
+
+	if req.Restock {
+		returnItems, err := qtx.ListReturnItems(ctx, id)
+		if err != nil {
+			tx.Rollback(ctx)
+			return ReturnDetails{}, &utils.DatabaseError{Query: "ListReturnItems", Err: err}
+		}
+
+		// items of variants go back to the variant's stock
+		orderItems, err := qtx.ListOrderItems(ctx, ret.OrderID)
+		if err != nil {
+			tx.Rollback(ctx)
+			return ReturnDetails{}, &utils.DatabaseError{Query: "ListOrderItems", Err: err}
+		}
+		variantOf := make(map[int64]pgtype.Int8, len(orderItems))
+		for _, oi := range orderItems {
+			variantOf[oi.ID] = oi.VariantID
+		}
+
+		for _, item := range returnItems {
+			variantID := variantOf[item.OrderItemID]
+			var stockAfter int32
+			if variantID.Valid {
+				variant, err := qtx.AdjustVariantStock(ctx, repo.AdjustVariantStockParams{
+					Delta: item.Quantity,
+					ID:    variantID.Int64,
+				})
+				if err != nil {
+					tx.Rollback(ctx)
+					return ReturnDetails{}, &utils.DatabaseError{Query: "AdjustVariantStock", Err: err}
+				}
+				stockAfter = variant.Stock
+			} else {
+				product, err := qtx.AdjustProductStock(ctx, repo.AdjustProductStockParams{
+					Delta: item.Quantity,
+					ID:    item.ProductID,
+				})
+				if err != nil {
+					tx.Rollback(ctx)
+					return ReturnDetails{}, &utils.DatabaseError{Query: "AdjustProductStock", Err: err}
+				}
+				stockAfter = product.Stock
+			}
+
+			err = inventory.Record(ctx, qtx, repo.AddInventoryMovementParams{
+				ProductID:     item.ProductID,
+				VariantID:     variantID,
+				Quantity:      item.Quantity,
+				StockAfter:    stockAfter,
+				Reason:        inventory.ReasonReturned,
+				Actor:         actor,
+				ReferenceType: inventory.ReferenceReturn,
+				ReferenceID:   strconv.FormatInt(id, 10),
+			})
+			if err != nil {
+				tx.Rollback(ctx)
+				return ReturnDetails{}, err
+			}
+		}
+	}
+
+	ret, err = qtx.ReceiveReturn(ctx, repo.ReceiveReturnParams{
+		Restocked:  req.Restock,
+		ReceivedBy: pgtype.Text{String: actor, Valid: true},
+		ID:         id,
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		return ReturnDetails{}, &utils.DatabaseError{Query: "ReceiveReturn", Err: err}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return ReturnDetails{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return s.details(ctx, ret)
+}
+
+// RetryRefund sends a pending or failed refund of an approved return to the gateway again
+func (s *Service) RetryRefund(ctx context.Context, id int64, actor string) (ReturnDetails, error) {
+	ret, err := s.getReturn(ctx, id)
+	if err != nil {
+		return ReturnDetails{}, err
+	}
+	if ret.Status != StatusApproved && ret.Status != StatusReceived {
+		return ReturnDetails{}, &utils.InvalidTransitionError{Resource: "Return", From: ret.Status, To: "refunded"}
+	}
+
+	if err := s.issueRefund(ctx, id, actor); err != nil {
+		return ReturnDetails{}, err
+	}
+
+	return s.details(ctx, ret)
+}
+
+func (s *Service) GetReturn(ctx context.Context, id int64, p auth.Principal) (ReturnDetails, error) {
+	ret, err := s.getReturn(ctx, id)
+	if err != nil {
+		return ReturnDetails{}, err
+	}
+	if !p.CanAccessCustomer(ret.CustomerRef) {
+		return ReturnDetails{}, &utils.AuthorizationError{Action: "view return " + strconv.FormatInt(id, 10)}
+	}
+
+	return s.details(ctx, ret)
+}
+
+// ListOrderReturns returns every return of an order, oldest first
+func (s *Service) ListOrderReturns(ctx context.Context, orderID int64, p auth.Principal) ([]repo.Return, error) {
+	order, err := s.repo.GetOrder(ctx, orderID)
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return nil, &utils.NotFoundError{Resource: "Order", ID: strconv.FormatInt(orderID, 10)}
+		}
+		return nil, &utils.DatabaseError{Query: "GetOrder", Err: err}
+	}
+	if !p.CanAccessCustomer(order.CustomerRef) {
+		return nil, &utils.AuthorizationError{Action: "view returns of order " + strconv.FormatInt(orderID, 10)}
+	}
+
+	rets, err := s.repo.ListOrderReturns(ctx, orderID)
+	if err != nil {
+		return nil, &utils.DatabaseError{Query: "ListOrderReturns", Err: err}
+	}
+	if rets == nil {
+		rets = []repo.Return{}
+	}
+	return rets, nil
+}
+
+// ListReturns returns one page of returns, newest first, optionally only those in status
+func (s *Service) ListReturns(ctx context.Context, status string, limit int32, cursor string) (utils.Page[repo.Return], error) {
+	params := repo.ListReturnsPageParams{
+		PageLimit: limit + 1,
+	}
+
+	if status != "" {
+		switch status {
+		case StatusRequested, StatusApproved, StatusRejected, StatusReceived:
+		default:
+			return utils.Page[repo.Return]{}, &utils.ValidationError{
+				Field:   "status",
+				Message: fmt.Sprintf("unknown status '%s'", status),
+			}
+		}
+		params.Status = pgtype.Text{String: status, Valid: true}
+	}
+
+	if cursor != "" {
+		c, err := utils.DecodeCursor(cursor)
+		if err != nil {
+			return utils.Page[repo.Return]{}, err
+		}
+		params.CursorID = pgtype.Int8{Int64: c.ID, Valid: true}
+	}
+
+	rets, err := s.repo.ListReturnsPage(ctx, params)
+	if err != nil {
+		return utils.Page[repo.Return]{}, &utils.DatabaseError{Query: "ListReturnsPage", Err: err}
+	}
+
+	return utils.NewPage(rets, limit, func(r repo.Return) utils.Cursor {
+		return utils.Cursor{ID: r.ID}
+	}), nil
+}
+
+func (s *Service) getReturn(ctx context.Context, id int64) (repo.Return, error) {
+	ret, err := s.repo.GetReturn(ctx, id)
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return repo.Return{}, &utils.NotFoundError{Resource: "Return", ID: strconv.FormatInt(id, 10)}
+		}
+		return repo.Return{}, &utils.DatabaseError{Query: "GetReturn", Err: err}
+	}
+	return ret, nil
+}
+
+// details loads the items and refund of ret, the refund is read again as issuing it may have changed it
+func (s *Service) details(ctx context.Context, ret repo.Return) (ReturnDetails, error) {
+	items, err := s.repo.ListReturnItems(ctx, ret.ID)
+	if err != nil {
+		return ReturnDetails{}, &utils.DatabaseError{Query: "ListReturnItems", Err: err}
+	}
+	if items == nil {
+		items = []repo.ReturnItem{}
+	}
+
+	details := ReturnDetails{Return: ret, Items: items}
+	refund, err := s.repo.GetReturnRefund(ctx, ret.ID)
+	switch {
+	case err == nil:
+		details.Refund = &refund
+	case err != pgx.ErrNoRows && err != sql.ErrNoRows:
+		return ReturnDetails{}, &utils.DatabaseError{Query: "GetReturnRefund", Err: err}
+	}
+	return details, nil
+}
+
+func lockReturn(ctx context.Context, qtx *repo.Queries, id int64) (repo.Return, error) {
+	ret, err := qtx.GetReturnForUpdate(ctx, id)
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return repo.Return{}, &utils.NotFoundError{Resource: "Return", ID: strconv.FormatInt(id, 10)}
+		}
+		return repo.Return{}, &utils.DatabaseError{Query: "GetReturnForUpdate", Err: err}
+	}
+	return ret, nil
+}
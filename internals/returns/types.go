@@ -0,0 +1,52 @@
+package returns
+
+import "ecomApis/internals/repo"
+
+// return statuses, a return is requested, then approved or rejected, and approved returns
+// are received once the parcel is back
+const (
+	StatusRequested = "requested"
+	StatusApproved  = "approved"
+	StatusRejected  = "rejected"
+	StatusReceived  = "received"
+)
+
+// refund kinds, a full refund covers every item left on the order
+const (
+	RefundFull    = "full"
+	RefundPartial = "partial"
+)
+
+// refund statuses, a refund stays pending when the order has no captured payment to refund
+const (
+	RefundPending   = "pending"
+	RefundCompleted = "completed"
+	RefundFailed    = "failed"
+)
+
+type ReturnItemRequest struct {
+	OrderItemID int64 `json:"order_item_id"`
+	Quantity    int32 `json:"quantity"`
+}
+
+type CreateReturnRequest struct {
+	Reason string              `json:"reason"`
+	Items  []ReturnItemRequest `json:"items"`
+}
+
+// ResolveRequest approves or rejects a return
+type ResolveRequest struct {
+	Note string `json:"note"`
+}
+
+// ReceiveRequest marks the items of a return as back, Restock puts them back on the shelf
+type ReceiveRequest struct {
+	Restock bool `json:"restock"`
+}
+
+// ReturnDetails is a return with its items and, once approved, its refund
+type ReturnDetails struct {
+	Return repo.Return       `json:"return"`
+	Items  []repo.ReturnItem `json:"items"`
+	Refund *repo.Refund      `json:"refund"`
+}
@@ -0,0 +1,59 @@
+package shipping
+
+import (
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"regexp"
+	"strings"
+)
+
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// Normalize trims the address and upper-cases its country, field prefixes the
+// name of any invalid field, e.g. "shipping_address"
+func (a Address) Normalize(field string) (Address, error) {
+	a.Name = strings.TrimSpace(a.Name)
+	a.Phone = strings.TrimSpace(a.Phone)
+	a.AddressLine1 = strings.TrimSpace(a.AddressLine1)
+	a.AddressLine2 = strings.TrimSpace(a.AddressLine2)
+	a.City = strings.TrimSpace(a.City)
+	a.Region = strings.TrimSpace(a.Region)
+	a.PostalCode = strings.TrimSpace(a.PostalCode)
+	a.Country = strings.ToUpper(strings.TrimSpace(a.Country))
+
+	for _, required := range []struct {
+		name  string
+		value string
+	}{
+		{"name", a.Name},
+		{"address_line1", a.AddressLine1},
+		{"city", a.City},
+		{"postal_code", a.PostalCode},
+	} {
+		if required.value == "" {
+			return Address{}, &utils.ValidationError{Field: field + "." + required.name, Message: "cannot be empty"}
+		}
+	}
+	if !countryPattern.MatchString(a.Country) {
+		return Address{}, &utils.ValidationError{Field: field + ".country", Message: "must be an ISO-3166 alpha-2 country code"}
+	}
+	return a, nil
+}
+
+// CustomerAddress is the address on the customer record, nil when it is incomplete
+func CustomerAddress(c repo.Customer) *Address {
+	a, err := Address{
+		Name:         c.Name,
+		Phone:        c.Phone,
+		AddressLine1: c.AddressLine1,
+		AddressLine2: c.AddressLine2,
+		City:         c.City,
+		Region:       c.Region,
+		PostalCode:   c.PostalCode,
+		Country:      c.Country,
+	}.Normalize("address")
+	if err != nil {
+		return nil
+	}
+	return &a
+}
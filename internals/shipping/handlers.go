@@ -0,0 +1,140 @@
+package shipping
+
+import (
+	"ecomApis/internals/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(s *Service) *Handler {
+	return &Handler{
+		service: s,
+	}
+}
+
+func (h *Handler) CreateMethod(w http.ResponseWriter, r *http.Request) {
+	var req CreateMethodRequest
+	err := utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	method, err := h.service.CreateMethod(r.Context(), req)
+	if err != nil {
+		writeShippingError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, method)
+}
+
+func (h *Handler) ListMethods(w http.ResponseWriter, r *http.Request) {
+	methods, err := h.service.ListMethods(r.Context())
+	if err != nil {
+		writeShippingError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, methods)
+}
+
+func (h *Handler) GetMethod(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid shipping method id"})
+		return
+	}
+
+	method, err := h.service.GetMethod(r.Context(), id)
+	if err != nil {
+		writeShippingError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, method)
+}
+
+// DeactivateMethod handles POST /shipping/methods/{id}/deactivate
+func (h *Handler) DeactivateMethod(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid shipping method id"})
+		return
+	}
+
+	method, err := h.service.DeactivateMethod(r.Context(), id)
+	if err != nil {
+		writeShippingError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, method)
+}
+
+// Quote handles GET /shipping/quote?country=DE&items=1:2,5:1&currency=EUR&method=standard
+func (h *Handler) Quote(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	items, err := parseItems(query.Get("items"))
+	if err != nil {
+		writeShippingError(w, err)
+		return
+	}
+
+	quotes, err := h.service.Quote(r.Context(), QuoteRequest{
+		Method:   query.Get("method"),
+		Country:  query.Get("country"),
+		Currency: query.Get("currency"),
+		Items:    items,
+	})
+	if err != nil {
+		writeShippingError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, quotes)
+}
+
+// parseItems reads a comma separated list of product_id:quantity pairs
+func parseItems(raw string) ([]QuoteItem, error) {
+	var items []QuoteItem
+	for _, pair := range strings.Split(raw, ",") {
+		if pair == "" {
+			continue
+		}
+		id, qty, ok := strings.Cut(pair, ":")
+		productID, err := strconv.ParseInt(id, 10, 64)
+		if !ok || err != nil {
+			return nil, &utils.ValidationError{Field: "items", Message: "must be a list of product_id:quantity pairs"}
+		}
+		quantity, err := strconv.ParseInt(qty, 10, 32)
+		if err != nil {
+			return nil, &utils.ValidationError{Field: "items", Message: "must be a list of product_id:quantity pairs"}
+		}
+		items = append(items, QuoteItem{ProductID: productID, Quantity: int32(quantity)})
+	}
+	return items, nil
+}
+
+func writeShippingError(w http.ResponseWriter, err error) {
+	switch e := err.(type) {
+	case *utils.ValidationError:
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": e.Error()})
+	case *utils.NotFoundError:
+		utils.WriteJSON(w, http.StatusNotFound, map[string]string{"error": e.Error()})
+	case *utils.AlreadyExistsError:
+		utils.WriteJSON(w, http.StatusConflict, map[string]string{"error": e.Error()})
+	case *utils.DatabaseError:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": e.Error()})
+	default:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,101 @@
+package shipping
+
+import (
+	"context"
+	"database/sql"
+	"ecomApis/internals/money"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"fmt"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// QuoteMethod prices the lines with the active method called code for a parcel going to
+// country. q lets orders quote inside their transaction
+func QuoteMethod(ctx context.Context, q *repo.Queries, code, country string, lines []Line) (Quote, error) {
+	method, err := q.GetShippingMethodByCode(ctx, normalizeCode(code))
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return Quote{}, &utils.ValidationError{Field: "shipping_method", Message: fmt.Sprintf("unknown shipping method '%s'", code)}
+		}
+		return Quote{}, &utils.DatabaseError{Query: "GetShippingMethodByCode", Err: err}
+	}
+
+	rates, err := q.ListShippingRates(ctx, []int64{method.ID})
+	if err != nil {
+		return Quote{}, &utils.DatabaseError{Query: "ListShippingRates", Err: err}
+	}
+
+	quote, reason := quoteMethod(method, rates, country, lines)
+	if reason != "" {
+		return Quote{}, &utils.ValidationError{Field: "shipping_method", Message: reason}
+	}
+	return quote, nil
+}
+
+// quoteMethod prices one method, reason says why the method cannot ship the lines
+func quoteMethod(method repo.ShippingMethod, rates []repo.ShippingRate, country string, lines []Line) (Quote, string) {
+	if !method.IsActive {
+		return Quote{}, fmt.Sprintf("shipping method '%s' is no longer offered", method.Code)
+	}
+	if len(method.Countries) > 0 && !slices.Contains(method.Countries, country) {
+		return Quote{}, fmt.Sprintf("shipping method '%s' does not ship to %s", method.Code, country)
+	}
+
+	weight := chargeableWeight(method, lines)
+	value := weight
+	if method.RateBasis == RateBasisPrice {
+		goods := money.Zero(method.Currency)
+		for _, line := range lines {
+			var err error
+			goods, err = goods.Add(line.Amount)
+			if err != nil {
+				return Quote{}, fmt.Sprintf("shipping method '%s' is only available for orders in %s", method.Code, method.Currency)
+			}
+		}
+		value = goods.Amount
+	} else if len(lines) > 0 && lines[0].Amount.Currency != method.Currency {
+		// the charge is added to the order total, so it has to be in the order currency
+		return Quote{}, fmt.Sprintf("shipping method '%s' is only available for orders in %s", method.Code, method.Currency)
+	}
+
+	// rates are sorted by min_value, the last tier reached wins
+	var tier *repo.ShippingRate
+	for i := range rates {
+		if rates[i].MethodID == method.ID && rates[i].MinValue <= value {
+			tier = &rates[i]
+		}
+	}
+	if tier == nil || (tier.MaxValue.Valid && value >= tier.MaxValue.Int64) {
+		return Quote{}, fmt.Sprintf("shipping method '%s' has no rate for this order", method.Code)
+	}
+
+	return Quote{
+		MethodID:    method.ID,
+		Code:        method.Code,
+		Name:        method.Name,
+		Amount:      money.Money{Amount: tier.Amount, Currency: method.Currency},
+		WeightGrams: weight,
+	}, ""
+}
+
+// chargeableWeight is the actual weight of the lines, or their volumetric weight
+// when the method has a divisor and that is higher
+func chargeableWeight(method repo.ShippingMethod, lines []Line) int64 {
+	var weight, volume int64
+	for _, line := range lines {
+		p := line.Product
+		qty := int64(line.Quantity)
+		weight += qty * int64(p.WeightGrams)
+		volume += qty * int64(p.LengthMm) * int64(p.WidthMm) * int64(p.HeightMm)
+	}
+	if method.VolumetricDivisor.Valid {
+		// a divisor of 5000 cm³/kg is 5000 mm³/g
+		if volumetric := volume / int64(method.VolumetricDivisor.Int32); volumetric > weight {
+			return volumetric
+		}
+	}
+	return weight
+}
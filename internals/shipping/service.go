@@ -0,0 +1,305 @@
+package shipping
+
+import (
+	"context"
+	"database/sql"
+	"ecomApis/internals/money"
+	"ecomApis/internals/pricing"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// postgres error code for unique violations
+const uniqueViolation = "23505"
+
+var codePattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+type Service struct {
+	repo    *repo.Queries
+	db      *pgxpool.Pool
+	pricing *pricing.Service
+}
+
+func NewService(r *repo.Queries, db *pgxpool.Pool, prices *pricing.Service) *Service {
+	return &Service{
+		repo:    r,
+		db:      db,
+		pricing: prices,
+	}
+}
+
+func normalizeCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
+
+// CreateMethod creates a shipping method together with its rate table
+func (s *Service) CreateMethod(ctx context.Context, req CreateMethodRequest) (MethodDetails, error) {
+	params, err := validateMethod(req)
+	if err != nil {
+		return MethodDetails{}, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return MethodDetails{}, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	method, err := qtx.CreateShippingMethod(ctx, params)
+	if err != nil {
+		tx.Rollback(ctx)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return MethodDetails{}, &utils.AlreadyExistsError{Resource: "ShippingMethod", ID: params.Code}
+		}
+		return MethodDetails{}, &utils.DatabaseError{Query: "CreateShippingMethod", Err: err}
+	}
+
+	rates := make([]repo.ShippingRate, 0, len(req.Rates))
+	for _, tier := range req.Rates {
+		var maxValue pgtype.Int8
+		if tier.MaxValue != nil {
+			maxValue = pgtype.Int8{Int64: *tier.MaxValue, Valid: true}
+		}
+		rate, err := qtx.AddShippingRate(ctx, repo.AddShippingRateParams{
+			MethodID: method.ID,
+			MinValue: tier.MinValue,
+			MaxValue: maxValue,
+			Amount:   tier.Amount,
+		})
+		if err != nil {
+			tx.Rollback(ctx)
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+				return MethodDetails{}, &utils.ValidationError{
+					Field:   "rates",
+					Message: fmt.Sprintf("more than one tier starts at %d", tier.MinValue),
+				}
+			}
+			return MethodDetails{}, &utils.DatabaseError{Query: "AddShippingRate", Err: err}
+		}
+		rates = append(rates, rate)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return MethodDetails{}, fmt.Errorf("commit tx: %w", err)
+	}
+
+	return MethodDetails{Method: method, Rates: rates}, nil
+}
+
+func validateMethod(req CreateMethodRequest) (repo.CreateShippingMethodParams, error) {
+	params := repo.CreateShippingMethodParams{
+		Code:      normalizeCode(req.Code),
+		Name:      strings.TrimSpace(req.Name),
+		RateBasis: req.RateBasis,
+		Countries: []string{},
+	}
+
+	if !codePattern.MatchString(params.Code) {
+		return params, &utils.ValidationError{Field: "code", Message: "may only contain lowercase letters, digits, dashes and underscores"}
+	}
+	if params.Name == "" {
+		return params, &utils.ValidationError{Field: "name", Message: "cannot be empty"}
+	}
+	if params.RateBasis != RateBasisWeight && params.RateBasis != RateBasisPrice {
+		return params, &utils.ValidationError{
+			Field:   "rate_basis",
+			Message: fmt.Sprintf("must be %s or %s", RateBasisWeight, RateBasisPrice),
+		}
+	}
+
+	params.Currency = money.NormalizeCurrency(req.Currency)
+	if !money.IsValidCurrency(params.Currency) {
+		return params, &utils.ValidationError{
+			Field:   "currency",
+			Message: fmt.Sprintf("'%s' is not a supported ISO-4217 currency", req.Currency),
+		}
+	}
+
+	for _, country := range req.Countries {
+		country = strings.ToUpper(strings.TrimSpace(country))
+		if !countryPattern.MatchString(country) {
+			return params, &utils.ValidationError{Field: "countries", Message: "must be ISO-3166 alpha-2 country codes"}
+		}
+		params.Countries = append(params.Countries, country)
+	}
+
+	if req.VolumetricDivisor != nil {
+		if *req.VolumetricDivisor <= 0 {
+			return params, &utils.ValidationError{Field: "volumetric_divisor", Message: "must be positive"}
+		}
+		params.VolumetricDivisor = pgtype.Int4{Int32: *req.VolumetricDivisor, Valid: true}
+	}
+
+	if len(req.Rates) == 0 {
+		return params, &utils.ValidationError{Field: "rates", Message: "cannot be empty"}
+	}
+	for _, tier := range req.Rates {
+		if tier.MinValue < 0 || tier.Amount < 0 {
+			return params, &utils.ValidationError{Field: "rates", Message: "min_value and amount cannot be negative"}
+		}
+		if tier.MaxValue != nil && *tier.MaxValue <= tier.MinValue {
+			return params, &utils.ValidationError{Field: "rates", Message: "max_value must be above min_value"}
+		}
+	}
+
+	return params, nil
+}
+
+func (s *Service) ListMethods(ctx context.Context) ([]MethodDetails, error) {
+	methods, err := s.repo.ListShippingMethods(ctx)
+	if err != nil {
+		return nil, &utils.DatabaseError{Query: "ListShippingMethods", Err: err}
+	}
+	return s.withRates(ctx, methods)
+}
+
+func (s *Service) GetMethod(ctx context.Context, id int64) (MethodDetails, error) {
+	method, err := s.repo.GetShippingMethod(ctx, id)
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return MethodDetails{}, &utils.NotFoundError{Resource: "ShippingMethod", ID: strconv.FormatInt(id, 10)}
+		}
+		return MethodDetails{}, &utils.DatabaseError{Query: "GetShippingMethod", Err: err}
+	}
+
+	details, err := s.withRates(ctx, []repo.ShippingMethod{method})
+	if err != nil {
+		return MethodDetails{}, err
+	}
+	return details[0], nil
+}
+
+// DeactivateMethod stops offering a method, orders that used it keep pointing at it
+func (s *Service) DeactivateMethod(ctx context.Context, id int64) (repo.ShippingMethod, error) {
+	method, err := s.repo.DeactivateShippingMethod(ctx, id)
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return repo.ShippingMethod{}, &utils.NotFoundError{Resource: "ShippingMethod", ID: strconv.FormatInt(id, 10)}
+		}
+		return repo.ShippingMethod{}, &utils.DatabaseError{Query: "DeactivateShippingMethod", Err: err}
+	}
+	return method, nil
+}
+
+func (s *Service) withRates(ctx context.Context, methods []repo.ShippingMethod) ([]MethodDetails, error) {
+	ids := make([]int64, 0, len(methods))
+	for _, m := range methods {
+		ids = append(ids, m.ID)
+	}
+
+	rates, err := s.repo.ListShippingRates(ctx, ids)
+	if err != nil {
+		return nil, &utils.DatabaseError{Query: "ListShippingRates", Err: err}
+	}
+
+	byMethod := make(map[int64][]repo.ShippingRate, len(methods))
+	for _, rate := range rates {
+		byMethod[rate.MethodID] = append(byMethod[rate.MethodID], rate)
+	}
+
+	details := make([]MethodDetails, 0, len(methods))
+	for _, m := range methods {
+		methodRates := byMethod[m.ID]
+		if methodRates == nil {
+			methodRates = []repo.ShippingRate{}
+		}
+		details = append(details, MethodDetails{Method: m, Rates: methodRates})
+	}
+	return details, nil
+}
+
+// Quote prices a basket without placing an order. Without a method every active method
+// that can ship the basket is quoted. Prices are the catalog ones, coupons are not applied
+func (s *Service) Quote(ctx context.Context, req QuoteRequest) ([]Quote, error) {
+	country := strings.ToUpper(strings.TrimSpace(req.Country))
+	if !countryPattern.MatchString(country) {
+		return nil, &utils.ValidationError{Field: "country", Message: "must be an ISO-3166 alpha-2 country code"}
+	}
+	if len(req.Items) == 0 {
+		return nil, &utils.ValidationError{Field: "items", Message: "cannot be empty"}
+	}
+
+	products := make([]repo.Product, 0, len(req.Items))
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			return nil, &utils.ValidationError{
+				Field:   "quantity",
+				Message: fmt.Sprintf("invalid quantity for product %d", item.ProductID),
+			}
+		}
+		product, err := s.repo.FindProductByID(ctx, item.ProductID)
+		if err != nil {
+			if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+				return nil, &utils.NotFoundError{Resource: "Product", ID: strconv.FormatInt(item.ProductID, 10)}
+			}
+			return nil, &utils.DatabaseError{Query: "FindProductByID", Err: err}
+		}
+		products = append(products, product)
+	}
+
+	// price the basket the way an order would
+	var quotes []pricing.Quote
+	if req.Currency == "" {
+		for _, p := range products {
+			quotes = append(quotes, pricing.Quote{Price: p.PriceMoney()})
+		}
+	} else {
+		var err error
+		quotes, err = s.pricing.PriceIn(ctx, s.repo, products, req.Currency)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	lines := make([]Line, 0, len(products))
+	for i, p := range products {
+		amount, err := quotes[i].Price.Mul(int64(req.Items[i].Quantity))
+		if err != nil {
+			return nil, &utils.ValidationError{Field: "total", Message: "order total is too large"}
+		}
+		if amount.Currency != quotes[0].Price.Currency {
+			return nil, &utils.ValidationError{
+				Field:   "currency",
+				Message: fmt.Sprintf("product %d is priced in %s, pass a currency to price the basket in", p.ID, amount.Currency),
+			}
+		}
+		lines = append(lines, Line{Product: p, Quantity: req.Items[i].Quantity, Amount: amount})
+	}
+
+	if req.Method != "" {
+		quote, err := QuoteMethod(ctx, s.repo, req.Method, country, lines)
+		if err != nil {
+			return nil, err
+		}
+		return []Quote{quote}, nil
+	}
+
+	methods, err := s.repo.ListActiveShippingMethods(ctx)
+	if err != nil {
+		return nil, &utils.DatabaseError{Query: "ListActiveShippingMethods", Err: err}
+	}
+	details, err := s.withRates(ctx, methods)
+	if err != nil {
+		return nil, err
+	}
+
+	available := []Quote{}
+	for _, d := range details {
+		if quote, reason := quoteMethod(d.Method, d.Rates, country, lines); reason == "" {
+			available = append(available, quote)
+		}
+	}
+	return available, nil
+}
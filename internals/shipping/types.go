@@ -0,0 +1,85 @@
+package shipping
+
+import (
+	"ecomApis/internals/money"
+	"ecomApis/internals/repo"
+)
+
+// what the rate tiers of a method are measured in
+const (
+	RateBasisWeight = "weight"
+	RateBasisPrice  = "price"
+)
+
+// order address kinds
+const (
+	AddressShipping = "shipping"
+	AddressBilling  = "billing"
+)
+
+// Address is a postal address captured on an order, Country is an ISO-3166 alpha-2 code
+type Address struct {
+	Name         string `json:"name"`
+	Phone        string `json:"phone"`
+	AddressLine1 string `json:"address_line1"`
+	AddressLine2 string `json:"address_line2"`
+	City         string `json:"city"`
+	Region       string `json:"region"`
+	PostalCode   string `json:"postal_code"`
+	Country      string `json:"country"`
+}
+
+// RateTier charges Amount for parcels from MinValue up to, not including, MaxValue.
+// Values are grams for weight based methods and minor units for price based ones
+type RateTier struct {
+	MinValue int64  `json:"min_value"`
+	MaxValue *int64 `json:"max_value"`
+	Amount   int64  `json:"amount"`
+}
+
+// CreateMethodRequest describes a shipping method and its rate table, charged in Currency.
+// An empty Countries list ships everywhere
+type CreateMethodRequest struct {
+	Code              string     `json:"code"`
+	Name              string     `json:"name"`
+	RateBasis         string     `json:"rate_basis"`
+	Currency          string     `json:"currency"`
+	Countries         []string   `json:"countries"`
+	VolumetricDivisor *int32     `json:"volumetric_divisor"`
+	Rates             []RateTier `json:"rates"`
+}
+
+// MethodDetails is a shipping method with its rate tiers
+type MethodDetails struct {
+	Method repo.ShippingMethod `json:"method"`
+	Rates  []repo.ShippingRate `json:"rates"`
+}
+
+// Line is one order line to ship, Amount is its price after discounts
+type Line struct {
+	Product  repo.Product
+	Quantity int32
+	Amount   money.Money
+}
+
+// Quote is what a method charges for a basket
+type Quote struct {
+	MethodID    int64       `json:"method_id"`
+	Code        string      `json:"code"`
+	Name        string      `json:"name"`
+	Amount      money.Money `json:"amount"`
+	WeightGrams int64       `json:"weight_grams"`
+}
+
+// QuoteRequest is a basket to price, see GET /shipping/quote
+type QuoteRequest struct {
+	Method   string
+	Country  string
+	Currency string
+	Items    []QuoteItem
+}
+
+type QuoteItem struct {
+	ProductID int64
+	Quantity  int32
+}
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+ALTER TABLE orders
+ADD COLUMN cancelled_at TIMESTAMP,
+ADD COLUMN cancelled_by TEXT,
+ADD COLUMN cancel_reason TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE orders
+DROP COLUMN cancelled_at,
+DROP COLUMN cancelled_by,
+DROP COLUMN cancel_reason;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id BIGSERIAL PRIMARY KEY,
+    scope TEXT NOT NULL, -- which endpoint the key was used on
+    idempotency_key TEXT NOT NULL,
+    request_hash TEXT NOT NULL, -- sha256 of the request body
+    status_code INTEGER NOT NULL,
+    response_body BYTEA NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    UNIQUE (scope, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE IF EXISTS idempotency_keys;
+DROP INDEX IF EXISTS idx_idempotency_keys_expires_at;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    key_prefix TEXT NOT NULL, -- first characters of the key so it can be recognised in listings
+    key_hash TEXT NOT NULL UNIQUE, -- sha256 of the key, the key itself is never stored
+    role TEXT NOT NULL CHECK (role IN ('admin', 'customer')),
+    customer_ref TEXT, -- set for customer keys
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    CHECK (role <> 'customer' OR customer_ref IS NOT NULL)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE IF EXISTS api_keys;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+CREATE TABLE IF NOT EXISTS customers (
+    id BIGSERIAL PRIMARY KEY,
+    customer_ref TEXT NOT NULL,
+    email TEXT,
+    name TEXT NOT NULL DEFAULT '',
+    phone TEXT NOT NULL DEFAULT '',
+    address_line1 TEXT NOT NULL DEFAULT '',
+    address_line2 TEXT NOT NULL DEFAULT '',
+    city TEXT NOT NULL DEFAULT '',
+    region TEXT NOT NULL DEFAULT '',
+    postal_code TEXT NOT NULL DEFAULT '',
+    country TEXT NOT NULL DEFAULT '',
+    is_deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_customers_customer_ref UNIQUE (customer_ref)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_customers_email ON customers(lower(email)) WHERE email IS NOT NULL;
+
+-- NOT VALID so existing orders are not checked yet,
+-- run cmd/backfill-customers to create their customers and validate the constraint
+ALTER TABLE orders
+ADD CONSTRAINT fk_orders_customer_ref FOREIGN KEY (customer_ref) REFERENCES customers(customer_ref) NOT VALID;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE orders
+DROP CONSTRAINT IF EXISTS fk_orders_customer_ref;
+DROP TABLE IF EXISTS customers;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+-- append-only ledger of every stock change, the sum of quantity per product is its on-hand stock.
+-- product_id has no foreign key so the history outlives deleted products
+CREATE TABLE IF NOT EXISTS inventory_movements (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity <> 0),
+    stock_after INTEGER NOT NULL,
+    reason TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    reference_type TEXT NOT NULL DEFAULT '',
+    reference_id TEXT NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_movements_product_id ON inventory_movements(product_id, id DESC);
+
+-- open the ledger with the current stock of every product
+INSERT INTO inventory_movements (product_id, quantity, stock_after, reason, actor)
+SELECT id, stock, stock, 'opening_balance', 'system'
+FROM products
+WHERE stock <> 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE IF EXISTS inventory_movements;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+-- amounts are stored in the minor unit of their ISO-4217 currency. existing rows were whole USD,
+-- which has 2 minor units, so they are scaled by 100
+ALTER TABLE products ALTER COLUMN price TYPE BIGINT USING price::BIGINT * 100;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'USD' CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE products ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE orders ALTER COLUMN total_price TYPE BIGINT USING total_price::BIGINT * 100;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'USD' CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE orders ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE order_items ALTER COLUMN unit_price TYPE BIGINT USING unit_price::BIGINT * 100;
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'USD' CHECK (currency ~ '^[A-Z]{3}$');
+ALTER TABLE order_items ALTER COLUMN currency DROP DEFAULT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- back to whole units, fractions of a unit are rounded
+ALTER TABLE order_items DROP COLUMN IF EXISTS currency;
+ALTER TABLE order_items ALTER COLUMN unit_price TYPE INTEGER USING ROUND(unit_price / 100.0)::INTEGER;
+ALTER TABLE orders DROP COLUMN IF EXISTS currency;
+ALTER TABLE orders ALTER COLUMN total_price TYPE INTEGER USING ROUND(total_price / 100.0)::INTEGER;
+ALTER TABLE products DROP COLUMN IF EXISTS currency;
+ALTER TABLE products ALTER COLUMN price TYPE INTEGER USING ROUND(price / 100.0)::INTEGER;
+-- +goose StatementEnd
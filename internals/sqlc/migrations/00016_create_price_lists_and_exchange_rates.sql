@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+-- explicit prices of a product in other currencies than products.currency
+CREATE TABLE IF NOT EXISTS product_prices (
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    currency TEXT NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (product_id, currency)
+);
+
+-- rate is the price of one unit of base_currency in quote_currency
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    base_currency TEXT NOT NULL CHECK (base_currency ~ '^[A-Z]{3}$'),
+    quote_currency TEXT NOT NULL CHECK (quote_currency ~ '^[A-Z]{3}$'),
+    rate NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    updated_by TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (base_currency, quote_currency)
+);
+
+-- the rate an order was converted with, NULL when every item had a price in the order currency
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS exchange_rate NUMERIC(20, 10);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS exchange_rate_base TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE orders DROP COLUMN IF EXISTS exchange_rate_base;
+ALTER TABLE orders DROP COLUMN IF EXISTS exchange_rate;
+DROP TABLE IF EXISTS exchange_rates;
+DROP TABLE IF EXISTS product_prices;
+-- +goose StatementEnd
@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+-- jurisdiction is an ISO-3166 country code, optionally followed by a region ("US-CA").
+-- rate is a fraction, 0.2 is 20%
+CREATE TABLE IF NOT EXISTS tax_rates (
+    jurisdiction TEXT NOT NULL CHECK (jurisdiction ~ '^[A-Z]{2}(-[A-Z0-9]{1,3})?$'),
+    tax_class TEXT NOT NULL CHECK (tax_class ~ '^[a-z0-9_]+$'),
+    rate NUMERIC(12, 10) NOT NULL CHECK (rate >= 0 AND rate < 1),
+    name TEXT NOT NULL DEFAULT '',
+    updated_by TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (jurisdiction, tax_class)
+);
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tax_class TEXT NOT NULL DEFAULT 'standard';
+
+-- total_price is the grand total, subtotal + tax_total
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS subtotal BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tax_total BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tax_jurisdiction TEXT;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS prices_include_tax BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS tax_class TEXT NOT NULL DEFAULT 'standard';
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS tax_rate NUMERIC(12, 10) NOT NULL DEFAULT 0;
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS tax_amount BIGINT NOT NULL DEFAULT 0;
+
+-- older orders were never taxed
+UPDATE orders SET subtotal = total_price;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE order_items DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE order_items DROP COLUMN IF EXISTS tax_rate;
+ALTER TABLE order_items DROP COLUMN IF EXISTS tax_class;
+ALTER TABLE orders DROP COLUMN IF EXISTS prices_include_tax;
+ALTER TABLE orders DROP COLUMN IF EXISTS tax_jurisdiction;
+ALTER TABLE orders DROP COLUMN IF EXISTS tax_total;
+ALTER TABLE orders DROP COLUMN IF EXISTS subtotal;
+ALTER TABLE products DROP COLUMN IF EXISTS tax_class;
+DROP TABLE IF EXISTS tax_rates;
+-- +goose StatementEnd
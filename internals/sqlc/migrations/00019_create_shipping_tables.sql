@@ -0,0 +1,70 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+-- weight in grams and dimensions in millimetres, 0 means unknown
+ALTER TABLE products ADD COLUMN IF NOT EXISTS weight_grams INT NOT NULL DEFAULT 0 CHECK (weight_grams >= 0);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS length_mm INT NOT NULL DEFAULT 0 CHECK (length_mm >= 0);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS width_mm INT NOT NULL DEFAULT 0 CHECK (width_mm >= 0);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS height_mm INT NOT NULL DEFAULT 0 CHECK (height_mm >= 0);
+
+-- rate_basis decides what the rate tiers are measured in, grams for weight and minor
+-- units of currency for price. An empty countries list ships everywhere.
+-- volumetric_divisor turns a parcel volume in mm³ into grams when it is set
+CREATE TABLE IF NOT EXISTS shipping_methods (
+    id BIGSERIAL PRIMARY KEY,
+    code TEXT NOT NULL,
+    name TEXT NOT NULL,
+    rate_basis TEXT NOT NULL CHECK (rate_basis IN ('weight', 'price')),
+    currency TEXT NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    countries TEXT[] NOT NULL DEFAULT '{}',
+    volumetric_divisor INT CHECK (volumetric_divisor > 0),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT uq_shipping_methods_code UNIQUE (code)
+);
+
+-- a parcel is charged the amount of the tier with the highest min_value it reaches,
+-- below the lowest tier the method is not available
+CREATE TABLE IF NOT EXISTS shipping_rates (
+    method_id BIGINT NOT NULL REFERENCES shipping_methods(id) ON DELETE CASCADE,
+    min_value BIGINT NOT NULL CHECK (min_value >= 0),
+    max_value BIGINT CHECK (max_value > min_value),
+    amount BIGINT NOT NULL CHECK (amount >= 0),
+    PRIMARY KEY (method_id, min_value)
+);
+
+-- where an order goes and who pays for it, copied so later customer edits leave it alone
+CREATE TABLE IF NOT EXISTS order_addresses (
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('shipping', 'billing')),
+    name TEXT NOT NULL,
+    phone TEXT NOT NULL DEFAULT '',
+    address_line1 TEXT NOT NULL,
+    address_line2 TEXT NOT NULL DEFAULT '',
+    city TEXT NOT NULL,
+    region TEXT NOT NULL DEFAULT '',
+    postal_code TEXT NOT NULL,
+    country TEXT NOT NULL CHECK (country ~ '^[A-Z]{2}$'),
+    PRIMARY KEY (order_id, kind)
+);
+
+-- total_price includes shipping_total
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_method_id BIGINT REFERENCES shipping_methods(id);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_total BIGINT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_total;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_method_id;
+DROP TABLE IF EXISTS order_addresses;
+DROP TABLE IF EXISTS shipping_rates;
+DROP TABLE IF EXISTS shipping_methods;
+ALTER TABLE products DROP COLUMN IF EXISTS height_mm;
+ALTER TABLE products DROP COLUMN IF EXISTS width_mm;
+ALTER TABLE products DROP COLUMN IF EXISTS length_mm;
+ALTER TABLE products DROP COLUMN IF EXISTS weight_grams;
+-- +goose StatementEnd
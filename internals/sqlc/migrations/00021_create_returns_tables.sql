@@ -0,0 +1,77 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+-- what has been taken back and refunded so far, refunds are granted when a return is approved
+ALTER TABLE order_items
+ADD COLUMN returned_quantity INT NOT NULL DEFAULT 0 CHECK (returned_quantity >= 0 AND returned_quantity <= quantity),
+ADD COLUMN refunded_amount BIGINT NOT NULL DEFAULT 0 CHECK (refunded_amount >= 0);
+
+ALTER TABLE orders
+ADD COLUMN refunded_total BIGINT NOT NULL DEFAULT 0 CHECK (refunded_total >= 0);
+
+-- a customer's request to send items of an order back
+CREATE TABLE IF NOT EXISTS returns (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id),
+    customer_ref TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'requested' CHECK (status IN ('requested', 'approved', 'rejected', 'received')),
+    reason TEXT NOT NULL DEFAULT '',
+    refund_total BIGINT NOT NULL DEFAULT 0 CHECK (refund_total >= 0),
+    currency TEXT NOT NULL,
+    requested_by TEXT NOT NULL,
+    resolved_by TEXT,
+    resolution_note TEXT NOT NULL DEFAULT '',
+    restocked BOOLEAN NOT NULL DEFAULT FALSE,
+    received_by TEXT,
+    received_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_returns_order_id ON returns(order_id);
+CREATE INDEX IF NOT EXISTS idx_returns_status ON returns(status, id);
+
+CREATE TABLE IF NOT EXISTS return_items (
+    id BIGSERIAL PRIMARY KEY,
+    return_id BIGINT NOT NULL REFERENCES returns(id) ON DELETE CASCADE,
+    order_item_id BIGINT NOT NULL REFERENCES order_items(id),
+    product_id BIGINT NOT NULL REFERENCES products(id),
+    quantity INT NOT NULL CHECK (quantity > 0),
+    refund_amount BIGINT NOT NULL DEFAULT 0 CHECK (refund_amount >= 0),
+    currency TEXT NOT NULL,
+    CONSTRAINT uq_return_items_order_item UNIQUE (return_id, order_item_id)
+);
+
+-- the money owed for an approved return, payment_id is set once it went back through the gateway
+CREATE TABLE IF NOT EXISTS refunds (
+    id BIGSERIAL PRIMARY KEY,
+    return_id BIGINT NOT NULL UNIQUE REFERENCES returns(id),
+    order_id BIGINT NOT NULL REFERENCES orders(id),
+    payment_id BIGINT REFERENCES payments(id),
+    kind TEXT NOT NULL CHECK (kind IN ('full', 'partial')),
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'completed', 'failed')),
+    amount BIGINT NOT NULL CHECK (amount >= 0),
+    currency TEXT NOT NULL,
+    failure_message TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_refunds_order_id ON refunds(order_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE IF EXISTS refunds;
+DROP TABLE IF EXISTS return_items;
+DROP TABLE IF EXISTS returns;
+
+ALTER TABLE orders
+DROP COLUMN refunded_total;
+
+ALTER TABLE order_items
+DROP COLUMN refunded_amount,
+DROP COLUMN returned_quantity;
+-- +goose StatementEnd
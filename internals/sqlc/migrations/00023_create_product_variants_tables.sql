@@ -0,0 +1,70 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+-- the dimensions variants differ in, such as size or colour, shared by every product
+CREATE TABLE IF NOT EXISTS option_types (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE CHECK (name ~ '^[a-z0-9]+(_[a-z0-9]+)*$'),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- a purchasable SKU of a product. price overrides products.price in products.currency when set,
+-- products with variants are sold and stocked per variant
+CREATE TABLE IF NOT EXISTS product_variants (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    sku TEXT NOT NULL UNIQUE CHECK (sku <> ''),
+    price BIGINT CHECK (price > 0),
+    stock INTEGER NOT NULL DEFAULT 0 CHECK (stock >= 0),
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_variants_product_id ON product_variants(product_id, position, id);
+
+CREATE TABLE IF NOT EXISTS variant_option_values (
+    variant_id BIGINT NOT NULL REFERENCES product_variants(id) ON DELETE CASCADE,
+    option_type_id BIGINT NOT NULL REFERENCES option_types(id),
+    value TEXT NOT NULL CHECK (value <> ''),
+    PRIMARY KEY (variant_id, option_type_id)
+);
+
+-- the variant an order line, reservation or cart line is for, NULL for products without variants
+ALTER TABLE order_items
+ADD COLUMN variant_id BIGINT REFERENCES product_variants(id) ON DELETE SET NULL,
+ADD COLUMN sku TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE reservations
+ADD COLUMN variant_id BIGINT REFERENCES product_variants(id);
+
+CREATE INDEX IF NOT EXISTS idx_reservations_active_variant_id ON reservations(variant_id) WHERE status = 'active';
+
+ALTER TABLE cart_items
+ADD COLUMN variant_id BIGINT REFERENCES product_variants(id) ON DELETE CASCADE;
+
+ALTER TABLE cart_items DROP CONSTRAINT IF EXISTS cart_items_cart_id_product_id_key;
+ALTER TABLE cart_items
+ADD CONSTRAINT cart_items_cart_id_product_id_variant_id_key UNIQUE NULLS NOT DISTINCT (cart_id, product_id, variant_id);
+
+-- movements of a variant's stock, stock_after is the variant's stock. no foreign key, like product_id
+ALTER TABLE inventory_movements
+ADD COLUMN variant_id BIGINT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE inventory_movements DROP COLUMN IF EXISTS variant_id;
+DELETE FROM cart_items WHERE variant_id IS NOT NULL;
+ALTER TABLE cart_items DROP CONSTRAINT IF EXISTS cart_items_cart_id_product_id_variant_id_key;
+ALTER TABLE cart_items DROP COLUMN IF EXISTS variant_id;
+ALTER TABLE cart_items ADD CONSTRAINT cart_items_cart_id_product_id_key UNIQUE (cart_id, product_id);
+ALTER TABLE reservations DROP COLUMN IF EXISTS variant_id;
+ALTER TABLE order_items DROP COLUMN IF EXISTS sku;
+ALTER TABLE order_items DROP COLUMN IF EXISTS variant_id;
+DROP TABLE IF EXISTS variant_option_values;
+DROP TABLE IF EXISTS product_variants;
+DROP TABLE IF EXISTS option_types;
+-- +goose StatementEnd
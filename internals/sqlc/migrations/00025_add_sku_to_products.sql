@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+-- the supplier's code for a product, bulk imports match on it before falling back to the name
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS sku TEXT UNIQUE CHECK (sku <> '');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE products DROP COLUMN IF EXISTS sku;
+-- +goose StatementEnd
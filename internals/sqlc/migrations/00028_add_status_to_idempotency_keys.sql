@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+-- a request claims its key with an in_progress row before running and completes it with the
+-- response. an in_progress row expires after a short lease so a crashed request frees its key
+ALTER TABLE idempotency_keys
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'completed' CHECK (status IN ('in_progress', 'completed'));
+
+ALTER TABLE idempotency_keys ALTER COLUMN status_code SET DEFAULT 0;
+ALTER TABLE idempotency_keys ALTER COLUMN response_body SET DEFAULT ''::bytea;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DELETE FROM idempotency_keys WHERE status = 'in_progress';
+ALTER TABLE idempotency_keys ALTER COLUMN response_body DROP DEFAULT;
+ALTER TABLE idempotency_keys ALTER COLUMN status_code DROP DEFAULT;
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS status;
+-- +goose StatementEnd
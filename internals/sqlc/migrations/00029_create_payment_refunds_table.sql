@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+-- whether the payment was to be captured right away, kept so an interrupted attempt can be resumed
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS capture BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- every refund sent to the gateway. the row is written before the gateway is called, so a refund
+-- the database has not heard back about stays pending and is sent again with the same
+-- idempotency key. reference is the caller's own key, e.g. the return it refunds
+CREATE TABLE IF NOT EXISTS payment_refunds (
+    id BIGSERIAL PRIMARY KEY,
+    payment_id BIGINT NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+    reference TEXT NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    actor TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    failure_message TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (payment_id, reference)
+);
+
+-- refunds made before they were tracked, so the refunds of a payment add up to its refunded amount
+INSERT INTO payment_refunds (payment_id, reference, amount, currency, status, actor, note)
+SELECT id, 'refunded-before-tracking', refunded_amount, currency, 'succeeded', 'system', ''
+FROM payments
+WHERE refunded_amount > 0;
+
+CREATE INDEX IF NOT EXISTS idx_payment_refunds_pending ON payment_refunds(updated_at) WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP TABLE IF EXISTS payment_refunds;
+ALTER TABLE payments DROP COLUMN IF EXISTS capture;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+-- the payment refund a return is sent as, written with payment_id before the gateway is called
+-- so a retry looks the refund up on that payment instead of making it again
+ALTER TABLE refunds ADD COLUMN IF NOT EXISTS payment_reference TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE refunds DROP COLUMN IF EXISTS payment_reference;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+-- when the webhook deliveries of an event were queued. the dispatcher queues them in a pass of
+-- its own, so a webhook problem never holds back or repeats publishing to the broker. events
+-- published so far had their deliveries queued along with it
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS webhooks_queued_at TIMESTAMP;
+UPDATE outbox SET webhooks_queued_at = published_at WHERE published_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_webhooks_unqueued ON outbox(id) WHERE webhooks_queued_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+DROP INDEX IF EXISTS idx_outbox_webhooks_unqueued;
+ALTER TABLE outbox DROP COLUMN IF EXISTS webhooks_queued_at;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+-- committed and released reservations are kept, they must not stop a variant from being deleted.
+-- deleting a variant with active reservations is refused by the service
+ALTER TABLE reservations DROP CONSTRAINT IF EXISTS reservations_variant_id_fkey;
+ALTER TABLE reservations
+ADD CONSTRAINT reservations_variant_id_fkey FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE reservations DROP CONSTRAINT IF EXISTS reservations_variant_id_fkey;
+ALTER TABLE reservations
+ADD CONSTRAINT reservations_variant_id_fkey FOREIGN KEY (variant_id) REFERENCES product_variants(id);
+-- +goose StatementEnd
@@ -0,0 +1,47 @@
+-- name: CreateCart :one
+INSERT INTO carts (customer_ref, expires_at)
+VALUES (sqlc.arg('customer_ref'), NOW() + make_interval(secs => sqlc.arg('ttl_seconds')::int))
+RETURNING *;
+
+-- name: GetCart :one
+SELECT * FROM carts
+WHERE id = $1;
+
+-- name: TouchCart :one
+UPDATE carts
+SET updated_at = NOW(), expires_at = NOW() + make_interval(secs => sqlc.arg('ttl_seconds')::int)
+WHERE id = sqlc.arg('id') AND status = 'open'
+RETURNING *;
+
+-- name: ListCartItems :many
+SELECT * FROM cart_items
+WHERE cart_id = $1
+ORDER BY id;
+
+-- name: AddCartItem :one
+INSERT INTO cart_items (cart_id, product_id, variant_id, quantity)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (cart_id, product_id, variant_id) DO UPDATE
+SET quantity = cart_items.quantity + EXCLUDED.quantity, updated_at = NOW()
+RETURNING *;
+
+-- name: SetCartItemQuantity :one
+UPDATE cart_items
+SET quantity = $1, updated_at = NOW()
+WHERE cart_id = $2 AND product_id = $3 AND variant_id IS NOT DISTINCT FROM $4
+RETURNING *;
+
+-- name: RemoveCartItem :execrows
+DELETE FROM cart_items
+WHERE cart_id = $1 AND product_id = $2 AND variant_id IS NOT DISTINCT FROM $3;
+
+-- name: MarkCartCheckedOut :one
+UPDATE carts
+SET status = 'checked_out', order_id = sqlc.arg('order_id'), updated_at = NOW()
+WHERE id = sqlc.arg('id') AND status = 'open' AND updated_at = sqlc.arg('updated_at')
+RETURNING *;
+
+-- name: ExpireCarts :execrows
+UPDATE carts
+SET status = 'expired', updated_at = NOW()
+WHERE status = 'open' AND expires_at <= NOW();
@@ -0,0 +1,72 @@
+-- name: CreateCategory :one
+INSERT INTO categories (parent_id, name, slug, description, position)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetCategory :one
+SELECT * FROM categories
+WHERE id = $1;
+
+-- name: ListCategories :many
+SELECT * FROM categories
+ORDER BY position, name, id;
+
+-- name: ListChildCategories :many
+SELECT * FROM categories
+WHERE parent_id = $1
+ORDER BY position, name, id;
+
+-- name: ListCategoryAncestors :many
+WITH RECURSIVE ancestors AS (
+    SELECT p.id, p.parent_id, p.name, p.slug, p.description, p.position, p.created_at, p.updated_at, 0 AS depth
+    FROM categories c JOIN categories p ON p.id = c.parent_id
+    WHERE c.id = $1
+    UNION ALL
+    SELECT c.id, c.parent_id, c.name, c.slug, c.description, c.position, c.created_at, c.updated_at, a.depth + 1
+    FROM categories c JOIN ancestors a ON c.id = a.parent_id
+)
+SELECT id, parent_id, name, slug, description, position, created_at, updated_at FROM ancestors
+ORDER BY depth DESC;
+
+-- name: ListCategorySubtreeIDs :many
+WITH RECURSIVE subtree AS (
+    SELECT id FROM categories WHERE id = $1
+    UNION ALL
+    SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+)
+SELECT id FROM subtree;
+
+-- name: LockCategoryTree :exec
+SELECT pg_advisory_xact_lock(hashtext('categories'));
+
+-- name: UpdateCategory :one
+UPDATE categories
+SET name = $1, slug = $2, description = $3, position = $4, updated_at = NOW()
+WHERE id = $5
+RETURNING *;
+
+-- name: MoveCategory :one
+UPDATE categories
+SET parent_id = $1, position = $2, updated_at = NOW()
+WHERE id = $3
+RETURNING *;
+
+-- name: DeleteCategory :execrows
+DELETE FROM categories
+WHERE id = $1;
+
+-- name: AddProductCategory :exec
+INSERT INTO product_categories (product_id, category_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteProductCategories :exec
+DELETE FROM product_categories
+WHERE product_id = $1;
+
+-- name: ListProductCategories :many
+SELECT c.id, c.parent_id, c.name, c.slug, c.description, c.position, c.created_at, c.updated_at
+FROM categories c
+JOIN product_categories pc ON pc.category_id = c.id
+WHERE pc.product_id = $1
+ORDER BY c.position, c.name, c.id;
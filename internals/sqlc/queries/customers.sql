@@ -0,0 +1,36 @@
+-- name: CreateCustomer :one
+INSERT INTO customers (customer_ref, email, name, phone, address_line1, address_line2, city, region, postal_code, country)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: GetCustomerByID :one
+SELECT * FROM customers
+WHERE id = $1 AND is_deleted = false;
+
+-- name: GetCustomerByRef :one
+SELECT * FROM customers
+WHERE customer_ref = $1 AND is_deleted = false;
+
+-- name: ListCustomersPage :many
+SELECT * FROM customers
+WHERE is_deleted = false
+  AND (sqlc.narg('cursor_id')::bigint IS NULL OR id > sqlc.narg('cursor_id')::bigint)
+ORDER BY id
+LIMIT sqlc.arg('page_limit');
+
+-- name: UpdateCustomer :one
+UPDATE customers
+SET email = $1, name = $2, phone = $3, address_line1 = $4, address_line2 = $5,
+    city = $6, region = $7, postal_code = $8, country = $9, updated_at = NOW()
+WHERE id = $10 AND is_deleted = false
+RETURNING *;
+
+-- name: DeleteCustomer :exec
+UPDATE customers
+SET is_deleted = true, updated_at = NOW()
+WHERE id = $1 AND is_deleted = false;
+
+-- name: BackfillCustomersFromOrders :execrows
+INSERT INTO customers (customer_ref)
+SELECT DISTINCT customer_ref FROM orders
+ON CONFLICT (customer_ref) DO NOTHING;
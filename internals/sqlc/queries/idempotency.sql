@@ -0,0 +1,36 @@
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE scope = $1 AND idempotency_key = $2 AND expires_at > NOW();
+
+-- name: ClaimIdempotencyKey :one
+INSERT INTO idempotency_keys (scope, idempotency_key, request_hash, status, expires_at)
+VALUES (
+    sqlc.arg('scope'), sqlc.arg('idempotency_key'), sqlc.arg('request_hash'), 'in_progress',
+    NOW() + make_interval(secs => sqlc.arg('lease_seconds')::int)
+)
+ON CONFLICT (scope, idempotency_key) DO UPDATE
+SET request_hash = EXCLUDED.request_hash,
+    status = 'in_progress',
+    status_code = 0,
+    response_body = ''::bytea,
+    created_at = NOW(),
+    expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= NOW()
+RETURNING *;
+
+-- name: CompleteIdempotencyKey :one
+UPDATE idempotency_keys
+SET status = 'completed',
+    status_code = sqlc.arg('status_code'),
+    response_body = sqlc.arg('response_body'),
+    expires_at = NOW() + make_interval(secs => sqlc.arg('ttl_seconds')::int)
+WHERE id = sqlc.arg('id') AND status = 'in_progress'
+RETURNING *;
+
+-- name: ReleaseIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE id = $1 AND status = 'in_progress';
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE expires_at <= NOW();
@@ -0,0 +1,57 @@
+-- name: CreateProductImage :one
+INSERT INTO product_images (product_id, storage_key, thumbnail_key, content_type, size_bytes, width, height, alt_text, position, is_primary)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: GetProductImage :one
+SELECT * FROM product_images
+WHERE id = $1 AND product_id = $2;
+
+-- name: ListProductImages :many
+SELECT * FROM product_images
+WHERE product_id = $1
+ORDER BY position, id;
+
+-- name: NextProductImagePosition :one
+SELECT COALESCE(MAX(position) + 1, 0)::int AS position
+FROM product_images
+WHERE product_id = $1;
+
+-- name: ProductHasPrimaryImage :one
+SELECT EXISTS (
+    SELECT 1 FROM product_images
+    WHERE product_id = $1 AND is_primary
+);
+
+-- name: ClearPrimaryProductImage :exec
+UPDATE product_images
+SET is_primary = FALSE
+WHERE product_id = $1 AND is_primary;
+
+-- name: UpdateProductImage :one
+UPDATE product_images
+SET alt_text = $1,
+    position = $2,
+    is_primary = $3
+WHERE id = $4
+RETURNING *;
+
+-- name: SetProductImagePosition :exec
+UPDATE product_images
+SET position = $1
+WHERE id = $2;
+
+-- name: PromoteFirstProductImage :exec
+UPDATE product_images
+SET is_primary = TRUE
+WHERE id = (
+    SELECT id FROM product_images
+    WHERE product_id = $1
+    ORDER BY position, id
+    LIMIT 1
+);
+
+-- name: DeleteProductImage :one
+DELETE FROM product_images
+WHERE id = $1 AND product_id = $2
+RETURNING *;
@@ -0,0 +1,33 @@
+-- name: AddInventoryMovement :one
+INSERT INTO inventory_movements (product_id, variant_id, quantity, stock_after, reason, actor, reference_type, reference_id, note)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: ListInventoryMovementsPage :many
+SELECT * FROM inventory_movements
+WHERE product_id = sqlc.arg('product_id')
+  AND (sqlc.narg('cursor_id')::bigint IS NULL OR id < sqlc.narg('cursor_id')::bigint)
+ORDER BY id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: ListStockDrift :many
+SELECT p.id AS product_id, p.stock, COALESCE(SUM(m.quantity), 0)::int AS ledger_stock
+FROM products p
+LEFT JOIN inventory_movements m ON m.product_id = p.id AND m.variant_id IS NULL
+GROUP BY p.id, p.stock
+HAVING p.stock <> COALESCE(SUM(m.quantity), 0)
+ORDER BY p.id;
+
+-- name: ListVariantStockDrift :many
+SELECT v.id AS variant_id, v.product_id, v.stock, COALESCE(SUM(m.quantity), 0)::int AS ledger_stock
+FROM product_variants v
+LEFT JOIN inventory_movements m ON m.variant_id = v.id
+GROUP BY v.id, v.product_id, v.stock
+HAVING v.stock <> COALESCE(SUM(m.quantity), 0)
+ORDER BY v.id;
+
+-- name: SetProductStock :one
+UPDATE products
+SET stock = $1, updated_at = NOW()
+WHERE id = $2
+RETURNING *;
@@ -1,11 +1,14 @@
 -- name: CreateOrder :one
-INSERT INTO orders (customer_ref)
-VALUES ($1)
+INSERT INTO orders (
+    customer_ref, total_price, currency, exchange_rate, exchange_rate_base, discount_total, coupon_code,
+    promotion_id, subtotal, tax_total, tax_jurisdiction, prices_include_tax, shipping_method_id, shipping_total
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 RETURNING *;
 
 -- name: AddOrderItem :one
-INSERT INTO order_items (order_id, product_id, quantity, unit_price)
-VALUES ($1, $2, $3, $4)
+INSERT INTO order_items (order_id, product_id, variant_id, sku, quantity, unit_price, currency, discount, tax_class, tax_rate, tax_amount)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 RETURNING *;
 
 -- name: ListOrderItems :many
@@ -42,4 +45,57 @@ WHERE id = $1 AND is_deleted = false;
 -- name: DeleteOrderItemsByOrderID :exec
 UPDATE order_items
 SET is_deleted = true
-WHERE order_id = $1 AND is_deleted = false;
\ No newline at end of file
+WHERE order_id = $1 AND is_deleted = false;
+
+-- name: ListOrdersPage :many
+SELECT * FROM orders
+WHERE is_deleted = false
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::bigint)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: ListOrdersByCustomerRefPage :many
+SELECT * FROM orders
+WHERE customer_ref = sqlc.arg('customer_ref') AND is_deleted = false
+  AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::bigint)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: GetOrderForUpdate :one
+SELECT * FROM orders
+WHERE id = $1 AND is_deleted = false
+FOR UPDATE;
+
+-- name: UpdateOrderStatus :one
+UPDATE orders
+SET status = sqlc.arg('to_status')
+WHERE id = sqlc.arg('id') AND status = sqlc.arg('from_status') AND is_deleted = false
+RETURNING *;
+
+-- name: AddOrderStatusHistory :one
+INSERT INTO order_status_history (order_id, from_status, to_status, note)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: ListOrderStatusHistory :many
+SELECT * FROM order_status_history
+WHERE order_id = $1
+ORDER BY created_at, id;
+
+-- name: CancelOrder :one
+UPDATE orders
+SET status = 'cancelled', cancelled_at = NOW(), cancelled_by = $1, cancel_reason = $2
+WHERE id = $3 AND status <> 'cancelled' AND is_deleted = false
+RETURNING *;
+
+-- name: OrderReachedStatus :one
+SELECT EXISTS (
+    SELECT 1 FROM order_status_history
+    WHERE order_id = $1 AND to_status = $2
+) AS reached;
@@ -0,0 +1,52 @@
+-- name: AddOutboxEvent :exec
+INSERT INTO outbox (event_type, aggregate_type, aggregate_id, payload)
+VALUES ($1, $2, $3, $4);
+
+-- name: CopyOutboxEvents :copyfrom
+INSERT INTO outbox (event_type, aggregate_type, aggregate_id, payload)
+VALUES ($1, $2, $3, $4);
+
+-- name: TryLockOutboxRelay :one
+SELECT pg_try_advisory_xact_lock(hashtextextended('outbox_relay', 0)) AS locked;
+
+-- name: ClaimOutboxEvents :many
+UPDATE outbox
+SET next_attempt_at = NOW() + make_interval(secs => sqlc.arg('lease_seconds')::int)
+WHERE id IN (
+    SELECT o.id FROM outbox o
+    WHERE o.published_at IS NULL
+      AND o.next_attempt_at <= NOW()
+      AND NOT EXISTS (
+        SELECT 1 FROM outbox b
+        WHERE b.published_at IS NULL
+          AND b.aggregate_type = o.aggregate_type
+          AND b.aggregate_id = o.aggregate_id
+          AND b.id < o.id
+          AND b.next_attempt_at > NOW()
+      )
+    ORDER BY o.id
+    LIMIT sqlc.arg('limit')
+)
+RETURNING *;
+
+-- name: MarkOutboxEventPublished :exec
+UPDATE outbox
+SET published_at = NOW(), last_error = ''
+WHERE id = $1;
+
+-- name: ReleaseOutboxEvents :exec
+UPDATE outbox
+SET next_attempt_at = NOW()
+WHERE id = ANY(sqlc.arg('ids')::bigint[]) AND published_at IS NULL;
+
+-- name: RecordOutboxFailure :exec
+UPDATE outbox
+SET attempts = attempts + 1,
+    last_error = sqlc.arg('last_error'),
+    next_attempt_at = NOW() + make_interval(secs => sqlc.arg('retry_seconds')::int)
+WHERE id = sqlc.arg('id');
+
+-- name: DeletePublishedOutboxEvents :execrows
+DELETE FROM outbox
+WHERE published_at < NOW() - make_interval(secs => sqlc.arg('retention_seconds')::int)
+  AND webhooks_queued_at IS NOT NULL;
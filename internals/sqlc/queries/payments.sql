@@ -0,0 +1,142 @@
+-- name: CreatePayment :one
+INSERT INTO payments (order_id, gateway, amount, currency, payment_method, capture)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetPayment :one
+SELECT * FROM payments
+WHERE id = $1;
+
+-- name: GetPaymentForUpdate :one
+SELECT * FROM payments
+WHERE id = $1
+FOR UPDATE;
+
+-- name: GetPaymentByGatewayRefForUpdate :one
+SELECT * FROM payments
+WHERE gateway = $1 AND gateway_ref = $2
+FOR UPDATE;
+
+-- name: ListOrderPayments :many
+SELECT * FROM payments
+WHERE order_id = $1
+ORDER BY id;
+
+-- name: CountOpenPayments :one
+SELECT COUNT(*) FROM payments
+WHERE order_id = $1
+  AND status IN ('pending', 'authorized', 'captured', 'partially_refunded');
+
+-- name: SetPaymentGatewayRef :one
+UPDATE payments
+SET gateway_ref = $1, updated_at = NOW()
+WHERE id = $2
+RETURNING *;
+
+-- name: UpdatePaymentStatus :one
+UPDATE payments
+SET status = sqlc.arg('to_status'),
+    refunded_amount = sqlc.arg('refunded_amount'),
+    failure_code = sqlc.narg('failure_code'),
+    failure_message = sqlc.narg('failure_message'),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id') AND status = sqlc.arg('from_status')
+RETURNING *;
+
+-- name: AddPaymentStatusHistory :one
+INSERT INTO payment_status_history (payment_id, from_status, to_status, amount, actor, note)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: ListPaymentStatusHistory :many
+SELECT * FROM payment_status_history
+WHERE payment_id = $1
+ORDER BY id;
+
+-- name: RecordPaymentWebhookEvent :execrows
+INSERT INTO payment_webhook_events (gateway, event_id, event_type)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: GetCapturedOrderPayment :one
+SELECT * FROM payments
+WHERE order_id = $1 AND status IN ('captured', 'partially_refunded')
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: ClaimStalePendingPayments :many
+UPDATE payments
+SET updated_at = NOW()
+WHERE id IN (
+    SELECT id FROM payments
+    WHERE status = 'pending' AND gateway_ref IS NULL
+      AND updated_at <= NOW() - make_interval(secs => sqlc.arg('older_than_seconds')::int)
+    ORDER BY id
+    LIMIT sqlc.arg('limit')
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: ListPaymentsOutOfSync :many
+SELECT p.* FROM payments p
+JOIN orders o ON o.id = p.order_id
+WHERE (p.status = 'captured' AND o.status = 'pending')
+   OR (p.status = 'refunded' AND o.status IN ('paid', 'fulfilled', 'shipped', 'delivered'))
+   OR (p.status = 'authorized' AND o.status = 'cancelled')
+   OR (p.status IN ('captured', 'partially_refunded') AND o.status = 'cancelled'
+       AND p.refunded_amount < p.amount
+       AND NOT EXISTS (
+           SELECT 1 FROM order_status_history h
+           WHERE h.order_id = o.id AND h.to_status = 'paid'
+       )
+       AND NOT EXISTS (
+           SELECT 1 FROM payment_refunds r
+           WHERE r.payment_id = p.id AND r.status = 'pending'
+       ))
+ORDER BY p.id
+LIMIT $1;
+
+-- name: CreatePaymentRefund :one
+INSERT INTO payment_refunds (payment_id, reference, amount, currency, actor, note)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetPaymentRefundByReference :one
+SELECT * FROM payment_refunds
+WHERE payment_id = $1 AND reference = $2;
+
+-- name: GetPaymentRefundForUpdate :one
+SELECT * FROM payment_refunds
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListPaymentRefunds :many
+SELECT * FROM payment_refunds
+WHERE payment_id = $1
+ORDER BY id;
+
+-- name: SumPaymentRefunds :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total
+FROM payment_refunds
+WHERE payment_id = $1 AND status = $2;
+
+-- name: UpdatePaymentRefundStatus :one
+UPDATE payment_refunds
+SET status = sqlc.arg('status'),
+    failure_message = sqlc.narg('failure_message'),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: ClaimStalePaymentRefunds :many
+UPDATE payment_refunds
+SET updated_at = NOW()
+WHERE id IN (
+    SELECT id FROM payment_refunds
+    WHERE status = 'pending'
+      AND updated_at <= NOW() - make_interval(secs => sqlc.arg('older_than_seconds')::int)
+    ORDER BY id
+    LIMIT sqlc.arg('limit')
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
@@ -0,0 +1,39 @@
+-- name: UpsertProductPrice :one
+INSERT INTO product_prices (product_id, currency, amount)
+VALUES ($1, $2, $3)
+ON CONFLICT (product_id, currency)
+DO UPDATE SET amount = EXCLUDED.amount, updated_at = NOW()
+RETURNING *;
+
+-- name: ListProductPrices :many
+SELECT * FROM product_prices
+WHERE product_id = $1
+ORDER BY currency;
+
+-- name: ListProductPricesIn :many
+SELECT * FROM product_prices
+WHERE product_id = ANY(sqlc.arg('product_ids')::bigint[])
+  AND currency = ANY(sqlc.arg('currencies')::text[]);
+
+-- name: DeleteProductPrice :execrows
+DELETE FROM product_prices
+WHERE product_id = $1 AND currency = $2;
+
+-- name: UpsertExchangeRate :one
+INSERT INTO exchange_rates (base_currency, quote_currency, rate, updated_by)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (base_currency, quote_currency)
+DO UPDATE SET rate = EXCLUDED.rate, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+RETURNING *;
+
+-- name: GetExchangeRate :one
+SELECT * FROM exchange_rates
+WHERE base_currency = $1 AND quote_currency = $2;
+
+-- name: ListExchangeRates :many
+SELECT * FROM exchange_rates
+ORDER BY base_currency, quote_currency;
+
+-- name: DeleteExchangeRate :execrows
+DELETE FROM exchange_rates
+WHERE base_currency = $1 AND quote_currency = $2;
@@ -1,6 +1,6 @@
 -- name: CreateProduct :one
-INSERT INTO products (name, description, price, stock)
-VALUES ($1, $2, $3, $4)
+INSERT INTO products (name, description, price, currency, stock, tax_class, weight_grams, length_mm, width_mm, height_mm)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 RETURNING *;
 
 -- name: ListProducts :many
@@ -12,7 +12,7 @@ SELECT * FROM products WHERE id = $1;
 
 -- name: UpdateProductStock :one
 UPDATE products
-SET stock = stock - $1
+SET stock = stock - $1, updated_at = NOW()
 WHERE id = $2 AND stock >= $1
 RETURNING *;
 
@@ -28,8 +28,32 @@ ORDER BY id;
 
 -- name: UpdateProductDetails :one
 UPDATE products
-SET name = $1, description = $2, price = $3, updated_at = NOW()
-WHERE id = $4
+SET name = $1, description = $2, price = $3, currency = $4, tax_class = $5,
+    weight_grams = $6, length_mm = $7, width_mm = $8, height_mm = $9, updated_at = NOW()
+WHERE id = $10
+RETURNING *;
+
+
+-- name: PatchProduct :one
+UPDATE products
+SET name = COALESCE(sqlc.narg('name'), name),
+    description = COALESCE(sqlc.narg('description'), description),
+    price = COALESCE(sqlc.narg('price'), price),
+    currency = COALESCE(sqlc.narg('currency'), currency),
+    tax_class = COALESCE(sqlc.narg('tax_class'), tax_class),
+    weight_grams = COALESCE(sqlc.narg('weight_grams'), weight_grams),
+    length_mm = COALESCE(sqlc.narg('length_mm'), length_mm),
+    width_mm = COALESCE(sqlc.narg('width_mm'), width_mm),
+    height_mm = COALESCE(sqlc.narg('height_mm'), height_mm),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+
+-- name: AdjustProductStock :one
+UPDATE products
+SET stock = stock + sqlc.arg('delta'), updated_at = NOW()
+WHERE id = sqlc.arg('id') AND stock + sqlc.arg('delta') >= 0
 RETURNING *;
 
 
@@ -49,3 +73,20 @@ WHERE name = $1;
 SELECT EXISTS(
     SELECT 1 FROM products WHERE name = $1
 );
+
+
+-- name: SearchProducts :many
+SELECT p.id, p.name, p.description, p.price, p.currency, p.stock, p.created_at, p.updated_at,
+    ts_rank(p.search_vector, q.query)::real AS rank,
+    ts_headline('english', p.name, q.query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true')::text AS name_snippet,
+    ts_headline('english', p.description, q.query, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5')::text AS description_snippet
+FROM products p, to_tsquery('english', sqlc.arg('query')) AS q(query)
+WHERE p.search_vector @@ q.query
+ORDER BY rank DESC, p.id
+LIMIT sqlc.arg('page_limit');
+
+
+-- name: GetProductForUpdate :one
+SELECT * FROM products
+WHERE id = $1
+FOR UPDATE;
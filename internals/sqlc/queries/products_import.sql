@@ -0,0 +1,66 @@
+-- name: NextProductIDs :many
+SELECT nextval(pg_get_serial_sequence('products', 'id'))::bigint AS id
+FROM generate_series(1, sqlc.arg('count')::int);
+
+-- name: CopyProducts :copyfrom
+INSERT INTO products (id, sku, name, description, price, currency, stock, tax_class, weight_grams, length_mm, width_mm, height_mm)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);
+
+-- name: CopyInventoryMovements :copyfrom
+INSERT INTO inventory_movements (product_id, variant_id, quantity, stock_after, reason, actor, reference_type, reference_id, note)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: ListProductsForImport :many
+SELECT * FROM products
+WHERE name = ANY(sqlc.arg('names')::text[]) OR sku = ANY(sqlc.arg('skus')::text[])
+ORDER BY id
+FOR UPDATE;
+
+-- name: ListProductVariantSummaries :many
+SELECT product_id, bool_or(price IS NOT NULL) AS has_own_prices
+FROM product_variants
+WHERE product_id = ANY(sqlc.arg('product_ids')::bigint[])
+GROUP BY product_id;
+
+-- name: ListReservedQuantities :many
+SELECT product_id, COALESCE(SUM(quantity), 0)::int AS reserved
+FROM reservations
+WHERE product_id = ANY(sqlc.arg('product_ids')::bigint[])
+    AND variant_id IS NULL AND status = 'active'
+GROUP BY product_id;
+
+-- name: UpdateImportedProducts :exec
+UPDATE products AS p
+SET sku = u.sku,
+    name = u.name,
+    description = u.description,
+    price = u.price,
+    currency = u.currency,
+    stock = u.stock,
+    tax_class = u.tax_class,
+    weight_grams = u.weight_grams,
+    length_mm = u.length_mm,
+    width_mm = u.width_mm,
+    height_mm = u.height_mm,
+    updated_at = NOW()
+FROM unnest(
+    sqlc.arg('ids')::bigint[],
+    sqlc.arg('skus')::text[],
+    sqlc.arg('names')::text[],
+    sqlc.arg('descriptions')::text[],
+    sqlc.arg('prices')::bigint[],
+    sqlc.arg('currencies')::text[],
+    sqlc.arg('stocks')::int[],
+    sqlc.arg('tax_classes')::text[],
+    sqlc.arg('weights')::int[],
+    sqlc.arg('lengths')::int[],
+    sqlc.arg('widths')::int[],
+    sqlc.arg('heights')::int[]
+) AS u(id, sku, name, description, price, currency, stock, tax_class, weight_grams, length_mm, width_mm, height_mm)
+WHERE p.id = u.id;
+
+-- name: ListProductsAfter :many
+SELECT * FROM products
+WHERE id > $1
+ORDER BY id
+LIMIT $2;
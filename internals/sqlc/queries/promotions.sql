@@ -0,0 +1,73 @@
+-- name: CreatePromotion :one
+INSERT INTO promotions (
+    code, name, kind, percent_off, amount_off, buy_quantity, get_quantity,
+    min_spend, currency, starts_at, ends_at, usage_limit, per_customer_limit
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+RETURNING *;
+
+-- name: AddPromotionProduct :exec
+INSERT INTO promotion_products (promotion_id, product_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: ListPromotionProducts :many
+SELECT product_id FROM promotion_products
+WHERE promotion_id = $1
+ORDER BY product_id;
+
+-- name: GetPromotion :one
+SELECT * FROM promotions
+WHERE id = $1;
+
+-- name: ListPromotionsPage :many
+SELECT * FROM promotions
+WHERE sqlc.narg('cursor_id')::bigint IS NULL OR id > sqlc.narg('cursor_id')::bigint
+ORDER BY id
+LIMIT sqlc.arg('page_limit');
+
+-- name: DeactivatePromotion :one
+UPDATE promotions
+SET is_active = false, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetPromotionByCodeForUpdate :one
+SELECT * FROM promotions
+WHERE code = $1
+FOR UPDATE;
+
+-- name: CountCustomerRedemptions :one
+SELECT COUNT(*) FROM promotion_redemptions
+WHERE promotion_id = $1 AND customer_ref = $2;
+
+-- name: IncrementPromotionUsage :one
+UPDATE promotions
+SET times_used = times_used + 1, updated_at = NOW()
+WHERE id = $1 AND (usage_limit IS NULL OR times_used < usage_limit)
+RETURNING *;
+
+-- name: CreatePromotionRedemption :one
+INSERT INTO promotion_redemptions (promotion_id, order_id, customer_ref, discount, currency)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: AddPromotionCategory :exec
+INSERT INTO promotion_categories (promotion_id, category_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: ListPromotionCategories :many
+SELECT category_id FROM promotion_categories
+WHERE promotion_id = $1
+ORDER BY category_id;
+
+-- name: ListPromotionCategoryProducts :many
+WITH RECURSIVE scope AS (
+    SELECT category_id AS id FROM promotion_categories WHERE promotion_id = sqlc.arg('promotion_id')
+    UNION
+    SELECT c.id FROM categories c JOIN scope s ON c.parent_id = s.id
+)
+SELECT DISTINCT pc.product_id FROM product_categories pc
+JOIN scope s ON s.id = pc.category_id
+WHERE pc.product_id = ANY(sqlc.arg('product_ids')::bigint[]);
@@ -0,0 +1,54 @@
+-- name: CreateReservation :one
+INSERT INTO reservations (product_id, variant_id, order_id, quantity, expires_at)
+VALUES (
+    sqlc.arg('product_id'), sqlc.arg('variant_id'), sqlc.arg('order_id'), sqlc.arg('quantity'),
+    NOW() + make_interval(secs => sqlc.arg('ttl_seconds')::int)
+)
+RETURNING *;
+
+-- name: GetReservedQuantity :one
+SELECT COALESCE(SUM(quantity), 0)::int AS reserved
+FROM reservations
+WHERE product_id = $1 AND variant_id IS NULL AND status = 'active';
+
+-- name: GetVariantReservedQuantity :one
+SELECT COALESCE(SUM(quantity), 0)::int AS reserved
+FROM reservations
+WHERE variant_id = $1 AND status = 'active';
+
+-- name: ListOrderReservations :many
+SELECT * FROM reservations
+WHERE order_id = $1
+ORDER BY id;
+
+-- name: CommitOrderReservations :many
+UPDATE reservations
+SET status = 'committed', updated_at = NOW()
+WHERE order_id = $1 AND status = 'active'
+RETURNING *;
+
+-- name: ReleaseActiveOrderReservations :execrows
+UPDATE reservations
+SET status = 'released', updated_at = NOW()
+WHERE order_id = $1 AND status = 'active';
+
+-- name: ReleaseCommittedOrderReservations :many
+UPDATE reservations
+SET status = 'released', updated_at = NOW()
+WHERE order_id = $1 AND status = 'committed'
+RETURNING *;
+
+-- name: ListOrdersWithExpiredReservations :many
+SELECT DISTINCT r.order_id FROM reservations r
+WHERE r.status = 'active' AND r.expires_at <= NOW()
+  AND NOT EXISTS (
+    SELECT 1 FROM payments p
+    WHERE p.order_id = r.order_id
+      AND (p.status = 'authorized' OR (p.status = 'pending' AND p.created_at > NOW() - INTERVAL '15 minutes'))
+  )
+LIMIT $1;
+
+-- name: ReleaseExpiredOrderReservations :execrows
+UPDATE reservations
+SET status = 'released', updated_at = NOW()
+WHERE order_id = $1 AND status = 'active' AND expires_at <= NOW();
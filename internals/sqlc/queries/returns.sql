@@ -0,0 +1,108 @@
+-- name: CreateReturn :one
+INSERT INTO returns (order_id, customer_ref, reason, currency, requested_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: AddReturnItem :one
+INSERT INTO return_items (return_id, order_item_id, product_id, quantity, currency)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetReturn :one
+SELECT * FROM returns
+WHERE id = $1;
+
+-- name: GetReturnForUpdate :one
+SELECT * FROM returns
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListReturnItems :many
+SELECT * FROM return_items
+WHERE return_id = $1
+ORDER BY id;
+
+-- name: ListOrderReturns :many
+SELECT * FROM returns
+WHERE order_id = $1
+ORDER BY id;
+
+-- name: ListReturnsPage :many
+SELECT * FROM returns
+WHERE (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('cursor_id')::bigint IS NULL OR id < sqlc.narg('cursor_id'))
+ORDER BY id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: ListRequestedReturnQuantities :many
+SELECT ri.order_item_id, SUM(ri.quantity)::bigint AS quantity
+FROM return_items ri
+JOIN returns r ON r.id = ri.return_id
+WHERE r.order_id = $1 AND r.status = 'requested'
+GROUP BY ri.order_item_id;
+
+-- name: ApproveReturn :one
+UPDATE returns
+SET status = 'approved', refund_total = $1, resolved_by = $2, resolution_note = $3, updated_at = NOW()
+WHERE id = $4 AND status = 'requested'
+RETURNING *;
+
+-- name: RejectReturn :one
+UPDATE returns
+SET status = 'rejected', resolved_by = $1, resolution_note = $2, updated_at = NOW()
+WHERE id = $3 AND status = 'requested'
+RETURNING *;
+
+-- name: ReceiveReturn :one
+UPDATE returns
+SET status = 'received', restocked = $1, received_by = $2, received_at = NOW(), updated_at = NOW()
+WHERE id = $3 AND status = 'approved'
+RETURNING *;
+
+-- name: SetReturnItemRefund :exec
+UPDATE return_items
+SET refund_amount = $1
+WHERE id = $2;
+
+-- name: AddOrderItemReturn :one
+UPDATE order_items
+SET returned_quantity = returned_quantity + sqlc.arg('quantity'),
+    refunded_amount = refunded_amount + sqlc.arg('amount')
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: AddOrderRefundedTotal :one
+UPDATE orders
+SET refunded_total = refunded_total + $1
+WHERE id = $2
+RETURNING *;
+
+-- name: CreateRefund :one
+INSERT INTO refunds (return_id, order_id, kind, amount, currency)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetReturnRefund :one
+SELECT * FROM refunds
+WHERE return_id = $1;
+
+-- name: GetReturnRefundForUpdate :one
+SELECT * FROM refunds
+WHERE return_id = $1
+FOR UPDATE;
+
+-- name: UpdateRefundStatus :one
+UPDATE refunds
+SET status = sqlc.arg('status'),
+    failure_message = sqlc.narg('failure_message'),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: SetRefundPayment :one
+UPDATE refunds
+SET payment_id = $1,
+    payment_reference = $2,
+    updated_at = NOW()
+WHERE id = $3
+RETURNING *;
@@ -0,0 +1,47 @@
+-- name: CreateShippingMethod :one
+INSERT INTO shipping_methods (code, name, rate_basis, currency, countries, volumetric_divisor)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: AddShippingRate :one
+INSERT INTO shipping_rates (method_id, min_value, max_value, amount)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetShippingMethod :one
+SELECT * FROM shipping_methods
+WHERE id = $1;
+
+-- name: GetShippingMethodByCode :one
+SELECT * FROM shipping_methods
+WHERE code = $1;
+
+-- name: ListShippingMethods :many
+SELECT * FROM shipping_methods
+ORDER BY id;
+
+-- name: ListActiveShippingMethods :many
+SELECT * FROM shipping_methods
+WHERE is_active = true
+ORDER BY id;
+
+-- name: ListShippingRates :many
+SELECT * FROM shipping_rates
+WHERE method_id = ANY(sqlc.arg('method_ids')::bigint[])
+ORDER BY method_id, min_value;
+
+-- name: DeactivateShippingMethod :one
+UPDATE shipping_methods
+SET is_active = false, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: AddOrderAddress :one
+INSERT INTO order_addresses (order_id, kind, name, phone, address_line1, address_line2, city, region, postal_code, country)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: ListOrderAddresses :many
+SELECT * FROM order_addresses
+WHERE order_id = $1
+ORDER BY kind DESC;
@@ -0,0 +1,18 @@
+-- name: UpsertTaxRate :one
+INSERT INTO tax_rates (jurisdiction, tax_class, rate, name, updated_by)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (jurisdiction, tax_class)
+DO UPDATE SET rate = EXCLUDED.rate, name = EXCLUDED.name, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+RETURNING *;
+
+-- name: ListTaxRates :many
+SELECT * FROM tax_rates
+ORDER BY jurisdiction, tax_class;
+
+-- name: ListTaxRatesIn :many
+SELECT * FROM tax_rates
+WHERE jurisdiction = ANY(sqlc.arg('jurisdictions')::text[]);
+
+-- name: DeleteTaxRate :execrows
+DELETE FROM tax_rates
+WHERE jurisdiction = $1 AND tax_class = $2;
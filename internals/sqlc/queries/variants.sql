@@ -0,0 +1,83 @@
+-- name: CreateOptionType :one
+INSERT INTO option_types (name)
+VALUES ($1)
+RETURNING *;
+
+-- name: ListOptionTypes :many
+SELECT * FROM option_types
+ORDER BY name;
+
+-- name: ListOptionTypesByNames :many
+SELECT * FROM option_types
+WHERE name = ANY(sqlc.arg('names')::text[])
+ORDER BY name;
+
+-- name: DeleteOptionType :execrows
+DELETE FROM option_types
+WHERE id = $1;
+
+-- name: CreateVariant :one
+INSERT INTO product_variants (product_id, sku, price, stock, position)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetVariant :one
+SELECT * FROM product_variants
+WHERE id = $1;
+
+-- name: GetVariantForUpdate :one
+SELECT * FROM product_variants
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListProductVariants :many
+SELECT * FROM product_variants
+WHERE product_id = $1
+ORDER BY position, id;
+
+-- name: ListVariantsByProductIDs :many
+SELECT * FROM product_variants
+WHERE product_id = ANY(sqlc.arg('product_ids')::bigint[])
+ORDER BY product_id, position, id;
+
+-- name: ProductHasVariants :one
+SELECT EXISTS(
+    SELECT 1 FROM product_variants WHERE product_id = $1
+);
+
+-- name: UpdateVariant :one
+UPDATE product_variants
+SET sku = $1, price = $2, position = $3, updated_at = NOW()
+WHERE id = $4
+RETURNING *;
+
+-- name: DeleteVariant :execrows
+DELETE FROM product_variants
+WHERE id = $1;
+
+-- name: AdjustVariantStock :one
+UPDATE product_variants
+SET stock = stock + sqlc.arg('delta'), updated_at = NOW()
+WHERE id = sqlc.arg('id') AND stock + sqlc.arg('delta') >= 0
+RETURNING *;
+
+-- name: SetVariantStock :one
+UPDATE product_variants
+SET stock = $1, updated_at = NOW()
+WHERE id = $2
+RETURNING *;
+
+-- name: AddVariantOptionValue :exec
+INSERT INTO variant_option_values (variant_id, option_type_id, value)
+VALUES ($1, $2, $3);
+
+-- name: DeleteVariantOptionValues :exec
+DELETE FROM variant_option_values
+WHERE variant_id = $1;
+
+-- name: ListVariantOptionValues :many
+SELECT v.variant_id, v.option_type_id, o.name, v.value
+FROM variant_option_values v
+JOIN option_types o ON o.id = v.option_type_id
+WHERE v.variant_id = ANY(sqlc.arg('variant_ids')::bigint[])
+ORDER BY v.variant_id, o.name;
@@ -0,0 +1,108 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (customer_ref, url, events, secret, description)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetWebhookSubscription :one
+SELECT * FROM webhook_subscriptions
+WHERE id = $1;
+
+-- name: ListWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions
+ORDER BY id;
+
+-- name: ListWebhookSubscriptionsByCustomer :many
+SELECT * FROM webhook_subscriptions
+WHERE customer_ref = $1
+ORDER BY id;
+
+-- name: UpdateWebhookSubscription :one
+UPDATE webhook_subscriptions
+SET url = COALESCE(sqlc.narg('url'), url),
+    events = COALESCE(sqlc.narg('events')::text[], events),
+    description = COALESCE(sqlc.narg('description'), description),
+    active = COALESCE(sqlc.narg('active'), active),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions
+WHERE id = $1;
+
+-- name: EnqueueWebhookDeliveries :execrows
+INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+SELECT s.id, sqlc.arg('event_id')::bigint, sqlc.arg('event_type')::text, sqlc.arg('payload')::jsonb
+FROM webhook_subscriptions s
+WHERE s.active
+  AND (s.customer_ref IS NULL OR s.customer_ref = sqlc.narg('customer_ref'))
+  AND (sqlc.arg('event_type')::text = ANY(s.events) OR sqlc.arg('event_group')::text = ANY(s.events) OR '*' = ANY(s.events))
+ON CONFLICT (subscription_id, event_id) DO NOTHING;
+
+-- name: ListUnqueuedOutboxEvents :many
+SELECT * FROM outbox
+WHERE webhooks_queued_at IS NULL
+ORDER BY id
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkOutboxEventsQueued :exec
+UPDATE outbox
+SET webhooks_queued_at = NOW()
+WHERE id = ANY(sqlc.arg('ids')::bigint[]);
+
+-- name: ClaimWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET next_attempt_at = NOW() + make_interval(secs => sqlc.arg('lease_seconds')::int)
+WHERE id IN (
+    SELECT d.id FROM webhook_deliveries d
+    JOIN webhook_subscriptions s ON s.id = d.subscription_id
+    WHERE d.status = 'pending' AND d.next_attempt_at <= NOW() AND s.active
+    ORDER BY d.next_attempt_at, d.id
+    LIMIT sqlc.arg('limit')
+    FOR UPDATE OF d SKIP LOCKED
+)
+RETURNING *;
+
+-- name: AddWebhookDeliveryAttempt :one
+INSERT INTO webhook_delivery_attempts (delivery_id, attempt, status_code, error, response_body, duration_ms)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: MarkWebhookDeliverySucceeded :one
+UPDATE webhook_deliveries
+SET status = 'succeeded', attempts = attempts + 1, last_error = '', delivered_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: MarkWebhookDeliveryFailed :one
+UPDATE webhook_deliveries
+SET attempts = attempts + 1,
+    status = sqlc.arg('status'),
+    last_error = sqlc.arg('last_error'),
+    next_attempt_at = NOW() + make_interval(secs => sqlc.arg('retry_seconds')::int)
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: ListWebhookDeliveriesPage :many
+SELECT * FROM webhook_deliveries
+WHERE subscription_id = sqlc.arg('subscription_id')
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status')::text)
+  AND (sqlc.narg('cursor_id')::bigint IS NULL OR id < sqlc.narg('cursor_id')::bigint)
+ORDER BY id DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries
+WHERE id = $1 AND subscription_id = $2;
+
+-- name: ListWebhookDeliveryAttempts :many
+SELECT * FROM webhook_delivery_attempts
+WHERE delivery_id = $1
+ORDER BY id;
+
+-- name: RedeliverWebhookDelivery :one
+UPDATE webhook_deliveries
+SET status = 'pending', attempts = 0, last_error = '', next_attempt_at = NOW(), delivered_at = NULL
+WHERE id = $1 AND subscription_id = $2
+RETURNING *;
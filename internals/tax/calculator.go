@@ -0,0 +1,160 @@
+package tax
+
+import (
+	"context"
+	"ecomApis/internals/money"
+	"ecomApis/internals/pricing"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+var (
+	jurisdictionPattern = regexp.MustCompile(`^[A-Z]{2}(-[A-Z0-9]{1,3})?$`)
+	classPattern        = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+// TableCalculator is the default TaxCalculator, it only needs the tax_rates table
+type TableCalculator struct {
+	repo   *repo.Queries
+	config Config
+}
+
+func NewTableCalculator(r *repo.Queries, cfg Config) *TableCalculator {
+	if !money.IsValidRoundingMode(cfg.Rounding.Mode) {
+		cfg.Rounding.Mode = money.RoundHalfUp
+	}
+	if cfg.Rounding.Increment <= 0 {
+		cfg.Rounding.Increment = 1
+	}
+	cfg.DefaultJurisdiction = strings.ToUpper(strings.TrimSpace(cfg.DefaultJurisdiction))
+
+	return &TableCalculator{
+		repo:   r,
+		config: cfg,
+	}
+}
+
+// Jurisdiction builds the jurisdiction of an address, "US-CA" when the region is a
+// subdivision code and just the country otherwise. Empty without a country
+func Jurisdiction(country, region string) string {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if country == "" {
+		return ""
+	}
+	if sub := country + "-" + strings.ToUpper(strings.TrimSpace(region)); jurisdictionPattern.MatchString(sub) {
+		return sub
+	}
+	return country
+}
+
+// NormalizeClass validates a product tax class, an empty class is the default one
+func NormalizeClass(class string) (string, error) {
+	class = strings.ToLower(strings.TrimSpace(class))
+	if class == "" {
+		return DefaultClass, nil
+	}
+	if !classPattern.MatchString(class) {
+		return "", &utils.ValidationError{Field: "tax_class", Message: "may only contain lowercase letters, digits and underscores"}
+	}
+	return class, nil
+}
+
+// Calculate taxes every line with the rate of its class in the jurisdiction. A region without
+// its own rate for a class falls back to the country rate, and no rate at all means no tax
+func (c *TableCalculator) Calculate(ctx context.Context, req Request) (Result, error) {
+	jurisdiction := req.Jurisdiction
+	if jurisdiction == "" {
+		jurisdiction = c.config.DefaultJurisdiction
+	}
+
+	result := Result{
+		Jurisdiction:     jurisdiction,
+		PricesIncludeTax: c.config.PricesIncludeTax,
+		Lines:            make([]LineTax, 0, len(req.Lines)),
+		Subtotal:         money.Zero(req.Currency),
+		Tax:              money.Zero(req.Currency),
+		Total:            money.Zero(req.Currency),
+	}
+
+	rates := map[string]*big.Rat{}
+	country, _, hasRegion := strings.Cut(jurisdiction, "-")
+	if jurisdiction != "" {
+		lookup := []string{jurisdiction}
+		if hasRegion {
+			lookup = append(lookup, country)
+		}
+		rows, err := c.repo.ListTaxRatesIn(ctx, lookup)
+		if err != nil {
+			return Result{}, &utils.DatabaseError{Query: "ListTaxRatesIn", Err: err}
+		}
+		// the country rates go in first so the region ones replace them
+		for _, row := range rows {
+			if row.Jurisdiction == country {
+				rates[row.TaxClass] = pricing.RateFromNumeric(row.Rate)
+			}
+		}
+		for _, row := range rows {
+			if row.Jurisdiction != country {
+				rates[row.TaxClass] = pricing.RateFromNumeric(row.Rate)
+			}
+		}
+	}
+
+	for _, line := range req.Lines {
+		rate, ok := rates[line.TaxClass]
+		if !ok || rate == nil {
+			rate = new(big.Rat)
+		}
+
+		lineTax, err := c.lineTax(line.Amount, rate)
+		if err != nil {
+			return Result{}, err
+		}
+		result.Lines = append(result.Lines, lineTax)
+
+		result.Subtotal, err = result.Subtotal.Add(lineTax.Net)
+		if err == nil {
+			result.Tax, err = result.Tax.Add(lineTax.Tax)
+		}
+		if err != nil {
+			return Result{}, &utils.ValidationError{Field: "total", Message: "order total is too large"}
+		}
+	}
+
+	total, err := result.Subtotal.Add(result.Tax)
+	if err != nil {
+		return Result{}, &utils.ValidationError{Field: "total", Message: "order total is too large"}
+	}
+	result.Total = total
+	return result, nil
+}
+
+// lineTax rounds the tax of a single line. With inclusive prices the tax is the part of
+// the amount above amount / (1 + rate), otherwise it is amount * rate on top
+func (c *TableCalculator) lineTax(amount money.Money, rate *big.Rat) (LineTax, error) {
+	if rate.Sign() == 0 || amount.IsZero() {
+		return LineTax{Rate: rate, Net: amount, Tax: money.Zero(amount.Currency)}, nil
+	}
+
+	factor := rate
+	if c.config.PricesIncludeTax {
+		factor = new(big.Rat).Quo(rate, new(big.Rat).Add(big.NewRat(1, 1), rate))
+	}
+
+	tax, err := amount.Convert(amount.Currency, factor, c.config.Rounding)
+	if err != nil {
+		return LineTax{}, &utils.ValidationError{Field: "total", Message: "order total is too large"}
+	}
+
+	net := amount
+	if c.config.PricesIncludeTax {
+		net, err = amount.Sub(tax)
+		if err != nil {
+			return LineTax{}, &utils.ValidationError{Field: "total", Message: "order total is too large"}
+		}
+	}
+	return LineTax{Rate: rate, Net: net, Tax: tax}, nil
+}
@@ -0,0 +1,90 @@
+package tax
+
+import (
+	"ecomApis/internals/money"
+	"math/big"
+	"testing"
+)
+
+func TestLineTax(t *testing.T) {
+	tests := []struct {
+		name      string
+		inclusive bool
+		mode      money.RoundingMode
+		amount    money.Money
+		rate      string
+		net       int64
+		tax       int64
+	}{
+		{name: "exclusive", amount: money.Money{Amount: 1999, Currency: "USD"}, rate: "0.2", net: 1999, tax: 400},
+		{name: "exclusive exact", amount: money.Money{Amount: 1000, Currency: "EUR"}, rate: "0.19", net: 1000, tax: 190},
+		{name: "exclusive half up", mode: money.RoundHalfUp, amount: money.Money{Amount: 1000, Currency: "USD"}, rate: "0.0725", net: 1000, tax: 73},
+		{name: "exclusive half even", mode: money.RoundHalfEven, amount: money.Money{Amount: 1000, Currency: "USD"}, rate: "0.0725", net: 1000, tax: 72},
+		{name: "exclusive down", mode: money.RoundDown, amount: money.Money{Amount: 1000, Currency: "USD"}, rate: "0.0725", net: 1000, tax: 72},
+		{name: "exclusive zero digits", amount: money.Money{Amount: 1999, Currency: "JPY"}, rate: "0.1", net: 1999, tax: 200},
+		{name: "inclusive", inclusive: true, amount: money.Money{Amount: 1999, Currency: "USD"}, rate: "0.2", net: 1666, tax: 333},
+		{name: "inclusive exact", inclusive: true, amount: money.Money{Amount: 1190, Currency: "EUR"}, rate: "0.19", net: 1000, tax: 190},
+		{name: "inclusive up", inclusive: true, mode: money.RoundUp, amount: money.Money{Amount: 1000, Currency: "USD"}, rate: "0.07", net: 934, tax: 66},
+		{name: "inclusive negative", inclusive: true, amount: money.Money{Amount: -1190, Currency: "EUR"}, rate: "0.19", net: -1000, tax: -190},
+		{name: "zero rate", amount: money.Money{Amount: 1999, Currency: "USD"}, rate: "0", net: 1999, tax: 0},
+		{name: "zero rate inclusive", inclusive: true, amount: money.Money{Amount: 1999, Currency: "USD"}, rate: "0", net: 1999, tax: 0},
+		{name: "zero amount", amount: money.Money{Amount: 0, Currency: "USD"}, rate: "0.2", net: 0, tax: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewTableCalculator(nil, Config{
+				PricesIncludeTax: tt.inclusive,
+				Rounding:         money.Rounding{Mode: tt.mode},
+			})
+			rate, ok := new(big.Rat).SetString(tt.rate)
+			if !ok {
+				t.Fatalf("bad rate %s", tt.rate)
+			}
+
+			got, err := c.lineTax(tt.amount, rate)
+			if err != nil {
+				t.Fatalf("lineTax: %v", err)
+			}
+			if got.Net.Amount != tt.net || got.Tax.Amount != tt.tax {
+				t.Errorf("lineTax = net %d tax %d, want net %d tax %d", got.Net.Amount, got.Tax.Amount, tt.net, tt.tax)
+			}
+			if got.Net.Currency != tt.amount.Currency || got.Tax.Currency != tt.amount.Currency {
+				t.Errorf("lineTax currencies = %s and %s, want %s", got.Net.Currency, got.Tax.Currency, tt.amount.Currency)
+			}
+			if got.Rate.Cmp(rate) != 0 {
+				t.Errorf("lineTax rate = %s, want %s", got.Rate.FloatString(4), tt.rate)
+			}
+			if tt.inclusive && got.Net.Amount+got.Tax.Amount != tt.amount.Amount {
+				t.Errorf("inclusive net %d + tax %d does not add up to %d", got.Net.Amount, got.Tax.Amount, tt.amount.Amount)
+			}
+		})
+	}
+}
+
+func TestJurisdiction(t *testing.T) {
+	tests := []struct {
+		country, region, want string
+	}{
+		{"us", "ca", "US-CA"},
+		{" DE ", "", "DE"},
+		{"GB", "Greater London", "GB"},
+		{"", "CA", ""},
+	}
+	for _, tt := range tests {
+		if got := Jurisdiction(tt.country, tt.region); got != tt.want {
+			t.Errorf("Jurisdiction(%q, %q) = %q, want %q", tt.country, tt.region, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeClass(t *testing.T) {
+	if got, err := NormalizeClass(""); err != nil || got != DefaultClass {
+		t.Errorf("NormalizeClass(\"\") = %q, %v, want %q", got, err, DefaultClass)
+	}
+	if got, err := NormalizeClass(" Reduced_Food "); err != nil || got != "reduced_food" {
+		t.Errorf("NormalizeClass = %q, %v, want reduced_food", got, err)
+	}
+	if _, err := NormalizeClass("food-reduced"); err == nil {
+		t.Error("NormalizeClass(food-reduced) accepted a dash")
+	}
+}
@@ -0,0 +1,73 @@
+package tax
+
+import (
+	"ecomApis/internals/auth"
+	"ecomApis/internals/utils"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(s *Service) *Handler {
+	return &Handler{
+		service: s,
+	}
+}
+
+func (h *Handler) ListTaxRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.service.ListTaxRates(r.Context())
+	if err != nil {
+		writeTaxError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, rates)
+}
+
+// SetTaxRate handles PUT /tax-rates/{jurisdiction}/{class}
+func (h *Handler) SetTaxRate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req SetTaxRateRequest
+	err := utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	p, _ := auth.FromContext(ctx)
+	rate, err := h.service.SetTaxRate(ctx, chi.URLParam(r, "jurisdiction"), chi.URLParam(r, "class"), req, p.Subject)
+	if err != nil {
+		writeTaxError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, rate)
+}
+
+func (h *Handler) DeleteTaxRate(w http.ResponseWriter, r *http.Request) {
+	err := h.service.DeleteTaxRate(r.Context(), chi.URLParam(r, "jurisdiction"), chi.URLParam(r, "class"))
+	if err != nil {
+		writeTaxError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+func writeTaxError(w http.ResponseWriter, err error) {
+	switch e := err.(type) {
+	case *utils.ValidationError:
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": e.Error()})
+	case *utils.NotFoundError:
+		utils.WriteJSON(w, http.StatusNotFound, map[string]string{"error": e.Error()})
+	case *utils.DatabaseError:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": e.Error()})
+	default:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,90 @@
+package tax
+
+import (
+	"context"
+	"ecomApis/internals/pricing"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Service manages the tax_rates table the TableCalculator reads
+type Service struct {
+	repo *repo.Queries
+}
+
+func NewService(r *repo.Queries) *Service {
+	return &Service{
+		repo: r,
+	}
+}
+
+func (s *Service) ListTaxRates(ctx context.Context) ([]repo.TaxRate, error) {
+	rates, err := s.repo.ListTaxRates(ctx)
+	if err != nil {
+		return nil, &utils.DatabaseError{Query: "ListTaxRates", Err: err}
+	}
+	if rates == nil {
+		rates = []repo.TaxRate{}
+	}
+	return rates, nil
+}
+
+// SetTaxRate creates or replaces the rate of a tax class in a jurisdiction
+func (s *Service) SetTaxRate(ctx context.Context, jurisdiction, class string, req SetTaxRateRequest, actor string) (repo.TaxRate, error) {
+	jurisdiction, err := normalizeJurisdiction(jurisdiction)
+	if err != nil {
+		return repo.TaxRate{}, err
+	}
+	class, err = NormalizeClass(class)
+	if err != nil {
+		return repo.TaxRate{}, err
+	}
+
+	rate, ok := new(big.Rat).SetString(strings.TrimSpace(req.Rate))
+	if !ok || rate.Sign() < 0 || rate.Cmp(big.NewRat(1, 1)) >= 0 {
+		return repo.TaxRate{}, &utils.ValidationError{Field: "rate", Message: "must be a decimal fraction from 0 up to 1, e.g. 0.2 for 20%"}
+	}
+
+	row, err := s.repo.UpsertTaxRate(ctx, repo.UpsertTaxRateParams{
+		Jurisdiction: jurisdiction,
+		TaxClass:     class,
+		Rate:         pricing.NumericFromRate(rate),
+		Name:         req.Name,
+		UpdatedBy:    actor,
+	})
+	if err != nil {
+		return repo.TaxRate{}, &utils.DatabaseError{Query: "UpsertTaxRate", Err: err}
+	}
+	return row, nil
+}
+
+func (s *Service) DeleteTaxRate(ctx context.Context, jurisdiction, class string) error {
+	jurisdiction = strings.ToUpper(strings.TrimSpace(jurisdiction))
+	class = strings.ToLower(strings.TrimSpace(class))
+
+	deleted, err := s.repo.DeleteTaxRate(ctx, repo.DeleteTaxRateParams{
+		Jurisdiction: jurisdiction,
+		TaxClass:     class,
+	})
+	if err != nil {
+		return &utils.DatabaseError{Query: "DeleteTaxRate", Err: err}
+	}
+	if deleted == 0 {
+		return &utils.NotFoundError{Resource: "TaxRate", ID: jurisdiction + "/" + class}
+	}
+	return nil
+}
+
+func normalizeJurisdiction(jurisdiction string) (string, error) {
+	normalized := strings.ToUpper(strings.TrimSpace(jurisdiction))
+	if !jurisdictionPattern.MatchString(normalized) {
+		return "", &utils.ValidationError{
+			Field:   "jurisdiction",
+			Message: fmt.Sprintf("'%s' is not a country code or a country-region code such as US-CA", jurisdiction),
+		}
+	}
+	return normalized, nil
+}
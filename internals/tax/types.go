@@ -0,0 +1,64 @@
+package tax
+
+import (
+	"context"
+	"ecomApis/internals/money"
+	"math/big"
+)
+
+// DefaultClass is the tax class of products that were not given one
+const DefaultClass = "standard"
+
+// Config controls how the table-driven calculator taxes order lines
+type Config struct {
+	// PricesIncludeTax means catalog prices already contain tax, which is then
+	// taken out of the line instead of added on top
+	PricesIncludeTax bool
+	// Rounding is applied to the tax of every line
+	Rounding money.Rounding
+	// DefaultJurisdiction is used for customers without a country
+	DefaultJurisdiction string
+}
+
+// TaxCalculator works out the tax of an order. The default implementation reads the
+// tax_rates table, an external tax provider can be plugged in by implementing it
+type TaxCalculator interface {
+	Calculate(ctx context.Context, req Request) (Result, error)
+}
+
+// Request is an order to tax, every line amount is in Currency and already discounted
+type Request struct {
+	Jurisdiction string
+	Currency     string
+	Lines        []Line
+}
+
+type Line struct {
+	ProductID int64
+	TaxClass  string
+	Amount    money.Money
+}
+
+// LineTax is the tax of one line, Net is the line amount without tax
+type LineTax struct {
+	Rate *big.Rat
+	Net  money.Money
+	Tax  money.Money
+}
+
+// Result holds the tax of every line in request order and the order totals.
+// Total is what the customer pays, Subtotal + Tax
+type Result struct {
+	Jurisdiction     string
+	PricesIncludeTax bool
+	Lines            []LineTax
+	Subtotal         money.Money
+	Tax              money.Money
+	Total            money.Money
+}
+
+// SetTaxRateRequest takes the rate as a decimal fraction string, "0.2" is 20%
+type SetTaxRateRequest struct {
+	Rate string `json:"rate"`
+	Name string `json:"name"`
+}
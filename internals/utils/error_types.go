@@ -40,6 +40,21 @@ func (e *ValidationError) Error() string {
 	return fmt.Sprintf("validation failed on field '%s': %s", e.Field, e.Message)
 }
 
+// ---------------------
+// State Errors
+// ---------------------
+
+// InvalidTransitionError represents a status change that is not allowed from the current status
+type InvalidTransitionError struct {
+	Resource string
+	From     string
+	To       string
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("%s cannot move from '%s' to '%s'", e.Resource, e.From, e.To)
+}
+
 // ---------------------
 // Authentication / Authorization Errors
 // ---------------------
@@ -87,13 +102,18 @@ func (e *DatabaseError) Unwrap() error {
 // External API / Service Errors
 // ---------------------
 
-// ExternalServiceError represents an error calling an external service
+// ExternalServiceError represents an error calling an external service.
+// Code is the service's own error code when it sent one, e.g. a payment gateway's
 type ExternalServiceError struct {
 	Service string
+	Code    string
 	Err     error
 }
 
 func (e *ExternalServiceError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("external service '%s' error %s: %v", e.Service, e.Code, e.Err)
+	}
 	return fmt.Sprintf("external service '%s' error: %v", e.Service, e.Err)
 }
 
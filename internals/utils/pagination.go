@@ -0,0 +1,77 @@
+package utils
+
+// keyset (cursor) pagination helpers shared by the listing endpoints
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// Page is the response envelope for every paginated listing
+type Page[T any] struct {
+	Data       []T     `json:"data"`
+	NextCursor *string `json:"next_cursor"`
+	Limit      int32   `json:"limit"`
+}
+
+// Cursor points at the last row of the previous page.
+// Value holds the sort column of that row and ID breaks ties between equal values.
+// Sort and Desc record the order the cursor was issued for
+type Cursor struct {
+	Sort  string `json:"s,omitempty"`
+	Desc  bool   `json:"d,omitempty"`
+	Value string `json:"v,omitempty"`
+	ID    int64  `json:"id"`
+}
+
+func EncodeCursor(c Cursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeCursor(s string) (Cursor, error) {
+	var c Cursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, &ValidationError{Field: "cursor", Message: "malformed cursor"}
+	}
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return c, &ValidationError{Field: "cursor", Message: "malformed cursor"}
+	}
+	return c, nil
+}
+
+// ParseLimit reads the limit query param, falling back to the default page size
+func ParseLimit(raw string) (int32, error) {
+	if raw == "" {
+		return DefaultPageLimit, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit <= 0 {
+		return 0, &ValidationError{Field: "limit", Message: "must be a positive integer"}
+	}
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
+	}
+	return int32(limit), nil
+}
+
+// NewPage trims the extra row fetched to detect a following page and builds the envelope.
+// cursorFor is only called for the last row that is returned
+func NewPage[T any](rows []T, limit int32, cursorFor func(T) Cursor) Page[T] {
+	page := Page[T]{Data: rows, Limit: limit}
+	if page.Data == nil {
+		page.Data = []T{}
+	}
+	if int32(len(page.Data)) > limit {
+		page.Data = page.Data[:limit]
+		next := EncodeCursor(cursorFor(page.Data[limit-1]))
+		page.NextCursor = &next
+	}
+	return page
+}
@@ -0,0 +1,298 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// failed deliveries wait RetryBase, then twice as long after each failure, up to this
+const maxRetryDelay = 6 * time.Hour
+
+// how much of a response body is kept in the attempt log
+const maxResponseBody = 1 << 10
+
+const userAgent = "ecomApis-Webhooks/1.0"
+
+// RunDispatcher queues the deliveries of new outbox events and sends due deliveries until ctx
+// is cancelled. It keeps going while batches come back full and waits PollInterval once
+// nothing is due
+func (s *Service) RunDispatcher(ctx context.Context) {
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		queued, queueErr := s.QueueEvents(ctx)
+		if queueErr != nil {
+			slog.Error("failed to queue webhook deliveries", "error", queueErr)
+		}
+
+		full, err := s.DispatchBatch(ctx)
+		if err != nil {
+			slog.Error("failed to dispatch webhook deliveries", "error", err)
+		}
+
+		if (queued && queueErr == nil) || (full && err == nil) {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+				continue
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchBatch sends one batch of due deliveries and reports whether the batch was full.
+// Claiming a delivery pushes its next attempt past the request timeout, so other instances skip
+// it while it is in flight and pick it up again if this one dies before recording the result
+func (s *Service) DispatchBatch(ctx context.Context) (bool, error) {
+	limit := s.config.Concurrency * 4
+	lease := s.config.Timeout + 30*time.Second
+
+	deliveries, err := s.repo.ClaimWebhookDeliveries(ctx, repo.ClaimWebhookDeliveriesParams{
+		LeaseSeconds: int32(lease.Seconds()),
+		Limit:        int32(limit),
+	})
+	if err != nil {
+		return false, &utils.DatabaseError{Query: "ClaimWebhookDeliveries", Err: err}
+	}
+
+	subscriptions := map[int64]repo.WebhookSubscription{}
+	for _, d := range deliveries {
+		if _, ok := subscriptions[d.SubscriptionID]; ok {
+			continue
+		}
+		sub, err := s.repo.GetWebhookSubscription(ctx, d.SubscriptionID)
+		if err != nil {
+			// deleted since the claim, its deliveries went with it
+			if err == pgx.ErrNoRows {
+				continue
+			}
+			return false, &utils.DatabaseError{Query: "GetWebhookSubscription", Err: err}
+		}
+		subscriptions[d.SubscriptionID] = sub
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, s.config.Concurrency)
+	for _, d := range deliveries {
+		sub, ok := subscriptions[d.SubscriptionID]
+		if !ok {
+			continue
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := s.deliver(ctx, sub, d); err != nil {
+				slog.Error("failed to record webhook delivery attempt", "delivery_id", d.ID, "error", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return len(deliveries) == limit, nil
+}
+
+// attempt is the outcome of one request
+type attempt struct {
+	statusCode   pgtype.Int4
+	err          string
+	responseBody string
+	duration     time.Duration
+}
+
+func (a attempt) succeeded() bool {
+	return a.err == ""
+}
+
+// deliver makes one request for a delivery and records how it went
+func (s *Service) deliver(ctx context.Context, sub repo.WebhookSubscription, d repo.WebhookDelivery) error {
+	result := s.send(ctx, sub, d)
+	if ctx.Err() != nil {
+		// shutting down, the lease runs out and the delivery is sent again
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	_, err = qtx.AddWebhookDeliveryAttempt(ctx, repo.AddWebhookDeliveryAttemptParams{
+		DeliveryID:   d.ID,
+		Attempt:      d.Attempts + 1,
+		StatusCode:   result.statusCode,
+		Error:        result.err,
+		ResponseBody: result.responseBody,
+		DurationMs:   int32(result.duration.Milliseconds()),
+	})
+	if err != nil {
+		tx.Rollback(ctx)
+		return &utils.DatabaseError{Query: "AddWebhookDeliveryAttempt", Err: err}
+	}
+
+	if result.succeeded() {
+		_, err = qtx.MarkWebhookDeliverySucceeded(ctx, d.ID)
+		if err != nil {
+			tx.Rollback(ctx)
+			return &utils.DatabaseError{Query: "MarkWebhookDeliverySucceeded", Err: err}
+		}
+	} else {
+		status, delay := s.afterFailure(d.Attempts)
+		updated, err := qtx.MarkWebhookDeliveryFailed(ctx, repo.MarkWebhookDeliveryFailedParams{
+			Status:       status,
+			LastError:    result.err,
+			RetrySeconds: int32(delay.Seconds()),
+			ID:           d.ID,
+		})
+		if err != nil {
+			tx.Rollback(ctx)
+			return &utils.DatabaseError{Query: "MarkWebhookDeliveryFailed", Err: err}
+		}
+		if updated.Status == StatusDead {
+			slog.Warn("webhook delivery is dead",
+				"delivery_id", d.ID,
+				"webhook_id", sub.ID,
+				"event_type", d.EventType,
+				"attempts", updated.Attempts,
+				"error", result.err,
+			)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// send posts the event to the subscription url. Anything but a 2xx response is a failure,
+// redirects are not followed
+func (s *Service) send(ctx context.Context, sub repo.WebhookSubscription, d repo.WebhookDelivery) attempt {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Url, bytes.NewReader(d.Payload))
+	if err != nil {
+		return attempt{err: err.Error()}
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderDeliveryID, strconv.FormatInt(d.ID, 10))
+	req.Header.Set(HeaderEventID, strconv.FormatInt(d.EventID, 10))
+	req.Header.Set(HeaderEventType, d.EventType)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, timestamp, d.Payload))
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return attempt{err: err.Error(), duration: time.Since(start)}
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	result := attempt{
+		statusCode:   pgtype.Int4{Int32: int32(resp.StatusCode), Valid: true},
+		responseBody: strings.ReplaceAll(strings.ToValidUTF8(string(body), ""), "\x00", ""),
+		duration:     time.Since(start),
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		result.err = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	}
+	return result
+}
+
+// Sign returns the X-Webhook-Signature value of a body sent at timestamp. Receivers compute the
+// same over the raw body and the X-Webhook-Timestamp header and compare in constant time
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// afterFailure is what becomes of a delivery that failed after attempts earlier attempts. It
+// is tried again after retryDelay, or dead once it used up MaxAttempts
+func (s *Service) afterFailure(attempts int32) (string, time.Duration) {
+	if int(attempts)+1 >= s.config.MaxAttempts {
+		return StatusDead, 0
+	}
+	return StatusPending, s.retryDelay(attempts)
+}
+
+func (s *Service) retryDelay(attempts int32) time.Duration {
+	delay := s.config.RetryBase
+	for range attempts {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return min(delay, maxRetryDelay)
+}
+
+// newClient builds the client deliveries are sent with. Unless private networks are allowed it
+// refuses to connect to loopback, private, link-local and similar addresses, checked after the
+// name is resolved so a public name pointing inside the network is refused as well
+func newClient(cfg Config) *http.Client {
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivateNetworks {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			ip := addrPort.Addr().Unmap()
+			if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+				ip.IsUnspecified() || ip.IsMulticast() || ip.IsInterfaceLocalMulticast() {
+				return errors.New("webhook urls must not resolve to a private address")
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
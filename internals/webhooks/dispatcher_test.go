@@ -0,0 +1,278 @@
+package webhooks
+
+import (
+	"context"
+	"ecomApis/internals/repo"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	got := Sign("whsec_0123456789abcdef", "1700000000", []byte(`{"id":1}`))
+	want := "v1=22f267bc13c9c3f35f76035954c196f8ad4cf971af76120dcbcbbb84458514d0"
+	if got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+
+	if Sign("whsec_0123456789abcdef", "1700000001", []byte(`{"id":1}`)) == want {
+		t.Error("Sign does not cover the timestamp")
+	}
+	if Sign("another_secret_value", "1700000000", []byte(`{"id":1}`)) == want {
+		t.Error("Sign does not depend on the secret")
+	}
+}
+
+// testService sends to local test servers, which the default client refuses
+func testService(cfg Config) *Service {
+	cfg.AllowPrivateNetworks = true
+	return NewService(nil, nil, cfg)
+}
+
+func testDelivery() repo.WebhookDelivery {
+	return repo.WebhookDelivery{
+		ID:        42,
+		EventID:   7,
+		EventType: "order.created",
+		Payload:   []byte(`{"type":"order.created"}`),
+	}
+}
+
+func TestSendSuccess(t *testing.T) {
+	sub := repo.WebhookSubscription{ID: 1, Secret: "whsec_0123456789abcdef"}
+	d := testDelivery()
+
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	sub.Url = srv.URL
+
+	result := testService(Config{}).send(context.Background(), sub, d)
+	if !result.succeeded() {
+		t.Fatalf("send failed: %s", result.err)
+	}
+	if !result.statusCode.Valid || result.statusCode.Int32 != http.StatusNoContent {
+		t.Errorf("status code = %v, want 204", result.statusCode)
+	}
+
+	if got.Method != http.MethodPost || string(body) != string(d.Payload) {
+		t.Errorf("request = %s %q, want POST %q", got.Method, body, d.Payload)
+	}
+	headers := map[string]string{
+		"Content-Type":   "application/json",
+		"User-Agent":     userAgent,
+		HeaderDeliveryID: "42",
+		HeaderEventID:    "7",
+		HeaderEventType:  "order.created",
+	}
+	for name, want := range headers {
+		if v := got.Header.Get(name); v != want {
+			t.Errorf("header %s = %q, want %q", name, v, want)
+		}
+	}
+
+	timestamp := got.Header.Get(HeaderTimestamp)
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || time.Since(time.Unix(unix, 0)) > time.Minute {
+		t.Errorf("timestamp = %q, want the current unix time", timestamp)
+	}
+	if sig := got.Header.Get(HeaderSignature); sig != Sign(sub.Secret, timestamp, body) {
+		t.Errorf("signature = %q does not verify", sig)
+	}
+}
+
+func TestSendFailures(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus int32
+		wantErr    string
+		wantBody   string
+	}{
+		{
+			name: "server error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+				io.WriteString(w, "boom")
+			},
+			wantStatus: 500,
+			wantErr:    "unexpected status 500",
+			wantBody:   "boom",
+		},
+		{
+			name: "redirect is not followed",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, "/elsewhere", http.StatusFound)
+			},
+			wantStatus: 302,
+			wantErr:    "unexpected status 302",
+		},
+		{
+			name: "long body is cut",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				io.WriteString(w, strings.Repeat("x", 3*maxResponseBody))
+			},
+			wantStatus: 400,
+			wantErr:    "unexpected status 400",
+			wantBody:   strings.Repeat("x", maxResponseBody),
+		},
+		{
+			name: "body is cleaned for the log",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("bad\x00\xffinput"))
+			},
+			wantStatus: 400,
+			wantErr:    "unexpected status 400",
+			wantBody:   "badinput",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+
+			sub := repo.WebhookSubscription{ID: 1, Url: srv.URL, Secret: "whsec_0123456789abcdef"}
+			result := testService(Config{}).send(context.Background(), sub, testDelivery())
+			if result.succeeded() {
+				t.Fatal("send succeeded")
+			}
+			if result.err != tt.wantErr {
+				t.Errorf("error = %q, want %q", result.err, tt.wantErr)
+			}
+			if !result.statusCode.Valid || result.statusCode.Int32 != tt.wantStatus {
+				t.Errorf("status code = %v, want %d", result.statusCode, tt.wantStatus)
+			}
+			if result.responseBody != tt.wantBody {
+				t.Errorf("response body = %q, want %q", result.responseBody, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestSendTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	sub := repo.WebhookSubscription{ID: 1, Url: srv.URL, Secret: "whsec_0123456789abcdef"}
+	result := testService(Config{Timeout: 50 * time.Millisecond}).send(context.Background(), sub, testDelivery())
+	if result.succeeded() || result.statusCode.Valid {
+		t.Fatalf("send = %+v, want a failure without a status code", result)
+	}
+	if !strings.Contains(result.err, "deadline exceeded") {
+		t.Errorf("error = %q, want a timeout", result.err)
+	}
+}
+
+func TestPrivateNetworksRefused(t *testing.T) {
+	var called bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	sub := repo.WebhookSubscription{ID: 1, Url: srv.URL, Secret: "whsec_0123456789abcdef"}
+	result := NewService(nil, nil, Config{}).send(context.Background(), sub, testDelivery())
+	if result.succeeded() || !strings.Contains(result.err, "private address") {
+		t.Errorf("send to %s = %+v, want it refused", srv.URL, result)
+	}
+	if called {
+		t.Error("the loopback server was reached")
+	}
+
+	result = testService(Config{}).send(context.Background(), sub, testDelivery())
+	if !result.succeeded() || !called {
+		t.Errorf("send with private networks allowed = %+v, want it delivered", result)
+	}
+}
+
+func TestNewClientRefusesPrivateAddresses(t *testing.T) {
+	addresses := []string{
+		"127.0.0.1:80",
+		"10.1.2.3:443",
+		"172.16.0.1:443",
+		"192.168.1.1:443",
+		"169.254.169.254:80",
+		"0.0.0.0:80",
+		"224.0.0.1:80",
+		"[::1]:443",
+		"[fe80::1]:443",
+		"[fd00::1]:443",
+		"[::ffff:127.0.0.1]:80",
+	}
+	for _, addr := range addresses {
+		t.Run(addr, func(t *testing.T) {
+			client := newClient(Config{Timeout: time.Second})
+			req, _ := http.NewRequest(http.MethodPost, "http://"+addr+"/hook", nil)
+			_, err := client.Do(req)
+			if err == nil || !strings.Contains(err.Error(), "private address") {
+				t.Errorf("request to %s: err = %v, want it refused before connecting", addr, err)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	s := testService(Config{RetryBase: 30 * time.Second})
+	tests := []struct {
+		attempts int32
+		want     time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{5, 16 * time.Minute},
+		{9, 4*time.Hour + 16*time.Minute},
+		{10, maxRetryDelay},
+		{1000, maxRetryDelay},
+	}
+	for _, tt := range tests {
+		if got := s.retryDelay(tt.attempts); got != tt.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestAfterFailure(t *testing.T) {
+	s := testService(Config{RetryBase: 30 * time.Second, MaxAttempts: 3})
+	tests := []struct {
+		attempts   int32
+		wantStatus string
+		wantDelay  time.Duration
+	}{
+		{0, StatusPending, 30 * time.Second},
+		{1, StatusPending, time.Minute},
+		// the third failure uses up the attempts and the delivery goes to the dead letter queue
+		{2, StatusDead, 0},
+		{5, StatusDead, 0},
+	}
+	for _, tt := range tests {
+		status, delay := s.afterFailure(tt.attempts)
+		if status != tt.wantStatus || delay != tt.wantDelay {
+			t.Errorf("afterFailure(%d) = %s after %v, want %s after %v", tt.attempts, status, delay, tt.wantStatus, tt.wantDelay)
+		}
+	}
+}
+
+func TestEventGroup(t *testing.T) {
+	if got := eventGroup("order.created"); got != "order.*" {
+		t.Errorf("eventGroup(order.created) = %s, want order.*", got)
+	}
+}
@@ -0,0 +1,230 @@
+package webhooks
+
+import (
+	"ecomApis/internals/auth"
+	"ecomApis/internals/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(s *Service) *Handler {
+	return &Handler{
+		service: s,
+	}
+}
+
+// CreateWebhook handles POST /webhooks
+func (h *Handler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req CreateWebhookRequest
+	err := utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	// customers can only subscribe for themselves
+	p, _ := auth.FromContext(r.Context())
+	if req.CustomerRef == "" && p.Role == auth.RoleCustomer {
+		req.CustomerRef = p.CustomerRef
+	}
+	if req.CustomerRef != "" && !p.CanAccessCustomer(req.CustomerRef) {
+		auth.WriteError(w, &utils.AuthorizationError{Action: "create webhook for customer " + req.CustomerRef})
+		return
+	}
+	if req.CustomerRef == "" && !p.IsAdmin() {
+		auth.WriteError(w, &utils.AuthorizationError{Action: "create webhook for every customer"})
+		return
+	}
+
+	webhook, err := h.service.CreateWebhook(r.Context(), req)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, webhook)
+}
+
+// ListWebhooks handles GET /webhooks, customers only see their own
+func (h *Handler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	p, _ := auth.FromContext(r.Context())
+	customerRef := ""
+	if !p.IsAdmin() {
+		if p.CustomerRef == "" {
+			auth.WriteError(w, &utils.AuthorizationError{Action: "list webhooks"})
+			return
+		}
+		customerRef = p.CustomerRef
+	}
+
+	webhooks, err := h.service.ListWebhooks(r.Context(), customerRef)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, webhooks)
+}
+
+func (h *Handler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := h.authorize(w, r, "view")
+	if !ok {
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, webhook)
+}
+
+// UpdateWebhook handles PATCH /webhooks/{id}
+func (h *Handler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := h.authorize(w, r, "update")
+	if !ok {
+		return
+	}
+
+	var req UpdateWebhookRequest
+	err := utils.ParseJSON(r.Body, &req)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	updated, err := h.service.UpdateWebhook(r.Context(), webhook.ID, req)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, updated)
+}
+
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := h.authorize(w, r, "delete")
+	if !ok {
+		return
+	}
+
+	err := h.service.DeleteWebhook(r.Context(), webhook.ID)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusNoContent, nil)
+}
+
+// ListDeliveries handles GET /webhooks/{id}/deliveries?status=, status=dead lists the dead letter queue
+func (h *Handler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := h.authorize(w, r, "view deliveries of")
+	if !ok {
+		return
+	}
+
+	limit, err := utils.ParseLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	page, err := h.service.ListDeliveries(r.Context(), webhook.ID, r.URL.Query().Get("status"), limit, r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, page)
+}
+
+// GetDelivery handles GET /webhooks/{id}/deliveries/{deliveryId}
+func (h *Handler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := h.authorize(w, r, "view deliveries of")
+	if !ok {
+		return
+	}
+
+	deliveryID, err := strconv.ParseInt(chi.URLParam(r, "deliveryId"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid delivery ID"})
+		return
+	}
+
+	details, err := h.service.GetDelivery(r.Context(), webhook.ID, deliveryID)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, details)
+}
+
+// Redeliver handles POST /webhooks/{id}/deliveries/{deliveryId}/redeliver
+func (h *Handler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	webhook, ok := h.authorize(w, r, "redeliver to")
+	if !ok {
+		return
+	}
+
+	deliveryID, err := strconv.ParseInt(chi.URLParam(r, "deliveryId"), 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid delivery ID"})
+		return
+	}
+
+	delivery, err := h.service.Redeliver(r.Context(), webhook.ID, deliveryID)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusAccepted, delivery)
+}
+
+// authorize loads the webhook named in the url and checks the caller may act on it. Customers
+// can only reach their own, the webhooks without a customer belong to admins. On failure the
+// error is already written
+func (h *Handler) authorize(w http.ResponseWriter, r *http.Request, action string) (Webhook, bool) {
+	idParam := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil {
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid webhook ID"})
+		return Webhook{}, false
+	}
+
+	webhook, err := h.service.GetWebhook(r.Context(), id)
+	if err != nil {
+		writeWebhookError(w, err)
+		return Webhook{}, false
+	}
+
+	customerRef := ""
+	if webhook.CustomerRef != nil {
+		customerRef = *webhook.CustomerRef
+	}
+	p, _ := auth.FromContext(r.Context())
+	if !p.CanAccessCustomer(customerRef) {
+		auth.WriteError(w, &utils.AuthorizationError{Action: action + " webhook " + idParam})
+		return Webhook{}, false
+	}
+	return webhook, true
+}
+
+func writeWebhookError(w http.ResponseWriter, err error) {
+	switch e := err.(type) {
+	case *utils.ValidationError:
+		utils.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": e.Error()})
+	case *utils.AuthorizationError:
+		auth.WriteError(w, e)
+	case *utils.NotFoundError:
+		utils.WriteJSON(w, http.StatusNotFound, map[string]string{"error": e.Error()})
+	case *utils.DatabaseError:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": e.Error()})
+	default:
+		utils.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,431 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"ecomApis/internals/outbox"
+	"ecomApis/internals/repo"
+	"ecomApis/internals/utils"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// postgres error codes
+const foreignKeyViolation = "23503"
+
+// secrets chosen by the caller must be at least this long
+const minSecretLength = 16
+
+// how many outbox events are queued at a time
+const queueBatch = 100
+
+type Service struct {
+	repo   *repo.Queries
+	db     *pgxpool.Pool
+	client *http.Client
+	config Config
+}
+
+func NewService(r *repo.Queries, db *pgxpool.Pool, cfg Config) *Service {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 8
+	}
+	if cfg.RetryBase <= 0 {
+		cfg.RetryBase = 30 * time.Second
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 8
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	return &Service{
+		repo:   r,
+		db:     db,
+		client: newClient(cfg),
+		config: cfg,
+	}
+}
+
+func (s *Service) CreateWebhook(ctx context.Context, req CreateWebhookRequest) (CreatedWebhook, error) {
+	customerRef := strings.TrimSpace(req.CustomerRef)
+
+	target, err := validateURL(req.URL)
+	if err != nil {
+		return CreatedWebhook{}, err
+	}
+
+	events, err := normalizeEvents(req.Events, customerRef != "")
+	if err != nil {
+		return CreatedWebhook{}, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		secret, err = newSecret()
+		if err != nil {
+			return CreatedWebhook{}, err
+		}
+	} else if len(secret) < minSecretLength {
+		return CreatedWebhook{}, &utils.ValidationError{
+			Field:   "secret",
+			Message: fmt.Sprintf("must be at least %d characters", minSecretLength),
+		}
+	}
+
+	row, err := s.repo.CreateWebhookSubscription(ctx, repo.CreateWebhookSubscriptionParams{
+		CustomerRef: pgtype.Text{String: customerRef, Valid: customerRef != ""},
+		Url:         target,
+		Events:      events,
+		Secret:      secret,
+		Description: strings.TrimSpace(req.Description),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			return CreatedWebhook{}, &utils.NotFoundError{Resource: "Customer", ID: customerRef}
+		}
+		return CreatedWebhook{}, &utils.DatabaseError{Query: "CreateWebhookSubscription", Err: err}
+	}
+
+	return CreatedWebhook{Webhook: newWebhook(row), Secret: secret}, nil
+}
+
+// ListWebhooks returns the subscriptions of a customer, or every subscription when customerRef is empty
+func (s *Service) ListWebhooks(ctx context.Context, customerRef string) ([]Webhook, error) {
+	var rows []repo.WebhookSubscription
+	var err error
+	if customerRef == "" {
+		rows, err = s.repo.ListWebhookSubscriptions(ctx)
+		if err != nil {
+			return nil, &utils.DatabaseError{Query: "ListWebhookSubscriptions", Err: err}
+		}
+	} else {
+		rows, err = s.repo.ListWebhookSubscriptionsByCustomer(ctx, pgtype.Text{String: customerRef, Valid: true})
+		if err != nil {
+			return nil, &utils.DatabaseError{Query: "ListWebhookSubscriptionsByCustomer", Err: err}
+		}
+	}
+
+	webhooks := make([]Webhook, 0, len(rows))
+	for _, row := range rows {
+		webhooks = append(webhooks, newWebhook(row))
+	}
+	return webhooks, nil
+}
+
+func (s *Service) GetWebhook(ctx context.Context, id int64) (Webhook, error) {
+	row, err := s.repo.GetWebhookSubscription(ctx, id)
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return Webhook{}, &utils.NotFoundError{Resource: "Webhook", ID: strconv.FormatInt(id, 10)}
+		}
+		return Webhook{}, &utils.DatabaseError{Query: "GetWebhookSubscription", Err: err}
+	}
+	return newWebhook(row), nil
+}
+
+// UpdateWebhook changes the url, events, description or active flag of a subscription.
+// Deliveries of an inactive subscription wait until it is active again
+func (s *Service) UpdateWebhook(ctx context.Context, id int64, req UpdateWebhookRequest) (Webhook, error) {
+	current, err := s.GetWebhook(ctx, id)
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	params := repo.UpdateWebhookSubscriptionParams{ID: id}
+
+	if req.URL != nil {
+		target, err := validateURL(*req.URL)
+		if err != nil {
+			return Webhook{}, err
+		}
+		params.Url = pgtype.Text{String: target, Valid: true}
+	}
+
+	if req.Events != nil {
+		params.Events, err = normalizeEvents(req.Events, current.CustomerRef != nil)
+		if err != nil {
+			return Webhook{}, err
+		}
+	}
+
+	if req.Description != nil {
+		params.Description = pgtype.Text{String: strings.TrimSpace(*req.Description), Valid: true}
+	}
+
+	if req.Active != nil {
+		params.Active = pgtype.Bool{Bool: *req.Active, Valid: true}
+	}
+
+	row, err := s.repo.UpdateWebhookSubscription(ctx, params)
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return Webhook{}, &utils.NotFoundError{Resource: "Webhook", ID: strconv.FormatInt(id, 10)}
+		}
+		return Webhook{}, &utils.DatabaseError{Query: "UpdateWebhookSubscription", Err: err}
+	}
+	return newWebhook(row), nil
+}
+
+// DeleteWebhook removes a subscription along with its delivery log
+func (s *Service) DeleteWebhook(ctx context.Context, id int64) error {
+	deleted, err := s.repo.DeleteWebhookSubscription(ctx, id)
+	if err != nil {
+		return &utils.DatabaseError{Query: "DeleteWebhookSubscription", Err: err}
+	}
+	if deleted == 0 {
+		return &utils.NotFoundError{Resource: "Webhook", ID: strconv.FormatInt(id, 10)}
+	}
+	return nil
+}
+
+// ListDeliveries returns the delivery log of a subscription newest first, optionally only the
+// deliveries with one status, e.g. the dead letters
+func (s *Service) ListDeliveries(ctx context.Context, webhookID int64, status string, limit int32, cursor string) (utils.Page[Delivery], error) {
+	params := repo.ListWebhookDeliveriesPageParams{
+		SubscriptionID: webhookID,
+		PageLimit:      limit + 1,
+	}
+
+	if status != "" {
+		if status != StatusPending && status != StatusSucceeded && status != StatusDead {
+			return utils.Page[Delivery]{}, &utils.ValidationError{
+				Field:   "status",
+				Message: fmt.Sprintf("must be %s, %s or %s", StatusPending, StatusSucceeded, StatusDead),
+			}
+		}
+		params.Status = pgtype.Text{String: status, Valid: true}
+	}
+
+	if cursor != "" {
+		c, err := utils.DecodeCursor(cursor)
+		if err != nil {
+			return utils.Page[Delivery]{}, err
+		}
+		params.CursorID = pgtype.Int8{Int64: c.ID, Valid: true}
+	}
+
+	rows, err := s.repo.ListWebhookDeliveriesPage(ctx, params)
+	if err != nil {
+		return utils.Page[Delivery]{}, &utils.DatabaseError{Query: "ListWebhookDeliveriesPage", Err: err}
+	}
+
+	deliveries := make([]Delivery, 0, len(rows))
+	for _, row := range rows {
+		deliveries = append(deliveries, newDelivery(row))
+	}
+	return utils.NewPage(deliveries, limit, func(d Delivery) utils.Cursor {
+		return utils.Cursor{ID: d.ID}
+	}), nil
+}
+
+func (s *Service) GetDelivery(ctx context.Context, webhookID, deliveryID int64) (DeliveryDetails, error) {
+	row, err := s.repo.GetWebhookDelivery(ctx, repo.GetWebhookDeliveryParams{
+		ID:             deliveryID,
+		SubscriptionID: webhookID,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return DeliveryDetails{}, &utils.NotFoundError{Resource: "Delivery", ID: strconv.FormatInt(deliveryID, 10)}
+		}
+		return DeliveryDetails{}, &utils.DatabaseError{Query: "GetWebhookDelivery", Err: err}
+	}
+
+	attempts, err := s.repo.ListWebhookDeliveryAttempts(ctx, deliveryID)
+	if err != nil {
+		return DeliveryDetails{}, &utils.DatabaseError{Query: "ListWebhookDeliveryAttempts", Err: err}
+	}
+	if attempts == nil {
+		attempts = []repo.WebhookDeliveryAttempt{}
+	}
+
+	return DeliveryDetails{
+		Delivery:   newDelivery(row),
+		Payload:    json.RawMessage(row.Payload),
+		AttemptLog: attempts,
+	}, nil
+}
+
+// Redeliver queues a delivery to be sent again right away with a fresh set of attempts,
+// whatever its status. Its earlier attempts stay in the log
+func (s *Service) Redeliver(ctx context.Context, webhookID, deliveryID int64) (Delivery, error) {
+	row, err := s.repo.RedeliverWebhookDelivery(ctx, repo.RedeliverWebhookDeliveryParams{
+		ID:             deliveryID,
+		SubscriptionID: webhookID,
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows || err == sql.ErrNoRows {
+			return Delivery{}, &utils.NotFoundError{Resource: "Delivery", ID: strconv.FormatInt(deliveryID, 10)}
+		}
+		return Delivery{}, &utils.DatabaseError{Query: "RedeliverWebhookDelivery", Err: err}
+	}
+	return newDelivery(row), nil
+}
+
+// QueueEvents queues the deliveries of one batch of outbox events and reports whether the
+// batch was full. It reads the outbox on its own, apart from the relay publishing to the
+// broker, so neither holds the other back. Events are taken in order and skipped while another
+// instance has them, a batch that fails is queued again and adds no delivery twice
+func (s *Service) QueueEvents(ctx context.Context) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return false, fmt.Errorf("begin tx: %w", err)
+	}
+	qtx := s.repo.WithTx(tx)
+
+	events, err := qtx.ListUnqueuedOutboxEvents(ctx, queueBatch)
+	if err != nil {
+		tx.Rollback(ctx)
+		return false, &utils.DatabaseError{Query: "ListUnqueuedOutboxEvents", Err: err}
+	}
+	if len(events) == 0 {
+		tx.Rollback(ctx)
+		return false, nil
+	}
+
+	ids := make([]int64, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+
+		msg, err := outbox.NewMessage(event)
+		if err == nil {
+			err = enqueue(ctx, qtx, msg)
+		}
+		if err != nil {
+			var dbErr *utils.DatabaseError
+			if errors.As(err, &dbErr) {
+				tx.Rollback(ctx)
+				return false, err
+			}
+			// an event that cannot be read never will be, it gets no deliveries
+			slog.Error("failed to queue webhook deliveries", "event_id", event.ID, "type", event.EventType, "error", err)
+		}
+	}
+
+	err = qtx.MarkOutboxEventsQueued(ctx, ids)
+	if err != nil {
+		tx.Rollback(ctx)
+		return false, &utils.DatabaseError{Query: "MarkOutboxEventsQueued", Err: err}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("commit tx: %w", err)
+	}
+	return len(events) == queueBatch, nil
+}
+
+// enqueue queues a delivery of the event for every active subscription that wants it. Order
+// events only go to the subscriptions of the order's customer and to those without a customer
+func enqueue(ctx context.Context, qtx *repo.Queries, msg outbox.Message) error {
+	var customerRef pgtype.Text
+	if msg.AggregateType == outbox.AggregateOrder {
+		var envelope struct {
+			Data struct {
+				Order struct {
+					CustomerRef string `json:"customer_ref"`
+				} `json:"order"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(msg.Body, &envelope); err != nil {
+			return fmt.Errorf("decode %s event %d: %w", msg.Type, msg.ID, err)
+		}
+		ref := envelope.Data.Order.CustomerRef
+		customerRef = pgtype.Text{String: ref, Valid: ref != ""}
+	}
+
+	_, err := qtx.EnqueueWebhookDeliveries(ctx, repo.EnqueueWebhookDeliveriesParams{
+		EventID:     msg.ID,
+		EventType:   msg.Type,
+		Payload:     msg.Body,
+		CustomerRef: customerRef,
+		EventGroup:  eventGroup(msg.Type),
+	})
+	if err != nil {
+		return &utils.DatabaseError{Query: "EnqueueWebhookDeliveries", Err: err}
+	}
+	return nil
+}
+
+// eventGroup is the wildcard that matches every event of the aggregate, e.g. order.*
+func eventGroup(eventType string) string {
+	aggregate, _, _ := strings.Cut(eventType, ".")
+	return aggregate + ".*"
+}
+
+// normalizeEvents checks an event filter. Each entry is an event type, <aggregate>.* or *.
+// Subscriptions of a customer can only have order events
+func normalizeEvents(events []string, customerScoped bool) ([]string, error) {
+	if len(events) == 0 {
+		return nil, &utils.ValidationError{Field: "events", Message: "must list at least one event type"}
+	}
+
+	normalized := make([]string, 0, len(events))
+	for _, e := range events {
+		e = strings.ToLower(strings.TrimSpace(e))
+
+		known := e == "*" || slices.Contains(outbox.EventTypes, e)
+		if !known && strings.HasSuffix(e, ".*") {
+			for _, t := range outbox.EventTypes {
+				if eventGroup(t) == e {
+					known = true
+					break
+				}
+			}
+		}
+		if !known {
+			return nil, &utils.ValidationError{
+				Field:   "events",
+				Message: fmt.Sprintf("unknown event type '%s', use one of %s, <aggregate>.* or *", e, strings.Join(outbox.EventTypes, ", ")),
+			}
+		}
+
+		if customerScoped && !strings.HasPrefix(e, outbox.AggregateOrder+".") {
+			return nil, &utils.ValidationError{
+				Field:   "events",
+				Message: fmt.Sprintf("'%s' is not available to customers, only order events are", e),
+			}
+		}
+
+		if !slices.Contains(normalized, e) {
+			normalized = append(normalized, e)
+		}
+	}
+	return normalized, nil
+}
+
+// validateURL accepts absolute http and https urls
+func validateURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", &utils.ValidationError{Field: "url", Message: "must be an absolute http or https url"}
+	}
+	return u.String(), nil
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,132 @@
+package webhooks
+
+import (
+	"ecomApis/internals/repo"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// delivery statuses
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	// StatusDead deliveries ran out of attempts, they wait in the dead letter queue to be redelivered
+	StatusDead = "dead"
+)
+
+// headers of every delivery
+const (
+	HeaderDeliveryID = "X-Webhook-Delivery-Id"
+	HeaderEventID    = "X-Webhook-Event-Id"
+	HeaderEventType  = "X-Webhook-Event"
+	// HeaderTimestamp is the unix time in seconds the request was signed at
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	// HeaderSignature is "v1=<hex hmac-sha256 of "<timestamp>.<body>" keyed with the secret>"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Config tunes the delivery worker
+type Config struct {
+	// Timeout bounds one request, including reading the response
+	Timeout time.Duration
+	// MaxAttempts is how many requests are made before a delivery is dead
+	MaxAttempts int
+	// RetryBase is the wait after the first failure, it doubles after each one
+	RetryBase time.Duration
+	// Concurrency is how many requests are in flight at once
+	Concurrency int
+	// PollInterval is how long the worker waits when nothing is due
+	PollInterval time.Duration
+	// AllowPrivateNetworks lets urls resolve to loopback and private addresses, for local development
+	AllowPrivateNetworks bool
+}
+
+// CreateWebhookRequest subscribes a url. Customers always subscribe for themselves, admins can
+// name a customer or leave CustomerRef empty to receive every event. Without a secret one is generated
+type CreateWebhookRequest struct {
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Description string   `json:"description"`
+	CustomerRef string   `json:"customer_ref"`
+	Secret      string   `json:"secret"`
+}
+
+// UpdateWebhookRequest only touches the fields that were sent
+type UpdateWebhookRequest struct {
+	URL         *string  `json:"url"`
+	Events      []string `json:"events"`
+	Description *string  `json:"description"`
+	Active      *bool    `json:"active"`
+}
+
+// Webhook is a subscription without its secret
+type Webhook struct {
+	ID          int64            `json:"id"`
+	CustomerRef *string          `json:"customer_ref,omitempty"`
+	URL         string           `json:"url"`
+	Events      []string         `json:"events"`
+	Description string           `json:"description"`
+	Active      bool             `json:"active"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+}
+
+// CreatedWebhook is only returned by the create call, the secret is not shown again
+type CreatedWebhook struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+func newWebhook(row repo.WebhookSubscription) Webhook {
+	w := Webhook{
+		ID:          row.ID,
+		URL:         row.Url,
+		Events:      row.Events,
+		Description: row.Description,
+		Active:      row.Active,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+	if row.CustomerRef.Valid {
+		w.CustomerRef = &row.CustomerRef.String
+	}
+	return w
+}
+
+// Delivery is one event sent to one subscription
+type Delivery struct {
+	ID            int64            `json:"id"`
+	WebhookID     int64            `json:"webhook_id"`
+	EventID       int64            `json:"event_id"`
+	EventType     string           `json:"event_type"`
+	Status        string           `json:"status"`
+	Attempts      int32            `json:"attempts"`
+	NextAttemptAt pgtype.Timestamp `json:"next_attempt_at"`
+	LastError     string           `json:"last_error"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	DeliveredAt   pgtype.Timestamp `json:"delivered_at"`
+}
+
+// DeliveryDetails is a delivery with the body it sends and every request made for it
+type DeliveryDetails struct {
+	Delivery
+	Payload    json.RawMessage               `json:"payload"`
+	AttemptLog []repo.WebhookDeliveryAttempt `json:"attempt_log"`
+}
+
+func newDelivery(row repo.WebhookDelivery) Delivery {
+	return Delivery{
+		ID:            row.ID,
+		WebhookID:     row.SubscriptionID,
+		EventID:       row.EventID,
+		EventType:     row.EventType,
+		Status:        row.Status,
+		Attempts:      row.Attempts,
+		NextAttemptAt: row.NextAttemptAt,
+		LastError:     row.LastError,
+		CreatedAt:     row.CreatedAt,
+		DeliveredAt:   row.DeliveredAt,
+	}
+}